@@ -5,7 +5,9 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
@@ -20,8 +22,39 @@ func main() {
 <br>
 Version: ` + grid.Prefs.VersionInfo())
 
+	// unknown args, such as the -psn_ process id added on macOS, are not
+	// errors: all the args are then taken as files to open, as before
+	fs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	export := fs.String("export", "", "export the svg files given to this file (e.g., out.png), or for several files, to each file with this format extension (e.g., png or pdf), without opening a window, and exit")
+	width := fs.Int("width", 0, "width in pixels of png exports -- 0 to preserve the aspect ratio given the height, or the physical size in px if both are 0")
+	height := fs.Int("height", 0, "height in pixels of png exports -- 0 to preserve the aspect ratio given the width")
+	args := os.Args[1:]
+	switch err := fs.Parse(args); {
+	case err == flag.ErrHelp:
+		fs.SetOutput(os.Stderr)
+		fs.Usage()
+		os.Exit(0)
+	case err == nil:
+		args = fs.Args()
+	case *export != "":
+		fmt.Fprintln(os.Stderr, "grid:", err)
+		os.Exit(2)
+	}
+
 	grid.InitPrefs()
 
+	if *export != "" {
+		err := grid.BatchExport(args, *export, *width, *height, func(outfile string) {
+			fmt.Println("exported:", outfile)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "grid: export:", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	/*
 			pdir := oswin.TheApp.AppDataDir()
 			pnm := filepath.Join(pdir, "grid.log")
@@ -48,8 +81,8 @@ Version: ` + grid.Prefs.VersionInfo())
 	var fnms []string
 	if len(ofs) > 0 {
 		fnms = ofs
-	} else if len(os.Args) > 1 {
-		fnms = os.Args[1:]
+	} else if len(args) > 0 {
+		fnms = args
 	}
 
 	if len(fnms) == 0 {
@@ -7,6 +7,10 @@ package grid
 // ActionHelpMap contains a set of help strings for different actions
 // which are the names given e.g., in the ActStart, SaveUndo etc.
 var ActionHelpMap = map[string]string{
-	"Move":    "<b>Alt</b> = move without snapping, <b>Ctrl</b> = constrain to axis with smallest delta",
-	"Reshape": "<b>Alt</b> = rotate, <b>Ctrl</b> = constraint to axis with smallest delta",
+	"Move":        "<b>Alt</b> = move without snapping, <b>Ctrl</b> = constrain to axis with smallest delta",
+	"Reshape":     "<b>Alt</b> = rotate, <b>Ctrl</b> = constraint to axis with smallest delta",
+	"NodeCtrl":    "drag the curve control point, <b>Ctrl</b> = constrain to axis with smallest delta",
+	"GradientAdj": "drag the gradient point, <b>Ctrl</b> = constrain to axis with smallest delta",
+	"NewPencil":   "drawing freehand -- release to make a smooth curve from the points drawn",
+	"Transform":   "scaled, rotated and moved the selection by exact amounts about its center",
 }
@@ -6,6 +6,7 @@ package grid
 
 import (
 	"image"
+	"sort"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/svg"
@@ -28,8 +29,10 @@ var KiT_AlignView = kit.Types.AddType(&AlignView{}, AlignViewProps)
 /////////////////////////////////////////////////////////////////////////
 //  Actions
 
-// AlignAnchorBBox returns the bounding box for given type of align anchor
-// and the anchor node if non-nil
+// AlignAnchorBBox returns the bounding box for given type of align anchor,
+// relative to the view, and the anchor node if non-nil: the first or last
+// selected item, the page of the drawing, or the combined bounding box
+// of the selection
 func (gv *GridView) AlignAnchorBBox(aa AlignAnchors) (image.Rectangle, svg.NodeSVG) {
 	es := &gv.EditState
 	sv := gv.SVG()
@@ -45,8 +48,12 @@ func (gv *GridView) AlignAnchorBBox(aa AlignAnchors) (image.Rectangle, svg.NodeS
 		sl := es.SelectedList(true) // descending
 		an = sl[0]
 		bb = an.AsSVGNode().WinBBox
+	case AlignDrawing:
+		pbb := sv.PageBBox()
+		bb = image.Rectangle{Min: pbb.Min.ToPointFloor(), Max: pbb.Max.ToPointCeil()}
 	case AlignSelectBox:
-		bb = image.Rectangle{Min: es.DragSelCurBBox.Min.ToPointFloor(), Max: es.DragSelCurBBox.Max.ToPointCeil()}
+		es.UpdateSelBBox()
+		bb = image.Rectangle{Min: es.SelBBox.Min.ToPointFloor(), Max: es.SelBBox.Max.ToPointCeil()}
 	}
 	bb = bb.Sub(svoff)
 	return bb, an
@@ -55,7 +62,7 @@ func (gv *GridView) AlignAnchorBBox(aa AlignAnchors) (image.Rectangle, svg.NodeS
 // AlignMin aligns to min coordinate (Left, Top) in bbox
 func (gv *GridView) AlignMin(aa AlignAnchors, dim mat32.Dims, act string) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
@@ -65,7 +72,7 @@ func (gv *GridView) AlignMin(aa AlignAnchors, dim mat32.Dims, act string) {
 	sc := mat32.V2(1, 1)
 	odim := mat32.OtherDim(dim)
 	for sn := range es.Selected {
-		if sn == an {
+		if sn == an || NodeIsLocked(sn) {
 			continue
 		}
 		sng := sn.AsSVGNode()
@@ -80,7 +87,7 @@ func (gv *GridView) AlignMin(aa AlignAnchors, dim mat32.Dims, act string) {
 
 func (gv *GridView) AlignMinAnchor(aa AlignAnchors, dim mat32.Dims, act string) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
@@ -90,7 +97,7 @@ func (gv *GridView) AlignMinAnchor(aa AlignAnchors, dim mat32.Dims, act string)
 	sc := mat32.V2(1, 1)
 	odim := mat32.OtherDim(dim)
 	for sn := range es.Selected {
-		if sn == an {
+		if sn == an || NodeIsLocked(sn) {
 			continue
 		}
 		sng := sn.AsSVGNode()
@@ -105,7 +112,7 @@ func (gv *GridView) AlignMinAnchor(aa AlignAnchors, dim mat32.Dims, act string)
 
 func (gv *GridView) AlignMax(aa AlignAnchors, dim mat32.Dims, act string) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
@@ -115,7 +122,7 @@ func (gv *GridView) AlignMax(aa AlignAnchors, dim mat32.Dims, act string) {
 	sc := mat32.V2(1, 1)
 	odim := mat32.OtherDim(dim)
 	for sn := range es.Selected {
-		if sn == an {
+		if sn == an || NodeIsLocked(sn) {
 			continue
 		}
 		sng := sn.AsSVGNode()
@@ -130,7 +137,7 @@ func (gv *GridView) AlignMax(aa AlignAnchors, dim mat32.Dims, act string) {
 
 func (gv *GridView) AlignMaxAnchor(aa AlignAnchors, dim mat32.Dims, act string) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
@@ -140,7 +147,7 @@ func (gv *GridView) AlignMaxAnchor(aa AlignAnchors, dim mat32.Dims, act string)
 	sc := mat32.V2(1, 1)
 	odim := mat32.OtherDim(dim)
 	for sn := range es.Selected {
-		if sn == an {
+		if sn == an || NodeIsLocked(sn) {
 			continue
 		}
 		sng := sn.AsSVGNode()
@@ -155,7 +162,7 @@ func (gv *GridView) AlignMaxAnchor(aa AlignAnchors, dim mat32.Dims, act string)
 
 func (gv *GridView) AlignCenter(aa AlignAnchors, dim mat32.Dims, act string) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
@@ -166,7 +173,7 @@ func (gv *GridView) AlignCenter(aa AlignAnchors, dim mat32.Dims, act string) {
 	sc := mat32.V2(1, 1)
 	odim := mat32.OtherDim(dim)
 	for sn := range es.Selected {
-		if sn == an {
+		if sn == an || NodeIsLocked(sn) {
 			continue
 		}
 		sng := sn.AsSVGNode()
@@ -180,8 +187,142 @@ func (gv *GridView) AlignCenter(aa AlignAnchors, dim mat32.Dims, act string) {
 	gv.ChangeMade()
 }
 
+// AlignLeft aligns the left edges of the selected items to the left
+// edge of given anchor
+func (gv *GridView) AlignLeft(aa AlignAnchors) {
+	gv.AlignMin(aa, mat32.X, "AlignLeft")
+}
+
+// AlignRight aligns the right edges of the selected items to the right
+// edge of given anchor
+func (gv *GridView) AlignRight(aa AlignAnchors) {
+	gv.AlignMax(aa, mat32.X, "AlignRight")
+}
+
+// AlignCenterHoriz aligns the horizontal centers of the selected items
+// to the horizontal center of given anchor
+func (gv *GridView) AlignCenterHoriz(aa AlignAnchors) {
+	gv.AlignCenter(aa, mat32.X, "AlignCenterHoriz")
+}
+
+// AlignTop aligns the top edges of the selected items to the top
+// edge of given anchor
+func (gv *GridView) AlignTop(aa AlignAnchors) {
+	gv.AlignMin(aa, mat32.Y, "AlignTop")
+}
+
+// AlignBottom aligns the bottom edges of the selected items to the
+// bottom edge of given anchor
+func (gv *GridView) AlignBottom(aa AlignAnchors) {
+	gv.AlignMax(aa, mat32.Y, "AlignBottom")
+}
+
+// AlignCenterVert aligns the vertical centers of the selected items
+// to the vertical center of given anchor
+func (gv *GridView) AlignCenterVert(aa AlignAnchors) {
+	gv.AlignCenter(aa, mat32.Y, "AlignCenterVert")
+}
+
+// DistributeDeltas returns the amount to move each of given bounding boxes
+// along given dimension to distribute them evenly between the first and
+// last boxes in that dimension, which do not move.  If gaps is true, the
+// gaps between successive boxes are made equal: the total free space
+// (the span from the first min to the last max, minus the sizes of all
+// boxes) is divided among the N-1 gaps.  Otherwise, the centers of the
+// boxes are made equidistant.  These differ when the boxes vary in size.
+// The order of boxes is by their center along the dimension.
+func DistributeDeltas(bbs []mat32.Box2, dim mat32.Dims, gaps bool) []float32 {
+	n := len(bbs)
+	dels := make([]float32, n)
+	if n < 3 {
+		return dels
+	}
+	ctr := func(i int) float32 {
+		return .5 * (bbs[i].Min.Dim(dim) + bbs[i].Max.Dim(dim))
+	}
+	ord := make([]int, n)
+	for i := range ord {
+		ord[i] = i
+	}
+	sort.SliceStable(ord, func(i, j int) bool {
+		return ctr(ord[i]) < ctr(ord[j])
+	})
+	fst := ord[0]
+	lst := ord[n-1]
+	if gaps {
+		tsz := float32(0)
+		for i := range bbs {
+			tsz += bbs[i].Max.Dim(dim) - bbs[i].Min.Dim(dim)
+		}
+		gap := (bbs[lst].Max.Dim(dim) - bbs[fst].Min.Dim(dim) - tsz) / float32(n-1)
+		pos := bbs[fst].Max.Dim(dim) + gap
+		for _, i := range ord[1 : n-1] {
+			dels[i] = pos - bbs[i].Min.Dim(dim)
+			pos += bbs[i].Max.Dim(dim) - bbs[i].Min.Dim(dim) + gap
+		}
+		return dels
+	}
+	st := ctr(fst)
+	inc := (ctr(lst) - st) / float32(n-1)
+	for oi, i := range ord[1 : n-1] {
+		dels[i] = st + float32(oi+1)*inc - ctr(i)
+	}
+	return dels
+}
+
+// Distribute distributes the selected items evenly along given dimension,
+// between the two outermost items, which do not move.  If gaps is true,
+// the gaps between items are made equal, otherwise their centers are made
+// equidistant (see DistributeDeltas).
+func (gv *GridView) Distribute(dim mat32.Dims, gaps bool, act string) {
+	es := &gv.EditState
+	var sl []svg.NodeSVG
+	for _, sn := range es.SelectedList(false) {
+		if !NodeIsLocked(sn) {
+			sl = append(sl, sn)
+		}
+	}
+	if len(sl) < 3 {
+		gv.SetStatus("Distribute: select at least 3 unlocked items")
+		return
+	}
+	sv := gv.SVG()
+	svoff := sv.WinBBox.Min
+	sv.UndoSave(act, es.SelectedNamesString())
+	bbs := make([]mat32.Box2, len(sl))
+	for i, sn := range sl {
+		bbs[i].SetFromRect(sn.AsSVGNode().WinBBox.Sub(svoff))
+	}
+	dels := DistributeDeltas(bbs, dim, gaps)
+	sc := mat32.V2(1, 1)
+	for i, sn := range sl {
+		if dels[i] == 0 {
+			continue
+		}
+		var del mat32.Vec2
+		del.SetDim(dim, dels[i])
+		sn.ApplyDeltaTransform(del, sc, 0, bbs[i].Min)
+	}
+	sv.UpdateView(true)
+	gv.ChangeMade()
+}
+
+// DistributeHoriz distributes 3 or more selected items horizontally,
+// with equal gaps between them, keeping the leftmost and rightmost fixed
+func (gv *GridView) DistributeHoriz() {
+	gv.Distribute(mat32.X, true, "DistribHorizGaps")
+}
+
+// DistributeVert distributes 3 or more selected items vertically,
+// with equal gaps between them, keeping the top and bottom fixed
+func (gv *GridView) DistributeVert() {
+	gv.Distribute(mat32.Y, true, "DistribVertGaps")
+}
+
 // GatherAlignPoints gets all the potential points of alignment for objects not
-// in selection group
+// in selection group, and the edges and center of the page if Prefs.SnapPage.
+// For each, the midpoints of the four edges and the center in X and Y are
+// gathered, as the points for each BBoxPoints type.
 func (sv *SVGView) GatherAlignPoints() {
 	es := sv.EditState()
 	if !es.HasSelected() {
@@ -191,6 +332,20 @@ func (sv *SVGView) GatherAlignPoints() {
 	for ap := BBLeft; ap < BBoxPointsN; ap++ {
 		es.AlignPts[ap] = make([]mat32.Vec2, 0)
 	}
+	if Prefs.SnapPage {
+		pbb := sv.PageBBox()
+		for ap := BBLeft; ap < BBoxPointsN; ap++ {
+			es.AlignPts[ap] = append(es.AlignPts[ap], ap.PointBox(pbb))
+		}
+	}
+	es.BaselinePts = make([]mat32.Vec2, 0)
+	es.SelBaseline = -1
+	for _, sii := range es.SelectedList(false) {
+		if txt, istxt := sii.(*svg.Text); istxt {
+			es.SelBaseline = sv.TextBaseline(txt).Y - es.DragSelStartBBox.Min.Y
+			break
+		}
+	}
 
 	sv.FuncDownMeFirst(0, sv.This(), func(k ki.Ki, level int, d any) bool {
 		if k == sv.This() {
@@ -217,10 +372,31 @@ func (sv *SVGView) GatherAlignPoints() {
 		for ap := BBLeft; ap < BBoxPointsN; ap++ {
 			es.AlignPts[ap] = append(es.AlignPts[ap], ap.PointRect(sg.WinBBox))
 		}
+		if txt, istxt := sii.(*svg.Text); istxt && !txt.IsParText() {
+			es.BaselinePts = append(es.BaselinePts, sv.TextBaseline(txt))
+		}
 		return ki.Continue
 	})
 }
 
+// PageBBox returns the bounding box of the page, in window coordinates
+func (sv *SVGView) PageBBox() mat32.Box2 {
+	vb := sv.ViewBox
+	return mat32.Box2{Min: sv.DocToWin(vb.Min), Max: sv.DocToWin(vb.Min.Add(vb.Size))}
+}
+
+// TextBaseline returns the start of the baseline of the first line of
+// given text element, in window coordinates
+func (sv *SVGView) TextBaseline(txt *svg.Text) mat32.Vec2 {
+	if txt.IsParText() {
+		if kt, ok := txt.Child(0).(*svg.Text); ok {
+			txt = kt
+		}
+	}
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	return txt.ParTransform(true).MulVec2AsPt(txt.Pos).Add(svoff)
+}
+
 ///////////////////////////////////////////////////////////////
 //  AlignView
 
@@ -266,7 +442,7 @@ func (av *AlignView) Config(gv *GridView) {
 	lft.SetProp("#icon", icprops)
 	lft.Tooltip = "align left edges of all selected items"
 	lft.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
-		av.GridView.AlignMin(av.AlignAnchor(), mat32.X, "AlignLeft")
+		av.GridView.AlignLeft(av.AlignAnchor())
 	})
 
 	ctr := gi.AddNewAction(atyp, "center")
@@ -274,7 +450,7 @@ func (av *AlignView) Config(gv *GridView) {
 	ctr.SetProp("#icon", icprops)
 	ctr.Tooltip = "align centers of all selected items"
 	ctr.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
-		av.GridView.AlignCenter(av.AlignAnchor(), mat32.X, "AlignCenter")
+		av.GridView.AlignCenterHoriz(av.AlignAnchor())
 	})
 
 	rgt := gi.AddNewAction(atyp, "right")
@@ -282,7 +458,7 @@ func (av *AlignView) Config(gv *GridView) {
 	rgt.SetProp("#icon", icprops)
 	rgt.Tooltip = "align right edges of all selected items"
 	rgt.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
-		av.GridView.AlignMax(av.AlignAnchor(), mat32.X, "AlignRight")
+		av.GridView.AlignRight(av.AlignAnchor())
 	})
 
 	lta := gi.AddNewAction(atyp, "left-anchor")
@@ -314,7 +490,7 @@ func (av *AlignView) Config(gv *GridView) {
 	top.SetProp("#icon", icprops)
 	top.Tooltip = "align top edges of all selected items"
 	top.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
-		av.GridView.AlignMin(av.AlignAnchor(), mat32.Y, "AlignTop")
+		av.GridView.AlignTop(av.AlignAnchor())
 	})
 
 	mid := gi.AddNewAction(atyp, "middle")
@@ -322,7 +498,7 @@ func (av *AlignView) Config(gv *GridView) {
 	mid.SetProp("#icon", icprops)
 	mid.Tooltip = "align middle vertical point of all selected items"
 	mid.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
-		av.GridView.AlignCenter(av.AlignAnchor(), mat32.Y, "AlignTop")
+		av.GridView.AlignCenterVert(av.AlignAnchor())
 	})
 
 	bot := gi.AddNewAction(atyp, "bottom")
@@ -330,7 +506,7 @@ func (av *AlignView) Config(gv *GridView) {
 	bot.SetProp("#icon", icprops)
 	bot.Tooltip = "align bottom edges of all selected items"
 	bot.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
-		av.GridView.AlignMax(av.AlignAnchor(), mat32.Y, "AlignBottom")
+		av.GridView.AlignBottom(av.AlignAnchor())
 	})
 
 	tpa := gi.AddNewAction(atyp, "top-anchor")
@@ -349,6 +525,41 @@ func (av *AlignView) Config(gv *GridView) {
 		av.GridView.AlignMax(av.AlignAnchor(), mat32.Y, "AlignBaseV")
 	})
 
+	dll := gi.AddNewLayout(av, "distrib-lab", gi.LayoutHoriz)
+	gi.AddNewLabel(dll, "distrib-lab", "<b>Distribute:  </b>")
+
+	dtyp := gi.AddNewLayout(av, "distrib-grid", gi.LayoutGrid)
+	dtyp.SetProp("columns", 2)
+	dtyp.SetProp("spacing", gi.StdDialogVSpaceUnits)
+
+	dhc := gi.AddNewAction(dtyp, "distrib-horiz-centers")
+	dhc.SetText("Horiz Centers")
+	dhc.Tooltip = "distribute selected items horizontally so their centers are equally spaced, between the leftmost and rightmost items"
+	dhc.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
+		av.GridView.Distribute(mat32.X, false, "DistribHorizCenters")
+	})
+
+	dhg := gi.AddNewAction(dtyp, "distrib-horiz-gaps")
+	dhg.SetText("Horiz Gaps")
+	dhg.Tooltip = "distribute selected items horizontally so the gaps between them are equal, between the leftmost and rightmost items"
+	dhg.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
+		av.GridView.DistributeHoriz()
+	})
+
+	dvc := gi.AddNewAction(dtyp, "distrib-vert-centers")
+	dvc.SetText("Vert Centers")
+	dvc.Tooltip = "distribute selected items vertically so their centers are equally spaced, between the top and bottom items"
+	dvc.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
+		av.GridView.Distribute(mat32.Y, false, "DistribVertCenters")
+	})
+
+	dvg := gi.AddNewAction(dtyp, "distrib-vert-gaps")
+	dvg.SetText("Vert Gaps")
+	dvg.Tooltip = "distribute selected items vertically so the gaps between them are equal, between the top and bottom items"
+	dvg.ActionSig.Connect(av.This(), func(recv, send ki.Ki, sig int64, data any) {
+		av.GridView.DistributeVert()
+	})
+
 	gi.AddNewStretch(av, "endstr")
 
 	av.UpdateEnd(updt)
@@ -384,10 +595,18 @@ var AlignViewProps = ki.Props{
 type AlignAnchors int
 
 const (
+	// AlignFirst aligns to the first selected item, which does not move
 	AlignFirst AlignAnchors = iota
+
+	// AlignLast aligns to the last selected item, which does not move
 	AlignLast
+
+	// AlignDrawing aligns to the page of the drawing
 	AlignDrawing
+
+	// AlignSelectBox aligns to the combined bounding box of the selection
 	AlignSelectBox
+
 	AlignAnchorsN
 )
 
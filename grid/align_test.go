@@ -0,0 +1,100 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"testing"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/mat32"
+)
+
+// testBoxes returns boxes with given min, max extents along dim,
+// and 0, 10 along the other dimension
+func testBoxes(dim mat32.Dims, ext ...float32) []mat32.Box2 {
+	bbs := make([]mat32.Box2, len(ext)/2)
+	for i := range bbs {
+		bbs[i].Max.SetDim(mat32.OtherDim(dim), 10)
+		bbs[i].Min.SetDim(dim, ext[2*i])
+		bbs[i].Max.SetDim(dim, ext[2*i+1])
+	}
+	return bbs
+}
+
+func TestDistributeDeltas(t *testing.T) {
+	tests := []struct {
+		name string
+		dim  mat32.Dims
+		ext  []float32 // min, max of each box
+		gaps []float32 // deltas for equal gaps
+		ctrs []float32 // deltas for equal center spacing
+	}{
+		{"mixed sizes", mat32.X, []float32{0, 10, 20, 60, 100, 140}, []float32{0, 15, 0}, []float32{0, 22.5, 0}},
+		{"unordered", mat32.X, []float32{100, 140, 0, 10, 20, 60}, []float32{0, 0, 15}, []float32{0, 0, 22.5}},
+		{"four", mat32.Y, []float32{0, 10, 12, 32, 40, 44, 90, 100}, []float32{0, 16.6667, 27.3333, 0}, []float32{0, 13, 23, 0}},
+		{"same size", mat32.X, []float32{0, 10, 30, 40, 100, 110}, []float32{0, 20, 0}, []float32{0, 20, 0}},
+		{"overlap", mat32.X, []float32{0, 50, 10, 20, 40, 60}, []float32{5, 0, 0}, []float32{7.5, 0, 0}},
+		{"two", mat32.X, []float32{0, 10, 50, 60}, []float32{0, 0}, []float32{0, 0}},
+	}
+	for _, tt := range tests {
+		for _, gaps := range []bool{true, false} {
+			want := tt.ctrs
+			if gaps {
+				want = tt.gaps
+			}
+			dels := DistributeDeltas(testBoxes(tt.dim, tt.ext...), tt.dim, gaps)
+			if len(dels) != len(want) {
+				t.Fatalf("%s: got %d deltas, want %d", tt.name, len(dels), len(want))
+			}
+			for i := range want {
+				if mat32.Abs(dels[i]-want[i]) > 1.0e-3 {
+					t.Errorf("%s, gaps %v: box %d delta = %g, want %g", tt.name, gaps, i, dels[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestDistribute(t *testing.T) {
+	const src = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<rect id="rect1" x="10" y="100" width="20" height="20"/>
+<rect id="rect2" x="50" y="40" width="80" height="20"/>
+<rect id="rect3" x="150" y="200" width="10" height="40"/>
+<rect id="rect4" x="300" y="10" width="100" height="10"/>
+</svg>
+`
+	for _, gaps := range []bool{true, false} {
+		gv := openTestView(t, src)
+		sv := gv.SVG()
+		var sns []svg.NodeSVG
+		for _, id := range []string{"rect1", "rect2", "rect3", "rect4"} {
+			sn := viewNode(t, sv, id)
+			gv.EditState.Select(sn)
+			sns = append(sns, sn)
+		}
+		gv.Distribute(mat32.X, gaps, "Distribute")
+		sv.HeadlessRender()
+		bbs := make([]mat32.Box2, len(sns))
+		for i, sn := range sns {
+			bbs[i].SetFromRect(sn.AsSVGNode().WinBBox)
+		}
+		for i := 1; i < len(bbs)-1; i++ {
+			var d0, d1 float32
+			if gaps {
+				d0 = bbs[i].Min.X - bbs[i-1].Max.X
+				d1 = bbs[i+1].Min.X - bbs[i].Max.X
+			} else {
+				d0 = bbs[i].Center().X - bbs[i-1].Center().X
+				d1 = bbs[i+1].Center().X - bbs[i].Center().X
+			}
+			if mat32.Abs(d0-d1) > 1.5 { // window bboxes are whole pixels
+				t.Errorf("gaps %v: spacing before item %d = %g, after = %g", gaps, i, d0, d1)
+			}
+		}
+		if ur := gv.EditState.UndoMgr.Recs; len(ur) == 0 || ur[len(ur)-1].Action != "Distribute" {
+			t.Errorf("gaps %v: distribute not saved for undo", gaps)
+		}
+	}
+}
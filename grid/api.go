@@ -0,0 +1,233 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// Doc is a drawing opened for editing from Go code without the GUI,
+// e.g., for scripts that batch-edit drawings.  It wraps a headless
+// GridView (see NewHeadlessGridView), and all positions and amounts
+// are in drawing (document) coordinates.  All edits are undoable
+// through the View, as in the GUI.  This is the supported surface for
+// programmatic editing -- the View can be used for anything else.
+type Doc struct {
+
+	// the headless view holding the drawing and edit state
+	View *GridView
+}
+
+// DocViewSize is the size in pixels of the off-screen view used by Doc
+var DocViewSize = image.Point{1024, 1024}
+
+// ErrNodeNotFound is returned by Doc methods when no element has given id
+var ErrNodeNotFound = errors.New("element not found")
+
+// NewDoc returns a new empty drawing of given size
+func NewDoc(sz PhysSize) *Doc {
+	return &Doc{View: NewHeadlessGridView(sz, DocViewSize)}
+}
+
+// ReadDoc returns a drawing read from given svg file contents
+func ReadDoc(b []byte) (*Doc, error) {
+	sz := PhysSize{}
+	sz.Defaults()
+	d := NewDoc(sz)
+	if err := d.View.OpenHeadlessXML(b); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// OpenDoc returns a drawing opened from given svg file
+func OpenDoc(fname string) (*Doc, error) {
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ReadDoc(b)
+	if err != nil {
+		return nil, err
+	}
+	d.View.Filename = gi.FileName(fname)
+	return d, nil
+}
+
+// SVG returns the SVGView holding the drawing
+func (d *Doc) SVG() *SVGView {
+	return d.View.SVG()
+}
+
+// Render updates the element transforms and bounding boxes after
+// changes made directly to the elements -- the Doc methods do this
+// automatically
+func (d *Doc) Render() {
+	d.SVG().HeadlessRender()
+}
+
+// NodeById returns the element with given id, or nil if none
+func (d *Doc) NodeById(id string) svg.NodeSVG {
+	var fn svg.NodeSVG
+	d.SVG().FuncDownMeFirst(0, nil, func(k ki.Ki, level int, data any) bool {
+		if fn != nil {
+			return ki.Break
+		}
+		if sii, ok := k.(svg.NodeSVG); ok && k.Name() == id {
+			fn = sii
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	return fn
+}
+
+// Select selects the elements with given ids, replacing any current
+// selection.  Returns ErrNodeNotFound for an unknown id, in which case
+// the selection is not changed.
+func (d *Doc) Select(ids ...string) error {
+	sel := make([]svg.NodeSVG, len(ids))
+	for i, id := range ids {
+		sel[i] = d.NodeById(id)
+		if sel[i] == nil {
+			return fmt.Errorf("Select: %s: %w", id, ErrNodeNotFound)
+		}
+	}
+	es := &d.View.EditState
+	es.ResetSelected()
+	for _, sn := range sel {
+		es.Select(sn)
+	}
+	return nil
+}
+
+// Selected returns the ids of the selected elements, in selection order
+func (d *Doc) Selected() []string {
+	return d.View.EditState.SelectedNames()
+}
+
+// Transform applies given translation, scaling and rotation (in degrees)
+// to the selected elements, around given pivot point, as one undoable
+// action
+func (d *Doc) Transform(trans, scale mat32.Vec2, rot float32, pivot mat32.Vec2) {
+	es := &d.View.EditState
+	if !es.HasSelected() {
+		return
+	}
+	sv := d.SVG()
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	wtr := trans.MulScalar(sv.Scale)
+	wpt := sv.DocToWin(pivot).Sub(svoff)
+	sv.UndoSave("Transform", es.SelectedNamesString())
+	for sn := range es.Selected {
+		sn.ApplyDeltaTransform(wtr, scale, mat32.DegToRad(rot), wpt)
+	}
+	d.Render()
+	es.Changed = true
+}
+
+// Move moves the selected elements by given amounts
+func (d *Doc) Move(dx, dy float32) {
+	d.Transform(mat32.V2(dx, dy), mat32.V2(1, 1), 0, mat32.Vec2{})
+}
+
+// BBox returns the bounding box of the element with given id
+func (d *Doc) BBox(id string) (mat32.Box2, error) {
+	sn := d.NodeById(id)
+	if sn == nil {
+		return mat32.Box2{}, fmt.Errorf("BBox: %s: %w", id, ErrNodeNotFound)
+	}
+	return d.SVG().DocBBox(sn), nil
+}
+
+// path returns the path with given id
+func (d *Doc) path(fun, id string) (*svg.Path, error) {
+	sn := d.NodeById(id)
+	if sn == nil {
+		return nil, fmt.Errorf("%s: %s: %w", fun, id, ErrNodeNotFound)
+	}
+	path, ok := sn.(*svg.Path)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a path", fun, id)
+	}
+	return path, nil
+}
+
+// PathNodes returns the positions of the nodes of the path with given id
+func (d *Doc) PathNodes(id string) ([]mat32.Vec2, error) {
+	path, err := d.path("PathNodes", id)
+	if err != nil {
+		return nil, err
+	}
+	sv := d.SVG()
+	pns, _ := sv.PathNodes(path)
+	pts := make([]mat32.Vec2, len(pns))
+	for i, pn := range pns {
+		pts[i] = sv.WinToDoc(pn.WinPt)
+	}
+	return pts, nil
+}
+
+// MovePathNode moves the node at given index of the path with given id
+// by given amounts, leaving the other nodes in place, as one undoable
+// action
+func (d *Doc) MovePathNode(id string, idx int, dx, dy float32) error {
+	path, err := d.path("MovePathNode", id)
+	if err != nil {
+		return err
+	}
+	sv := d.SVG()
+	pns, _ := sv.PathNodes(path)
+	if idx < 0 || idx >= len(pns) {
+		return fmt.Errorf("MovePathNode: %s: node index %d out of range, n = %d", id, idx, len(pns))
+	}
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	sv.UndoSave("NodeAdj", id)
+	sv.PathNodeSetOnePoint(path, pns, idx, mat32.V2(dx, dy).MulScalar(sv.Scale), svoff)
+	d.Render()
+	d.View.EditState.Changed = true
+	return nil
+}
+
+// Undo undoes the last edit, returning the action that was undone
+func (d *Doc) Undo() string {
+	act := d.SVG().Undo()
+	d.Render()
+	return act
+}
+
+// Redo redoes the last undone edit, returning the action that was redone
+func (d *Doc) Redo() string {
+	act := d.SVG().Redo()
+	d.Render()
+	return act
+}
+
+// Bytes returns the drawing as svg file contents, as saved by Save
+func (d *Doc) Bytes() ([]byte, error) {
+	d.SVG().SetMetaData()
+	return d.View.SVGBytes()
+}
+
+// Save saves the drawing to given svg file
+func (d *Doc) Save(fname string) error {
+	b, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	err = WriteSVGBytes(gi.FileName(fname), b)
+	if err == nil {
+		d.View.EditState.Changed = false
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// OpenSVGHeadless opens given svg file into a new view that is not in a
+// GridView or window, styled and ready for exporting with the registered
+// exporters (see ExportFileHeadless).  The view is at scale 1, so its
+// window coordinates are the drawing coordinates.
+func OpenSVGHeadless(fname string) (*SVGView, error) {
+	sv := &SVGView{}
+	sv.InitName(sv, "svg")
+	sv.Scale = 1
+	err := sv.OpenXML(gi.FileName(fname))
+	if err != nil && err != io.EOF {
+		sv.Destroy()
+		return nil, err
+	}
+	sv.Init2DTree()
+	sv.Style2DTree()
+	return sv, nil
+}
+
+// ExportFileHeadless exports given svg file to given output file, in the
+// registered export format for its extension, without a GUI window, over
+// the whole page.  For png, the image is width by height pixels, or
+// either 0 to preserve the aspect ratio, or both 0 for the physical size
+// in px (see PNGExportFunc).
+func ExportFileHeadless(infile, outfile string, width, height int) error {
+	ext := ExportExt(filepath.Ext(outfile))
+	fn := PNGExportFunc(width, height)
+	if ext != ".png" {
+		ex := ExporterForExt(ext)
+		if ex == nil {
+			return fmt.Errorf("no exporter registered for extension: %q", ext)
+		}
+		fn = ex.Func
+	}
+	sv, err := OpenSVGHeadless(infile)
+	if err != nil {
+		return err
+	}
+	defer sv.Destroy()
+	return sv.ExportToFile(gi.FileName(outfile), fn)
+}
+
+// BatchExport exports each of given svg files without a GUI window (see
+// ExportFileHeadless), for the command line.  The output is either the
+// name of the file to export a single input file to, or a format
+// extension (e.g., png or .pdf), for exporting each file to the same name
+// with that extension.  Calls done with each output file when it is written.
+// Stops at the first error.
+func BatchExport(infiles []string, output string, width, height int, done func(outfile string)) error {
+	if len(infiles) == 0 {
+		return fmt.Errorf("no svg files given to export")
+	}
+	isext := filepath.Ext(output) == "" || (strings.HasPrefix(output, ".") && !strings.ContainsAny(output, `/\`))
+	if !isext && len(infiles) > 1 {
+		return fmt.Errorf("exporting %d files: give a format extension (e.g., png) instead of an output file name: %s", len(infiles), output)
+	}
+	output = CleanPath(ExpandPath(output))
+	for _, in := range infiles {
+		in = CleanPath(ExpandPath(in))
+		out := output
+		if isext {
+			out = strings.TrimSuffix(in, filepath.Ext(in)) + ExportExt(output)
+		}
+		if AbsPath(out) == AbsPath(in) {
+			return fmt.Errorf("%s: cannot export over the input file itself", in)
+		}
+		if err := ExportFileHeadless(in, out, width, height); err != nil {
+			return fmt.Errorf("%s: %w", in, err)
+		}
+		if done != nil {
+			done(out)
+		}
+	}
+	return nil
+}
@@ -100,6 +100,20 @@ func (ev BBoxPoints) Dim() mat32.Dims {
 	}
 }
 
+// SnapTargets returns the types of align points of other elements that
+// this point of a bounding box snaps to: the center snaps to the centers
+// and the edge midpoints of the others in the same dimension, and the
+// edges snap to the same edges
+func (ev BBoxPoints) SnapTargets() []BBoxPoints {
+	switch ev {
+	case BBCenter:
+		return []BBoxPoints{BBLeft, BBCenter, BBRight}
+	case BBMiddle:
+		return []BBoxPoints{BBTop, BBMiddle, BBBottom}
+	}
+	return []BBoxPoints{ev}
+}
+
 // ReshapeBBoxPoints returns the X and Y BBoxPoints for given sprite Reshape
 // control point.
 func ReshapeBBoxPoints(reshape Sprites) (bbX, bbY BBoxPoints) {
@@ -0,0 +1,552 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/svg"
+	"github.com/goki/mat32"
+)
+
+// PathFlattenSegs is the number of straight line segments that each
+// curve or arc is divided into when flattening paths and shapes to
+// polygons for the boolean path operations
+var PathFlattenSegs = 32
+
+// PathFlatten returns the polygonal representation of given path data,
+// for each subpath, as in PathPolys, with each curve and arc segment
+// divided into PathFlattenSegs straight line segments.  Points from
+// flattening have a node index of -1.
+func PathFlatten(data []svg.PathData) []*PathPoly {
+	segs := PathSegs(data)
+	sts := PathSegStarts(segs)
+	var polys []*PathPoly
+	var cur *PathPoly
+	node := 0
+	for i, ps := range segs {
+		switch {
+		case ps.IsMove():
+			cur = &PathPoly{Pts: []mat32.Vec2{ps.Pt}, Nodes: []int{node}}
+			polys = append(polys, cur)
+			node++
+			continue
+		case ps.IsClose():
+			if cur != nil {
+				cur.Closed = true
+			}
+			cur = nil
+			continue
+		}
+		if cur == nil { // continues from the start of a closed subpath
+			cur = &PathPoly{Pts: []mat32.Vec2{sts[i]}, Nodes: []int{-1}}
+			polys = append(polys, cur)
+		}
+		n := 1
+		switch ps.Cmd &^ 1 {
+		case svg.PcC, svg.PcS, svg.PcQ, svg.PcT:
+			n = PathFlattenSegs
+		case svg.PcA:
+			if ps.Rad.X != 0 && ps.Rad.Y != 0 { // otherwise a straight line
+				n = PathFlattenSegs
+			}
+		}
+		for j := 1; j < n; j++ {
+			cur.Pts = append(cur.Pts, ps.PointAt(sts[i], float32(j)/float32(n)))
+			cur.Nodes = append(cur.Nodes, -1)
+		}
+		cur.Pts = append(cur.Pts, ps.Pt)
+		cur.Nodes = append(cur.Nodes, node)
+		node++
+	}
+	return polys
+}
+
+// EllipsePoly returns a closed polygon approximating the arc of given
+// ellipse (with no rotation) from angle a1 through da radians, with
+// 4 * PathFlattenSegs segments for the full ellipse
+func EllipsePoly(ctr, rad mat32.Vec2, a1, da float32) *PathPoly {
+	n := int(mat32.Ceil(mat32.Abs(da) / (0.5 * mat32.Pi) * float32(PathFlattenSegs)))
+	if n < 1 {
+		n = 1
+	}
+	pl := &PathPoly{Closed: true}
+	for i := 0; i <= n; i++ {
+		if i == n && mat32.Abs(da) >= 2*mat32.Pi {
+			break // same as the first
+		}
+		pl.Pts = append(pl.Pts, EllipsePt(ctr, rad, 0, a1+da*float32(i)/float32(n)))
+		pl.Nodes = append(pl.Nodes, -1)
+	}
+	return pl
+}
+
+// ElPolys returns the polygonal representation of the outline of given
+// path or shape, flattening any curves, in its own local coordinates.
+// Returns false for elements that do not have a fillable outline
+// (e.g., lines, text and groups).
+func ElPolys(sn svg.NodeSVG) ([]*PathPoly, bool) {
+	switch el := sn.(type) {
+	case *svg.Path:
+		return PathFlatten(el.Data), true
+	case *svg.Rect:
+		p, sz := el.Pos, el.Size
+		r := mat32.Min(el.Radius.X, 0.5*mat32.Min(sz.X, sz.Y))
+		if r <= 0 {
+			return []*PathPoly{{Pts: []mat32.Vec2{p, {p.X + sz.X, p.Y}, p.Add(sz), {p.X, p.Y + sz.Y}},
+				Nodes: []int{-1, -1, -1, -1}, Closed: true}}, true
+		}
+		pl := &PathPoly{Closed: true}
+		rv := mat32.V2(r, r)
+		ctrs := []mat32.Vec2{{p.X + sz.X - r, p.Y + r}, {p.X + sz.X - r, p.Y + sz.Y - r}, {p.X + r, p.Y + sz.Y - r}, {p.X + r, p.Y + r}}
+		for i, ctr := range ctrs {
+			cp := EllipsePoly(ctr, rv, float32(i-1)*0.5*mat32.Pi, 0.5*mat32.Pi)
+			pl.Pts = append(pl.Pts, cp.Pts...)
+			pl.Nodes = append(pl.Nodes, cp.Nodes...)
+		}
+		return []*PathPoly{pl}, true
+	case *svg.Circle:
+		return []*PathPoly{EllipsePoly(el.Pos, mat32.V2(el.Radius, el.Radius), 0, 2*mat32.Pi)}, true
+	case *svg.Ellipse:
+		return []*PathPoly{EllipsePoly(el.Pos, el.Radii, 0, 2*mat32.Pi)}, true
+	case *svg.Polygon:
+		return []*PathPoly{pointsPoly(el.Points)}, true
+	case *svg.Polyline: // filled as if closed
+		return []*PathPoly{pointsPoly(el.Points)}, true
+	}
+	return nil, false
+}
+
+// pointsPoly returns a closed poly with given points
+func pointsPoly(pts []mat32.Vec2) *PathPoly {
+	pl := &PathPoly{Pts: make([]mat32.Vec2, len(pts)), Nodes: make([]int, len(pts)), Closed: true}
+	copy(pl.Pts, pts)
+	for i := range pl.Nodes {
+		pl.Nodes[i] = i
+	}
+	return pl
+}
+
+// BoolOperand is one of the elements combined by a boolean path
+// operation: its outline as closed polygons, and its fill rule,
+// which together determine the region that it fills
+type BoolOperand struct {
+
+	// outline polygons, all treated as closed, as for filling
+	Polys []*PathPoly
+
+	// use the evenodd fill rule instead of nonzero
+	EvenOdd bool
+}
+
+// Inside returns true if given point is within the filled region
+func (bo *BoolOperand) Inside(pt mat32.Vec2) bool {
+	wn := PathPolysWinding(bo.Polys, pt)
+	if bo.EvenOdd {
+		return wn%2 != 0
+	}
+	return wn != 0
+}
+
+// PathPolysWinding returns the winding number of given polys, all
+// treated as closed, around given point
+func PathPolysWinding(polys []*PathPoly, pt mat32.Vec2) int {
+	wn := 0
+	for _, pl := range polys {
+		np := len(pl.Pts)
+		for i := 0; i < np; i++ {
+			a := pl.Pts[i]
+			b := pl.Pts[(i+1)%np]
+			side := (b.X-a.X)*(pt.Y-a.Y) - (pt.X-a.X)*(b.Y-a.Y)
+			if a.Y <= pt.Y {
+				if b.Y > pt.Y && side > 0 {
+					wn++
+				}
+			} else if b.Y <= pt.Y && side < 0 {
+				wn--
+			}
+		}
+	}
+	return wn
+}
+
+// BoolOpFunc returns whether a point is within the result of a boolean
+// path operation, given whether it is within each of the operands, in order
+type BoolOpFunc func(ins []bool) bool
+
+// BoolUnionFunc is within any of the operands
+func BoolUnionFunc(ins []bool) bool {
+	for _, in := range ins {
+		if in {
+			return true
+		}
+	}
+	return false
+}
+
+// BoolIntersectFunc is within all of the operands
+func BoolIntersectFunc(ins []bool) bool {
+	for _, in := range ins {
+		if !in {
+			return false
+		}
+	}
+	return true
+}
+
+// BoolDifferenceFunc is within the first operand and none of the others
+func BoolDifferenceFunc(ins []bool) bool {
+	return ins[0] && !BoolUnionFunc(ins[1:])
+}
+
+// BoolExcludeFunc is within an odd number of the operands -- for two,
+// within either one but not both
+func BoolExcludeFunc(ins []bool) bool {
+	n := 0
+	for _, in := range ins {
+		if in {
+			n++
+		}
+	}
+	return n%2 != 0
+}
+
+// boolEdge is one edge of an operand, with its bounding box, and the
+// parameters along it where it is split by the other edges
+type boolEdge struct {
+	a, b mat32.Vec2
+	bb   mat32.Box2
+	ts   []float32
+}
+
+// splitAtPt splits the edge where given point lies on it, within given
+// tolerance -- this catches the junctions and collinear overlaps that
+// are missed by SegIntersect
+func (be *boolEdge) splitAtPt(pt mat32.Vec2, tol float32) {
+	d := be.b.Sub(be.a)
+	t := pt.Sub(be.a).Dot(d) / d.LengthSq()
+	if t <= 0 || t >= 1 {
+		return
+	}
+	if be.a.Add(d.MulScalar(t)).Sub(pt).Length() <= tol {
+		be.ts = append(be.ts, t)
+	}
+}
+
+// boolVerts merges points within a tolerance into shared vertices,
+// using a grid of cells of the tolerance size
+type boolVerts struct {
+	tol  float32
+	pts  []mat32.Vec2
+	grid map[[2]int64][]int
+}
+
+// cell returns the grid cell of given point
+func (bv *boolVerts) cell(pt mat32.Vec2) [2]int64 {
+	return [2]int64{int64(math.Floor(float64(pt.X / bv.tol))), int64(math.Floor(float64(pt.Y / bv.tol)))}
+}
+
+// Idx returns the index of the vertex at given point, adding it if new
+func (bv *boolVerts) Idx(pt mat32.Vec2) int {
+	c := bv.cell(pt)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for _, vi := range bv.grid[[2]int64{c[0] + dx, c[1] + dy}] {
+				if bv.pts[vi].Sub(pt).Length() <= bv.tol {
+					return vi
+				}
+			}
+		}
+	}
+	vi := len(bv.pts)
+	bv.pts = append(bv.pts, pt)
+	bv.grid[c] = append(bv.grid[c], vi)
+	return vi
+}
+
+// BoolOpPolys returns the closed polygons outlining the region given by
+// the boolean operation function applied to the regions of the operands.
+// All edges of the operands are split where they meet any other edge, and
+// each resulting piece is kept if the result is within the region on one
+// side of it and not the other, oriented with the region on the same side.
+// The kept pieces are then joined into closed polygons, so the result
+// fills correctly with either fill rule.  This handles any number of
+// operands, each of which can have holes or overlap itself.
+func BoolOpPolys(ops []*BoolOperand, fun BoolOpFunc) []*PathPoly {
+	var edges []*boolEdge
+	bb := mat32.Box2{}
+	bb.SetEmpty()
+	for _, op := range ops {
+		for _, pl := range op.Polys {
+			np := len(pl.Pts)
+			if np < 2 {
+				continue
+			}
+			for i, a := range pl.Pts {
+				b := pl.Pts[(i+1)%np]
+				bb.ExpandByPoint(a)
+				if a != b {
+					edges = append(edges, &boolEdge{a: a, b: b, bb: mat32.Box2{Min: a.Min(b), Max: a.Max(b)}})
+				}
+			}
+		}
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+	diag := bb.Size().Length()
+	if diag == 0 {
+		return nil
+	}
+	tol := 1.0e-5 * diag
+	eps := 4 * tol // offset of the points tested on each side of a piece
+
+	// sweep along X: only edges whose bounding boxes overlap, within
+	// the tolerance, can meet, and edges sorted by their left side
+	// cannot overlap any edge once past its right side
+	sort.Slice(edges, func(i, j int) bool { return edges[i].bb.Min.X < edges[j].bb.Min.X })
+	for i, ei := range edges {
+		for _, ej := range edges[i+1:] {
+			if ej.bb.Min.X > ei.bb.Max.X+tol {
+				break
+			}
+			if ej.bb.Min.Y > ei.bb.Max.Y+tol || ej.bb.Max.Y < ei.bb.Min.Y-tol {
+				continue
+			}
+			t, s, ok := SegIntersect(ei.a, ei.b, ej.a, ej.b)
+			if ok && t > 0 && t < 1 && s > 0 && s < 1 {
+				ei.ts = append(ei.ts, t)
+				ej.ts = append(ej.ts, s)
+			}
+			ei.splitAtPt(ej.a, tol)
+			ei.splitAtPt(ej.b, tol)
+			ej.splitAtPt(ei.a, tol)
+			ej.splitAtPt(ei.b, tol)
+		}
+	}
+
+	bv := &boolVerts{tol: tol, grid: make(map[[2]int64][]int)}
+	type piece struct{ from, to int }
+	var pieces []piece
+	kept := make(map[piece]bool)
+	insL := make([]bool, len(ops))
+	insR := make([]bool, len(ops))
+	for _, e := range edges {
+		ts := append([]float32{0, 1}, e.ts...)
+		sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+		d := e.b.Sub(e.a)
+		pv := bv.Idx(e.a)
+		for i := 1; i < len(ts); i++ {
+			pt := e.b
+			if i < len(ts)-1 {
+				pt = e.a.Add(d.MulScalar(ts[i]))
+			}
+			vi := bv.Idx(pt)
+			if vi == pv {
+				continue
+			}
+			pa, pb := bv.pts[pv], bv.pts[vi]
+			mid := pa.Add(pb).MulScalar(0.5)
+			nrm := mat32.V2(pa.Y-pb.Y, pb.X-pa.X).Normal().MulScalar(eps)
+			for k, op := range ops {
+				insL[k] = op.Inside(mid.Add(nrm))
+				insR[k] = op.Inside(mid.Sub(nrm))
+			}
+			rl, rr := fun(insL), fun(insR)
+			if rl != rr {
+				pc := piece{pv, vi}
+				if !rl {
+					pc = piece{vi, pv}
+				}
+				if !kept[pc] { // coincident edges of different operands
+					kept[pc] = true
+					pieces = append(pieces, pc)
+				}
+			}
+			pv = vi
+		}
+	}
+
+	outs := make(map[int][]int)
+	for i, pc := range pieces {
+		outs[pc.from] = append(outs[pc.from], i)
+	}
+	used := make([]bool, len(pieces))
+	var polys []*PathPoly
+	for i, pc := range pieces {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		vs := []int{pc.from}
+		cur := pc.to
+		closed := false
+		for {
+			if cur == pc.from {
+				closed = true
+				break
+			}
+			vs = append(vs, cur)
+			next := -1
+			for _, ni := range outs[cur] {
+				if !used[ni] {
+					next = ni
+					break
+				}
+			}
+			if next < 0 {
+				break
+			}
+			used[next] = true
+			cur = pieces[next].to
+		}
+		if !closed {
+			continue
+		}
+		pl := &PathPoly{Closed: true}
+		for _, vi := range vs {
+			pl.Pts = append(pl.Pts, bv.pts[vi])
+			pl.Nodes = append(pl.Nodes, -1)
+		}
+		PathPolyRemoveCollinear(pl, tol)
+		if len(pl.Pts) >= 3 {
+			polys = append(polys, pl)
+		}
+	}
+	return polys
+}
+
+// PathPolyRemoveCollinear removes points of given closed poly that lie
+// on the straight line between their neighbors, within given tolerance,
+// e.g., where edges were split by the boolean path operations
+func PathPolyRemoveCollinear(pl *PathPoly, tol float32) {
+	for {
+		np := len(pl.Pts)
+		if np < 3 {
+			return
+		}
+		rm := -1
+		for i := 0; i < np; i++ {
+			a := pl.Pts[(i+np-1)%np]
+			b := pl.Pts[i]
+			c := pl.Pts[(i+1)%np]
+			ac := c.Sub(a)
+			ln := ac.Length()
+			if ln == 0 || ac.Dot(b.Sub(a)) <= 0 || ac.Dot(c.Sub(b)) <= 0 {
+				continue
+			}
+			if mat32.Abs(ac.X*(b.Y-a.Y)-ac.Y*(b.X-a.X))/ln <= tol {
+				rm = i
+				break
+			}
+		}
+		if rm < 0 {
+			return
+		}
+		pl.Pts = append(pl.Pts[:rm], pl.Pts[rm+1:]...)
+		pl.Nodes = append(pl.Nodes[:rm], pl.Nodes[rm+1:]...)
+	}
+}
+
+// SelBoolOp replaces the selected paths and shapes (at least two) with a
+// single new path outlining the result of given boolean operation on the
+// regions that they fill, with curves flattened to straight segments.
+// The operands are in stacking order, bottom first, and the new path is
+// placed at the bottom one, with its style.  It is a single undoable action.
+// Locked items are left out.
+func (gv *GridView) SelBoolOp(act string, fun BoolOpFunc) {
+	es := &gv.EditState
+	sv := gv.SVG()
+	var sl []svg.NodeSVG // bottom first
+	for _, se := range es.SelectedListDepth(sv, false) {
+		if !NodeIsLocked(se) {
+			sl = append(sl, se)
+		}
+	}
+	if len(sl) < 2 {
+		gv.SetStatus(act + ": select two or more unlocked paths or shapes")
+		return
+	}
+	ops := make([]*BoolOperand, len(sl))
+	for i, se := range sl {
+		polys, ok := ElPolys(se)
+		if !ok {
+			gv.SetStatus(fmt.Sprintf("%s: %s is not a path or shape", act, se.Name()))
+			return
+		}
+		sn := se.AsSVGNode()
+		xf := sn.ParTransform(true) // include self
+		for _, pl := range polys {
+			for j := range pl.Pts {
+				pl.Pts[j] = xf.MulVec2AsPt(pl.Pts[j])
+			}
+		}
+		ops[i] = &BoolOperand{Polys: polys, EvenOdd: sn.Pnt.FillStyle.Rule == gist.FillRuleEvenOdd}
+	}
+	res := BoolOpPolys(ops, fun)
+	if len(res) == 0 {
+		gv.SetStatus(act + ": the result is empty -- nothing changed")
+		return
+	}
+	sv.UndoSave(act, es.SelectedNamesString())
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+
+	bot := sl[0]
+	par := bot.Parent()
+	bidx, _ := bot.IndexInParent()
+	np := par.InsertNewChild(svg.KiT_Path, bidx, "path").(*svg.Path)
+	sv.SetSVGName(np)
+	np.CopyPropsFrom(bot, true)
+	np.DeleteProp("transform") // included in the points
+	xfi := np.ParTransform(false).Inverse()
+	for _, pl := range res {
+		for j := range pl.Pts {
+			pl.Pts[j] = xfi.MulVec2AsPt(pl.Pts[j])
+		}
+	}
+	np.SetData(PathPolysString(res))
+
+	es.ResetSelected()
+	for _, se := range sl {
+		se.Delete(true)
+	}
+	es.Select(np)
+
+	sv.UpdateEnd(updt)
+	gv.UpdateAll()
+	sv.UpdateSelect()
+	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("%s: combined %d items into %s", act, len(sl), np.Name()))
+}
+
+// PathUnion replaces the selected paths and shapes with a single path
+// outlining the combined region that they fill (see SelBoolOp)
+func (gv *GridView) PathUnion() {
+	gv.SelBoolOp("PathUnion", BoolUnionFunc)
+}
+
+// PathIntersect replaces the selected paths and shapes with a single path
+// outlining the region that they all fill (see SelBoolOp)
+func (gv *GridView) PathIntersect() {
+	gv.SelBoolOp("PathIntersect", BoolIntersectFunc)
+}
+
+// PathDifference replaces the selected paths and shapes with a single path
+// outlining the region of the bottom one minus that of the others
+// (see SelBoolOp)
+func (gv *GridView) PathDifference() {
+	gv.SelBoolOp("PathDifference", BoolDifferenceFunc)
+}
+
+// PathExclude replaces the selected paths and shapes with a single path
+// outlining the region filled by an odd number of them -- for two items,
+// the region filled by either one but not both (see SelBoolOp)
+func (gv *GridView) PathExclude() {
+	gv.SelBoolOp("PathExclude", BoolExcludeFunc)
+}
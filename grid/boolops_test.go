@@ -0,0 +1,116 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+// boxPoly returns a closed poly around given box, counter-clockwise
+// when the Y axis points down if ccw, else clockwise
+func boxPoly(x0, y0, x1, y1 float32, ccw bool) *PathPoly {
+	pts := []mat32.Vec2{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}}
+	if ccw {
+		pts[1], pts[3] = pts[3], pts[1]
+	}
+	return &PathPoly{Pts: pts, Nodes: []int{-1, -1, -1, -1}, Closed: true}
+}
+
+// polysArea returns the total signed area of given closed polys,
+// which is the filled area when holes have the opposite orientation
+func polysArea(polys []*PathPoly) float32 {
+	var a float32
+	for _, pl := range polys {
+		np := len(pl.Pts)
+		for i, p := range pl.Pts {
+			q := pl.Pts[(i+1)%np]
+			a += p.X*q.Y - q.X*p.Y
+		}
+	}
+	return 0.5 * a
+}
+
+func TestBoolOpPolys(t *testing.T) {
+	ops := map[string]BoolOpFunc{
+		"union":      BoolUnionFunc,
+		"intersect":  BoolIntersectFunc,
+		"difference": BoolDifferenceFunc,
+		"exclude":    BoolExcludeFunc,
+	}
+	circle := EllipsePoly(mat32.V2(20, 10), mat32.V2(10, 10), 0, 2*mat32.Pi)
+	circArea := mat32.Abs(polysArea([]*PathPoly{circle}))
+	tests := []struct {
+		name  string
+		ops   []*BoolOperand
+		areas map[string]float32 // expected area by op, -1 for empty
+		polys map[string]int     // expected number of polys, if checked
+	}{
+		{"overlapping", []*BoolOperand{
+			{Polys: []*PathPoly{boxPoly(0, 0, 10, 10, false)}},
+			{Polys: []*PathPoly{boxPoly(5, 5, 15, 15, true)}},
+		}, map[string]float32{"union": 175, "intersect": 25, "difference": 75, "exclude": 150},
+			map[string]int{"union": 1, "intersect": 1, "difference": 1}},
+		{"nested", []*BoolOperand{
+			{Polys: []*PathPoly{boxPoly(0, 0, 20, 20, false)}},
+			{Polys: []*PathPoly{boxPoly(5, 5, 15, 15, false)}},
+		}, map[string]float32{"union": 400, "intersect": 100, "difference": 300, "exclude": 300},
+			map[string]int{"union": 1, "intersect": 1, "difference": 2, "exclude": 2}},
+		{"nested in hole", []*BoolOperand{
+			{Polys: []*PathPoly{boxPoly(0, 0, 30, 30, false), boxPoly(5, 5, 25, 25, false)}, EvenOdd: true},
+			{Polys: []*PathPoly{boxPoly(10, 10, 20, 20, false)}},
+		}, map[string]float32{"union": 600, "intersect": -1, "difference": 500, "exclude": 600},
+			map[string]int{"union": 3, "difference": 2}},
+		{"shared edge", []*BoolOperand{
+			{Polys: []*PathPoly{boxPoly(0, 0, 10, 10, false)}},
+			{Polys: []*PathPoly{boxPoly(10, 0, 20, 10, false)}},
+		}, map[string]float32{"union": 200, "intersect": -1, "difference": 100, "exclude": 200},
+			map[string]int{"union": 1, "difference": 1, "exclude": 1}},
+		{"disjoint", []*BoolOperand{
+			{Polys: []*PathPoly{boxPoly(0, 0, 10, 10, false)}},
+			{Polys: []*PathPoly{boxPoly(50, 50, 60, 60, false)}},
+		}, map[string]float32{"union": 200, "intersect": -1, "difference": 100, "exclude": 200},
+			map[string]int{"union": 2, "difference": 1}},
+		{"curve", []*BoolOperand{
+			{Polys: []*PathPoly{boxPoly(0, 0, 20, 20, false)}},
+			{Polys: []*PathPoly{circle}},
+		}, map[string]float32{"union": 400 + 0.5*circArea, "intersect": 0.5 * circArea, "difference": 400 - 0.5*circArea, "exclude": 400},
+			nil},
+	}
+	for _, tt := range tests {
+		for opnm, fun := range ops {
+			res := BoolOpPolys(tt.ops, fun)
+			want := tt.areas[opnm]
+			if want < 0 {
+				if len(res) != 0 {
+					t.Errorf("%s %s: got %d polys, want none", tt.name, opnm, len(res))
+				}
+				continue
+			}
+			if got := mat32.Abs(polysArea(res)); mat32.Abs(got-want) > 1.0e-3*want {
+				t.Errorf("%s %s: area = %g, want %g", tt.name, opnm, got, want)
+			}
+			if n, ok := tt.polys[opnm]; ok && len(res) != n {
+				t.Errorf("%s %s: got %d polys, want %d", tt.name, opnm, len(res), n)
+			}
+			// the result fills exactly where the op applies, with either rule
+			rop := &BoolOperand{Polys: res}
+			reo := &BoolOperand{Polys: res, EvenOdd: true}
+			ins := make([]bool, len(tt.ops))
+			for x := float32(-2.13); x < 62; x += 1.7 {
+				for y := float32(-2.31); y < 62; y += 1.9 {
+					pt := mat32.V2(x, y)
+					for k, op := range tt.ops {
+						ins[k] = op.Inside(pt)
+					}
+					if w := fun(ins); rop.Inside(pt) != w || reo.Inside(pt) != w {
+						t.Errorf("%s %s: inside %v = %v, want %v", tt.name, opnm, pt, !w, w)
+					}
+				}
+			}
+		}
+	}
+}
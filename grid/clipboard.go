@@ -0,0 +1,322 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mimedata"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+	"github.com/goki/pi/filecat"
+)
+
+// SVGMimeType is the mime type of the svg content that is copied to
+// the clipboard -- plain text is also written with the same content
+const SVGMimeType = "image/svg+xml"
+
+// PasteOffset is the offset, in drawing units in both directions, of
+// pasted items relative to the items they were copied from, for each
+// successive paste of the same clipboard contents
+var PasteOffset = float32(10)
+
+// URLProps are the properties of elements that can refer to an
+// element in the Defs by url: gradients and markers
+var URLProps = []string{"fill", "stroke", "marker-start", "marker-mid", "marker-end"}
+
+// ClipboardDefs returns the elements in the Defs that are referred to
+// by given items or any of their children, including the gradients
+// that any of those gradients get their stops from
+func (sv *SVGView) ClipboardDefs(sl []svg.NodeSVG) []ki.Ki {
+	var dl []ki.Ki
+	has := map[ki.Ki]bool{}
+	var add func(df gi.Node2D)
+	add = func(df gi.Node2D) {
+		if df == nil || has[df.This()] {
+			return
+		}
+		has[df.This()] = true
+		if gr, isgr := df.(*gi.Gradient); isgr && gr.StopsName != "" {
+			add(svg.NodeFindURL(gr, gr.StopsName))
+		}
+		dl = append(dl, df.This())
+	}
+	for _, sn := range sl {
+		sn.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+			kn, isn := k.(gi.Node2D)
+			if !isn {
+				return ki.Break
+			}
+			for _, pnm := range URLProps {
+				ps, ok := k.Prop(pnm).(string)
+				if !ok || !strings.HasPrefix(ps, "url(") {
+					continue
+				}
+				add(svg.NodeFindURL(kn, ps))
+			}
+			return ki.Continue
+		})
+	}
+	return dl
+}
+
+// SelectedSVG returns the selected items, in drawing order, as a
+// standalone svg document, along with the defs that they refer to
+func (gv *GridView) SelectedSVG() []byte {
+	es := &gv.EditState
+	sv := gv.SVG()
+	sl := es.SelectedListDepth(sv, false)
+	var b bytes.Buffer
+	enc := svg.NewXMLEncoder(&b)
+	enc.Indent("", "  ")
+	me := xml.StartElement{}
+	me.Name.Local = "svg"
+	svg.XMLAddAttr(&me.Attr, "xmlns:xlink", "http://www.w3.org/1999/xlink")
+	svg.XMLAddAttr(&me.Attr, "xmlns", "http://www.w3.org/2000/svg")
+	enc.EncodeToken(me)
+	if dl := sv.ClipboardDefs(sl); len(dl) > 0 {
+		de := xml.StartElement{}
+		de.Name.Local = "defs"
+		enc.EncodeToken(de)
+		for _, df := range dl {
+			if dnm, _ := svg.SVGNodeTreeMarshalXML(df, enc, ""); dnm != "" {
+				enc.WriteEnd(dnm)
+			}
+		}
+		enc.WriteEnd(de.Name.Local)
+	}
+	for _, sn := range sl {
+		if sn.Parent() != sv.This() { // bake in transforms of layers and groups
+			cn := sn.Clone().(svg.NodeSVG)
+			SetNodeTransform(cn, sn.AsSVGNode().ParTransform(true).Mul(sv.Pnt.Transform.Inverse()))
+			sn = cn
+		}
+		if knm, _ := svg.SVGNodeTreeMarshalXML(sn, enc, ""); knm != "" {
+			enc.WriteEnd(knm)
+		}
+	}
+	enc.WriteEnd(me.Name.Local)
+	enc.Flush()
+	return b.Bytes()
+}
+
+// defXML returns the svg xml of given def, for comparing defs
+func defXML(df ki.Ki) string {
+	var b bytes.Buffer
+	enc := svg.NewXMLEncoder(&b)
+	if dnm, _ := svg.SVGNodeTreeMarshalXML(df, enc, ""); dnm != "" {
+		enc.WriteEnd(dnm)
+	}
+	enc.Flush()
+	return b.String()
+}
+
+// PasteDefs adds given defs of pasted items to the Defs, skipping
+// those that are the same as a def of the same name already there.
+// A def whose name is taken by a different def is added under a new
+// unique name, and the returned map from old to new names must be
+// used to update the references to it (see RenameURLs).
+func (sv *SVGView) PasteDefs(defs ki.Slice) map[string]string {
+	rnm := map[string]string{}
+	for _, df := range defs {
+		nd := df.Clone()
+		if gr, isgr := nd.(*gi.Gradient); isgr {
+			if nnm, ok := rnm[gr.StopsName]; ok { // stops come before their users
+				gr.StopsName = nnm
+			}
+		}
+		ed := sv.Defs.ChildByName(nd.Name(), 0)
+		if ed != nil {
+			if defXML(ed) == defXML(nd) {
+				continue
+			}
+			nm, _ := svg.SplitNameIdDig(nd.Name())
+			nnm := svg.NameId(nm, sv.NewUniqueId())
+			rnm[nd.Name()] = nnm
+			nd.SetName(nnm)
+		}
+		sv.Defs.AddChild(nd)
+	}
+	return rnm
+}
+
+// RenameURLs updates the url references to defs in given item and
+// all of its children according to given map from old to new names
+func RenameURLs(sn svg.NodeSVG, rnm map[string]string) {
+	if len(rnm) == 0 {
+		return
+	}
+	sn.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		for _, pnm := range URLProps {
+			ps, ok := k.Prop(pnm).(string)
+			if !ok {
+				continue
+			}
+			if nnm, ok := rnm[svg.NameFromURL(ps)]; ok {
+				k.SetProp(pnm, svg.NameToURL(nnm))
+			}
+		}
+		return ki.Continue
+	})
+}
+
+// CopySelected copies selected items in SVG view to the clipboard,
+// as svg, along with the gradients and markers that they use
+func (gv *GridView) CopySelected() {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		gv.SetStatus("Copy: nothing selected")
+		return
+	}
+	b := gv.SelectedSVG()
+	oswin.TheApp.Clipboard(gv.ParentWindow().OSWin).Write(mimedata.NewTextPlus(string(b), SVGMimeType, b))
+	es.PasteN = 0
+	gv.SetStatus("Copied selected items")
+}
+
+// CutSelected copies selected items in SVG view to the clipboard,
+// as with CopySelected, and then deletes them -- locked items are
+// left out of both
+func (gv *GridView) CutSelected() {
+	es := &gv.EditState
+	tvl := gv.SelectedAsTreeViews()
+	if len(tvl) == 0 {
+		gv.SetStatus("Cut: no tree items found")
+		return
+	}
+	for sn := range es.Selected {
+		if NodeIsLocked(sn) {
+			es.Unselect(sn)
+		}
+	}
+	sv := gv.SVG()
+	sv.UndoSave("CutSelected", es.SelectedNamesString())
+	gv.CopySelected()
+	es.PasteN = -1 // first paste goes back in place
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	es.ResetSelected()
+	tv := gv.TreeView()
+	tvupdt := tv.UpdateStart()
+	tv.SetFullReRender()
+	for _, tvi := range tvl {
+		tvi.SrcDelete()
+	}
+	gv.SetStatus("Cut selected items")
+	tv.ReSync() // todo: should not be needed
+	tv.UpdateEnd(tvupdt)
+	sv.UpdateEnd(updt)
+	sv.UpdateSelSprites()
+	gv.ChangeMade()
+}
+
+// ClipboardSVG returns the svg content of the clipboard, from
+// SVGMimeType data or from plain text that is svg, or nil if none
+func (gv *GridView) ClipboardSVG() []byte {
+	md := oswin.TheApp.Clipboard(gv.ParentWindow().OSWin).Read([]string{SVGMimeType, filecat.TextPlain})
+	if md == nil {
+		return nil
+	}
+	if b := md.TypeData(SVGMimeType); len(b) > 0 {
+		return b
+	}
+	b := bytes.TrimSpace(md.TypeData(filecat.TextPlain))
+	if bytes.HasPrefix(b, []byte("<svg")) || bytes.HasPrefix(b, []byte("<?xml")) {
+		return b
+	}
+	return nil
+}
+
+// OffsetCopy moves given newly added copy of an element by given
+// amount in drawing units, right and down
+func (sv *SVGView) OffsetCopy(sn svg.NodeSVG, off float32) {
+	if off == 0 {
+		return
+	}
+	sn.AsSVGNode().Style2DTree() // get transforms from props
+	del := mat32.V2(off, off).MulScalar(sv.Scale)
+	sn.ApplyDeltaTransform(del, mat32.V2(1, 1), 0, mat32.Vec2{})
+}
+
+// Paste pastes the svg items on the clipboard into the current layer
+// (see PasteSVG)
+func (gv *GridView) Paste() {
+	b := gv.ClipboardSVG()
+	if b == nil {
+		gv.SetStatus("Paste: no svg on clipboard")
+		return
+	}
+	if err := gv.PasteSVG(b); err != nil {
+		gv.SetStatus("Paste: error reading svg on clipboard: " + err.Error())
+		return
+	}
+	gv.SetStatus("Pasted items from clipboard")
+}
+
+// PasteSVG pastes the items in given svg document into the current
+// layer, as new items with new unique names, offset by PasteOffset for
+// each time the same contents have been pasted, and selects them.
+// The items keep their place in the drawing whatever the transform of
+// the layer.  Any gradients and markers that they use that are not
+// already in the drawing are added to it, renamed if their names are
+// already taken by different ones.
+func (gv *GridView) PasteSVG(b []byte) error {
+	tmp := &svg.SVG{}
+	tmp.InitName(tmp, "paste")
+	if err := tmp.ReadXML(bytes.NewReader(b)); err != nil {
+		return err
+	}
+	es := &gv.EditState
+	sv := gv.SVG()
+	sv.UndoSave("Paste", "")
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+
+	rnm := sv.PasteDefs(tmp.Defs.Kids)
+
+	par := sv.This()
+	if es.CurLayer != "" {
+		ly := sv.ChildByName(es.CurLayer, 1)
+		if ly != nil {
+			par = ly
+		}
+	}
+	pxf := mat32.Identity2D()
+	if pn, ok := par.(svg.NodeSVG); ok && par != sv.This() {
+		pxf = pn.AsSVGNode().ParTransform(true).Mul(sv.Pnt.Transform.Inverse())
+	}
+	es.PasteN++
+	es.ResetSelected()
+	par.SetChildAdded()
+	for _, k := range tmp.Kids {
+		if NodeIsMetaData(k) {
+			continue
+		}
+		if _, issv := k.(svg.NodeSVG); !issv {
+			continue
+		}
+		sn := k.Clone().(svg.NodeSVG)
+		par.AddChild(sn)
+		sv.SetSVGNameTree(sn)
+		RenameURLs(sn, rnm)
+		if !pxf.IsIdentity() {
+			sn.AsSVGNode().Style2DTree() // get transform from props
+			SetNodeTransform(sn, sn.AsSVGNode().Pnt.Transform.Mul(pxf.Inverse()))
+		}
+		sv.OffsetCopy(sn, float32(es.PasteN)*PasteOffset)
+		es.Select(sn)
+	}
+	gv.UpdateTreeView()
+	sv.UpdateEnd(updt)
+	sv.UpdateView(true)
+	sv.UpdateSelect()
+	gv.ChangeMade()
+	return nil
+}
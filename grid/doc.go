@@ -3,6 +3,10 @@
 // license that can be found in the LICENSE file.
 
 /*
-Package grid provides the main code for the Grid SVG vector drawing program
+Package grid provides the main code for the Grid SVG vector drawing program.
+
+For editing drawings from Go code without the GUI, e.g., in scripts that
+batch-edit drawings, use Doc (OpenDoc, NewDoc), which wraps a headless
+GridView with a documented set of editing functions.
 */
 package grid
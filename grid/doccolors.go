@@ -0,0 +1,59 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+)
+
+// Colors returns the background, border and grid colors to use for
+// this drawing: the document-level override if set, else the colors
+// from preferences
+func (sv *SVGView) Colors() *ColorPrefs {
+	if es := sv.EditState(); es != nil && es.DocColors != nil {
+		return es.DocColors
+	}
+	return &Prefs.Colors
+}
+
+///////////////////////////////////////////////////////////////////////
+//   Actions
+
+// EditDocColors opens a dialog to set the background, border and grid
+// colors for this drawing, overriding the colors from preferences.
+// These are saved with the drawing.
+func (gv *GridView) EditDocColors() {
+	es := &gv.EditState
+	dc := &ColorPrefs{}
+	*dc = *gv.SVG().Colors()
+	giv.StructViewDialog(gv.Viewport, dc, giv.DlgOpts{Title: "Drawing Colors", Prompt: "Background, border and grid colors for this drawing, overriding the colors from preferences", Ok: true, Cancel: true}, gv.This(),
+		func(recv, send ki.Ki, sig int64, d any) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			es.DocColors = dc
+			gv.DocColorsChanged()
+		})
+}
+
+// ClearDocColors removes the document-level color override, so the
+// colors from preferences are used
+func (gv *GridView) ClearDocColors() {
+	es := &gv.EditState
+	es.DocColors = nil
+	gv.DocColorsChanged()
+}
+
+// DocColorsChanged updates the display after the drawing colors have changed
+func (gv *GridView) DocColorsChanged() {
+	sv := gv.SVG()
+	gv.EditState.Changed = true
+	sv.SetMetaData()
+	sv.RenderBg()
+	sv.UpdateView(true)
+	gv.ChangeMade()
+}
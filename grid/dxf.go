@@ -0,0 +1,391 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// DXFPrefs are preferences for DXF export and import
+type DXFPrefs struct {
+
+	// maximum distance, in output DXF units (mm or in), between a curve and the straight segments that approximate it, for curves that DXF cannot represent exactly (beziers, ellipses) -- straight segments and circular arcs are exported exactly
+	FlattenTol float32 `min:"0.001"`
+
+	// stroke width, in millimeters, of the lines imported from DXF files, which have no line widths of their own
+	LineWidth float32 `min:"0.01"`
+}
+
+// Defaults sets default values
+func (dp *DXFPrefs) Defaults() {
+	dp.FlattenTol = 0.05
+	dp.LineWidth = 0.25
+}
+
+func init() {
+	RegisterExporter(".dxf", ExportDXFFunc)
+}
+
+// DXFUnitsPerInch returns the DXF drawing units for given physical
+// units: inches for inches, and millimeters for all others, with the
+// DXF $INSUNITS code and the number of those units per inch
+func DXFUnitsPerInch(un units.Units) (code int, perInch float32) {
+	if un == units.In {
+		return 1, 1
+	}
+	return 4, units.MmPerInch
+}
+
+// DXFWriter writes DXF (AutoCAD R12 ASCII) entities, in DXF units
+// with Y pointing up, all on the single model-space layer 0
+type DXFWriter struct {
+	w *bufio.Writer
+}
+
+// NewDXFWriter returns a new DXFWriter writing to given writer
+func NewDXFWriter(w io.Writer) *DXFWriter {
+	return &DXFWriter{w: bufio.NewWriter(w)}
+}
+
+// Code writes one group code and value pair
+func (dw *DXFWriter) Code(code int, val string) {
+	fmt.Fprintf(dw.w, "%d\n%s\n", code, val)
+}
+
+// Num writes one group code and number value pair
+func (dw *DXFWriter) Num(code int, val float32) {
+	dw.Code(code, strconv.FormatFloat(float64(val), 'f', -1, 32))
+}
+
+// Point writes the X, Y group codes for given point, starting with code (10, 11, ...)
+func (dw *DXFWriter) Point(code int, pt mat32.Vec2) {
+	dw.Num(code, pt.X)
+	dw.Num(code+10, pt.Y)
+}
+
+// Start writes the header and the start of the entities section
+func (dw *DXFWriter) Start(insunits int) {
+	dw.Code(0, "SECTION")
+	dw.Code(2, "HEADER")
+	dw.Code(9, "$ACADVER")
+	dw.Code(1, "AC1009")
+	dw.Code(9, "$INSUNITS")
+	dw.Code(70, strconv.Itoa(insunits))
+	dw.Code(0, "ENDSEC")
+	dw.Code(0, "SECTION")
+	dw.Code(2, "ENTITIES")
+}
+
+// End writes the end of the entities section and the file
+func (dw *DXFWriter) End() error {
+	dw.Code(0, "ENDSEC")
+	dw.Code(0, "EOF")
+	return dw.w.Flush()
+}
+
+// Line writes a LINE entity
+func (dw *DXFWriter) Line(st, ed mat32.Vec2) {
+	dw.Code(0, "LINE")
+	dw.Code(8, "0")
+	dw.Point(10, st)
+	dw.Point(11, ed)
+}
+
+// Circle writes a CIRCLE entity
+func (dw *DXFWriter) Circle(ctr mat32.Vec2, r float32) {
+	dw.Code(0, "CIRCLE")
+	dw.Code(8, "0")
+	dw.Point(10, ctr)
+	dw.Num(40, r)
+}
+
+// Polyline writes a POLYLINE entity with given vertexes and bulges
+// (tangent of 1/4 of the included angle of a circular arc from each
+// vertex to the next, + for counter-clockwise -- nil or 0 for straight)
+func (dw *DXFWriter) Polyline(pts []mat32.Vec2, bulges []float32, closed bool) {
+	if len(pts) < 2 {
+		return
+	}
+	dw.Code(0, "POLYLINE")
+	dw.Code(8, "0")
+	dw.Code(66, "1")
+	dw.Point(10, mat32.Vec2{})
+	flg := 0
+	if closed {
+		flg = 1
+	}
+	dw.Code(70, strconv.Itoa(flg))
+	for i, pt := range pts {
+		dw.Code(0, "VERTEX")
+		dw.Code(8, "0")
+		dw.Point(10, pt)
+		if i < len(bulges) && bulges[i] != 0 {
+			dw.Num(42, bulges[i])
+		}
+	}
+	dw.Code(0, "SEQEND")
+	dw.Code(8, "0")
+}
+
+// DXFPoly is a polyline in DXF coordinates, with bulges for circular arcs
+type DXFPoly struct {
+	Pts    []mat32.Vec2
+	Bulges []float32
+	Closed bool
+}
+
+// Add adds a point, with the bulge of the segment leading to it from
+// the previous point
+func (dp *DXFPoly) Add(pt mat32.Vec2, bulge float32) {
+	if n := len(dp.Bulges); n > 0 {
+		dp.Bulges[n-1] = bulge
+	}
+	dp.Pts = append(dp.Pts, pt)
+	dp.Bulges = append(dp.Bulges, 0)
+}
+
+// dxfExport holds the state for exporting one drawing to DXF
+type dxfExport struct {
+	ExportXForm
+	dw *DXFWriter
+
+	// flattening tolerance in drawing units
+	tol float32
+}
+
+// localTol returns the flattening tolerance in the local
+// coordinates of an element with given transform
+func (de *dxfExport) localTol(xf mat32.Mat2) float32 {
+	sc, _ := de.XFScale(xf)
+	if sc <= 0 {
+		return de.tol
+	}
+	return de.tol / sc
+}
+
+// Node exports one element
+func (de *dxfExport) Node(sii svg.NodeSVG) {
+	xf := sii.AsSVGNode().ParTransform(true)
+	switch nd := sii.(type) {
+	case *svg.Line:
+		de.dw.Line(de.Pt(xf, nd.Start), de.Pt(xf, nd.End))
+	case *svg.Polygon:
+		de.points(xf, nd.Points, true)
+	case *svg.Polyline:
+		de.points(xf, nd.Points, false)
+	case *svg.Rect:
+		p := nd.Pos
+		s := nd.Size
+		de.points(xf, []mat32.Vec2{p, mat32.V2(p.X+s.X, p.Y), p.Add(s), mat32.V2(p.X, p.Y+s.Y)}, true)
+	case *svg.Circle:
+		if sc, sim := de.XFScale(xf); sim {
+			de.dw.Circle(de.Pt(xf, nd.Pos), nd.Radius*sc*de.Scale)
+			return
+		}
+		de.ellipse(xf, nd.Pos, mat32.V2(nd.Radius, nd.Radius))
+	case *svg.Ellipse:
+		de.ellipse(xf, nd.Pos, nd.Radii)
+	case *svg.Path:
+		de.path(xf, nd.Data)
+	}
+}
+
+// points exports given points as a polyline
+func (de *dxfExport) points(xf mat32.Mat2, pts []mat32.Vec2, closed bool) {
+	dp := &DXFPoly{Closed: closed}
+	for _, pt := range pts {
+		dp.Add(de.Pt(xf, pt), 0)
+	}
+	de.dw.Polyline(dp.Pts, dp.Bulges, dp.Closed)
+}
+
+// arcSteps returns the number of straight segments needed to
+// approximate an arc of given radius and angle within given tolerance
+func arcSteps(r, ang, tol float32) int {
+	if r <= tol {
+		return 1
+	}
+	step := 2 * float32(math.Acos(float64(1-tol/r)))
+	n := int(mat32.Ceil(mat32.Abs(ang) / step))
+	if n < 1 {
+		n = 1
+	}
+	if n > 1000 {
+		n = 1000
+	}
+	return n
+}
+
+// ellipse exports an ellipse, flattened to a closed polyline
+func (de *dxfExport) ellipse(xf mat32.Mat2, ctr, rad mat32.Vec2) {
+	n := arcSteps(mat32.Max(rad.X, rad.Y), 2*mat32.Pi, de.localTol(xf))
+	if n < 8 {
+		n = 8
+	}
+	dp := &DXFPoly{Closed: true}
+	for i := 0; i < n; i++ {
+		a := 2 * mat32.Pi * float32(i) / float32(n)
+		dp.Add(de.Pt(xf, mat32.V2(ctr.X+rad.X*mat32.Cos(a), ctr.Y+rad.Y*mat32.Sin(a))), 0)
+	}
+	de.dw.Polyline(dp.Pts, dp.Bulges, dp.Closed)
+}
+
+// bezierSteps returns the number of straight segments needed to
+// approximate a bezier curve with given control points (including
+// the end points) within given tolerance, based on the bound on
+// its second derivative
+func bezierSteps(pts []mat32.Vec2, tol float32) int {
+	var m float32
+	for i := 0; i+2 < len(pts); i++ {
+		m = mat32.Max(m, pts[i].Sub(pts[i+1].MulScalar(2)).Add(pts[i+2]).Length())
+	}
+	deg := float32(len(pts) - 1)
+	n := int(mat32.Ceil(mat32.Sqrt(deg * (deg - 1) * m / (8 * tol))))
+	if n < 1 {
+		n = 1
+	}
+	if n > 1000 {
+		n = 1000
+	}
+	return n
+}
+
+// bezierPt returns the point at t along the bezier curve with given
+// control points, using de Casteljau's algorithm
+func bezierPt(pts []mat32.Vec2, t float32) mat32.Vec2 {
+	tmp := make([]mat32.Vec2, len(pts))
+	copy(tmp, pts)
+	for n := len(tmp) - 1; n > 0; n-- {
+		for i := 0; i < n; i++ {
+			tmp[i] = tmp[i].MulScalar(1 - t).Add(tmp[i+1].MulScalar(t))
+		}
+	}
+	return tmp[0]
+}
+
+// path exports path data as polylines, one per subpath, with circular
+// arcs as exact bulges (when the transform preserves circles) and
+// other curves flattened
+func (de *dxfExport) path(xf mat32.Mat2, data []svg.PathData) {
+	tol := de.localTol(xf)
+	_, sim := de.XFScale(xf)
+	flip := xf.XX*xf.YY-xf.XY*xf.YX < 0
+
+	zidx := PathCloseIdxs(data)
+	closedBefore := func(from, to int) bool {
+		zi := sort.SearchInts(zidx, from)
+		return zi < len(zidx) && zidx[zi] < to
+	}
+
+	var dp *DXFPoly
+	lidx := 0
+	var lcp, sst mat32.Vec2 // last point, subpath start
+	flush := func(to int) {
+		if dp != nil {
+			dp.Closed = closedBefore(lidx, to)
+			de.dw.Polyline(dp.Pts, dp.Bulges, dp.Closed)
+		}
+		dp = nil
+	}
+	svg.PathDataIterFunc(data, func(idx int, cmd svg.PathCmds, ptIdx int, cp mat32.Vec2, ctrls []mat32.Vec2) bool {
+		if (cmd == svg.PcM || cmd == svg.Pcm) && ptIdx == 0 || dp == nil {
+			flush(idx)
+			dp = &DXFPoly{}
+			dp.Add(de.Pt(xf, cp), 0)
+			lidx = idx
+			lcp = cp
+			sst = cp
+			return ki.Continue
+		}
+		if closedBefore(lidx, idx) { // new subpath from the start point after Z
+			flush(idx)
+			dp = &DXFPoly{}
+			dp.Add(de.Pt(xf, sst), 0)
+			lcp = sst
+		}
+		switch cmd {
+		case svg.PcC, svg.Pcc:
+			de.addBezier(dp, xf, []mat32.Vec2{lcp, ctrls[0], ctrls[1], cp}, tol)
+		case svg.PcS, svg.Pcs: // note: ctrls are in reverse order for S
+			de.addBezier(dp, xf, []mat32.Vec2{lcp, ctrls[1], ctrls[0], cp}, tol)
+		case svg.PcQ, svg.Pcq, svg.PcT, svg.Pct:
+			de.addBezier(dp, xf, []mat32.Vec2{lcp, ctrls[0], cp}, tol)
+		case svg.PcA, svg.Pca:
+			de.addArc(dp, xf, ctrls, cp, tol, sim, flip)
+		default:
+			dp.Add(de.Pt(xf, cp), 0)
+		}
+		lidx = idx
+		lcp = cp
+		return ki.Continue
+	})
+	flush(len(data))
+}
+
+// addBezier adds a flattened bezier curve to given polyline
+func (de *dxfExport) addBezier(dp *DXFPoly, xf mat32.Mat2, pts []mat32.Vec2, tol float32) {
+	n := bezierSteps(pts, tol)
+	for i := 1; i <= n; i++ {
+		dp.Add(de.Pt(xf, bezierPt(pts, float32(i)/float32(n))), 0)
+	}
+}
+
+// addArc adds an elliptical arc to given polyline, given the ctrls from
+// PathDataIterFunc (center, start, radii, x axis rotation, flags):
+// as an exact bulge if it is circular and the transform preserves
+// circles, and otherwise flattened
+func (de *dxfExport) addArc(dp *DXFPoly, xf mat32.Mat2, ctrls []mat32.Vec2, cp mat32.Vec2, tol float32, sim, flip bool) {
+	ctr, rad := ctrls[0], ctrls[2]
+	rot, a1, da := PathArcAngles(ctrls, cp)
+	if sim && mat32.Abs(rad.X-rad.Y) <= 1.0e-4*mat32.Max(rad.X, rad.Y) {
+		// drawing angles increase clockwise on the page (Y down), and DXF
+		// Y is up, so positive sweep is clockwise = negative bulge, unless
+		// the transform mirrors
+		bulge := -mat32.Tan(da / 4)
+		if flip {
+			bulge = -bulge
+		}
+		dp.Add(de.Pt(xf, cp), bulge)
+		return
+	}
+	n := arcSteps(mat32.Max(rad.X, rad.Y), da, tol)
+	for i := 1; i <= n; i++ {
+		if i == n {
+			dp.Add(de.Pt(xf, cp), 0)
+			break
+		}
+		dp.Add(de.Pt(xf, EllipsePt(ctr, rad, rot, a1+da*float32(i)/float32(n))), 0)
+	}
+}
+
+// ExportDXFFunc is the ExportFunc for the .dxf format, which writes
+// lines, polylines, polygons, rects, circles, ellipses and paths as DXF
+// entities on a single model-space layer, in millimeters (or inches, for
+// drawings in inches), with Y up.  Straight segments and circular arcs
+// are exact; other curves are flattened to within Prefs.DXF.FlattenTol.
+// Text, images, fills and styles are not exported, and rounded rect
+// corners are exported as square.
+func ExportDXFFunc(sv *SVGView, sz *PhysSize, w io.Writer) error {
+	code, perIn := DXFUnitsPerInch(sz.Units)
+	de := &dxfExport{ExportXForm: NewExportXForm(sv, sz, perIn), dw: NewDXFWriter(w)}
+	tol := Prefs.DXF.FlattenTol
+	if tol <= 0 {
+		tol = 0.05
+	}
+	de.tol = tol / de.Scale
+	de.dw.Start(code)
+	sv.ExportNodes(de.Node)
+	return de.dw.End()
+}
@@ -0,0 +1,538 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/mat32"
+)
+
+// DXFCode is one group code and value pair read from a DXF file
+type DXFCode struct {
+	Code int
+	Val  string
+}
+
+// DXFVertex is one vertex of a DXF polyline, with the bulge
+// (tan of 1/4 of the included angle, positive = counter-clockwise)
+// of the arc from it to the next vertex, 0 for a straight segment
+type DXFVertex struct {
+	Pt    mat32.Vec2
+	Bulge float32
+}
+
+// DXFEntity is one entity read from the ENTITIES section of a DXF file
+type DXFEntity struct {
+
+	// entity type, e.g., LINE, LWPOLYLINE, CIRCLE
+	Type string
+
+	// layer name
+	Layer string
+
+	// group codes of the entity, in order, excluding the 0 code
+	Codes []DXFCode
+
+	// vertices, for LWPOLYLINE and POLYLINE entities
+	Verts []DXFVertex
+}
+
+// Str returns the value of the first given group code, and "" if none
+func (de *DXFEntity) Str(code int) string {
+	for _, c := range de.Codes {
+		if c.Code == code {
+			return c.Val
+		}
+	}
+	return ""
+}
+
+// Num returns the number value of the first given group code, and 0 if none
+func (de *DXFEntity) Num(code int) float32 {
+	v, _ := strconv.ParseFloat(de.Str(code), 32)
+	return float32(v)
+}
+
+// Int returns the integer value of the first given group code, and def if none
+func (de *DXFEntity) Int(code int, def int) int {
+	v, err := strconv.Atoi(de.Str(code))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Point returns the X, Y point of given group code (10, 11, ...)
+func (de *DXFEntity) Point(code int) mat32.Vec2 {
+	return mat32.V2(de.Num(code), de.Num(code+10))
+}
+
+// Closed returns true if the polyline flag (code 70) has the closed bit
+func (de *DXFEntity) Closed() bool {
+	return de.Int(70, 0)&1 != 0
+}
+
+// lwVerts sets the Verts of an LWPOLYLINE from its codes, where
+// each vertex is a 10, 20 pair optionally followed by a 42 bulge
+func (de *DXFEntity) lwVerts() {
+	for _, c := range de.Codes {
+		v, _ := strconv.ParseFloat(c.Val, 32)
+		switch c.Code {
+		case 10:
+			de.Verts = append(de.Verts, DXFVertex{Pt: mat32.V2(float32(v), 0)})
+		case 20, 42:
+			if n := len(de.Verts); n > 0 {
+				if c.Code == 20 {
+					de.Verts[n-1].Pt.Y = float32(v)
+				} else {
+					de.Verts[n-1].Bulge = float32(v)
+				}
+			}
+		}
+	}
+}
+
+// DXFFile is the content read from a DXF file by ReadDXF
+type DXFFile struct {
+
+	// $INSUNITS header value: 0 = unitless, 1 = in, 2 = ft, 4 = mm, 5 = cm, 6 = m
+	InsUnits int
+
+	// layer names in the order defined in the LAYER table, followed by
+	// any others used by the entities, in order of use
+	Layers []string
+
+	// layer colors from the LAYER table, as AutoCAD color index --
+	// negative if the layer is off
+	LayerColors map[string]int
+
+	// model space entities, in file order
+	Entities []*DXFEntity
+}
+
+// AddLayer adds given layer name if not already present
+func (df *DXFFile) AddLayer(nm string) {
+	for _, l := range df.Layers {
+		if l == nm {
+			return
+		}
+	}
+	df.Layers = append(df.Layers, nm)
+}
+
+// ReadDXF reads the header units, layers and model space entities from
+// given ASCII DXF file contents.  The vertices of old-style POLYLINE
+// entities are gathered from their VERTEX entities.
+func ReadDXF(r io.Reader) (*DXFFile, error) {
+	df := &DXFFile{LayerColors: map[string]int{}}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	var codes []DXFCode
+	line := 0
+	for sc.Scan() {
+		cs := strings.TrimSpace(sc.Text())
+		line++
+		if !sc.Scan() {
+			break
+		}
+		line++
+		code, err := strconv.Atoi(cs)
+		if err != nil {
+			return nil, fmt.Errorf("dxf: invalid group code %q at line %d", cs, line-1)
+		}
+		codes = append(codes, DXFCode{Code: code, Val: strings.TrimSpace(sc.Text())})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	section := ""
+	var cur, poly *DXFEntity
+	finish := func() {
+		if cur == nil {
+			return
+		}
+		ent := cur
+		cur = nil
+		if ent.Int(67, 0) == 1 { // paper space
+			return
+		}
+		switch section {
+		case "HEADER":
+		case "TABLES":
+			if ent.Type == "LAYER" {
+				nm := ent.Str(2)
+				df.AddLayer(nm)
+				df.LayerColors[nm] = ent.Int(62, 7)
+			}
+		case "ENTITIES":
+			switch ent.Type {
+			case "POLYLINE":
+				poly = ent
+				return
+			case "VERTEX":
+				if poly != nil {
+					poly.Verts = append(poly.Verts, DXFVertex{Pt: ent.Point(10), Bulge: ent.Num(42)})
+				}
+				return
+			case "SEQEND":
+				if poly != nil {
+					df.AddLayer(poly.Layer)
+					df.Entities = append(df.Entities, poly)
+				}
+				poly = nil
+				return
+			case "LWPOLYLINE":
+				ent.lwVerts()
+			}
+			df.AddLayer(ent.Layer)
+			df.Entities = append(df.Entities, ent)
+		}
+	}
+	for i := 0; i < len(codes); i++ {
+		c := codes[i]
+		switch {
+		case c.Code == 0:
+			finish()
+			switch c.Val {
+			case "SECTION":
+				if i+1 < len(codes) && codes[i+1].Code == 2 {
+					section = codes[i+1].Val
+					i++
+				}
+			case "ENDSEC":
+				section = ""
+			case "EOF":
+				return df, nil
+			default:
+				cur = &DXFEntity{Type: c.Val, Layer: "0"}
+			}
+		case section == "HEADER" && c.Code == 9 && c.Val == "$INSUNITS":
+			if i+1 < len(codes) {
+				df.InsUnits, _ = strconv.Atoi(codes[i+1].Val)
+				i++
+			}
+		case cur != nil:
+			if c.Code == 8 {
+				cur.Layer = c.Val
+			}
+			cur.Codes = append(cur.Codes, c)
+		}
+	}
+	finish()
+	return df, nil
+}
+
+// DXFInsUnitsPerInch returns the number of DXF units per inch for given
+// $INSUNITS code, and false if the drawing is unitless or in units
+// that are not supported
+func DXFInsUnitsPerInch(code int) (float32, bool) {
+	switch code {
+	case 1:
+		return 1, true
+	case 2:
+		return 1.0 / 12.0, true
+	case 4:
+		return units.MmPerInch, true
+	case 5:
+		return units.CmPerInch, true
+	case 6:
+		return units.MmPerInch / 1000, true
+	}
+	return 1, false
+}
+
+// DXFColors are the colors of the standard AutoCAD color indexes 1-7,
+// with 7 (white on the usual dark background) drawn as black
+var DXFColors = []string{"#000000", "#ff0000", "#ffff00", "#00ff00", "#00ffff", "#0000ff", "#ff00ff", "#000000"}
+
+// DXFColor returns the color for given AutoCAD color index, and
+// "" for BYBLOCK (0) and BYLAYER (256), to inherit the color.
+// Indexes beyond the standard colors are drawn as black.
+func DXFColor(aci int) string {
+	if aci < 0 {
+		aci = -aci
+	}
+	switch {
+	case aci == 0 || aci == 256:
+		return ""
+	case aci < len(DXFColors):
+		return DXFColors[aci]
+	}
+	return DXFColors[0]
+}
+
+// dxfImport holds the state for importing a DXF file into a drawing
+type dxfImport struct {
+	sv *SVGView
+
+	// drawing units per DXF unit
+	scale float32
+
+	// page bottom in drawing units, for flipping Y
+	bottom float32
+
+	// page left in drawing units
+	left float32
+
+	// layer colors from the DXF file, for text, which is filled
+	layerColors map[string]int
+}
+
+// pt returns the drawing coordinates of given DXF point
+func (di *dxfImport) pt(p mat32.Vec2) mat32.Vec2 {
+	return mat32.V2(di.left+p.X*di.scale, di.bottom-p.Y*di.scale)
+}
+
+// num formats a number for svg path data
+func (di *dxfImport) num(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+// ptStr formats the drawing coordinates of given DXF point for svg path data
+func (di *dxfImport) ptStr(p mat32.Vec2) string {
+	dp := di.pt(p)
+	return di.num(dp.X) + "," + di.num(dp.Y)
+}
+
+// arcStr returns the svg arc command to given DXF point, with given
+// radius in DXF units and included angle in radians, positive for
+// counter-clockwise with DXF Y up, which is clockwise (no sweep) in svg
+func (di *dxfImport) arcStr(to mat32.Vec2, r, ang float32) string {
+	laf := 0
+	if mat32.Abs(ang) > mat32.Pi {
+		laf = 1
+	}
+	sf := 0
+	if ang < 0 {
+		sf = 1
+	}
+	rs := di.num(mat32.Abs(r) * di.scale)
+	return fmt.Sprintf("A %s,%s 0 %d %d %s ", rs, rs, laf, sf, di.ptStr(to))
+}
+
+// polyStr returns the svg path data for given polyline vertices
+func (di *dxfImport) polyStr(verts []DXFVertex, closed bool) string {
+	var sb strings.Builder
+	n := len(verts)
+	for i, v := range verts {
+		if i == 0 {
+			sb.WriteString("M " + di.ptStr(v.Pt) + " ")
+			continue
+		}
+		di.segStr(&sb, verts[i-1], v.Pt)
+	}
+	if closed && n > 1 {
+		if verts[n-1].Bulge != 0 {
+			di.segStr(&sb, verts[n-1], verts[0].Pt)
+		}
+		sb.WriteString("Z")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// segStr adds the segment from given vertex to given point
+func (di *dxfImport) segStr(sb *strings.Builder, from DXFVertex, to mat32.Vec2) {
+	if from.Bulge == 0 {
+		sb.WriteString("L " + di.ptStr(to) + " ")
+		return
+	}
+	ang := 4 * mat32.Atan(from.Bulge)
+	chord := to.Sub(from.Pt).Length()
+	r := chord / (2 * mat32.Sin(ang/2))
+	sb.WriteString(di.arcStr(to, r, ang))
+}
+
+// newEl adds a new element of given type to given parent
+func (di *dxfImport) newEl(par svg.NodeSVG, typ reflect.Type) svg.NodeSVG {
+	el := par.AddNewChild(typ, "").(svg.NodeSVG)
+	di.sv.SetSVGName(el)
+	return el
+}
+
+// DXFLayerName returns given DXF layer name as a valid element name,
+// with characters other than letters, digits, - and _ replaced by _
+func DXFLayerName(nm string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, nm)
+}
+
+// textColor returns the fill color of given text entity: its own color,
+// else that of its layer, as text does not inherit the layer stroke color
+func (di *dxfImport) textColor(ent *DXFEntity) string {
+	if cl := DXFColor(ent.Int(62, 256)); cl != "" {
+		return cl
+	}
+	if cl := DXFColor(di.layerColors[ent.Layer]); cl != "" {
+		return cl
+	}
+	return DXFColors[0]
+}
+
+// Entity adds the element for given entity to given layer group,
+// returning false if the entity type is not supported
+func (di *dxfImport) Entity(lg svg.NodeSVG, ent *DXFEntity) bool {
+	var el svg.NodeSVG
+	switch ent.Type {
+	case "LINE":
+		ln := di.newEl(lg, svg.KiT_Line).(*svg.Line)
+		ln.Start = di.pt(ent.Point(10))
+		ln.End = di.pt(ent.Point(11))
+		el = ln
+	case "LWPOLYLINE", "POLYLINE":
+		if len(ent.Verts) < 2 {
+			return false
+		}
+		p := di.newEl(lg, svg.KiT_Path).(*svg.Path)
+		p.SetData(di.polyStr(ent.Verts, ent.Closed()))
+		el = p
+	case "CIRCLE":
+		c := di.newEl(lg, svg.KiT_Circle).(*svg.Circle)
+		c.Pos = di.pt(ent.Point(10))
+		c.Radius = ent.Num(40) * di.scale
+		el = c
+	case "ARC":
+		ctr := ent.Point(10)
+		r := ent.Num(40)
+		a0 := mat32.DegToRad(ent.Num(50))
+		ang := mat32.DegToRad(ent.Num(51)) - a0
+		for ang <= 0 {
+			ang += 2 * mat32.Pi
+		}
+		st := ctr.Add(mat32.V2(r*mat32.Cos(a0), r*mat32.Sin(a0)))
+		end := ctr.Add(mat32.V2(r*mat32.Cos(a0+ang), r*mat32.Sin(a0+ang)))
+		p := di.newEl(lg, svg.KiT_Path).(*svg.Path)
+		p.SetData("M " + di.ptStr(st) + " " + strings.TrimSpace(di.arcStr(end, r, ang)))
+		el = p
+	case "TEXT", "MTEXT":
+		str := ent.Str(1)
+		if ent.Type == "MTEXT" {
+			var sb strings.Builder
+			for _, c := range ent.Codes {
+				if c.Code == 3 {
+					sb.WriteString(c.Val)
+				}
+			}
+			str = strings.ReplaceAll(sb.String()+str, `\P`, " ")
+		}
+		if str == "" {
+			return false
+		}
+		pos := di.pt(ent.Point(10))
+		txt := di.newEl(lg, svg.KiT_Text).(*svg.Text)
+		tspan := di.newEl(txt, svg.KiT_Text).(*svg.Text)
+		tspan.Text = str
+		txt.Pos = pos
+		tspan.Pos = pos
+		if h := ent.Num(40) * di.scale; h > 0 {
+			txt.SetProp("font-size", units.NewPx(h).String())
+		}
+		if rot := ent.Num(50); rot != 0 {
+			txt.SetProp("transform", fmt.Sprintf("rotate(%g,%g,%g)", -rot, pos.X, pos.Y))
+		}
+		txt.SetProp("fill", di.textColor(ent))
+		txt.SetProp("stroke", "none")
+		return true
+	default:
+		return false
+	}
+	if cl := DXFColor(ent.Int(62, 256)); cl != "" {
+		el.SetProp("stroke", cl)
+	}
+	return true
+}
+
+// ImportDXF imports the lines, polylines, circles, arcs and text in the
+// model space of given DXF file into a new group in the current layer,
+// with a sub-group for each DXF layer.  The DXF units are converted to
+// the drawing units, with the DXF origin at the lower-left of the page,
+// and unitless DXF files are taken to be in the physical units of the
+// drawing.  Lines are drawn Prefs.DXF.LineWidth wide, and text is filled,
+// in the colors of their entities or layers.  Blocks (INSERT) and other entities are
+// skipped, and counted in the status message.
+func (gv *GridView) ImportDXF(fname gi.FileName) error {
+	fp, err := os.Open(string(fname))
+	if err != nil {
+		return err
+	}
+	df, err := ReadDXF(fp)
+	fp.Close()
+	if err != nil {
+		return err
+	}
+	sv := gv.SVG()
+	sz := &PhysSize{}
+	sz.SetFromSVG(sv)
+	// drawing units per physical unit
+	dpu := float32(1)
+	if sz.Size.X > 0 {
+		dpu = sv.ViewBox.Size.X / sz.Size.X
+	}
+	di := &dxfImport{sv: sv, left: sv.ViewBox.Min.X, bottom: sv.ViewBox.Min.Y + sv.ViewBox.Size.Y, layerColors: df.LayerColors}
+	di.scale = dpu
+	if perIn, ok := DXFInsUnitsPerInch(df.InsUnits); ok {
+		di.scale = dpu * UnitsPerInch(sz.Units) / perIn
+	}
+	lw := Prefs.DXF.LineWidth
+	if lw <= 0 {
+		lw = 0.25
+	}
+	lw *= dpu * UnitsPerInch(sz.Units) / units.MmPerInch
+
+	sv.UndoSave("ImportDXF", string(fname))
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	gp := sv.NewEl(svg.KiT_Group)
+	gp.SetProp("fill", "none")
+	gp.SetProp("stroke", DXFColors[0])
+	gp.SetProp("stroke-width", di.num(lw))
+	lgs := map[string]svg.NodeSVG{}
+	for _, ly := range df.Layers {
+		lg := di.newEl(gp, svg.KiT_Group)
+		lg.SetName(DXFLayerName(ly))
+		if cl := DXFColor(df.LayerColors[ly]); cl != "" {
+			lg.SetProp("stroke", cl)
+		}
+		if df.LayerColors[ly] < 0 {
+			lg.SetProp("display", "none")
+		}
+		lgs[ly] = lg
+	}
+	nimp, nskip := 0, 0
+	for _, ent := range df.Entities {
+		if di.Entity(lgs[ent.Layer], ent) {
+			nimp++
+		} else {
+			nskip++
+		}
+	}
+	for _, lg := range lgs { // remove layers with nothing imported
+		if !lg.HasChildren() {
+			lg.Delete(true)
+		}
+	}
+	sv.UpdateEnd(updt)
+	gv.UpdateTreeView()
+	sv.UpdateView(true)
+	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("Imported %d elements from: %s (%d skipped)", nimp, filepath.Base(string(fname)), nskip))
+	return nil
+}
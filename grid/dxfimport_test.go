@@ -0,0 +1,63 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+)
+
+// dxfTestFile has a layer in red, with BYLAYER text on it, and BYLAYER
+// text on a layer that is not in the LAYER table
+var dxfTestFile = strings.Join([]string{
+	"0", "SECTION", "2", "TABLES",
+	"0", "TABLE", "2", "LAYER",
+	"0", "LAYER", "2", "Notes", "62", "1",
+	"0", "ENDTAB",
+	"0", "ENDSEC",
+	"0", "SECTION", "2", "ENTITIES",
+	"0", "TEXT", "8", "Notes", "10", "10", "20", "10", "40", "5", "1", "red",
+	"0", "TEXT", "8", "Other", "10", "10", "20", "30", "40", "5", "62", "256", "1", "black",
+	"0", "ENDSEC",
+	"0", "EOF",
+}, "\n")
+
+// TestImportDXFTextFill tests that imported text is filled with the
+// color of its layer when it has no color of its own
+func TestImportDXFTextFill(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "text.dxf")
+	if err := os.WriteFile(fn, []byte(dxfTestFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gv := openTestView(t, lockTestSVG)
+	if err := gv.ImportDXF(gi.FileName(fn)); err != nil {
+		t.Fatal(err)
+	}
+	fills := map[string]string{}
+	gv.SVG().FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		txt, ok := k.(*svg.Text)
+		if !ok {
+			return ki.Continue
+		}
+		for _, sk := range txt.Kids {
+			if ts, ok := sk.(*svg.Text); ok {
+				fills[ts.Text] = svgPropString(txt, "fill")
+			}
+		}
+		return ki.Break
+	})
+	want := map[string]string{"red": "#ff0000", "black": "#000000"}
+	for str, fill := range want {
+		if fills[str] != fill {
+			t.Errorf("text %q: got fill %q, want %q", str, fills[str], fill)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -43,21 +44,45 @@ type EditState struct {
 	// current shared gradients, referenced by obj-specific gradients
 	Gradients []*Gradient
 
+	// document-level named colors, referenced by fill / stroke of elements
+	NamedColors NamedColors
+
+	// construction guides at arbitrary angles, used as snap targets
+	Guides Guides
+
+	// perspective guide system, with vanishing points that project construction rays
+	Perspective Perspective
+
+	// document-level override of the background, border and grid colors -- nil to use the colors from preferences
+	DocColors *ColorPrefs
+
 	// current text styling info
 	Text TextStyle
 
+	// version of Grid that last saved the opened file, from its metadata -- empty if not saved by Grid
+	FileVersion string `view:"inactive"`
+
+	// foreign content from the opened file, not understood by the svg reader, retained for saving
+	Foreign ForeignXML `view:"-"`
+
 	// undo manager
 	UndoMgr undo.Mgr
 
 	// contents have changed
 	Changed bool `view:"inactive"`
 
+	// autosave file for a drawing that has not been saved yet, unique to this view
+	NewAutoSave string `copy:"-" json:"-" xml:"-" view:"-"`
+
 	// action mutex, protecting start / end of actions
 	ActMu sync.Mutex `copy:"-" json:"-" xml:"-" view:"-"`
 
 	// selected item(s)
 	Selected map[svg.NodeSVG]*SelState `copy:"-" json:"-" xml:"-" view:"-"`
 
+	// nested groups entered in isolation mode, outermost first -- only elements within the last one can be selected
+	Isolated []*svg.Group `copy:"-" json:"-" xml:"-" view:"-"`
+
 	// selection just happened on press, and no drag happened in between
 	SelNoDrag bool
 
@@ -70,6 +95,9 @@ type EditState struct {
 	// current dragging position, mouse coords
 	DragCurPos image.Point
 
+	// how a box select drag combines the elements within the box with the existing selection, from the modifier keys of the last drag event (see BoxSelectMode)
+	BoxSelMode mouse.SelectModes
+
 	// current selection bounding box
 	SelBBox mat32.Box2
 
@@ -94,9 +122,27 @@ type EditState struct {
 	// potential points of alignment for dragging
 	AlignPts [BBoxPointsN][]mat32.Vec2
 
+	// baselines of text elements not in the selection, for aligning text on baselines while dragging -- start of baseline in window coords
+	BaselinePts []mat32.Vec2
+
+	// offset of the baseline of the first selected text element from the top of the selection bbox at the start of dragging -- -1 if no text is selected
+	SelBaseline float32
+
+	// a snap to alignment points is currently engaged -- for snap feedback
+	Snapped bool
+
+	// number of current snap zone sprites in use
+	NSnapZones int
+
 	// number of current node sprites in use
 	NNodeSprites int
 
+	// index of the node of the active path that the node being dragged is currently snapped to -- -1 if none
+	NodeSnapIdx int
+
+	// window coords of the gradient point being dragged, at the start of dragging
+	DragGradPt mat32.Vec2
+
 	// currently manipulating path object
 	ActivePath *svg.Path
 
@@ -106,8 +152,41 @@ type EditState struct {
 	// selected path nodes
 	PathSel map[int]struct{}
 
+	// path that was active before the current one, with a single node selected -- the first path for JoinPaths, nil if none
+	JoinPath *svg.Path
+
+	// index of the node that was selected in JoinPath
+	JoinNode int
+
+	// points drawn so far with the PencilTool, in window coordinates
+	PencilPts []mat32.Vec2
+
+	// index of the path node last clicked -- the node that the node toolbar X, Y values apply to, -1 if none
+	CurNode int
+
+	// node toolbar X, Y values are relative: move the node by that amount, instead of to that position
+	NodeRel bool
+
 	// current path command indexes within PathNodes -- where the commands start
 	PathCmds []int
+
+	// name of last live path operation (e.g., RoundCorners) -- repeating it on the same selection re-applies it to the original path data
+	PathOp string `view:"-"`
+
+	// original path data of paths affected by the last live path operation
+	PathOpOrig map[*svg.Path][]svg.PathData `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// selected path nodes of the active path at the start of the last live path operation
+	PathOpNodes map[int]struct{} `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// radius of the last live RoundCorners operation, for the round corners handle
+	RoundRadius float32 `view:"-"`
+
+	// number of pastes since the last copy -- each paste is offset by PasteOffset more than the last -- -1 after a cut, so the first paste is in place
+	PasteN int `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// time of the last nudge of the selection, for combining a burst of nudges into one undo step
+	LastNudge time.Time `copy:"-" json:"-" xml:"-" view:"-"`
 }
 
 // Init initializes the edit state -- e.g. after opening a new file
@@ -116,6 +195,13 @@ func (es *EditState) Init() {
 	es.ActData = ""
 	es.CurLayer = ""
 	es.Gradients = nil
+	es.NamedColors = nil
+	es.Guides = nil
+	es.Perspective = Perspective{}
+	es.DocColors = nil
+	es.FileVersion = ""
+	es.Foreign.Reset()
+	es.Isolated = nil
 	es.UndoMgr.Reset()
 	es.Changed = false
 }
@@ -346,6 +432,46 @@ func (es *EditState) SelectAction(itm svg.NodeSVG, mode mouse.SelectModes, pos i
 	}
 }
 
+// SetJoinNode records the active path as the JoinPath when it has a
+// single node selected, before another path becomes active, so the two
+// can be joined at their selected nodes (see JoinPaths)
+func (es *EditState) SetJoinNode() {
+	if es.ActivePath == nil {
+		return
+	}
+	es.JoinPath = nil
+	if len(es.PathSel) != 1 {
+		return
+	}
+	for idx := range es.PathSel {
+		es.JoinNode = idx
+	}
+	es.JoinPath = es.ActivePath
+}
+
+// SelectNode updates the selected path nodes for a select action on
+// given node index, according to the selection mode (ExtendContinuous,
+// ExtendOne toggle the node).  A SelectOne action on an already
+// selected node retains the current selection, so it can be dragged.
+func (es *EditState) SelectNode(idx int, mode mouse.SelectModes) {
+	if es.PathSel == nil {
+		es.PathSel = make(map[int]struct{})
+	}
+	_, has := es.PathSel[idx]
+	switch mode {
+	case mouse.SelectOne:
+		if !has {
+			es.PathSel = map[int]struct{}{idx: {}}
+		}
+	case mouse.ExtendContinuous, mouse.ExtendOne:
+		if has {
+			delete(es.PathSel, idx)
+		} else {
+			es.PathSel[idx] = struct{}{}
+		}
+	}
+}
+
 func (es *EditState) SelectedToRecents() {
 	for k, v := range es.Selected {
 		es.RecentlySelected[k] = v
@@ -366,7 +492,8 @@ func (es *EditState) StartRecents(pos image.Point) {
 // PosInLastSel returns true if position is within tolerance of
 // last selection point
 func (es *EditState) PosInLastSel(pos image.Point) bool {
-	tol := image.Point{Prefs.SnapTol, Prefs.SnapTol}
+	t := int(mat32.Round(SnapTolPx()))
+	tol := image.Point{t, t}
 	bb := image.Rectangle{Min: es.LastSelPos.Sub(tol), Max: es.LastSelPos.Add(tol)}
 	return pos.In(bb)
 }
@@ -411,10 +538,55 @@ func (es *EditState) DragSelStart(pos image.Point) {
 	}
 }
 
+// CurPathNode returns the current path node of the active path,
+// last clicked in the node tool -- nil if none
+func (es *EditState) CurPathNode() *PathNode {
+	if es.ActivePath == nil || es.CurNode < 0 || es.CurNode >= len(es.PathNodes) {
+		return nil
+	}
+	return es.PathNodes[es.CurNode]
+}
+
+// CurSubPathNode returns the index of a node in the subpath of the active
+// path that subpath actions apply to: the current node, or else the first
+// selected node, or else the last node of the path -- -1 if no active path
+func (es *EditState) CurSubPathNode() int {
+	if es.ActivePath == nil || len(es.PathNodes) == 0 {
+		return -1
+	}
+	if es.CurPathNode() != nil {
+		return es.CurNode
+	}
+	idx := -1
+	for i := range es.PathSel {
+		if i < len(es.PathNodes) && (idx < 0 || i < idx) {
+			idx = i
+		}
+	}
+	if idx >= 0 {
+		return idx
+	}
+	return len(es.PathNodes) - 1
+}
+
+// CurSubPathClosed returns whether the subpath of the active path that
+// subpath actions apply to (see CurSubPathNode) is closed, and false
+// for ok if there is no active path
+func (es *EditState) CurSubPathClosed() (closed, ok bool) {
+	idx := es.CurSubPathNode()
+	if idx < 0 {
+		return false, false
+	}
+	_, ed := PathSubPathEnds(es.PathNodes, idx)
+	_, closed = PathSubPathCloseIdx(es.ActivePath, es.PathNodes[ed])
+	return closed, true
+}
+
 // DragNodeStart captures the current state at start of node dragging.
 // position is starting position.
 func (es *EditState) DragNodeStart(pos image.Point) {
 	es.DragStartPos = pos
+	es.NodeSnapIdx = -1
 }
 
 //////////////////////////////////////////////////////
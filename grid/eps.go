@@ -0,0 +1,311 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+func init() {
+	RegisterExporter(".eps", ExportEPSFunc)
+}
+
+// epsProlog defines the short path operators used in the EPS body
+const epsProlog = `/m { moveto } bind def
+/l { lineto } bind def
+/c { curveto } bind def
+/h { closepath } bind def
+`
+
+// epsExport holds the state for exporting one drawing to EPS
+type epsExport struct {
+	ExportXForm
+	w *bufio.Writer
+
+	// current element path, in PostScript operators
+	path strings.Builder
+}
+
+// num formats a number for PostScript output
+func (ee *epsExport) num(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+// op adds given points and operator to the current path
+func (ee *epsExport) op(xf mat32.Mat2, op string, pts ...mat32.Vec2) {
+	for _, pt := range pts {
+		p := ee.Pt(xf, pt)
+		ee.path.WriteString(ee.num(p.X) + " " + ee.num(p.Y) + " ")
+	}
+	ee.path.WriteString(op + "\n")
+}
+
+// arc adds an elliptical arc to the current path as cubic beziers of
+// at most 90 degrees each, which are exact under the transform,
+// from angle a1 through sweep angle da (see EllipsePt)
+func (ee *epsExport) arc(xf mat32.Mat2, ctr, rad mat32.Vec2, rot, a1, da float32) {
+	n := int(mat32.Ceil(mat32.Abs(da) / (mat32.Pi / 2)))
+	if n < 1 {
+		n = 1
+	}
+	step := da / float32(n)
+	k := 4.0 / 3.0 * mat32.Tan(step/4)
+	// derivative of EllipsePt with respect to the angle
+	tan := func(a float32) mat32.Vec2 {
+		return EllipsePt(mat32.Vec2{}, rad, rot, a+mat32.Pi/2)
+	}
+	a := a1
+	for i := 0; i < n; i++ {
+		p1 := EllipsePt(ctr, rad, rot, a)
+		p2 := EllipsePt(ctr, rad, rot, a+step)
+		c1 := p1.Add(tan(a).MulScalar(k))
+		c2 := p2.Sub(tan(a + step).MulScalar(k))
+		ee.op(xf, "c", c1, c2, p2)
+		a += step
+	}
+}
+
+// points adds given points to the current path
+func (ee *epsExport) points(xf mat32.Mat2, pts []mat32.Vec2, closed bool) {
+	for i, pt := range pts {
+		if i == 0 {
+			ee.op(xf, "m", pt)
+		} else {
+			ee.op(xf, "l", pt)
+		}
+	}
+	if closed && len(pts) > 0 {
+		ee.op(xf, "h")
+	}
+}
+
+// ellipse adds a closed ellipse to the current path
+func (ee *epsExport) ellipse(xf mat32.Mat2, ctr, rad mat32.Vec2) {
+	ee.op(xf, "m", mat32.V2(ctr.X+rad.X, ctr.Y))
+	ee.arc(xf, ctr, rad, 0, 0, 2*mat32.Pi)
+	ee.op(xf, "h")
+}
+
+// rect adds a rectangle to the current path, with corners rounded by
+// given radius, as rendered
+func (ee *epsExport) rect(xf mat32.Mat2, pos, sz mat32.Vec2, r float32) {
+	if r <= 0 {
+		ee.points(xf, []mat32.Vec2{pos, mat32.V2(pos.X+sz.X, pos.Y), pos.Add(sz), mat32.V2(pos.X, pos.Y+sz.Y)}, true)
+		return
+	}
+	x0, x1, x2, x3 := pos.X, pos.X+r, pos.X+sz.X-r, pos.X+sz.X
+	y0, y1, y2, y3 := pos.Y, pos.Y+r, pos.Y+sz.Y-r, pos.Y+sz.Y
+	rad := mat32.V2(r, r)
+	hp := mat32.Pi / 2
+	ee.op(xf, "m", mat32.V2(x1, y0))
+	ee.op(xf, "l", mat32.V2(x2, y0))
+	ee.arc(xf, mat32.V2(x2, y1), rad, 0, 3*hp, hp)
+	ee.op(xf, "l", mat32.V2(x3, y2))
+	ee.arc(xf, mat32.V2(x2, y2), rad, 0, 0, hp)
+	ee.op(xf, "l", mat32.V2(x1, y3))
+	ee.arc(xf, mat32.V2(x1, y2), rad, 0, hp, hp)
+	ee.op(xf, "l", mat32.V2(x0, y1))
+	ee.arc(xf, mat32.V2(x1, y1), rad, 0, 2*hp, hp)
+	ee.op(xf, "h")
+}
+
+// pathData adds svg path data to the current path
+func (ee *epsExport) pathData(xf mat32.Mat2, data []svg.PathData) {
+	zidx := PathCloseIdxs(data)
+	zi := 0
+	var lcp, sst mat32.Vec2 // last point, subpath start
+	closeTo := func(idx int) {
+		for zi < len(zidx) && zidx[zi] < idx {
+			ee.op(xf, "h")
+			lcp = sst
+			zi++
+		}
+	}
+	svg.PathDataIterFunc(data, func(idx int, cmd svg.PathCmds, ptIdx int, cp mat32.Vec2, ctrls []mat32.Vec2) bool {
+		closeTo(idx)
+		switch cmd {
+		case svg.PcM, svg.Pcm:
+			if ptIdx == 0 {
+				ee.op(xf, "m", cp)
+				sst = cp
+			} else {
+				ee.op(xf, "l", cp)
+			}
+		case svg.PcC, svg.Pcc:
+			ee.op(xf, "c", ctrls[0], ctrls[1], cp)
+		case svg.PcS, svg.Pcs: // note: ctrls are in reverse order for S
+			ee.op(xf, "c", ctrls[1], ctrls[0], cp)
+		case svg.PcQ, svg.Pcq, svg.PcT, svg.Pct: // as exact cubic
+			q := ctrls[0]
+			ee.op(xf, "c", lcp.Add(q.Sub(lcp).MulScalar(2.0/3.0)), cp.Add(q.Sub(cp).MulScalar(2.0/3.0)), cp)
+		case svg.PcA, svg.Pca:
+			rot, a1, da := PathArcAngles(ctrls, cp)
+			ee.arc(xf, ctrls[0], ctrls[2], rot, a1, da)
+		default:
+			ee.op(xf, "l", cp)
+		}
+		lcp = cp
+		return ki.Continue
+	})
+	closeTo(len(data))
+}
+
+// EPSColor returns the PostScript RGB color for given color spec, and
+// false if it is fully transparent.  Gradients are approximated
+// by the average of their stop colors.
+func EPSColor(cs *gist.ColorSpec) (rgb [3]float32, ok bool) {
+	if cs.Source != gist.SolidColor && cs.Gradient != nil && len(cs.Gradient.Stops) > 0 {
+		var tot float32
+		for _, st := range cs.Gradient.Stops {
+			var c gist.Color
+			c.SetColor(st.StopColor)
+			r, g, b, a := c.ToNPFloat32()
+			a *= float32(st.Opacity)
+			rgb[0] += r * a
+			rgb[1] += g * a
+			rgb[2] += b * a
+			tot += a
+		}
+		if tot == 0 {
+			return rgb, false
+		}
+		for i := range rgb {
+			rgb[i] /= tot
+		}
+		return rgb, true
+	}
+	r, g, b, a := cs.Color.ToNPFloat32()
+	return [3]float32{r, g, b}, a > 0
+}
+
+// setColor writes the setrgbcolor for given color spec,
+// returning false if it is fully transparent
+func (ee *epsExport) setColor(cs *gist.ColorSpec, opacity float32) bool {
+	rgb, ok := EPSColor(cs)
+	if !ok || opacity <= 0 {
+		return false
+	}
+	fmt.Fprintf(ee.w, "%s %s %s setrgbcolor\n", ee.num(rgb[0]), ee.num(rgb[1]), ee.num(rgb[2]))
+	return true
+}
+
+// shape adds the outline of given element, with given transform (from
+// ParTransform), to the current path, returning false if it is not one
+// of the exported shapes
+func (ee *epsExport) shape(sii svg.NodeSVG, xf mat32.Mat2) bool {
+	switch nd := sii.(type) {
+	case *svg.Line:
+		ee.points(xf, []mat32.Vec2{nd.Start, nd.End}, false)
+	case *svg.Polygon:
+		ee.points(xf, nd.Points, true)
+	case *svg.Polyline:
+		ee.points(xf, nd.Points, false)
+	case *svg.Rect:
+		ee.rect(xf, nd.Pos, nd.Size, nd.Radius.X)
+	case *svg.Circle:
+		ee.ellipse(xf, nd.Pos, mat32.V2(nd.Radius, nd.Radius))
+	case *svg.Ellipse:
+		ee.ellipse(xf, nd.Pos, nd.Radii)
+	case *svg.Path:
+		ee.pathData(xf, nd.Data)
+	default:
+		return false
+	}
+	return true
+}
+
+// strokeParams returns the line width and dashes in output units, and
+// the PostScript line cap and join codes, which are the same in PDF,
+// for given stroke style of an element with given transform
+func (ee *epsExport) strokeParams(xf mat32.Mat2, ps *gist.Stroke) (wd float32, lcap, join int, dsh []string) {
+	sc, _ := ee.XFScale(xf)
+	sc *= ee.Scale
+	wd = ps.Width.Dots
+	if wd == 0 {
+		wd = ps.Width.Val
+	}
+	switch ps.Cap {
+	case gist.LineCapRound:
+		lcap = 1
+	case gist.LineCapSquare:
+		lcap = 2
+	}
+	switch ps.Join {
+	case gist.LineJoinRound, gist.LineJoinArcs, gist.LineJoinArcsClip:
+		join = 1
+	case gist.LineJoinBevel:
+		join = 2
+	}
+	dsh = make([]string, len(ps.Dashes))
+	for i, d := range ps.Dashes {
+		dsh[i] = ee.num(float32(d) * sc)
+	}
+	return wd * sc, lcap, join, dsh
+}
+
+// Node exports one element
+func (ee *epsExport) Node(sii svg.NodeSVG) {
+	g := sii.AsSVGNode()
+	xf := g.ParTransform(true)
+	ee.path.Reset()
+	if !ee.shape(sii, xf) || ee.path.Len() == 0 {
+		return
+	}
+	pc := &g.Pnt
+	ee.w.WriteString("newpath\n" + ee.path.String())
+	if pc.FillStyle.On {
+		ee.w.WriteString("gsave\n")
+		if ee.setColor(&pc.FillStyle.Color, pc.FillStyle.Opacity) {
+			if pc.FillStyle.Rule == gist.FillRuleEvenOdd {
+				ee.w.WriteString("eofill\n")
+			} else {
+				ee.w.WriteString("fill\n")
+			}
+		}
+		ee.w.WriteString("grestore\n")
+	}
+	if pc.StrokeStyle.On && ee.setColor(&pc.StrokeStyle.Color, pc.StrokeStyle.Opacity) {
+		ps := &pc.StrokeStyle
+		wd, lcap, join, dsh := ee.strokeParams(xf, ps)
+		fmt.Fprintf(ee.w, "%s setlinewidth %d setlinecap %d setlinejoin %s setmiterlimit\n", ee.num(wd), lcap, join, ee.num(mat32.Max(ps.MiterLimit, 1)))
+		fmt.Fprintf(ee.w, "[%s] 0 setdash\n", strings.Join(dsh, " "))
+		ee.w.WriteString("stroke\n")
+	}
+}
+
+// ExportEPSFunc is the ExportFunc for the .eps format, which writes
+// an Encapsulated PostScript file the size of the PhysSize, in points,
+// with lines, polylines, polygons, rects, circles, ellipses and paths
+// as PostScript paths with their fill and stroke colors, widths, caps,
+// joins and dashes.  All curves are exact, as beziers.  PostScript has
+// no transparency, so opacity is ignored (except that fully transparent
+// paint is skipped), and gradients are approximated by the average of
+// their stop colors.  Text, images and markers are not exported.
+func ExportEPSFunc(sv *SVGView, sz *PhysSize, w io.Writer) error {
+	ee := &epsExport{ExportXForm: NewExportXForm(sv, sz, units.PtPerInch), w: bufio.NewWriter(w)}
+	pw := sz.Size.X * units.PtPerInch / UnitsPerInch(sz.Units)
+	ph := sz.Size.Y * units.PtPerInch / UnitsPerInch(sz.Units)
+	fmt.Fprintf(ee.w, "%%!PS-Adobe-3.0 EPSF-3.0\n%%%%Creator: Grid\n")
+	fmt.Fprintf(ee.w, "%%%%BoundingBox: 0 0 %d %d\n", int(mat32.Ceil(pw)), int(mat32.Ceil(ph)))
+	fmt.Fprintf(ee.w, "%%%%HiResBoundingBox: 0 0 %s %s\n", ee.num(pw), ee.num(ph))
+	fmt.Fprintf(ee.w, "%%%%LanguageLevel: 2\n%%%%Pages: 1\n%%%%EndComments\n")
+	fmt.Fprintf(ee.w, "%%%%BeginProlog\n%s%%%%EndProlog\n%%%%Page: 1 1\ngsave\n", epsProlog)
+	if sv.exportClip != nil {
+		fmt.Fprintf(ee.w, "0 0 %s %s rectclip\n", ee.num(pw), ee.num(ph))
+	}
+	sv.ExportNodes(ee.Node)
+	ee.w.WriteString("grestore\nshowpage\n%%EOF\n")
+	return ee.w.Flush()
+}
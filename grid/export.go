@@ -0,0 +1,482 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// ExportPrefs are preferences for the region covered by exports, and
+// what is drawn into raster exports in addition to the drawing itself
+type ExportPrefs struct {
+
+	// region of the drawing covered by exports: the page, the bounding box of all the contents, or of the selection
+	Extent ExportExtents
+
+	// margin added around the contents or selection export extents, in the physical units of the drawing (e.g., mm)
+	Margin float32 `min:"0"`
+
+	// with the selection export extent, export only the selected elements, leaving out any others within the extent
+	SelOnly bool
+
+	// clip the content to the export extent, so elements extending beyond it are cut off, as when printing -- otherwise they may be partly included, depending on the format (DXF exports are never clipped)
+	Clip bool
+
+	// draw the grid into exported images, as graph-paper background
+	Grid bool
+
+	// draw construction guides into exported images
+	Guides bool
+
+	// draw the page border into exported images
+	Border bool
+}
+
+// ExportOverlaySVG returns the svg elements to draw behind the drawing
+// in raster exports, according to given export prefs, over given region in
+// drawing coordinates, with given grid spacing and line width
+func (sv *SVGView) ExportOverlaySVG(ep *ExportPrefs, bb mat32.Box2, spc, wd float32) string {
+	var els []string
+	if ep.Grid {
+		els = append(els, sv.GridSVG(bb, spc, wd))
+	}
+	if ep.Guides {
+		els = append(els, sv.GuidesSVG(bb, wd))
+	}
+	if ep.Border {
+		els = append(els, sv.BorderSVG(wd))
+	}
+	return strings.Join(els, "\n")
+}
+
+// BorderSVG returns an svg element drawing the page border
+// with given line width
+func (sv *SVGView) BorderSVG(wd float32) string {
+	pos := sv.ViewBox.Min
+	sz := sv.ViewBox.Size
+	return fmt.Sprintf(`<rect id="export-border" style="fill:none;stroke:%s;stroke-width:%g" x="%g" y="%g" width="%g" height="%g" />`, sv.Colors().Border.HexString(), wd, pos.X, pos.Y, sz.X, sz.Y)
+}
+
+// ExportExtents are the regions of the drawing covered by exports
+type ExportExtents int
+
+const (
+	// ExportPage exports the page, as set by the physical size
+	ExportPage ExportExtents = iota
+
+	// ExportContents exports the bounding box of all the contents
+	ExportContents
+
+	// ExportSelection exports the bounding box of the selected elements
+	ExportSelection
+
+	ExportExtentsN
+)
+
+//go:generate stringer -type=ExportExtents
+
+var KiT_ExportExtents = kit.Enums.AddEnum(ExportExtentsN, kit.NotBitFlag, nil)
+
+func (ev ExportExtents) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *ExportExtents) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// ExtentBBox returns the region of the drawing for given export
+// extent, in drawing coordinates, or an error if it is empty.  The
+// contents and selection extents include the Margin in Prefs.Export.
+func (sv *SVGView) ExtentBBox(ext ExportExtents) (mat32.Box2, error) {
+	var bb mat32.Box2
+	switch ext {
+	case ExportContents:
+		bb = sv.ContentsBBox()
+	case ExportSelection:
+		es := sv.EditState()
+		if !es.HasSelected() {
+			return bb, errors.New("export: no elements selected for exporting the selection")
+		}
+		bb = es.SelBBox
+	default:
+		return mat32.Box2{Min: sv.ViewBox.Min, Max: sv.ViewBox.Min.Add(sv.ViewBox.Size)}, nil
+	}
+	if bb.IsEmpty() {
+		return bb, fmt.Errorf("export: the %v export extent is empty", ext)
+	}
+	bb.Min = bb.Min.DivScalar(sv.Scale).Sub(sv.Trans)
+	bb.Max = bb.Max.DivScalar(sv.Scale).Sub(sv.Trans)
+	if upd := sv.UnitsPerDoc(); Prefs.Export.Margin > 0 && upd > 0 {
+		bb.ExpandByScalar(Prefs.Export.Margin / upd)
+	}
+	return bb, nil
+}
+
+// ExportSelected returns true if given element is exported when exporting
+// only the selection: it or one of the groups it is in is selected
+func (sv *SVGView) ExportSelected(k ki.Ki) bool {
+	es := sv.EditState()
+	for ; k != nil && k != sv.This(); k = k.Parent() {
+		if sn, ok := k.(svg.NodeSVG); ok {
+			if _, sel := es.Selected[sn]; sel {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ExportHasSelected returns true if given element is selected or has
+// selected elements within it, so that it is at least partly exported
+// when exporting only the selection
+func (sv *SVGView) ExportHasSelected(k ki.Ki) bool {
+	has := false
+	k.FuncDownMeFirst(0, nil, func(kk ki.Ki, level int, d any) bool {
+		if sn, ok := kk.(svg.NodeSVG); ok {
+			if _, sel := sv.EditState().Selected[sn]; sel {
+				has = true
+				return ki.Break
+			}
+		}
+		return !has
+	})
+	return has
+}
+
+// ExportNodes calls given function on each of the elements of the
+// drawing, in drawing order, for exporters that write the elements
+// directly -- the defs and metadata are skipped, and when exporting
+// only the selection (see ExportWith), the elements that are not selected
+// or within a selected group
+func (sv *SVGView) ExportNodes(fun func(sii svg.NodeSVG)) {
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if k == sv.This() {
+			return ki.Continue
+		}
+		if k == sv.Defs.This() || NodeIsMetaData(k) {
+			return ki.Break
+		}
+		sii, issvg := k.(svg.NodeSVG)
+		if !issvg {
+			return ki.Break
+		}
+		if sv.exportSel && !sv.ExportSelected(k) {
+			if sv.ExportHasSelected(k) {
+				return ki.Continue // a group with selected elements
+			}
+			return ki.Break
+		}
+		fun(sii)
+		return ki.Continue
+	})
+}
+
+// SelectedOnlySVG returns given svg file contents of the drawing with
+// only the selected elements, and the groups and layers that they are in
+// -- all the defs are kept, and the root svg element, with its size.
+// Elements are matched by their path of names from the root (see
+// exportNodePath), as names are only unique among siblings.
+func (sv *SVGView) SelectedOnlySVG(b []byte) ([]byte, error) {
+	es := sv.EditState()
+	paths := map[string]bool{} // true for selected, false for parents
+	for sn := range es.Selected {
+		paths[exportNodePath(sn, sv.This())] = true
+		for pk := sn.Parent(); pk != nil && pk != sv.This(); pk = pk.Parent() {
+			pp := exportNodePath(pk, sv.This())
+			if _, has := paths[pp]; !has {
+				paths[pp] = false
+			}
+		}
+	}
+	tmp := &svg.SVG{}
+	tmp.InitName(tmp, "export-sel")
+	err := tmp.ReadXML(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	var prune func(par ki.Ki)
+	prune = func(par ki.Ki) {
+		for i := par.NumChildren() - 1; i >= 0; i-- {
+			k := par.Child(i)
+			sel, has := paths[exportNodePath(k, tmp.This())]
+			switch {
+			case !has:
+				par.DeleteChildAtIndex(i, ki.DestroyKids)
+			case !sel:
+				prune(k)
+			}
+		}
+	}
+	prune(tmp.This())
+	var out bytes.Buffer
+	err = tmp.WriteXML(&out, true)
+	tmp.Destroy()
+	if err != nil {
+		return nil, err
+	}
+	ob := out.Bytes()
+	if Prefs.StableSave {
+		ob = StableXMLPrec(ob, Prefs.StablePrec)
+	}
+	return ob, nil
+}
+
+// SetExportExtent sets the page of the drawing (ViewBox and physical
+// size) to given region in drawing coordinates, keeping the same
+// physical units per drawing unit, so that exporters export just that
+// region, and optionally clips the content to it (see ExportSVGBytes).
+// Returns a function that restores the page, which must be called when
+// done exporting.
+func (sv *SVGView) SetExportExtent(bb mat32.Box2, clip bool) func() {
+	vb, pw, ph := sv.ViewBox, sv.PhysWidth, sv.PhysHeight
+	sz := bb.Size()
+	if vb.Size.X > 0 {
+		sv.PhysWidth.Val *= sz.X / vb.Size.X
+	}
+	if vb.Size.Y > 0 {
+		sv.PhysHeight.Val *= sz.Y / vb.Size.Y
+	}
+	sv.ViewBox.Min = bb.Min
+	sv.ViewBox.Size = sz
+	if clip {
+		sv.exportClip = &bb
+	}
+	return func() {
+		sv.ViewBox, sv.PhysWidth, sv.PhysHeight = vb, pw, ph
+		sv.exportClip = nil
+	}
+}
+
+// ClipSVGToBox wraps the contents of given svg file contents in a group
+// clipped to given region, in drawing coordinates
+func ClipSVGToBox(b []byte, bb mat32.Box2) []byte {
+	sz := bb.Size()
+	cp := fmt.Sprintf(`<clipPath id="export-clip"><rect x="%g" y="%g" width="%g" height="%g" /></clipPath><g id="export-clip-group" clip-path="url(#export-clip)">`, bb.Min.X, bb.Min.Y, sz.X, sz.Y)
+	ei := bytes.LastIndex(b, []byte("</svg>"))
+	if ei < 0 {
+		return b
+	}
+	var out bytes.Buffer
+	out.Write(b[:ei])
+	out.WriteString("</g>\n")
+	out.Write(b[ei:])
+	return InsertSVGBehind(out.Bytes(), cp)
+}
+
+// InsertSVGBehind inserts given svg elements into given svg file contents
+// just after the root svg start tag, so they are drawn behind everything else
+func InsertSVGBehind(b []byte, els string) []byte {
+	if els == "" {
+		return b
+	}
+	si := bytes.Index(b, []byte("<svg"))
+	if si < 0 {
+		return b
+	}
+	ri := si + bytes.IndexByte(b[si:], '>') + 1
+	var out bytes.Buffer
+	out.Write(b[:ri])
+	out.WriteString("\n" + els + "\n")
+	out.Write(b[ri:])
+	return out.Bytes()
+}
+
+// GridSVG returns svg elements drawing the grid with given spacing and
+// line width, over given region, in drawing coordinates
+func (sv *SVGView) GridSVG(bb mat32.Box2, spc, wd float32) string {
+	if spc <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	off := sv.GridOffset
+	x0 := mat32.Ceil((bb.Min.X-off.X)/spc)*spc + off.X
+	for x := x0; x <= bb.Max.X; x += spc {
+		sb.WriteString(fmt.Sprintf("M %g,%g V %g ", x, bb.Min.Y, bb.Max.Y))
+	}
+	y0 := mat32.Ceil((bb.Min.Y-off.Y)/spc)*spc + off.Y
+	for y := y0; y <= bb.Max.Y; y += spc {
+		sb.WriteString(fmt.Sprintf("M %g,%g H %g ", bb.Min.X, y, bb.Max.X))
+	}
+	return fmt.Sprintf(`<path id="export-grid" style="fill:none;stroke:%s;stroke-width:%g" d="%s" />`, sv.Colors().Grid.HexString(), wd, strings.TrimSpace(sb.String()))
+}
+
+// viewStateAttrRe matches the view zoom and position attributes that
+// SetMetaData records for restoring the view
+var viewStateAttrRe = regexp.MustCompile(`\s+inkscape:(zoom|cx|cy)="[^"]*"`)
+
+// exportNodePath returns the path of names to given node from given
+// root, not including the root, to match the same node in a copy of
+// the drawing read from its svg file contents
+func exportNodePath(k, root ki.Ki) string {
+	pth := ""
+	for ; k != nil && k != root; k = k.Parent() {
+		pth = "/" + k.Name() + pth
+	}
+	return pth
+}
+
+// ExportSVGBytes returns the drawing as svg file contents for exporting,
+// which is the same as SVGBytes but without the view zoom and position
+// metadata.  Exports render the page from drawing coordinates, at the
+// requested size or resolution, so the output is the same regardless of
+// the current zoom level of the view (except ExportViewPNG, which is
+// explicitly of the current view).  When exporting only the selection
+// (see ExportWith), the other elements are left out (see SelectedOnlySVG),
+// and when exporting with clipping to the export extent (see
+// SetExportExtent), the contents are clipped to it.
+func (gv *GridView) ExportSVGBytes() ([]byte, error) {
+	b, err := gv.SVGBytes()
+	if err != nil {
+		return nil, err
+	}
+	sv := gv.SVG()
+	if sv.exportSel {
+		b, err = sv.SelectedOnlySVG(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	b = viewStateAttrRe.ReplaceAll(b, nil)
+	if sv.exportClip != nil {
+		b = ClipSVGToBox(b, *sv.exportClip)
+	}
+	return b, nil
+}
+
+// ExportSVGBytes returns the drawing as svg file contents for exporting,
+// as GridView.ExportSVGBytes, or for a view opened without a GridView
+// (see OpenSVGHeadless), as written from the view, clipped as needed
+func (sv *SVGView) ExportSVGBytes() ([]byte, error) {
+	if sv.GridView != nil {
+		return sv.GridView.ExportSVGBytes()
+	}
+	var b bytes.Buffer
+	err := sv.WriteXML(&b, true)
+	if err != nil {
+		return nil, err
+	}
+	ob := viewStateAttrRe.ReplaceAll(b.Bytes(), nil)
+	if sv.exportClip != nil {
+		ob = ClipSVGToBox(ob, *sv.exportClip)
+	}
+	return ob, nil
+}
+
+// UnitsPerInch returns the number of given physical units per inch,
+// treating units other than absolute lengths as px
+func UnitsPerInch(un units.Units) float32 {
+	switch un {
+	case units.In:
+		return 1
+	case units.Cm:
+		return units.CmPerInch
+	case units.Mm:
+		return units.MmPerInch
+	case units.Pt:
+		return units.PtPerInch
+	case units.Pc:
+		return units.PcPerInch
+	}
+	return units.PxPerInch
+}
+
+// ExportXForm converts the local coordinates of elements to the
+// coordinates of a vector export format, relative to the page, with
+// Y pointing up, in the output units, for native exporters (e.g., DXF)
+type ExportXForm struct {
+
+	// the view being exported
+	SV *SVGView
+
+	// output units per drawing unit
+	Scale float32
+
+	// page height in drawing units, for flipping Y
+	Height float32
+
+	// window offset of the view
+	svoff mat32.Vec2
+}
+
+// NewExportXForm returns an ExportXForm for given view, of given
+// physical size, with given number of output units per inch
+func NewExportXForm(sv *SVGView, sz *PhysSize, perInch float32) ExportXForm {
+	ex := ExportXForm{SV: sv, Scale: 1, Height: sv.ViewBox.Size.Y, svoff: mat32.NewVec2FmPoint(sv.WinBBox.Min)}
+	if sv.ViewBox.Size.X > 0 {
+		ex.Scale = (sz.Size.X / sv.ViewBox.Size.X) * perInch / UnitsPerInch(sz.Units)
+	}
+	return ex
+}
+
+// Pt returns the output coordinates of given point in the local
+// coordinates of an element with given transform (from ParTransform)
+func (ex *ExportXForm) Pt(xf mat32.Mat2, pt mat32.Vec2) mat32.Vec2 {
+	dp := ex.SV.WinToDoc(xf.MulVec2AsPt(pt).Add(ex.svoff)).Sub(ex.SV.ViewBox.Min)
+	return mat32.V2(dp.X*ex.Scale, (ex.Height-dp.Y)*ex.Scale)
+}
+
+// XFScale returns the overall scale factor of given transform (from
+// ParTransform) from local to drawing units, and whether it is a
+// similarity transform (uniform scaling, no skew), which preserves circles
+func (ex *ExportXForm) XFScale(xf mat32.Mat2) (float32, bool) {
+	c1 := mat32.V2(xf.XX, xf.YX)
+	c2 := mat32.V2(xf.XY, xf.YY)
+	l1 := c1.Length()
+	l2 := c2.Length()
+	sc := mat32.Max(l1, l2) / ex.SV.Scale
+	sim := mat32.Abs(l1-l2) <= 1.0e-4*mat32.Max(l1, l2) && mat32.Abs(c1.Dot(c2)) <= 1.0e-4*l1*l2
+	return sc, sim
+}
+
+// ViewBBox returns the region of the drawing that is currently visible
+// in the view, in drawing coordinates
+func (sv *SVGView) ViewBBox() mat32.Box2 {
+	sz := mat32.NewVec2FmPoint(sv.WinBBox.Size())
+	bb := mat32.Box2{}
+	bb.Min = sv.Trans.Negate()
+	bb.Max = bb.Min.Add(sz.DivScalar(sv.Scale))
+	return bb
+}
+
+// ExportViewPNG exports the region of the drawing currently visible in
+// the view to a PNG image (auto-names to same name with _view.png suffix),
+// rendering it natively at the current screen resolution, optionally
+// including the grid as currently displayed.  Guides and page border are
+// included according to Prefs.Export.  Content outside the view is not
+// rendered.
+func (gv *GridView) ExportViewPNG(grid bool) error {
+	sv := gv.SVG()
+	ep := Prefs.Export
+	ep.Grid = grid
+	isz := sv.WinBBox.Size()
+	fext := filepath.Ext(string(gv.Filename))
+	onm := strings.TrimSuffix(string(gv.Filename), fext) + "_view.png"
+	restore := sv.SetExportExtent(sv.ViewBBox(), false)
+	defer restore()
+	err := sv.ExportToFile(gi.FileName(onm), func(sv *SVGView, sz *PhysSize, w io.Writer) error {
+		img, err := sv.RenderImageOverlay(sz, isz.X, isz.Y, &ep, sv.GridEff)
+		if err != nil {
+			return err
+		}
+		return png.Encode(w, img)
+	})
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	gv.SetStatus("Exported view to: " + onm)
+	return nil
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+)
+
+// setTestZoom sets the zoom level and position of given view,
+// as zooming in the GUI does
+func setTestZoom(sv *SVGView, scale, tx, ty float32) {
+	sv.Scale = scale
+	sv.Trans.Set(tx, ty)
+	sv.SetTransform()
+	sv.SetMetaData()
+	sv.HeadlessRender()
+}
+
+// TestExportZoom tests that the PNG, PDF and svg exports are
+// the same at all zoom levels of the view
+func TestExportZoom(t *testing.T) {
+	b, err := os.ReadFile("../testdata/shapes.svg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gv := openTestView(t, string(b))
+	sv := gv.SVG()
+	sep := Prefs.Export
+	defer func() { Prefs.Export = sep }()
+	Prefs.Export = ExportPrefs{Extent: ExportPage, Grid: true, Border: true}
+	dir := t.TempDir()
+	var svgs, pngs, pdfs [][]byte
+	zooms := [][3]float32{{sv.Scale, sv.Trans.X, sv.Trans.Y}, {3, -40, -25}, {0.25, 100, 80}}
+	for i, zm := range zooms {
+		setTestZoom(sv, zm[0], zm[1], zm[2])
+		if i > 0 {
+			if vb, _ := gv.SVGBytes(); !bytes.Contains(vb, []byte(fmt.Sprintf(`inkscape:zoom="%g"`, zm[0]))) {
+				t.Errorf("zoom %g not saved in the drawing metadata", zm[0])
+			}
+		}
+		sb, err := gv.ExportSVGBytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		svgs = append(svgs, sb)
+		pfn := filepath.Join(dir, "zoom.png")
+		if err := gv.ExportPNG(gi.FileName(pfn), 320, 0); err != nil {
+			t.Fatal(err)
+		}
+		pb, _ := os.ReadFile(pfn)
+		pngs = append(pngs, pb)
+		dfn := filepath.Join(dir, "zoom.pdf")
+		if err := gv.ExportPDF(gi.FileName(dfn)); err != nil {
+			t.Fatal(err)
+		}
+		db, _ := os.ReadFile(dfn)
+		pdfs = append(pdfs, db)
+	}
+	for i := 1; i < len(zooms); i++ {
+		if !bytes.Equal(svgs[i], svgs[0]) {
+			t.Errorf("export svg differs at zoom %g", zooms[i][0])
+		}
+		if !bytes.Equal(pngs[i], pngs[0]) {
+			t.Errorf("png differs at zoom %g", zooms[i][0])
+		}
+		if !bytes.Equal(pdfs[i], pdfs[0]) {
+			t.Errorf("pdf differs at zoom %g", zooms[i][0])
+		}
+	}
+	img, err := png.Decode(bytes.NewReader(pngs[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sz := img.Bounds().Size(); sz.X != 320 || sz.Y != 180 {
+		t.Errorf("png size = %v, want 320x180", sz)
+	}
+	if !bytes.HasPrefix(pdfs[0], []byte("%PDF-")) {
+		t.Error("pdf output has no PDF header")
+	}
+}
+
+func TestExportManifest(t *testing.T) {
+	gv := openTestView(t, headlessTestSVG)
+	dir := t.TempDir()
+	em := &ExportManifest{Items: []*ExportItem{
+		{File: "page.png", Width: 320},
+		{Id: "rect1", File: "rect.png", DPI: 192},
+		{Id: "rect1", File: filepath.Join(dir, "rect"), Format: "PDF"},
+		{Id: "nosuch", File: "nosuch.png"},
+		{File: "page.nosuch"},
+	}}
+	mfn := gi.FileName(filepath.Join(dir, "manifest.json"))
+	if err := em.SaveJSON(mfn); err != nil {
+		t.Fatal(err)
+	}
+	if err := gv.ExportManifest(mfn); err == nil || !strings.Contains(err.Error(), "nosuch.png") {
+		t.Errorf("got error %v, want error for nosuch.png", err)
+	}
+	for _, tt := range []struct {
+		file string
+		x, y int
+	}{
+		{"page.png", 320, 180},
+		{"rect.png", 160, 80}, // 80x40 px rect at 2x
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Errorf("%s: %v", tt.file, err)
+			continue
+		}
+		if sz := img.Bounds().Size(); sz.X != tt.x || sz.Y != tt.y {
+			t.Errorf("%s: size = %v, want %dx%d", tt.file, sz, tt.x, tt.y)
+		}
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "rect")); err != nil || !bytes.HasPrefix(b, []byte("%PDF-")) {
+		t.Errorf("rect pdf not exported: %v", err)
+	}
+	for _, fn := range []string{"nosuch.png", "page.nosuch"} {
+		if _, err := os.Stat(filepath.Join(dir, fn)); err == nil {
+			t.Errorf("%s exported for a failed item", fn)
+		}
+	}
+}
+
+func TestExportViewPNG(t *testing.T) {
+	gv := openTestView(t, headlessTestSVG)
+	sv := gv.SVG()
+	dir := t.TempDir()
+	gv.Filename = gi.FileName(filepath.Join(dir, "view.svg"))
+	setTestZoom(sv, 2, -50, -20)
+	if err := gv.ExportViewPNG(true); err != nil {
+		t.Fatal(err)
+	}
+	ents, _ := os.ReadDir(dir)
+	if len(ents) != 1 || ents[0].Name() != "view_view.png" {
+		t.Fatalf("files in drawing dir: %v, want only view_view.png", ents)
+	}
+	b, _ := os.ReadFile(filepath.Join(dir, "view_view.png"))
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sz, wsz := img.Bounds().Size(), sv.WinBBox.Size(); sz != wsz {
+		t.Errorf("png size = %v, want view size %v", sz, wsz)
+	}
+	if vb := sv.ViewBox.Size; vb.X != 640 || vb.Y != 360 {
+		t.Errorf("page not restored after export: ViewBox size %v", vb)
+	}
+}
+
+func TestSelectedOnlySVG(t *testing.T) {
+	const src = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<g id="g1">
+<rect id="r" x="10" y="10" width="40" height="20"/>
+<rect id="r2" x="60" y="10" width="40" height="20"/>
+</g>
+<g id="g2">
+<rect id="r" x="10" y="100" width="40" height="20"/>
+</g>
+<g id="r">
+<circle id="c" cx="300" cy="200" r="20"/>
+</g>
+</svg>
+`
+	gv := openTestView(t, src)
+	sv := gv.SVG()
+	g1 := sv.ChildByName("g1", 0)
+	if g1 == nil {
+		t.Fatal("g1 not found")
+	}
+	sel := g1.ChildByName("r", 0)
+	if sel == nil {
+		t.Fatal("g1/r not found")
+	}
+	gv.EditState.Select(sel.(svg.NodeSVG))
+	b, err := gv.SVGBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ob, err := sv.SelectedOnlySVG(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(ob)
+	if !strings.Contains(out, `id="g1"`) {
+		t.Error("group of the selected element left out")
+	}
+	if n := strings.Count(out, "<rect"); n != 1 {
+		t.Errorf("got %d rects, want 1:\n%s", n, out)
+	}
+	for _, id := range []string{"r2", "g2", "c"} {
+		if strings.Contains(out, `id="`+id+`"`) {
+			t.Errorf("unselected %s exported:\n%s", id, out)
+		}
+	}
+}
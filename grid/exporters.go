@@ -0,0 +1,181 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// ExportFunc writes the drawing in given view, which has given physical
+// size, to given writer in an export format
+type ExportFunc func(sv *SVGView, sz *PhysSize, w io.Writer) error
+
+// Exporter is a registered export format
+type Exporter struct {
+
+	// file extension for the format, lowercase, including the leading . (e.g., .png)
+	Ext string
+
+	// function that writes the format
+	Func ExportFunc
+}
+
+// Exporters are the registered export formats, in order of registration,
+// including the built-in .svg, .png and .pdf formats.  Use
+// RegisterExporter to add formats.
+var Exporters []*Exporter
+
+// ExportExt returns the normalized form of given export file extension:
+// lowercase, with a leading .
+func ExportExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// RegisterExporter registers an export format for given file extension,
+// so it is listed in the Export menu and used by ExportFormat and
+// ExportFile.  Registering an extension that is already registered
+// replaces its function, so built-in formats can be overridden.
+// Call this in an init function, before the GUI is started.
+func RegisterExporter(ext string, fn ExportFunc) {
+	ext = ExportExt(ext)
+	if ex := ExporterForExt(ext); ex != nil {
+		ex.Func = fn
+		return
+	}
+	Exporters = append(Exporters, &Exporter{Ext: ext, Func: fn})
+}
+
+// ExporterForExt returns the registered exporter for given
+// file extension, or nil if none
+func ExporterForExt(ext string) *Exporter {
+	ext = ExportExt(ext)
+	for _, ex := range Exporters {
+		if ex.Ext == ext {
+			return ex
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterExporter(".svg", ExportSVGFunc)
+	RegisterExporter(".png", PNGExportFunc(0, 0))
+	RegisterExporter(".pdf", ExportPDFFunc)
+}
+
+// ExportSVGFunc is the ExportFunc for the .svg format, which writes the
+// drawing as an svg file without the view zoom and position metadata
+func ExportSVGFunc(sv *SVGView, sz *PhysSize, w io.Writer) error {
+	b, err := sv.ExportSVGBytes()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// ExportWith exports the drawing with given export function to given file,
+// over the export extent in Prefs.Export -- with the selection extent and
+// SelOnly, only the selected elements are exported
+func (gv *GridView) ExportWith(fname gi.FileName, fn ExportFunc) error {
+	sv := gv.SVG()
+	bb, err := sv.ExtentBBox(Prefs.Export.Extent)
+	if err != nil {
+		return err
+	}
+	restore := sv.SetExportExtent(bb, Prefs.Export.Clip)
+	defer restore()
+	if Prefs.Export.Extent == ExportSelection && Prefs.Export.SelOnly {
+		sv.exportSel = true
+		defer func() { sv.exportSel = false }()
+	}
+	err = sv.ExportToFile(fname, fn)
+	if err != nil {
+		return err
+	}
+	gv.SetStatus("Exported: " + string(fname))
+	return nil
+}
+
+// ExportToFile writes the drawing in the view, over its current page, to
+// given file with given export function, removing the file on error
+func (sv *SVGView) ExportToFile(fname gi.FileName, fn ExportFunc) error {
+	sz := &PhysSize{}
+	sz.SetFromSVG(sv)
+	fp, err := os.Create(string(fname))
+	if err != nil {
+		return err
+	}
+	err = fn(sv, sz, fp)
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(string(fname))
+	}
+	return err
+}
+
+// SetExportPrefs sets the region of the drawing covered by exports,
+// and whether content is clipped to it, and saves the preferences
+func (gv *GridView) SetExportPrefs(ext ExportExtents, clip bool) {
+	Prefs.Export.Extent = ext
+	Prefs.Export.Clip = clip
+	Prefs.Save()
+	cs := ""
+	if clip {
+		cs = ", clipped"
+	}
+	gv.SetStatus("Export extent: " + strings.TrimPrefix(ext.String(), "Export") + cs)
+}
+
+// SetExportSelOnly sets whether exports with the selection extent export
+// only the selected elements, and saves the preferences
+func (gv *GridView) SetExportSelOnly(on bool) {
+	Prefs.Export.SelOnly = on
+	Prefs.Save()
+	if on {
+		gv.SetStatus("Export selection: only the selected elements")
+	} else {
+		gv.SetStatus("Export selection: all elements within the selection extent")
+	}
+}
+
+// ExportFile exports the drawing to given file, in the registered export
+// format for its extension.  The drawing file itself cannot be the target.
+func (gv *GridView) ExportFile(fname gi.FileName) error {
+	fname = gi.FileName(CleanPath(string(fname)))
+	if AbsPath(string(fname)) == string(gv.Filename) {
+		return fmt.Errorf("ExportFile: cannot export over the drawing file itself: %s", fname)
+	}
+	ext := filepath.Ext(string(fname))
+	ex := ExporterForExt(ext)
+	if ex == nil {
+		return fmt.Errorf("ExportFile: no exporter registered for extension: %q", ext)
+	}
+	return gv.ExportWith(fname, ex.Func)
+}
+
+// ExportFormat exports the drawing in the registered export format for
+// given file extension (auto-names to same name with that extension)
+func (gv *GridView) ExportFormat(ext string) error {
+	ext = ExportExt(ext)
+	fext := filepath.Ext(string(gv.Filename))
+	onm := strings.TrimSuffix(string(gv.Filename), fext) + ext
+	if ext == ".svg" && strings.ToLower(fext) == ".svg" {
+		onm = strings.TrimSuffix(string(gv.Filename), fext) + "_export.svg"
+	}
+	return gv.ExportFile(gi.FileName(onm))
+}
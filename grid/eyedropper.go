@@ -0,0 +1,61 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"image"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/cursor"
+)
+
+// SetToolCursor updates the mouse cursor when switching from the prv
+// tool to the nxt one: the EyedropperTool, MeasureTool and PencilTool use a crosshair
+func (gv *GridView) SetToolCursor(prv, nxt Tools) {
+	if prv == nxt {
+		return
+	}
+	win := gv.ParentWindow()
+	if win == nil || win.OSWin == nil {
+		return
+	}
+	if ToolUsesCrossCursor(prv) {
+		oswin.TheApp.Cursor(win.OSWin).Pop()
+	}
+	if ToolUsesCrossCursor(nxt) {
+		oswin.TheApp.Cursor(win.OSWin).Push(cursor.Cross)
+	}
+}
+
+// PickColor sets the fill color of the default shape style (or the
+// stroke color if stroke is true) to the color of the rendered drawing
+// at given point in window coordinates, including the background, as
+// with the EyedropperTool.  Returns false if the point is outside the
+// drawing view.
+func (gv *GridView) PickColor(pt image.Point, stroke bool) bool {
+	sv := gv.SVG()
+	if sv.Pixels == nil {
+		return false
+	}
+	ppt := pt.Sub(sv.WinBBox.Min)
+	if !ppt.In(sv.Pixels.Bounds()) {
+		return false
+	}
+	var clr gist.Color
+	clr.SetColor(sv.Pixels.RGBAAt(ppt.X, ppt.Y))
+	ps := &Prefs.ShapeStyle
+	if stroke {
+		ps.StrokeStyle.Color.SetColor(clr)
+		ps.StrokeStyle.On = true
+		gv.SetStatus("stroke color: " + clr.HexString())
+	} else {
+		ps.FillStyle.Color.SetColor(clr)
+		ps.FillStyle.On = true
+		gv.SetStatus("fill color: " + clr.HexString())
+	}
+	gv.SetDefaultStyle()
+	return true
+}
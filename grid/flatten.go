@@ -0,0 +1,150 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// xformIsScaleTrans returns true if given transform only scales and
+// translates, with no rotation or skew
+func xformIsScaleTrans(xf mat32.Mat2) bool {
+	return xf.XY == 0 && xf.YX == 0
+}
+
+// xformIsSimilar returns true if given transform preserves shapes:
+// a uniform scale, rotation and translation, possibly mirrored
+func xformIsSimilar(xf mat32.Mat2) bool {
+	a := mat32.V2(xf.XX, xf.YX)
+	b := mat32.V2(xf.XY, xf.YY)
+	la, lb := a.Length(), b.Length()
+	return la > 0 && mat32.Abs(la-lb) <= 1.0e-5*la && mat32.Abs(a.Dot(b)) <= 1.0e-5*la*lb
+}
+
+// NodePropTransform returns the transform set in the transform property
+// of given element, which is current even before it has been styled
+func NodePropTransform(sn svg.NodeSVG) mat32.Mat2 {
+	xf := mat32.Identity2D()
+	switch tp := sn.Prop("transform").(type) {
+	case string:
+		xf.SetString(tp)
+	case mat32.Mat2:
+		xf = tp
+	case *mat32.Mat2:
+		xf = *tp
+	}
+	return xf
+}
+
+// FlattenNodeTransform applies given transform directly to the geometry
+// of given element, so that it can be drawn without a transform, and
+// returns true if it could.  Paths, lines, polylines and polygons take
+// any transform (except paths with arcs, which need one that preserves
+// their shape or only scales and translates unrotated arcs); rects and
+// ellipses take one that only scales and translates; and circles one
+// that also keeps them circular.  Other elements, e.g., text and images,
+// are not flattened.  The element's own transform is not changed.
+func FlattenNodeTransform(sn svg.NodeSVG, xf mat32.Mat2) bool {
+	if xf.IsIdentity() {
+		return true
+	}
+	switch el := sn.(type) {
+	case *svg.Path:
+		segs := PathSegs(el.Data)
+		sim := xformIsSimilar(xf)
+		for _, ps := range segs {
+			if ps.Cmd&^1 == svg.PcA && !sim && !(xformIsScaleTrans(xf) && mat32.Mod(ps.Ang, 90) == 0) {
+				return false
+			}
+		}
+		for _, ps := range segs {
+			ps.XForm(xf)
+		}
+		el.Data = PathSegsData(segs)
+	case *svg.Line:
+		el.Start = xf.MulVec2AsPt(el.Start)
+		el.End = xf.MulVec2AsPt(el.End)
+	case *svg.Polyline:
+		for i, p := range el.Points {
+			el.Points[i] = xf.MulVec2AsPt(p)
+		}
+	case *svg.Polygon:
+		for i, p := range el.Points {
+			el.Points[i] = xf.MulVec2AsPt(p)
+		}
+	case *svg.Rect:
+		if !xformIsScaleTrans(xf) {
+			return false
+		}
+		a := xf.MulVec2AsPt(el.Pos)
+		b := xf.MulVec2AsPt(el.Pos.Add(el.Size))
+		el.Pos = a.Min(b)
+		el.Size = b.Sub(a).Abs()
+		el.Radius = mat32.V2(el.Radius.X*mat32.Abs(xf.XX), el.Radius.Y*mat32.Abs(xf.YY))
+	case *svg.Ellipse:
+		if !xformIsScaleTrans(xf) {
+			return false
+		}
+		el.Pos = xf.MulVec2AsPt(el.Pos)
+		el.Radii = mat32.V2(el.Radii.X*mat32.Abs(xf.XX), el.Radii.Y*mat32.Abs(xf.YY))
+	case *svg.Circle:
+		if !xformIsSimilar(xf) {
+			return false
+		}
+		el.Pos = xf.MulVec2AsPt(el.Pos)
+		el.Radius *= mat32.V2(xf.XX, xf.YX).Length()
+	default:
+		return false
+	}
+	if sc := mat32.Sqrt(mat32.Abs(xf.XX*xf.YY - xf.XY*xf.YX)); sc != 1 {
+		w := units.NewPx(1) // the default
+		if wp, has := sn.PropInherit("stroke-width", ki.Inherit, ki.NoTypeProps); has {
+			w.SetIFace(wp, "stroke-width")
+		}
+		w.Val *= sc
+		sn.SetProp("stroke-width", w)
+	}
+	return true
+}
+
+// FlattenTransforms applies the transforms of all the elements in the
+// drawing, including those inherited from their groups and layers, to
+// their geometry where possible (see FlattenNodeTransform), and clears
+// the transforms, so that element positions and sizes are as they appear.
+// Stroke widths are scaled to match, but gradients are not transformed.
+// Elements that cannot be flattened keep the net transform of their
+// groups, which are left without transforms.  Returns the number of
+// elements flattened and not.
+func (sv *SVGView) FlattenTransforms() (nflat, nleft int) {
+	sv.flattenKids(sv.Kids, mat32.Identity2D(), &nflat, &nleft)
+	return
+}
+
+// flattenKids flattens the transforms of given children of a group or
+// the drawing, with given net transform from their parents
+func (sv *SVGView) flattenKids(kids ki.Slice, pxf mat32.Mat2, nflat, nleft *int) {
+	for _, k := range kids {
+		sn, ok := k.(svg.NodeSVG)
+		if !ok {
+			continue
+		}
+		xf := NodePropTransform(sn).Mul(pxf)
+		if gp, isgp := sn.(*svg.Group); isgp {
+			SetNodeTransform(sn, mat32.Identity2D())
+			sv.flattenKids(gp.Kids, xf, nflat, nleft)
+			continue
+		}
+		if FlattenNodeTransform(sn, xf) {
+			SetNodeTransform(sn, mat32.Identity2D())
+			*nflat++
+		} else {
+			SetNodeTransform(sn, xf)
+			*nleft++
+		}
+	}
+}
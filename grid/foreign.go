@@ -0,0 +1,208 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// SVGKnownElements are the element names that are processed by the svg reader.
+// Any other element is a foreign element that would otherwise be dropped.
+// Elements with the prefixes in SVGKnownPrefixes are also processed.
+var SVGKnownElements = map[string]bool{
+	"svg": true, "desc": true, "title": true, "defs": true, "g": true,
+	"rect": true, "circle": true, "ellipse": true, "line": true, "polygon": true,
+	"polyline": true, "path": true, "image": true, "text": true, "tspan": true,
+	"linearGradient": true, "radialGradient": true, "stop": true, "style": true,
+	"clipPath": true, "marker": true, "use": true, "Work": true, "RDF": true,
+	"format": true, "type": true, "namedview": true, "perspective": true,
+	"grid": true, "guide": true, "metadata": true,
+}
+
+// SVGKnownPrefixes are element name prefixes processed by the svg reader
+var SVGKnownPrefixes = []string{"flow", "fe", "path-effect", "filter"}
+
+// SVGWrittenRootAttrs are the attributes that the svg writer always
+// writes on the root svg element
+var SVGWrittenRootAttrs = map[string]bool{
+	"width": true, "height": true, "viewBox": true, "xmlns": true,
+	"xmlns:inkscape": true, "xmlns:sodipodi": true, "xmlns:xlink": true,
+}
+
+// IsKnownSVGElement returns true if the given element name is
+// processed by the svg reader.
+func IsKnownSVGElement(nm string) bool {
+	if SVGKnownElements[nm] {
+		return true
+	}
+	for _, pf := range SVGKnownPrefixes {
+		if strings.HasPrefix(nm, pf) {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignXML records the parts of an svg file that are not understood
+// by the svg reader, so they can be written back verbatim on save:
+// attributes of the root svg element (e.g., namespace declarations) and
+// foreign elements at the top level of the drawing (e.g., tool-specific
+// metadata).  Foreign elements nested within known elements are not retained.
+type ForeignXML struct {
+
+	// root svg element attributes not otherwise written, as name="value" strings
+	RootAttrs []string
+
+	// raw xml of foreign top-level elements, in original order
+	Elements []string
+}
+
+// Reset resets all recorded foreign content
+func (fx *ForeignXML) Reset() {
+	fx.RootAttrs = nil
+	fx.Elements = nil
+}
+
+// IsEmpty returns true if there is no foreign content
+func (fx *ForeignXML) IsEmpty() bool {
+	return len(fx.RootAttrs) == 0 && len(fx.Elements) == 0
+}
+
+// OpenXML records the foreign content from given svg file
+func (fx *ForeignXML) OpenXML(fname gi.FileName) error {
+	b, err := os.ReadFile(string(fname))
+	if err != nil {
+		return err
+	}
+	return fx.ReadXML(b)
+}
+
+// ReadXML records the foreign content from given svg file contents
+func (fx *ForeignXML) ReadXML(b []byte) error {
+	fx.Reset()
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+	depth := 0
+	for {
+		st := decoder.InputOffset()
+		t, err := decoder.RawToken() // raw: retains namespace prefixes
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		switch se := t.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 1 && se.Name.Local == "svg":
+				for _, attr := range se.Attr {
+					anm := attr.Name.Local
+					if attr.Name.Space != "" {
+						anm = attr.Name.Space + ":" + anm
+					}
+					if SVGWrittenRootAttrs[anm] {
+						continue
+					}
+					var sb strings.Builder
+					xml.EscapeText(&sb, []byte(attr.Value))
+					fx.RootAttrs = append(fx.RootAttrs, anm+`="`+sb.String()+`"`)
+				}
+			case depth == 2 && !IsKnownSVGElement(se.Name.Local):
+				if err := fx.skipElement(decoder); err != nil {
+					return err
+				}
+				depth--
+				fx.Elements = append(fx.Elements, string(b[st:decoder.InputOffset()]))
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// skipElement consumes tokens through the end of the current element
+func (fx *ForeignXML) skipElement(decoder *xml.Decoder) error {
+	depth := 1
+	for depth > 0 {
+		t, err := decoder.RawToken()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return nil
+}
+
+// Insert inserts the recorded foreign content into given svg output
+// generated by the svg writer
+func (fx *ForeignXML) Insert(b []byte) []byte {
+	if fx.IsEmpty() {
+		return b
+	}
+	var out bytes.Buffer
+	si := bytes.Index(b, []byte("<svg"))
+	ei := bytes.LastIndex(b, []byte("</svg>"))
+	if si < 0 || ei < si {
+		return b
+	}
+	ri := si + bytes.IndexByte(b[si:], '>')
+	out.Write(b[:ri])
+	for _, at := range fx.RootAttrs {
+		out.WriteString(" " + at)
+	}
+	out.Write(b[ri:ei])
+	for _, el := range fx.Elements {
+		out.WriteString("  " + el + "\n")
+	}
+	out.Write(b[ei:])
+	return out.Bytes()
+}
+
+// SaveSVG saves the drawing to given file, retaining any foreign content
+// recorded when the file was opened.  If Prefs.StableSave is set, the
+// output is made deterministic using StableXMLPrec.
+func (gv *GridView) SaveSVG(fname gi.FileName) error {
+	b, err := gv.SVGBytes()
+	if err != nil {
+		return err
+	}
+	return WriteSVGBytes(fname, b)
+}
+
+// SVGBytes returns the drawing as svg file contents, as saved by SaveSVG
+func (gv *GridView) SVGBytes() ([]byte, error) {
+	sv := gv.SVG()
+	var b bytes.Buffer
+	err := sv.WriteXML(&b, true)
+	if err != nil {
+		return nil, err
+	}
+	ob := b.Bytes()
+	if Prefs.StableSave {
+		ob = StableXMLPrec(ob, Prefs.StablePrec)
+	}
+	return gv.EditState.Foreign.Insert(ob), nil
+}
+
+// WriteSVGBytes writes given svg file contents to given file, replacing
+// it only once fully written (see WriteFileAtomic)
+func WriteSVGBytes(fname gi.FileName, b []byte) error {
+	return WriteFileAtomic(string(fname), b, 0644)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestForeignXMLReadXML(t *testing.T) {
+	tests := []struct {
+		name  string
+		svg   string
+		attrs []string
+		els   []string
+	}{
+		{"none", `<svg width="10" height="10"><rect id="r" /></svg>`, nil, nil},
+		{"root attrs",
+			`<svg width="10" xmlns:dc="urn:dc" sodipodi:docname="a &amp; b.svg" version="1.1"><rect id="r" /></svg>`,
+			[]string{`xmlns:dc="urn:dc"`, `sodipodi:docname="a &amp; b.svg"`, `version="1.1"`}, nil},
+		{"foreign elements",
+			`<svg><inkscape:clipboard min="1,2" /><rect id="r" /><foo:a><foo:b x="1" /></foo:a></svg>`,
+			nil, []string{`<inkscape:clipboard min="1,2" />`, `<foo:a><foo:b x="1" /></foo:a>`}},
+		{"nested foreign not retained", `<svg><g id="g"><foo:a /></g></svg>`, nil, nil},
+	}
+	for _, tt := range tests {
+		var fx ForeignXML
+		if err := fx.ReadXML([]byte(tt.svg)); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(fx.RootAttrs, tt.attrs) {
+			t.Errorf("%s: RootAttrs = %q, want %q", tt.name, fx.RootAttrs, tt.attrs)
+		}
+		if !reflect.DeepEqual(fx.Elements, tt.els) {
+			t.Errorf("%s: Elements = %q, want %q", tt.name, fx.Elements, tt.els)
+		}
+	}
+}
+
+func TestForeignXMLInsert(t *testing.T) {
+	fx := ForeignXML{RootAttrs: []string{`version="1.1"`}, Elements: []string{`<foo:a />`}}
+	got := string(fx.Insert([]byte("<svg width=\"10\">\n  <rect id=\"r\" />\n</svg>\n")))
+	want := "<svg width=\"10\" version=\"1.1\">\n  <rect id=\"r\" />\n  <foo:a />\n</svg>\n"
+	if got != want {
+		t.Errorf("Insert:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+// TestForeignRoundTrip saves an Inkscape drawing, twice, and checks that
+// nothing Grid does not understand is lost or duplicated
+func TestForeignRoundTrip(t *testing.T) {
+	d := readTestDoc(t, "testdata/inkscape.svg")
+	b1, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2, err := roundTripDoc(t, d).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keep := []string{
+		`inkscape:version="1.1.2 (0a00cf5339, 2022-02-04)"`,
+		`sodipodi:docname="drawing.svg"`,
+		`xmlns:dc="http://purl.org/dc/elements/1.1/"`,
+		`xmlns:cc="http://creativecommons.org/ns#"`,
+		`xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"`,
+		"<inkscape:clipboard\n    min=\"20,30\"\n    max=\"80,70\" />",
+		`<foo:option`,
+		`id="layer1"`,
+		`id="rect10"`,
+		`id="path12"`,
+	}
+	for i, b := range [][]byte{b1, b2} {
+		out := string(b)
+		for _, k := range keep {
+			if n := strings.Count(out, k); n != 1 {
+				t.Errorf("save %d: %q occurs %d times, want once:\n%s", i+1, k, n, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"image"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/key"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+	"github.com/srwiley/rasterx"
+)
+
+// GradientProps are the properties that can have a gradient, in the
+// order of the idx of their gradient sprites
+var GradientProps = []string{"fill", "stroke"}
+
+// NodeGradient returns the gradient used for given property ("fill" or
+// "stroke") of given element, or nil if it does not use one
+func NodeGradient(sii svg.NodeSVG, prop string) *gi.Gradient {
+	pstr, ok := sii.Prop(prop).(string)
+	if !ok || !strings.HasPrefix(pstr, "url(#") {
+		return nil
+	}
+	gr := svg.GradientByName(sii, pstr)
+	if gr == nil || gr.Grad.Gradient == nil {
+		return nil
+	}
+	return gr
+}
+
+// GradientHandles returns the sprite subtypes of the handles of given
+// gradient: start and end for linear, center and radius for radial
+func GradientHandles(g *rasterx.Gradient) []Sprites {
+	if g.IsRadial {
+		return []Sprites{SpGradientCenter, SpGradientRadius}
+	}
+	return []Sprites{SpGradientStart, SpGradientEnd}
+}
+
+// GradientHandlePt returns the point of given handle of given gradient,
+// in gradient coordinates -- the radius handle is at the right of the center
+func GradientHandlePt(g *rasterx.Gradient, sub Sprites) mat32.Vec2 {
+	p := g.Points
+	switch sub {
+	case SpGradientEnd:
+		return mat32.V2(float32(p[2]), float32(p[3]))
+	case SpGradientRadius:
+		return mat32.V2(float32(p[0]+p[4]), float32(p[1]))
+	}
+	return mat32.V2(float32(p[0]), float32(p[1])) // start, center
+}
+
+// SetGradientHandlePt sets the point of given handle of given gradient,
+// in gradient coordinates.  Moving the center of a radial gradient also
+// moves its focal point, and the radius is the distance to the center.
+func SetGradientHandlePt(g *rasterx.Gradient, sub Sprites, pt mat32.Vec2) {
+	p := &g.Points
+	switch sub {
+	case SpGradientStart:
+		p[0], p[1] = float64(pt.X), float64(pt.Y)
+	case SpGradientEnd:
+		p[2], p[3] = float64(pt.X), float64(pt.Y)
+	case SpGradientCenter:
+		p[2] += float64(pt.X) - p[0]
+		p[3] += float64(pt.Y) - p[1]
+		p[0], p[1] = float64(pt.X), float64(pt.Y)
+	case SpGradientRadius:
+		p[4] = float64(pt.Sub(mat32.V2(float32(p[0]), float32(p[1]))).Length())
+	}
+}
+
+// GradientXForm returns the transform from the coordinates of given
+// gradient of given element to window coordinates relative to the view:
+// the gradient transform, then the bounding box for ObjectBoundingBox
+// units, then the transform of the element and its parents
+func (sv *SVGView) GradientXForm(sii svg.NodeSVG, gr *gi.Gradient) mat32.Mat2 {
+	g := gr.Grad.Gradient
+	m := g.Matrix
+	xf := mat32.Mat2{XX: float32(m.A), YX: float32(m.B), XY: float32(m.C), YY: float32(m.D), X0: float32(m.E), Y0: float32(m.F)}
+	if g.Units == rasterx.ObjectBoundingBox {
+		bb := sii.SVGLocalBBox()
+		sz := bb.Size()
+		xf = xf.Mul(mat32.Mat2{XX: sz.X, YY: sz.Y, X0: bb.Min.X, Y0: bb.Min.Y})
+	}
+	return xf.Mul(sii.AsSVGNode().ParTransform(true)) // include self
+}
+
+// GradientSpritesNode returns the element whose gradients are edited
+// with the gradient sprites: the one selected element, with the select tool
+func (sv *SVGView) GradientSpritesNode() svg.NodeSVG {
+	es := sv.EditState()
+	if es.Tool != SelectTool || len(es.Selected) != 1 {
+		return nil
+	}
+	for sii := range es.Selected {
+		return sii
+	}
+	return nil
+}
+
+// UpdateGradientSprites updates the draggable sprites for the points of
+// the fill and stroke gradients of the selected element, if any, with
+// a line between the two points of each gradient
+func (sv *SVGView) UpdateGradientSprites(win *gi.Window) {
+	InactivateSprites(win, SpGradientPt)
+	InactivateSprites(win, SpGradientLine)
+	sii := sv.GradientSpritesNode()
+	if sii == nil {
+		return
+	}
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	for i, prop := range GradientProps {
+		gr := NodeGradient(sii, prop)
+		if gr == nil {
+			continue
+		}
+		g := gr.Grad.Gradient
+		xf := sv.GradientXForm(sii, gr)
+		subs := GradientHandles(g)
+		wps := make([]mat32.Vec2, len(subs))
+		for j, sub := range subs {
+			wps[j] = xf.MulVec2AsPt(GradientHandlePt(g, sub)).Add(svoff)
+		}
+		lsp := Sprite(win, SpGradientLine, SpUnk, i, image.ZP)
+		DrawSpriteNodeCtrlLine(lsp, wps[0], wps[1])
+		for j, sub := range subs {
+			idx, sb := i, sub // key to get local vars
+			sp := SpriteConnectEvent(win, SpGradientPt, sub, i, image.ZP, sv.This(), func(recv, send ki.Ki, sig int64, d any) {
+				ssvg := recv.Embed(KiT_SVGView).(*SVGView)
+				ssvg.GradientSpriteEvent(idx, sb, oswin.EventType(sig), d)
+			})
+			SetSpritePos(sp, wps[j].ToPoint())
+		}
+	}
+}
+
+// GradientSpriteEvent processes a mouse event on a gradient point sprite,
+// for the fill (idx = 0) or stroke (idx = 1) gradient
+func (sv *SVGView) GradientSpriteEvent(idx int, sub Sprites, et oswin.EventType, d any) {
+	win := sv.GridView.ParentWindow()
+	es := sv.EditState()
+	es.SelNoDrag = false
+	switch et {
+	case oswin.MouseEvent:
+		me := d.(*mouse.Event)
+		me.SetProcessed()
+		if me.Action == mouse.Press {
+			win.SpriteDragging = SpriteName(SpGradientPt, sub, idx)
+			es.DragNodeStart(me.Where)
+			es.DragGradPt = sv.GradientHandleWinPt(idx, sub)
+		} else if me.Action == mouse.Release {
+			if es.InAction() {
+				sv.ManipDone()
+			}
+		}
+	case oswin.MouseDragEvent:
+		me := d.(*mouse.DragEvent)
+		me.SetProcessed()
+		sv.SpriteGradientDrag(idx, sub, win, me)
+	}
+}
+
+// GradientHandleWinPt returns the current window coordinates of given
+// handle of the fill (idx = 0) or stroke (idx = 1) gradient of the
+// selected element
+func (sv *SVGView) GradientHandleWinPt(idx int, sub Sprites) mat32.Vec2 {
+	sii := sv.GradientSpritesNode()
+	if sii == nil {
+		return mat32.Vec2{}
+	}
+	gr := NodeGradient(sii, GradientProps[idx])
+	if gr == nil {
+		return mat32.Vec2{}
+	}
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	return sv.GradientXForm(sii, gr).MulVec2AsPt(GradientHandlePt(gr.Grad.Gradient, sub)).Add(svoff)
+}
+
+// SpriteGradientDrag moves given handle of the fill (idx = 0) or stroke
+// (idx = 1) gradient of the selected element while dragging its sprite,
+// updating the gradient coordinates and re-rendering
+func (sv *SVGView) SpriteGradientDrag(idx int, sub Sprites, win *gi.Window, me *mouse.DragEvent) {
+	es := sv.EditState()
+	sii := sv.GradientSpritesNode()
+	if sii == nil {
+		return
+	}
+	gr := NodeGradient(sii, GradientProps[idx])
+	if gr == nil {
+		return
+	}
+	if !es.InAction() {
+		sv.ManipStart("GradientAdj", sii.Name())
+		sv.GatherAlignPoints()
+	}
+
+	sv.InactivateAlignSprites(win)
+
+	spt := mat32.NewVec2FmPoint(es.DragStartPos)
+	mpt := mat32.NewVec2FmPoint(me.Where)
+	if me.HasAnyModifier(key.Control) {
+		mpt, _ = sv.ConstrainPoint(spt, mpt)
+	}
+	if Prefs.SnapNodes {
+		mpt = sv.SnapPoint(mpt)
+	}
+	es.DragCurPos = mpt.ToPoint()
+
+	nwp := es.DragGradPt.Add(mpt.Sub(spt)) // new window coord
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	xf := sv.GradientXForm(sii, gr)
+	SetGradientHandlePt(gr.Grad.Gradient, sub, xf.Inverse().MulVec2AsPt(nwp.Sub(svoff)))
+
+	sv.UpdateGradientSprites(win)
+	sv.SetFullReRender() // elements get their gradients when styled
+	go sv.ManipUpdate()
+	win.UpdateSig()
+}
@@ -11,10 +11,11 @@ import (
 	"io"
 	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -62,7 +63,7 @@ func (gv *GridView) Defaults() {
 
 // OpenDrawingFile opens a new .svg drawing file -- just the basic opening
 func (gv *GridView) OpenDrawingFile(fnm gi.FileName) error {
-	path, _ := filepath.Abs(string(fnm))
+	path := AbsPath(string(fnm))
 	gv.Filename = gi.FileName(path)
 	sv := gv.SVG()
 	err := sv.OpenXML(gi.FileName(path))
@@ -75,12 +76,19 @@ func (gv *GridView) OpenDrawingFile(fnm gi.FileName) error {
 	fdir, _ := filepath.Split(path)
 	os.Chdir(fdir)
 	gv.EditState.Init()
+	if ferr := gv.EditState.Foreign.OpenXML(gi.FileName(path)); ferr != nil {
+		log.Println(ferr)
+	}
+	if Prefs.FlattenOnOpen {
+		sv.FlattenTransforms()
+	}
 	gv.UpdateLayerView()
 
 	gv.EditState.Gradients = sv.Gradients()
 	sv.GatherIds() // also ensures uniqueness, key for json saving
 	sv.ZoomToContents(false)
 	sv.ReadMetaData()
+	gv.UpdateNamedColorsView()
 	sv.SetTransform()
 	return err
 }
@@ -105,9 +113,22 @@ func (gv *GridView) OpenDrawing(fnm gi.FileName) error {
 	tv.CloseAll()
 	sv.bgGridEff = 0
 	sv.UpdateView(true)
+	gv.CheckFileVersion()
 	return err
 }
 
+// CheckFileVersion warns if the opened file was saved by a newer version
+// of Grid than the one running, as saving it may lose features
+func (gv *GridView) CheckFileVersion() {
+	fv := gv.EditState.FileVersion
+	if fv == "" || !VersionNewer(fv, Version) {
+		return
+	}
+	gi.PromptDialog(gv.Viewport, gi.DlgOpts{Title: "File Saved by Newer Version",
+		Prompt: fmt.Sprintf("This file was saved by Grid version %s, which is newer than the version running: %s.  Saving it with this version may lose features that it does not support.", fv, Prefs.VersionInfo())},
+		gi.AddOk, gi.NoCancel, nil, nil)
+}
+
 // NewDrawing opens a new drawing window
 func (gv *GridView) NewDrawing(sz PhysSize) *GridView {
 	ngr := NewDrawing(sz)
@@ -122,6 +143,7 @@ func (gv *GridView) PromptPhysSize() {
 	giv.StructViewDialog(gv.Viewport, sz, giv.DlgOpts{Title: "SVG Physical Size", Ok: true, Cancel: true}, gv.This(),
 		func(recv, send ki.Ki, sig int64, d any) {
 			if sig == int64(gi.DialogAccepted) {
+				sz.Update()
 				gv.SetPhysSize(sz)
 				sv.bgGridEff = -1
 				sv.UpdateView(true)
@@ -135,7 +157,11 @@ func (gv *GridView) SetPhysSize(sz *PhysSize) {
 		return
 	}
 	if sz.Size.IsNil() {
-		sz.SetStdSize(Prefs.Size.StdSize)
+		if Prefs.Size.UserSize != "" {
+			sz.SetUserSize(Prefs.Size.UserSize)
+		} else {
+			sz.SetStdSize(Prefs.Size.StdSize)
+		}
 	}
 	sv := gv.SVG()
 	sz.SetToSVG(sv)
@@ -143,6 +169,73 @@ func (gv *GridView) SetPhysSize(sz *PhysSize) {
 	sv.ZoomToPage(false)
 }
 
+// RotatePage swaps the width and height of the drawing, switching between
+// portrait and landscape orientation.  If content is true, the contents are
+// rotated by 90 degrees to fit the rotated page.
+func (gv *GridView) RotatePage(content bool) {
+	sv := gv.SVG()
+	sv.UndoSave("RotatePage", "")
+	sz := &PhysSize{}
+	sz.SetFromSVG(sv)
+	if content {
+		sv.ZoomToPage(false)
+		sv.UpdateView(true)
+		psz := sz.Size.MulScalar(.5 * sv.Scale)
+		ctr := sv.ViewBox.Min.MulScalar(sv.Scale).Add(psz)
+		del := mat32.V2(psz.Y-psz.X, psz.X-psz.Y)
+		sv.TransformAllLeaves(del, mat32.V2(1, 1), mat32.DegToRad(90), ctr)
+	}
+	sz.Rotate()
+	sz.SetToSVG(sv)
+	sv.SetMetaData()
+	sv.ZoomToPage(false)
+	sv.UpdateView(true)
+	gv.ChangeMade()
+	if sz.Portrait {
+		gv.SetStatus("Rotate Page: portrait")
+	} else {
+		gv.SetStatus("Rotate Page: landscape")
+	}
+}
+
+// SetGridSpacing sets the grid spacing of the drawing, in drawing units
+func (gv *GridView) SetGridSpacing(spc float32) {
+	if spc <= 0 {
+		return
+	}
+	sv := gv.SVG()
+	sv.Grid = spc
+	sv.bgGridEff = -1
+	sv.SetMetaData()
+	sv.UpdateView(true)
+	gv.ChangeMade()
+	gv.SetStatus("Grid spacing: " + Prefs.FmtVal(spc))
+}
+
+// CycleGridPreset switches the grid spacing to the next larger (up) or
+// smaller grid spacing preset in Prefs.GridPresets
+func (gv *GridView) CycleGridPreset(up bool) {
+	sv := gv.SVG()
+	ps := make([]float32, len(Prefs.GridPresets))
+	copy(ps, Prefs.GridPresets)
+	sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+	if up {
+		for _, p := range ps {
+			if p > sv.Grid {
+				gv.SetGridSpacing(p)
+				return
+			}
+		}
+	} else {
+		for i := len(ps) - 1; i >= 0; i-- {
+			if ps[i] < sv.Grid {
+				gv.SetGridSpacing(ps[i])
+				return
+			}
+		}
+	}
+}
+
 // SaveDrawing saves .svg drawing to current filename
 func (gv *GridView) SaveDrawing() error {
 	if gv.Filename == "" {
@@ -152,7 +245,7 @@ func (gv *GridView) SaveDrawing() error {
 	sv := gv.SVG()
 	sv.RemoveOrphanedDefs()
 	sv.SetMetaData()
-	err := sv.SaveXML(gv.Filename)
+	err := gv.SaveSVG(gv.Filename)
 	if err != nil && err != io.EOF {
 		log.Println(err)
 	} else {
@@ -168,14 +261,14 @@ func (gv *GridView) SaveDrawingAs(fname gi.FileName) error {
 	if fname == "" {
 		return errors.New("SaveDrawingAs: filename is empty")
 	}
-	path, _ := filepath.Abs(string(fname))
+	path := AbsPath(string(fname))
 	gv.Filename = gi.FileName(path)
 	SavedPaths.AddPath(path, gi.Prefs.Params.SavedPathsMax)
 	SavePaths()
 	sv := gv.SVG()
 	sv.RemoveOrphanedDefs()
 	sv.SetMetaData()
-	err := sv.SaveXML(gi.FileName(path))
+	err := gv.SaveSVG(gi.FileName(path))
 	if err != nil && err != io.EOF {
 		log.Println(err)
 	} else {
@@ -187,73 +280,6 @@ func (gv *GridView) SaveDrawingAs(fname gi.FileName) error {
 	return err
 }
 
-// ExportPNG exports drawing to a PNG image (auto-names to same name
-// with .png suffix).  Calls inkscape -- needs to be on the PATH.
-// specify either width or height of resulting image, or nothing for
-// physical size as set.  Renders full current page -- do ResizeToContents
-// to render just current contents.
-func (gv *GridView) ExportPNG(width, height float32) error {
-	path, _ := filepath.Split(string(gv.Filename))
-	fnm := filepath.Join(path, "export_png.svg")
-	sv := gv.SVG()
-	err := sv.SaveXML(gi.FileName(fnm))
-	if err != nil && err != io.EOF {
-		log.Println(err)
-		return err
-	}
-	fext := filepath.Ext(string(gv.Filename))
-	onm := strings.TrimSuffix(string(gv.Filename), fext) + ".png"
-	cstr := "inkscape"
-	args := []string{`--export-type=png`, "-o", onm}
-	if width > 0 {
-		args = append(args, fmt.Sprintf("--export-width=%g", width))
-	}
-	if height > 0 {
-		args = append(args, fmt.Sprintf("--export-height=%g", height))
-	}
-	args = append(args, fnm)
-	cmd := exec.Command(cstr, args...)
-	fmt.Printf("executing command: %s %v\n", cstr, args)
-	out, err := cmd.CombinedOutput()
-	// if err != nil {
-	fmt.Println(string(out))
-	// }
-	os.Remove(fnm)
-	return err
-}
-
-// ExportPDF exports drawing to a PDF file (auto-names to same name
-// with .pdf suffix).  Calls inkscape -- needs to be on the PATH.
-// specify DPI of resulting image for effects rendering.
-// Renders full current page -- do ResizeToContents
-// to render just current contents.
-func (gv *GridView) ExportPDF(dpi float32) error {
-	path, _ := filepath.Split(string(gv.Filename))
-	fnm := filepath.Join(path, "export_pdf.svg")
-	sv := gv.SVG()
-	err := sv.SaveXML(gi.FileName(fnm))
-	if err != nil && err != io.EOF {
-		log.Println(err)
-		return err
-	}
-	fext := filepath.Ext(string(gv.Filename))
-	onm := strings.TrimSuffix(string(gv.Filename), fext) + ".pdf"
-	cstr := "inkscape"
-	args := []string{`--export-type=pdf`, "-o", onm}
-	if dpi > 0 {
-		args = append(args, fmt.Sprintf("--export-dpi=%g", dpi))
-	}
-	args = append(args, fnm)
-	cmd := exec.Command(cstr, args...)
-	fmt.Printf("executing command: %s %v\n", cstr, args)
-	out, err := cmd.CombinedOutput()
-	// if err != nil {
-	fmt.Println(string(out))
-	// }
-	os.Remove(fnm)
-	return err
-}
-
 // ResizeToContents resizes the drawing to just fit the current contents,
 // including moving everything to start at upper-left corner,
 // preserving the current grid offset, so grid snapping
@@ -514,11 +540,40 @@ func (gv *GridView) ConfigMainToolbar() {
 			grr := recv.Embed(KiT_GridView).(*GridView)
 			grr.PromptPhysSize()
 		})
+	szmen.Menu.AddAction(gi.ActOpts{Label: "Rotate Page", Icon: "gear", Tooltip: "swap the width and height of the drawing, switching between portrait and landscape -- optionally rotating the contents to fit"},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			giv.CallMethod(grr, "RotatePage", grr.ViewportSafe())
+		})
 	szmen.Menu.AddAction(gi.ActOpts{Label: "Resize To Contents", Icon: "gear", Tooltip: "resizes the drawing to fit the current contents, moving everything to upper-left corner while preserving grid alignment"},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
 			grr.ResizeToContents()
 		})
+	grmen := tb.AddAction(gi.ActOpts{Label: "Grid", Icon: "gear", Tooltip: "switch the grid spacing among the presets in preferences -- the [ and ] keys cycle through them"}, nil, nil)
+	grmen.MakeMenuFunc = func(obj ki.Ki, m *gi.Menu) {
+		*m = gi.Menu{}
+		m.AddAction(gi.ActOpts{Label: "Smaller Grid  [", Icon: "minus"},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				grr.CycleGridPreset(false)
+			})
+		m.AddAction(gi.ActOpts{Label: "Larger Grid  ]", Icon: "plus"},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				grr.CycleGridPreset(true)
+			})
+		m.AddSeparator("sep-presets")
+		cur := gv.SVG().Grid
+		for _, p := range Prefs.GridPresets {
+			spc := p
+			m.AddAction(gi.ActOpts{Label: Prefs.FmtVal(spc)},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					grr.SetGridSpacing(spc)
+				}).SetSelectedState(spc == cur)
+		}
+	}
 	tb.AddAction(gi.ActOpts{Label: "Open...", Icon: "file-open", Tooltip: "Open a drawing from .svg file"},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
@@ -535,16 +590,44 @@ func (gv *GridView) ConfigMainToolbar() {
 			giv.CallMethod(grr, "SaveDrawingAs", grr.ViewportSafe())
 		})
 	expmen := tb.AddAction(gi.ActOpts{Label: "Export", Icon: "file-save"}, nil, nil)
-	expmen.Menu.AddAction(gi.ActOpts{Label: "Export PNG", Icon: "file-image", Tooltip: "Export drawing to a .png file -- requires cairosvg.org to be installed"},
-		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
-			grr := recv.Embed(KiT_GridView).(*GridView)
-			giv.CallMethod(grr, "ExportPNG", grr.ViewportSafe())
-		})
-	expmen.Menu.AddAction(gi.ActOpts{Label: "Export PDF", Icon: "file-pdf", Tooltip: "Export drawing to a .pdf  file -- requires cairosvg.org to be installed"},
-		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
-			grr := recv.Embed(KiT_GridView).(*GridView)
-			giv.CallMethod(grr, "ExportPDF", grr.ViewportSafe())
-		})
+	expmen.MakeMenuFunc = func(obj ki.Ki, m *gi.Menu) {
+		*m = gi.Menu{}
+		m.AddAction(gi.ActOpts{Label: "Export View PNG", Icon: "file-image", Tooltip: "Export only the region of the drawing visible in the view to a .png file -- requires inkscape to be installed"},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				giv.CallMethod(grr, "ExportViewPNG", grr.ViewportSafe())
+			})
+		m.AddSeparator("sep-formats")
+		for _, ex := range Exporters {
+			ext := ex.Ext
+			m.AddAction(gi.ActOpts{Label: "Export " + strings.ToUpper(strings.TrimPrefix(ext, ".")), Icon: "file-save", Tooltip: "Export drawing to a " + ext + " file with the registered exporter for that format, using its default settings"},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					if err := grr.ExportFormat(ext); err != nil {
+						grr.SetStatus("Export error: " + err.Error())
+					}
+				})
+		}
+		m.AddSeparator("sep-extent")
+		for ext := ExportPage; ext < ExportExtentsN; ext++ {
+			ext := ext
+			m.AddAction(gi.ActOpts{Label: "Extent: " + strings.TrimPrefix(ext.String(), "Export"), Tooltip: "set the region of the drawing covered by exports (also in preferences)"},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					grr.SetExportPrefs(ext, Prefs.Export.Clip)
+				}).SetSelectedState(ext == Prefs.Export.Extent)
+		}
+		m.AddAction(gi.ActOpts{Label: "Clip to Extent", Tooltip: "clip the content to the export extent, so elements extending beyond it are cut off, as when printing"},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				grr.SetExportPrefs(Prefs.Export.Extent, !Prefs.Export.Clip)
+			}).SetSelectedState(Prefs.Export.Clip)
+		m.AddAction(gi.ActOpts{Label: "Selected Only", Tooltip: "with the Selection extent, export only the selected elements, leaving out any others within it -- e.g., to extract one icon from a sheet.  The Margin around the extent is set in preferences"},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				grr.SetExportSelOnly(!Prefs.Export.SelOnly)
+			}).SetSelectedState(Prefs.Export.SelOnly)
+	}
 
 	gi.NewSeparator(tb, "sep-undo")
 	tb.AddAction(gi.ActOpts{Label: "Undo", Icon: "rotate-left", Tooltip: "Undo last action", UpdateFunc: gv.UndoAvailFunc},
@@ -558,7 +641,7 @@ func (gv *GridView) ConfigMainToolbar() {
 			grr.Redo()
 		})
 	gi.NewSeparator(tb, "sep-edit")
-	tb.AddAction(gi.ActOpts{Label: "Duplicate", Icon: "documents", Tooltip: "Duplicate current selection -- original items will remain selected", UpdateFunc: gv.SelectedEnableFunc},
+	tb.AddAction(gi.ActOpts{Label: "Duplicate", Icon: "documents", Tooltip: "Duplicate current selection (Ctrl+D) -- the copies are offset a little and become the selection", UpdateFunc: gv.SelectedEnableFunc},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
 			grr.DuplicateSelected()
@@ -576,7 +659,7 @@ func (gv *GridView) ConfigMainToolbar() {
 	tb.AddAction(gi.ActOpts{Label: "Paste", Icon: "paste", Tooltip: "Paste clipboard contents", UpdateFunc: gv.PasteAvailFunc},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
-			grr.PasteClip()
+			grr.Paste()
 		})
 	gi.NewSeparator(tb, "sep-import")
 	tb.AddAction(gi.ActOpts{Label: "Add Image...", Icon: "file-image", Tooltip: "add an image from a file"},
@@ -634,6 +717,7 @@ func (gv *GridView) ConfigStatusBar() {
 	lbl.SetProp("margin", 0)
 	lbl.SetProp("padding", 0)
 	lbl.SetProp("tab-size", 4)
+	gi.AddNewToolbar(sb, "crumbs")
 }
 
 // SetStatus updates the statusbar label with given message, along with other status info
@@ -678,10 +762,9 @@ func (gv *GridView) CloseWindowReq() bool {
 	return false // not yet
 }
 
-// QuitReq is called when user tries to quit the app -- we go through all open
-// main windows and look for grid windows and call their CloseWindowReq
-// functions!
-func QuitReq() bool {
+// OpenGridViews returns the GridViews of all open grid main windows
+func OpenGridViews() []*GridView {
+	var gvs []*GridView
 	for _, win := range gi.MainWindows {
 		if !strings.HasPrefix(win.Nm, "grid-") {
 			continue
@@ -690,11 +773,20 @@ func QuitReq() bool {
 		if err != nil {
 			continue
 		}
-		gek := mfr.ChildByName("grid", 0)
+		gek := mfr.ChildByName("gridview", 0)
 		if gek == nil {
 			continue
 		}
-		gv := gek.Embed(KiT_GridView).(*GridView)
+		gvs = append(gvs, gek.Embed(KiT_GridView).(*GridView))
+	}
+	return gvs
+}
+
+// QuitReq is called when user tries to quit the app -- we go through all open
+// main windows and look for grid windows and call their CloseWindowReq
+// functions!
+func QuitReq() bool {
+	for _, gv := range OpenGridViews() {
 		if !gv.CloseWindowReq() {
 			return false
 		}
@@ -726,7 +818,7 @@ func NewGridWindow(fnm string) (*gi.Window, *GridView) {
 	path := ""
 	dfnm := ""
 	if fnm != "" {
-		path, _ = filepath.Abs(fnm)
+		path = AbsPath(fnm)
 		dfnm = giv.DirAndFile(path)
 	}
 	winm := "grid-" + dfnm
@@ -819,9 +911,13 @@ func (gv *GridView) ConfigTabs() {
 	pv.Config(gv)
 	av := gv.RecycleTab("Align", KiT_AlignView, false).(*AlignView)
 	av.Config(gv)
+	cv := gv.RecycleTab("Colors", KiT_ColorsView, false).(*ColorsView)
+	cv.Config(gv)
 	gv.EditState.Text.Defaults()
 	txv := gv.RecycleTab("Text", giv.KiT_StructView, false).(*giv.StructView)
 	txv.SetStruct(&gv.EditState.Text)
+	hv := gv.RecycleTab("History", KiT_HistoryView, false).(*HistoryView)
+	hv.Config(gv)
 }
 
 func (gv *GridView) PaintView() *PaintView {
@@ -850,7 +946,7 @@ func (gv *GridView) SetDefaultStyle() {
 	switch es.Tool {
 	case TextTool:
 		pv.Update(&Prefs.TextStyle, nil)
-	case BezierTool:
+	case BezierTool, PencilTool:
 		pv.Update(&Prefs.PathStyle, nil)
 	default:
 		pv.Update(&Prefs.ShapeStyle, nil)
@@ -901,6 +997,7 @@ func (gv *GridView) Undo() string {
 		gv.SetStatus("Undo: no more to undo")
 	}
 	gv.UpdateAll()
+	gv.UpdateHistoryView()
 	return act
 }
 
@@ -914,12 +1011,17 @@ func (gv *GridView) Redo() string {
 		gv.SetStatus("Redo: no more to redo")
 	}
 	gv.UpdateAll()
+	gv.UpdateHistoryView()
 	return act
 }
 
 // ChangeMade should be called after any change is completed on the drawing.
 // Calls autosave.
 func (gv *GridView) ChangeMade() {
+	if gv.ParentWindow() == nil { // headless
+		return
+	}
+	gv.UpdateHistoryView()
 	go gv.AutoSave()
 }
 
@@ -939,15 +1041,24 @@ func (gv *GridView) OSFileEvent() {
 	})
 }
 
-// OpenRecent opens a recently-used file
+// OpenRecent opens a recently-used file -- if it no longer exists, it
+// is removed from the recents instead
 func (gv *GridView) OpenRecent(filename gi.FileName) {
 	if string(filename) == GridViewResetRecents {
 		SavedPaths = nil
 		gi.StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
+	} else if string(filename) == GridViewClearMissingRecents {
+		nrm := SavedPathsRemoveMissing()
+		SavePaths()
+		gv.SetStatus(fmt.Sprintf("Removed %d missing files from the recents", nrm))
 	} else if string(filename) == GridViewEditRecents {
 		gv.EditRecents()
+	} else if _, err := os.Stat(ExpandPath(string(filename))); os.IsNotExist(err) {
+		SavedPathsRemoveMissing()
+		SavePaths()
+		gv.SetStatus("File no longer exists, removed from the recents: " + string(filename))
 	} else {
-		gv.OpenDrawing(filename)
+		gv.OpenDrawing(gi.FileName(ExpandPath(string(filename))))
 	}
 }
 
@@ -1009,14 +1120,30 @@ func (gv *GridView) HelpWiki() {
 ////////////////////////////////////////////////////////////////////////////////////////
 //		AutoSave
 
-// AutoSaveFilename returns the autosave filename
+// newAutoSaveMu protects the numbering of NewAutoSave files
+var newAutoSaveMu sync.Mutex
+
+// newAutoSaves is the number of NewAutoSave files named so far
+var newAutoSaves int
+
+// AutoSaveFilename returns the autosave filename: #name# next to the
+// drawing file, or for a drawing that has not been saved yet, a file in
+// the temporary directory with a name unique to this view and process,
+// so that different windows do not overwrite each other's autosaves
 func (gv *GridView) AutoSaveFilename() string {
-	path, fn := filepath.Split(string(gv.Filename))
-	if fn == "" {
-		fn = "new_file_" + gv.Nm + ".svg"
+	if gv.Filename == "" {
+		newAutoSaveMu.Lock()
+		defer newAutoSaveMu.Unlock()
+		es := &gv.EditState
+		if es.NewAutoSave == "" {
+			newAutoSaves++
+			fn := fmt.Sprintf("#new_file_%d_%d.svg#", os.Getpid(), newAutoSaves)
+			es.NewAutoSave = filepath.Join(os.TempDir(), fn)
+		}
+		return es.NewAutoSave
 	}
-	asfn := filepath.Join(path, "#"+fn+"#")
-	return asfn
+	path, fn := filepath.Split(CleanPath(string(gv.Filename)))
+	return filepath.Join(path, "#"+fn+"#")
 }
 
 // AutoSave does the autosave -- safe to call in a separate goroutine
@@ -1026,8 +1153,7 @@ func (gv *GridView) AutoSave() error {
 	}
 	gv.SetFlag(int(GridViewAutoSaving))
 	asfn := gv.AutoSaveFilename()
-	sv := gv.SVG()
-	err := sv.SaveXML(gi.FileName(asfn))
+	err := gv.SaveSVG(gi.FileName(asfn))
 	if err != nil && err != io.EOF {
 		log.Println(err)
 	}
@@ -1035,10 +1161,15 @@ func (gv *GridView) AutoSave() error {
 	return err
 }
 
-// AutoSaveDelete deletes any existing autosave file
+// AutoSaveDelete deletes any existing autosave file, including the one
+// from before a new drawing was first saved
 func (gv *GridView) AutoSaveDelete() {
 	asfn := gv.AutoSaveFilename()
 	os.Remove(asfn)
+	if es := &gv.EditState; gv.Filename != "" && es.NewAutoSave != "" {
+		os.Remove(es.NewAutoSave)
+		es.NewAutoSave = ""
+	}
 }
 
 // AutoSaveCheck checks if an autosave file exists -- logic for dealing with
@@ -1135,23 +1266,51 @@ var GridViewProps = ki.Props{
 				"label": "Resize To Contents",
 				"desc":  "resizes the drawing to fit the current contents, moving everything to upper-left corner while preserving grid alignment",
 			}},
+			{"RotatePage", ki.Props{
+				"label": "Rotate Page...",
+				"desc":  "swaps the width and height of the drawing, switching between portrait and landscape orientation -- optionally rotates the contents by 90 degrees to fit the rotated page",
+				"Args": ki.PropSlice{
+					{"Content", ki.Props{
+						"default": true,
+					}},
+				},
+			}},
 			{"sep-exp", ki.BlankProp{}},
-			{"ExportPNG", ki.Props{
-				"desc": "Export drawing as a PNG image file (uses cairosvg -- must install!) -- specify either width or height in pixels as non-zero, or both 0 to use physical size.  Renders full page -- do Resize To Contents to only render contents.",
+			{"PromptExportPNG", ki.Props{
+				"label": "Export PNG...",
+				"desc":  "Export drawing as a PNG image file of given width and height in pixels, defaulting to the physical size in px, with the drawing scaled to fit.  Renders full page, or the export extent set in Export Extent.",
+			}},
+			{"ExportPDF", ki.Props{
+				"label": "Export PDF...",
+				"desc":  "Export drawing as a vector PDF file, with a page the physical size of the drawing.  Renders full page, or the export extent set in Export Extent.",
 				"Args": ki.PropSlice{
-					{"Width", ki.Props{
-						"default": 1280,
+					{"File Name", ki.Props{
+						"ext": ".pdf",
 					}},
-					{"Height", ki.Props{
-						"default": 0,
+				},
+			}},
+			{"ExportFile", ki.Props{
+				"label": "Export File...",
+				"desc":  "Export drawing to a file, in the registered export format for the file extension (e.g., .png, .pdf, or any format added with RegisterExporter)",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{}},
+				},
+			}},
+			{"ExportViewPNG", ki.Props{
+				"label": "Export View PNG...",
+				"desc":  "Export only the region of the drawing currently visible in the view as a PNG image, at screen resolution, optionally including the grid",
+				"Args": ki.PropSlice{
+					{"Grid", ki.Props{
+						"default": false,
 					}},
 				},
 			}},
-			{"ExportPDF", ki.Props{
-				"desc": "Export drawing as a PDF file (uses cairosvg -- must install!), at given specified DPI (only relevant for rendered effects).  Renders full page -- do Resize To Contents to only render contents.",
+			{"ExportManifest", ki.Props{
+				"label": "Export Manifest...",
+				"desc":  "Export all the outputs listed in a JSON export manifest file, with the element id, output file, format and size of each",
 				"Args": ki.PropSlice{
-					{"DPI", ki.Props{
-						"default": 300,
+					{"Manifest File", ki.Props{
+						"ext": ".json",
 					}},
 				},
 			}},
@@ -1168,34 +1327,262 @@ var GridViewProps = ki.Props{
 					{"Height", ki.Props{}},
 				},
 			}},
+			{"ImportDXF", ki.Props{
+				"label": "Import DXF...",
+				"desc":  "Import the lines, polylines, circles, arcs and text of a DXF (CAD) file into a new group in the current layer, with a sub-group for each DXF layer, converting the DXF units to the drawing units.",
+				"Args": ki.PropSlice{
+					{"File Name", ki.Props{
+						"ext": ".dxf",
+					}},
+				},
+			}},
 			{"sep-af", ki.BlankProp{}},
 			{"Close Window", ki.BlankProp{}},
 		}},
 		{"Edit", ki.PropSlice{
-			{"Duplicate", ki.Props{
+			{"SelectAll", ki.Props{
+				"label":  "Select All",
+				"keyfun": keyfun.SelectAll,
+				"desc":   "select all of the elements in the current layer, or isolated group, except hidden and locked ones",
+			}},
+			{"SelectNone", ki.Props{
+				"label": "Select None",
+				"desc":  "clear the selection -- also Escape in the drawing",
+			}},
+			{"InvertSelection", ki.Props{
+				"label": "Invert Selection",
+				"desc":  "select the elements that are not selected, and unselect the rest",
+			}},
+			{"Select Same", ki.PropSlice{
+				{"SelectSameType", ki.Props{
+					"label": "Type",
+					"desc":  "add the elements of the same type (rect, path, text, etc) as the first selected one to the selection, from those that Select All selects",
+				}},
+				{"SelectSameFill", ki.Props{
+					"label": "Fill",
+					"desc":  "add the elements with the same fill color or gradient as the first selected one to the selection, from those that Select All selects",
+				}},
+			}},
+			{"sep-select", ki.BlankProp{}},
+			{"DuplicateSelected", ki.Props{
+				"label":  "Duplicate",
 				"keyfun": keyfun.Duplicate,
 				// "updtfunc": GridViewInactiveTextSelectionFunc,
 			}},
-			{"Copy", ki.Props{
+			{"CopySelected", ki.Props{
+				"label":  "Copy",
 				"keyfun": keyfun.Copy,
 				// "updtfunc": GridViewInactiveTextSelectionFunc,
 			}},
-			{"Cut", ki.Props{
+			{"CutSelected", ki.Props{
+				"label":  "Cut",
 				"keyfun": keyfun.Cut,
 				// "updtfunc": GridViewInactiveTextSelectionFunc,
 			}},
 			{"Paste", ki.Props{
 				"keyfun": keyfun.Paste,
 			}},
+			{"sep-stack", ki.BlankProp{}},
+			{"RaiseToTop", ki.Props{
+				"label":    "Raise To Top",
+				"desc":     "move the selected items above all the other items in their layer or group, keeping their order",
+				"shortcut": "Home",
+			}},
+			{"RaiseSelection", ki.Props{
+				"label":    "Raise",
+				"desc":     "move the selected items one step up in the stacking order within their layer or group",
+				"shortcut": "PageUp",
+			}},
+			{"LowerSelection", ki.Props{
+				"label":    "Lower",
+				"desc":     "move the selected items one step down in the stacking order within their layer or group",
+				"shortcut": "PageDown",
+			}},
+			{"LowerToBottom", ki.Props{
+				"label":    "Lower To Bottom",
+				"desc":     "move the selected items below all the other items in their layer or group, keeping their order",
+				"shortcut": "End",
+			}},
+			{"sep-color", ki.BlankProp{}},
+			{"PromptReplaceColor", ki.Props{
+				"label": "Replace Color...",
+				"desc":  "replace all fill / stroke colors matching a given color with another color, across the entire drawing",
+			}},
+			{"PromptSelRename", ki.Props{
+				"label": "Rename Selected...",
+				"desc":  "rename the ids of the selected items using a pattern such as btn-{n}, with auto-incrementing numbers, previewing the new names before renaming",
+			}},
+			{"SaveStylePreset", ki.Props{
+				"label": "Save Style Preset...",
+				"desc":  "save the full style (fill, stroke, width, dash, markers and effects) of the selected element as a named style preset in preferences, replacing any existing preset of that name -- apply presets to the selection with one click from the Style Presets bar in the Paint tab",
+				"Args": ki.PropSlice{
+					{"Name", ki.Props{
+						"width": 30,
+					}},
+				},
+			}},
+			{"SelFlipHoriz", ki.Props{
+				"label": "Flip Horizontal",
+				"desc":  "mirror the selection left to right, in place",
+			}},
+			{"SelFlipVert", ki.Props{
+				"label": "Flip Vertical",
+				"desc":  "mirror the selection top to bottom, in place",
+			}},
+			{"DistributeHoriz", ki.Props{
+				"label": "Distribute Horizontally",
+				"desc":  "space 3 or more selected items horizontally with equal gaps between them -- the leftmost and rightmost items stay in place",
+			}},
+			{"DistributeVert", ki.Props{
+				"label": "Distribute Vertically",
+				"desc":  "space 3 or more selected items vertically with equal gaps between them -- the top and bottom items stay in place",
+			}},
+			{"PromptTransform", ki.Props{
+				"label": "Transform...",
+				"desc":  "scale (separately horizontally and vertically), rotate and move the selection by exact amounts, about the center of the selection",
+			}},
+			{"sep-pixels", ki.BlankProp{}},
+			{"SelSnapToPixels", ki.Props{
+				"label": "Snap To Pixels...",
+				"desc":  "move selected items to integer pixel coordinates, and optionally resize to integer pixel sizes, for crisp rendering at 1:1 scale",
+				"Args": ki.PropSlice{
+					{"Sizes", ki.Props{
+						"default": false,
+					}},
+				},
+			}},
+			{"sep-path", ki.BlankProp{}},
+			{"SelTrim", ki.Props{
+				"label": "Trim Path",
+				"desc":  "trim the end of the first selected path back to where it meets the second selected path",
+			}},
+			{"SelExtend", ki.Props{
+				"label": "Extend Path",
+				"desc":  "extend the end of the first selected path to where it meets the second selected path",
+			}},
+			{"SelCleanupPaths", ki.Props{
+				"label": "Clean Up Paths",
+				"desc":  "remove duplicate points and zero-length segments from selected paths, and check for self-intersections",
+			}},
+			{"SimplifyPath", ki.Props{
+				"label": "Simplify Path",
+				"desc":  "reduce the number of nodes in the selected paths, keeping them within the SimplifyTol preference (in screen pixels) of where they were -- straight runs drop points that are nearly in line, and smooth runs of curves are re-fit with fewer curves",
+			}},
+			{"JoinPaths", ki.Props{
+				"label": "Join Paths",
+				"desc":  "with the node tool: join the path with the end node selected before clicking on the current path, to the selected end node of the current path -- ends at the same place are merged, otherwise joined with a line",
+			}},
+			{"TogglePathClosed", ki.Props{
+				"label": "Close / Open Path",
+				"desc":  "with the node tool: close the current subpath of the path (with the current or selected node) back to its start if it is open, or open it if it is closed",
+			}},
+			{"Path Ops", ki.PropSlice{
+				{"PathUnion", ki.Props{
+					"label": "Union",
+					"desc":  "replace the selected paths and shapes with a single path outlining the region that any of them fills -- curves are flattened to straight segments",
+				}},
+				{"PathIntersect", ki.Props{
+					"label": "Intersection",
+					"desc":  "replace the selected paths and shapes with a single path outlining the region that all of them fill",
+				}},
+				{"PathDifference", ki.Props{
+					"label": "Difference",
+					"desc":  "replace the selected paths and shapes with a single path outlining the region of the bottom one, minus the regions of the ones above it",
+				}},
+				{"PathExclude", ki.Props{
+					"label": "Exclusion",
+					"desc":  "replace the selected paths and shapes with a single path outlining the region filled by an odd number of them -- for two, by either one but not both",
+				}},
+			}},
+			{"sep-lock", ki.BlankProp{}},
+			{"LockSelected", ki.Props{
+				"label": "Lock",
+				"desc":  "lock the selected items, so they cannot be selected in the drawing, or moved -- locked items can still be selected in the tree",
+			}},
+			{"UnlockSelected", ki.Props{
+				"label": "Unlock",
+				"desc":  "unlock the selected items -- select locked items in the tree",
+			}},
+			{"UnlockAll", ki.Props{
+				"label": "Unlock All",
+				"desc":  "unlock all the locked items in the drawing (locked layers stay locked)",
+			}},
+			{"sep-isolate", ki.BlankProp{}},
+			{"EnterGroup", ki.Props{
+				"label": "Enter Group",
+				"desc":  "edit the elements within the selected group in isolation: everything outside of the group is dimmed and cannot be selected, and a breadcrumb of the nested groups is shown in the status bar -- also double-click on a group.  Escape exits one level.",
+			}},
+			{"ExitGroup", ki.Props{
+				"label": "Exit Group",
+				"desc":  "exit one level of group isolation",
+			}},
 			{"sep-undo", ki.BlankProp{}},
 			{"Undo", ki.Props{
 				"keyfun": keyfun.Undo,
 			}},
 			{"Redo", ki.Props{
 				"keyfun": keyfun.Redo,
+				"desc":   "redo the last undone action -- also Ctrl+Shift+Z",
+			}},
+			{"ShowHistory", ki.Props{
+				"label": "Undo History",
+				"desc":  "show the undo history, in which selecting any action goes back to the state just after it, or forward again to redo it",
 			}},
 		}},
 		{"View", ki.PropSlice{
+			{"AddGuide", ki.Props{
+				"label": "Add Guide...",
+				"desc":  "add a construction guide line through given anchor point (in drawing units) at given angle in degrees counter-clockwise from horizontal (e.g., 30 for isometric) -- guides are used as snap targets",
+				"Args": ki.PropSlice{
+					{"X", ki.Props{}},
+					{"Y", ki.Props{}},
+					{"Angle", ki.Props{
+						"default": 30,
+					}},
+				},
+			}},
+			{"ClearGuides", ki.Props{
+				"label": "Clear Guides",
+				"desc":  "remove all construction guides",
+			}},
+			{"SetPerspective", ki.Props{
+				"label": "Perspective Guides...",
+				"desc":  "set up 1, 2 or 3 point perspective guides (0 = off), projecting given number of rays from each vanishing point -- drag the vanishing points to move them.  Rays are snap targets, and are not exported.",
+				"Args": ki.PropSlice{
+					{"Points", ki.Props{
+						"default": 2,
+					}},
+					{"Rays", ki.Props{
+						"default": 36,
+					}},
+				},
+			}},
+			{"EditDocColors", ki.Props{
+				"label": "Drawing Colors...",
+				"desc":  "set the background, border and grid colors for this drawing, overriding the colors from preferences -- saved with the drawing",
+			}},
+			{"ClearDocColors", ki.Props{
+				"label": "Use Preference Colors",
+				"desc":  "remove the drawing colors override, using the background, border and grid colors from preferences",
+			}},
+			{"SaveSnapProfile", ki.Props{
+				"label": "Save Snap Profile...",
+				"desc":  "save the current snap settings (grid, guide, node and page snapping, and snap tolerance) as a named snap profile in preferences, replacing any existing profile of that name -- switch profiles with the Snap chooser in the select toolbar",
+				"Args": ki.PropSlice{
+					{"Name", ki.Props{
+						"width": 30,
+					}},
+				},
+			}},
+			{"sep-guides", ki.BlankProp{}},
+			{"ToggleRulers", ki.Props{
+				"label": "Show Rulers",
+				"desc":  "toggles the rulers along the top and left edges of the view, which show the position in the units of the drawing",
+			}},
+			{"ToggleBBoxes", ki.Props{
+				"label": "Show BBoxes / IDs",
+				"desc":  "toggles a debug overlay showing the bounding box and id of every element",
+			}},
 			{"Splits", ki.PropSlice{
 				{"SplitsSetView", ki.Props{
 					"label":   "Set View",
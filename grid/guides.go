@@ -0,0 +1,206 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/mat32"
+)
+
+// Guide is a construction guide: an infinite line through an anchor
+// point at a given angle, which is drawn in the view and used as a snap
+// target.  Guides at angles other than horizontal / vertical support
+// isometric and other angled construction.
+type Guide struct {
+
+	// point that the guide passes through, in drawing coordinates
+	Anchor mat32.Vec2
+
+	// angle of the guide in degrees, counter-clockwise from horizontal (0 = horizontal, 90 = vertical, 30 = isometric)
+	Angle float32
+}
+
+// Dir returns the unit direction vector of the guide, in drawing
+// coordinates (where Y increases downward)
+func (gd *Guide) Dir() mat32.Vec2 {
+	rad := mat32.DegToRad(gd.Angle)
+	return mat32.V2(mat32.Cos(rad), -mat32.Sin(rad))
+}
+
+// Project returns the closest point on the guide to given point,
+// and the distance to it
+func (gd *Guide) Project(pt mat32.Vec2) (mat32.Vec2, float32) {
+	u := gd.Dir()
+	t := pt.Sub(gd.Anchor).Dot(u)
+	pp := gd.Anchor.Add(u.MulScalar(t))
+	return pp, pp.Sub(pt).Length()
+}
+
+// Ends returns two points along the guide that extend beyond
+// given region, for drawing it as an infinite line
+func (gd *Guide) Ends(bb mat32.Box2) (mat32.Vec2, mat32.Vec2) {
+	ctr := bb.Min.Add(bb.Max).MulScalar(.5)
+	pp, _ := gd.Project(ctr)
+	ext := bb.Size().Length() + 1
+	u := gd.Dir().MulScalar(ext)
+	return pp.Sub(u), pp.Add(u)
+}
+
+// Guides is the list of construction guides for a drawing.
+// They are saved in the drawing metadata.
+type Guides []*Guide
+
+// String returns the metadata encoding of the guides:
+// x,y,angle entries separated by semicolons
+func (gs Guides) String() string {
+	var sb strings.Builder
+	for i, gd := range gs {
+		if i > 0 {
+			sb.WriteString(";")
+		}
+		sb.WriteString(fmt.Sprintf("%g,%g,%g", gd.Anchor.X, gd.Anchor.Y, gd.Angle))
+	}
+	return sb.String()
+}
+
+// FromString sets the guides from the metadata encoding generated by String
+func (gs *Guides) FromString(s string) {
+	*gs = make(Guides, 0)
+	for _, ent := range strings.Split(s, ";") {
+		gd := &Guide{}
+		if n, _ := fmt.Sscanf(ent, "%g,%g,%g", &gd.Anchor.X, &gd.Anchor.Y, &gd.Angle); n != 3 {
+			continue
+		}
+		*gs = append(*gs, gd)
+	}
+}
+
+// GuideColor is the color used for drawing construction guides
+var GuideColor = gist.Color{R: 0, G: 160, B: 255, A: 192}
+
+// RenderGuides renders the construction guides into the background
+func (sv *SVGView) RenderGuides() {
+	es := sv.EditState()
+	if len(es.Guides) == 0 {
+		return
+	}
+	rs := &sv.BgRender
+	pc := &rs.Paint
+	pc.StrokeStyle.SetColor(&GuideColor)
+	pc.StrokeStyle.Width.Dots = 1 / sv.Scale
+	vb := sv.ViewBBox()
+	for _, gd := range es.Guides {
+		st, ed := gd.Ends(vb)
+		pc.DrawLine(rs, st.X, st.Y, ed.X, ed.Y)
+	}
+	pc.FillStrokeClear(rs)
+}
+
+// GuidesSVG returns an svg element drawing the construction guides
+// over given region in drawing coordinates, with given line width
+func (sv *SVGView) GuidesSVG(bb mat32.Box2, wd float32) string {
+	es := sv.EditState()
+	if es == nil || len(es.Guides) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, gd := range es.Guides {
+		st, ed := gd.Ends(bb)
+		sb.WriteString(fmt.Sprintf("M %g,%g L %g,%g ", st.X, st.Y, ed.X, ed.Y))
+	}
+	return fmt.Sprintf(`<path id="export-guides" style="fill:none;stroke:%s;stroke-width:%g" d="%s" />`, GuideColor.HexString(), wd, strings.TrimSpace(sb.String()))
+}
+
+// SnapToGuides snaps given point in window coordinates to the closest
+// construction guide or perspective ray, if within the snap tolerance.
+// Returns the snapped point and true if snapped.
+func (sv *SVGView) SnapToGuides(rawpt mat32.Vec2) (mat32.Vec2, bool) {
+	es := sv.EditState()
+	if len(es.Guides) == 0 && !es.Perspective.On() {
+		return rawpt, false
+	}
+	dpt := sv.WinToDoc(rawpt)
+	gds := make([]Guide, 0, len(es.Guides)+len(es.Perspective.VanishPts))
+	for _, gd := range es.Guides {
+		gds = append(gds, *gd)
+	}
+	for _, vp := range es.Perspective.VanishPts {
+		gds = append(gds, es.Perspective.NearestRay(vp, dpt))
+	}
+	clDst := float32(-1)
+	var clPt mat32.Vec2
+	for _, gd := range gds {
+		pp, dst := gd.Project(dpt)
+		if clDst < 0 || dst < clDst {
+			clDst = dst
+			clPt = pp
+		}
+	}
+	if clDst > sv.SnapTolDoc() {
+		return rawpt, false
+	}
+	return sv.DocToWin(clPt), true
+}
+
+// SnapBBoxToGuides snaps given bbox in window coordinates so that its
+// corner or center closest to a construction guide lies on that guide,
+// if within the snap tolerance.  Returns the snapped bbox and true if snapped.
+func (sv *SVGView) SnapBBoxToGuides(rawbb mat32.Box2) (mat32.Box2, bool) {
+	es := sv.EditState()
+	if len(es.Guides) == 0 && !es.Perspective.On() {
+		return rawbb, false
+	}
+	pts := []mat32.Vec2{rawbb.Min, rawbb.Max, mat32.V2(rawbb.Min.X, rawbb.Max.Y), mat32.V2(rawbb.Max.X, rawbb.Min.Y), rawbb.Min.Add(rawbb.Max).MulScalar(.5)}
+	clDst := float32(-1)
+	var del mat32.Vec2
+	for _, pt := range pts {
+		spt, snap := sv.SnapToGuides(pt)
+		if !snap {
+			continue
+		}
+		d := spt.Sub(pt)
+		if dst := d.Length(); clDst < 0 || dst < clDst {
+			clDst = dst
+			del = d
+		}
+	}
+	if clDst < 0 {
+		return rawbb, false
+	}
+	rawbb.Min.SetAdd(del)
+	rawbb.Max.SetAdd(del)
+	return rawbb, true
+}
+
+///////////////////////////////////////////////////////////////////////
+//   Actions
+
+// AddGuide adds a construction guide through given anchor point, in
+// drawing coordinates, at given angle in degrees counter-clockwise from
+// horizontal (e.g., 30 for isometric)
+func (gv *GridView) AddGuide(x, y, angle float32) {
+	es := &gv.EditState
+	es.Guides = append(es.Guides, &Guide{Anchor: mat32.V2(x, y), Angle: angle})
+	gv.GuidesChanged()
+}
+
+// ClearGuides removes all construction guides
+func (gv *GridView) ClearGuides() {
+	es := &gv.EditState
+	es.Guides = nil
+	gv.GuidesChanged()
+}
+
+// GuidesChanged updates the display after the guides have changed
+func (gv *GridView) GuidesChanged() {
+	sv := gv.SVG()
+	gv.EditState.Changed = true
+	sv.RenderBg()
+	sv.UpdateView(true)
+	gv.ChangeMade()
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"testing"
+
+	"github.com/goki/mat32"
+)
+
+// TestSnapToGuidesZoom tests that the guide snap tolerance is the same
+// number of screen pixels at all zoom levels
+func TestSnapToGuidesZoom(t *testing.T) {
+	gv := newTestGridView(t)
+	sv := gv.SVG()
+	es := &gv.EditState
+	setTestSnapPrefs(t, false, true)
+	es.Guides = Guides{&Guide{Anchor: mat32.V2(0, 100), Angle: 0}, &Guide{Anchor: mat32.V2(200, 0), Angle: 90}}
+	for _, scale := range []float32{0.25, 0.5, 1, 2, 4, 16} {
+		sv.Scale = scale
+		on := sv.DocToWin(mat32.V2(50, 100))
+		tests := []struct {
+			off  mat32.Vec2 // window pixels from the horizontal guide
+			snap bool
+		}{
+			{mat32.V2(0, 0), true},
+			{mat32.V2(7, 2.5), true},
+			{mat32.V2(-3, -2.9), true},
+			{mat32.V2(0, 3.1), false},
+			{mat32.V2(0, -6), false},
+		}
+		for _, tt := range tests {
+			raw := on.Add(tt.off)
+			got, snap := sv.SnapToGuides(raw)
+			if snap != tt.snap {
+				t.Errorf("scale %g: SnapToGuides(on + %v) snap = %v, want %v", scale, tt.off, snap, tt.snap)
+				continue
+			}
+			want := raw
+			if tt.snap {
+				want.Y = on.Y
+			}
+			if !vec2Near(got, want) {
+				t.Errorf("scale %g: SnapToGuides(on + %v) = %v, want %v", scale, tt.off, got, want)
+			}
+		}
+		vt := sv.DocToWin(mat32.V2(200, 20)).Add(mat32.V2(2, 0))
+		if got, snap := sv.SnapToGuides(vt); !snap || !vec2Near(got, vt.Sub(mat32.V2(2, 0))) {
+			t.Errorf("scale %g: vertical guide: SnapToGuides = %v, %v", scale, got, snap)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"image"
+	"log"
+)
+
+// NewHeadlessGridView returns a new GridView that is not in a window,
+// with the drawing shown in an off-screen view of given size in pixels.
+// This allows the editing and geometry functions (e.g., PathNodes,
+// SnapPoint, DragMove, actions) to be used without a GUI, for testing
+// and scripting.  Sprites are not used, and no autosave is done.
+// Call HeadlessRender after changing the drawing to update the element
+// transforms and bounding boxes, as rendering in a window normally does.
+func NewHeadlessGridView(sz PhysSize, winSize image.Point) *GridView {
+	gv := &GridView{}
+	gv.InitName(gv, "gridview")
+	gv.Defaults()
+	gv.Config()
+	sv := gv.SVG()
+	sv.WinBBox = image.Rectangle{Max: winSize}
+	sv.Geom.Size = winSize
+	sv.Resize(winSize)
+	gv.SetPhysSize(&sz)
+	sv.HeadlessRender()
+	return gv
+}
+
+// IsHeadless returns true if the view is not in a window
+// (see NewHeadlessGridView)
+func (sv *SVGView) IsHeadless() bool {
+	return sv.GridView.ParentWindow() == nil
+}
+
+// HeadlessRender renders the drawing off-screen in a headless view,
+// updating the element transforms and bounding boxes
+func (sv *SVGView) HeadlessRender() {
+	sv.SetTransform()
+	sv.FullRender2DTree()
+}
+
+// OpenHeadlessXML reads the drawing from given svg file contents into
+// a headless view, and renders it
+func (gv *GridView) OpenHeadlessXML(b []byte) error {
+	sv := gv.SVG()
+	gv.EditState.Init()
+	err := sv.ReadXML(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	if ferr := gv.EditState.Foreign.ReadXML(b); ferr != nil {
+		log.Println(ferr)
+	}
+	sv.ReadMetaData()
+	sv.GatherIds()
+	sv.HeadlessRender()
+	return nil
+}
@@ -0,0 +1,378 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+const headlessTestSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<path id="path1" transform="translate(100,50)" d="M 10 20 L 110 20 l 0 50 h -50 v -10" style="fill:none;stroke:#000000"/>
+<rect id="rect1" x="300" y="100" width="80" height="40" style="fill:#0000ff"/>
+</svg>
+`
+
+// newTestGridView returns a new headless GridView with an empty
+// 640x360 px drawing with a 12 px grid, zoomed to fit a 1280x720 view
+func newTestGridView(t *testing.T) *GridView {
+	t.Helper()
+	ps := PhysSize{}
+	ps.Defaults()
+	ps.StdSize = CustomSize
+	ps.Size.Set(640, 360)
+	gv := NewHeadlessGridView(ps, image.Point{1280, 720})
+	if gv.SVG().Scale <= 0 {
+		t.Fatalf("headless view not zoomed: scale %g", gv.SVG().Scale)
+	}
+	return gv
+}
+
+// openTestView returns a new headless GridView (see newTestGridView)
+// with given svg drawing opened in it
+func openTestView(t *testing.T, src string) *GridView {
+	t.Helper()
+	gv := newTestGridView(t)
+	if err := gv.OpenHeadlessXML([]byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	return gv
+}
+
+// viewNode returns the element with given id in the drawing of given view,
+// failing the test if not found
+func viewNode(t *testing.T, sv *SVGView, id string) svg.NodeSVG {
+	t.Helper()
+	var fn svg.NodeSVG
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, data any) bool {
+		if fn != nil {
+			return ki.Break
+		}
+		if sii, ok := k.(svg.NodeSVG); ok && k.Name() == id {
+			fn = sii
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	if fn == nil {
+		t.Fatalf("element %s not found", id)
+	}
+	return fn
+}
+
+// testPathNodes returns the nodes of given path in its local coordinates
+func testPathNodes(sv *SVGView, path *svg.Path) []mat32.Vec2 {
+	pns, _ := sv.PathNodes(path)
+	pts := make([]mat32.Vec2, len(pns))
+	for i, pn := range pns {
+		pts[i] = pn.Cp
+	}
+	return pts
+}
+
+func TestNewHeadlessGridView(t *testing.T) {
+	gv := newTestGridView(t)
+	sv := gv.SVG()
+	if !sv.IsHeadless() {
+		t.Error("view is not headless")
+	}
+	if sz := sv.WinBBox.Size(); sz.X != 1280 || sz.Y != 720 {
+		t.Errorf("WinBBox size = %v, want 1280x720", sz)
+	}
+	pbb := sv.PageBBox()
+	if pbb.Min.X < 0 || pbb.Min.Y < 0 || pbb.Max.X > 1280 || pbb.Max.Y > 720 {
+		t.Errorf("page %v not within the view", pbb)
+	}
+	for _, pt := range []mat32.Vec2{mat32.V2(0, 0), mat32.V2(320, 180), mat32.V2(640, 360)} {
+		if got := sv.WinToDoc(sv.DocToWin(pt)); !vec2Near(got, pt) {
+			t.Errorf("WinToDoc(DocToWin(%v)) = %v", pt, got)
+		}
+	}
+}
+
+func TestHeadlessPathNodes(t *testing.T) {
+	gv := openTestView(t, headlessTestSVG)
+	sv := gv.SVG()
+	path := viewNode(t, sv, "path1").(*svg.Path)
+
+	want := []mat32.Vec2{mat32.V2(10, 20), mat32.V2(110, 20), mat32.V2(110, 70), mat32.V2(60, 70), mat32.V2(60, 60)}
+	pns, _ := sv.PathNodes(path)
+	if len(pns) != len(want) {
+		t.Fatalf("got %d nodes, want %d", len(pns), len(want))
+	}
+	off := mat32.V2(100, 50) // path transform
+	for i, pn := range pns {
+		if !vec2Near(pn.Cp, want[i]) {
+			t.Errorf("node %d: Cp = %v, want %v", i, pn.Cp, want[i])
+		}
+		if got := sv.WinToDoc(pn.WinPt); !vec2Near(got, want[i].Add(off)) {
+			t.Errorf("node %d: WinPt in drawing = %v, want %v", i, got, want[i].Add(off))
+		}
+	}
+
+	// setting a point moves the later relative points with it
+	tests := []struct {
+		idx  int
+		npt  mat32.Vec2
+		want []mat32.Vec2
+	}{
+		{2, mat32.V2(120, 80), []mat32.Vec2{mat32.V2(10, 20), mat32.V2(110, 20), mat32.V2(120, 80), mat32.V2(70, 80), mat32.V2(70, 70)}},
+		{1, mat32.V2(100, 30), []mat32.Vec2{mat32.V2(10, 20), mat32.V2(100, 30), mat32.V2(110, 90), mat32.V2(60, 90), mat32.V2(60, 80)}},
+		{3, mat32.V2(50, 90), []mat32.Vec2{mat32.V2(10, 20), mat32.V2(100, 30), mat32.V2(110, 90), mat32.V2(50, 90), mat32.V2(50, 80)}},
+		{0, mat32.V2(0, 0), []mat32.Vec2{mat32.V2(0, 0), mat32.V2(100, 30), mat32.V2(110, 90), mat32.V2(50, 90), mat32.V2(50, 80)}},
+	}
+	for _, tt := range tests {
+		pns, _ := sv.PathNodes(path)
+		sv.PathNodeSetPoint(path, pns[tt.idx], tt.npt)
+		got := testPathNodes(sv, path)
+		for i := range tt.want {
+			if !vec2Near(got[i], tt.want[i]) {
+				t.Errorf("set node %d to %v: node %d = %v, want %v", tt.idx, tt.npt, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHeadlessSnapAlign(t *testing.T) {
+	gv := openTestView(t, headlessTestSVG)
+	sv := gv.SVG()
+	es := &gv.EditState
+	setTestSnapPrefs(t, false, true)
+	spage := Prefs.SnapPage
+	defer func() { Prefs.SnapPage = spage }()
+	Prefs.SnapPage = false
+
+	es.Select(viewNode(t, sv, "path1"))
+	sv.GatherAlignPoints()
+	rbb := viewNode(t, sv, "rect1").AsSVGNode().WinBBox
+	for ap, pts := range es.AlignPts {
+		if len(pts) != 1 {
+			t.Errorf("%v: got %d align points, want 1 (rect1 only)", BBoxPoints(ap), len(pts))
+		}
+	}
+
+	y := sv.DocToWin(mat32.V2(0, 300)).Y // away from all align points
+	tests := []struct {
+		raw  mat32.Vec2
+		want mat32.Vec2
+	}{
+		{mat32.V2(float32(rbb.Min.X)+2, y), mat32.V2(float32(rbb.Min.X), y)},
+		{mat32.V2(float32(rbb.Max.X)-1, y), mat32.V2(float32(rbb.Max.X), y)},
+		{mat32.V2(float32(rbb.Min.X)-10, y), mat32.V2(float32(rbb.Min.X)-10, y)},
+		{mat32.V2(40, float32(rbb.Min.Y)+2.5), mat32.V2(40, float32(rbb.Min.Y))},
+	}
+	for _, tt := range tests {
+		if got := sv.SnapPoint(tt.raw); !vec2Near(got, tt.want) {
+			t.Errorf("SnapPoint(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestHeadlessJoinPaths(t *testing.T) {
+	const src = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<g id="g1" transform="translate(20,10)">
+<path id="patha" transform="scale(2)" d="M 10 10 L 50 10" style="fill:none;stroke:#000000"/>
+</g>
+<path id="pathb" transform="translate(300,100) rotate(90)" d="M 0 0 L 40 0" style="fill:none;stroke:#000000"/>
+</svg>
+`
+	gv := openTestView(t, src)
+	sv := gv.SVG()
+	es := &gv.EditState
+	d := &Doc{View: gv}
+	setTestZoom(sv, 2, -50, -20)
+	apath := testNode(t, d, "patha").(*svg.Path)
+	bpath := testNode(t, d, "pathb").(*svg.Path)
+
+	es.JoinPath = apath
+	es.JoinNode = 1 // end of patha
+	es.ActivePath = bpath
+	es.PathNodes, es.PathCmds = sv.PathNodes(bpath)
+	es.PathSel = map[int]struct{}{0: {}} // start of pathb
+	gv.JoinPaths()
+	if bpath.Parent() != nil {
+		t.Error("pathb not deleted")
+	}
+	// the nodes stay where they were in the drawing
+	want := []mat32.Vec2{mat32.V2(40, 30), mat32.V2(120, 30), mat32.V2(300, 100), mat32.V2(300, 140)}
+	pns, _ := sv.PathNodes(apath)
+	if len(pns) != len(want) {
+		t.Fatalf("got %d nodes, want %d", len(pns), len(want))
+	}
+	for i, pn := range pns {
+		if got := sv.WinToDoc(pn.WinPt); !vec2Near(got, want[i]) {
+			t.Errorf("node %d: in drawing = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestHeadlessTrimExtend(t *testing.T) {
+	const src = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<g id="g1" transform="translate(20,10)">
+<path id="pathp" transform="scale(2)" d="M 10 10 L 50 10" style="fill:none;stroke:#000000"/>
+</g>
+<path id="trimb" transform="translate(100,100) rotate(90)" d="M -200 0 L 200 0" style="fill:none;stroke:#000000"/>
+<path id="extb" transform="translate(300,100) rotate(90)" d="M -200 100 L 200 100" style="fill:none;stroke:#000000"/>
+</svg>
+`
+	tests := []struct {
+		extend bool
+		bound  string
+		want   []mat32.Vec2 // nodes in drawing coordinates
+	}{
+		{false, "trimb", []mat32.Vec2{mat32.V2(40, 30), mat32.V2(100, 30)}},
+		{true, "extb", []mat32.Vec2{mat32.V2(40, 30), mat32.V2(200, 30)}},
+	}
+	for _, tt := range tests {
+		gv := openTestView(t, src)
+		sv := gv.SVG()
+		es := &gv.EditState
+		setTestZoom(sv, 2, -50, -20)
+		path := viewNode(t, sv, "pathp").(*svg.Path)
+		es.Select(path)
+		es.Select(viewNode(t, sv, tt.bound))
+		gv.SelTrimExtend(tt.extend)
+		pns, _ := sv.PathNodes(path)
+		if len(pns) != len(tt.want) {
+			t.Fatalf("extend %v: got %d nodes, want %d", tt.extend, len(pns), len(tt.want))
+		}
+		for i, pn := range pns {
+			if got := sv.WinToDoc(pn.WinPt); !vec2Near(got, tt.want[i]) {
+				t.Errorf("extend %v: node %d: in drawing = %v, want %v", tt.extend, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestHeadlessCopyPaste(t *testing.T) {
+	const src = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<defs>
+<linearGradient id="linear1"><stop offset="0" stop-color="#ff0000"/><stop offset="1" stop-color="#0000ff"/></linearGradient>
+</defs>
+<g id="layer1" transform="translate(20,10) scale(2)">
+<rect id="rect1" x="10" y="10" width="40" height="20" style="fill:url(#linear1)"/>
+</g>
+<g id="layer2" transform="translate(-30,40)">
+</g>
+</svg>
+`
+	const dst = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<defs>
+<linearGradient id="linear1"><stop offset="0" stop-color="#00ff00"/><stop offset="1" stop-color="#ffffff"/></linearGradient>
+</defs>
+<g id="layer2" transform="translate(-30,40)">
+</g>
+</svg>
+`
+	gv := openTestView(t, src)
+	sv := gv.SVG()
+	es := &gv.EditState
+	setTestZoom(sv, 2, -50, -20)
+	rect := viewNode(t, sv, "rect1")
+	want := sv.DocBBox(rect)
+	es.Select(rect)
+	b := gv.SelectedSVG()
+
+	for _, tt := range []struct {
+		name, src, grad string
+	}{
+		{"same drawing", src, "linear1"},
+		{"other drawing", dst, ""},
+	} {
+		pv := gv
+		if tt.src != src {
+			pv = openTestView(t, tt.src)
+		}
+		psv := pv.SVG()
+		pes := &pv.EditState
+		pes.CurLayer = "layer2"
+		pes.PasteN = -1 // in place
+		if err := pv.PasteSVG(b); err != nil {
+			t.Fatal(err)
+		}
+		psv.HeadlessRender()
+		sl := pes.SelectedList(false)
+		if len(sl) != 1 {
+			t.Fatalf("%s: got %d pasted items, want 1", tt.name, len(sl))
+		}
+		pn := sl[0]
+		if pn.Parent().Name() != "layer2" {
+			t.Errorf("%s: pasted into %s, want layer2", tt.name, pn.Parent().Name())
+		}
+		if got := psv.DocBBox(pn); !vec2Near(got.Min, want.Min) || !vec2Near(got.Max, want.Max) {
+			t.Errorf("%s: pasted bbox = %v, want %v", tt.name, got, want)
+		}
+		gnm := svg.NameFromURL(pn.Prop("fill").(string))
+		if tt.grad != "" && gnm != tt.grad {
+			t.Errorf("%s: fill gradient = %s, want %s", tt.name, gnm, tt.grad)
+		}
+		if tt.grad == "" && (gnm == "linear1" || psv.Defs.ChildByName(gnm, 0) == nil) {
+			t.Errorf("%s: fill gradient = %s, want new gradient in defs", tt.name, gnm)
+		}
+	}
+}
+
+func TestHeadlessRoundHandle(t *testing.T) {
+	gv := openTestView(t, headlessTestSVG)
+	sv := gv.SVG()
+	es := &gv.EditState
+	es.Tool = NodeTool
+	for _, tt := range []struct {
+		id  string
+		dir mat32.Vec2 // side of the corner that the handle moves along, in window coords
+	}{
+		{"rect1", mat32.V2(1, 0)},
+		{"path1", mat32.V2(0, 1)},
+	} {
+		sn := viewNode(t, sv, tt.id)
+		es.ResetSelected()
+		es.Select(sn)
+		sv.UpdateSelect()
+		wp, ok := sv.RoundHandleWinPt(sn)
+		if !ok {
+			t.Fatalf("%s: no round corners handle", tt.id)
+		}
+		nrec := len(es.UndoMgr.Recs)
+		for _, r := range []float32{5, 8} {
+			sv.RoundDrag(wp.Add(tt.dir.MulScalar(r * sv.Scale)))
+			if got := sv.RoundRadius(sn); mat32.Abs(got-r) > 1.0e-3 {
+				t.Errorf("%s: radius = %g, want %g", tt.id, got, r)
+			}
+			nwp, _ := sv.RoundHandleWinPt(sn)
+			if want := wp.Add(tt.dir.MulScalar(r * sv.Scale)); !vec2Near(nwp, want) {
+				t.Errorf("%s: radius %g: handle at %v, want %v", tt.id, r, nwp, want)
+			}
+		}
+		if n := len(es.UndoMgr.Recs) - nrec; n != 1 {
+			t.Errorf("%s: %d undo records for dragging the handle, want 1", tt.id, n)
+		}
+		if rc, ok := sn.(*svg.Rect); ok && rc.Radius != mat32.V2(8, 8) {
+			t.Errorf("%s: Rx, Ry = %v, want 8, 8", tt.id, rc.Radius)
+		}
+	}
+}
+
+func TestSelRotation(t *testing.T) {
+	gv := openTestView(t, `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<rect id="rect1" x="100" y="50" width="40" height="20" transform="rotate(30)"/>
+<path id="path1" d="M 200 100 L 240 120 L 220 160 z"/>
+</svg>
+`)
+	es := &gv.EditState
+	d := &Doc{View: gv}
+	es.Select(testNode(t, d, "rect1"))
+	if rot, known := gv.SelRotation(); !known || mat32.Abs(rot-30) > 1.0e-3 {
+		t.Errorf("rect: got rotation %g, %v, want 30, true", rot, known)
+	}
+	es.ResetSelected()
+	es.Select(testNode(t, d, "path1"))
+	if rot, known := gv.SelRotation(); known || rot != 0 {
+		t.Errorf("path: got rotation %g, %v, want 0, false", rot, known)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"os"
+	"testing"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// readTestDoc reads given svg file into a Doc, failing the test on error
+func readTestDoc(t *testing.T, fname string) *Doc {
+	t.Helper()
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := ReadDoc(b)
+	if err != nil {
+		t.Fatalf("%s: %v", fname, err)
+	}
+	return d
+}
+
+// roundTripDoc returns given Doc saved and read back into a new Doc
+func roundTripDoc(t *testing.T, d *Doc) *Doc {
+	t.Helper()
+	b, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd, err := ReadDoc(b)
+	if err != nil {
+		t.Fatalf("reading saved drawing: %v\n%s", err, b)
+	}
+	return rd
+}
+
+// testNode returns the element with given id in given Doc,
+// failing the test if not found
+func testNode(t *testing.T, d *Doc, id string) svg.NodeSVG {
+	t.Helper()
+	sn := d.NodeById(id)
+	if sn == nil {
+		t.Fatalf("element %s not found", id)
+	}
+	return sn
+}
+
+// docTexts returns, for each text element and run in given Doc by id,
+// its text and its fill and text-anchor properties
+func docTexts(d *Doc) map[string][3]string {
+	txts := make(map[string][3]string)
+	d.SVG().FuncDownMeFirst(0, nil, func(k ki.Ki, level int, data any) bool {
+		if tn, ok := k.(*svg.Text); ok {
+			txts[tn.Name()] = [3]string{tn.Text, svgPropString(tn, "fill"), svgPropString(tn, "text-anchor")}
+		}
+		return ki.Continue
+	})
+	return txts
+}
+
+// svgPropString returns the given property of given element as a string,
+// empty if not set
+func svgPropString(sn svg.NodeSVG, prop string) string {
+	if p := sn.Prop(prop); p != nil {
+		return kit.ToString(p)
+	}
+	return ""
+}
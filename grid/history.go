@@ -0,0 +1,193 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// HistoryItem is one entry in the undo history shown in the HistoryView
+type HistoryItem struct {
+
+	// name of the action, as given to UndoSave
+	Action string `inactive:"+"`
+
+	// action data, e.g., the names of the items acted on
+	Data string `inactive:"+" width:"30"`
+
+	// action has been undone -- selecting it, or any later action, redoes it
+	Undone bool `inactive:"+"`
+}
+
+// HistoryStartAction is the name of the first entry in the undo history,
+// for the state before any of the actions
+const HistoryStartAction = "<start>"
+
+// History returns the undo history, starting with an entry for the state
+// before the first action, followed by each of the actions in order
+func (es *EditState) History() []*HistoryItem {
+	um := &es.UndoMgr
+	hl := []*HistoryItem{{Action: HistoryStartAction}}
+	for i, rec := range um.Recs {
+		if rec.UndoSave { // state at the end, not an action
+			continue
+		}
+		hl = append(hl, &HistoryItem{Action: rec.Action, Data: rec.Data, Undone: i > um.Idx})
+	}
+	return hl
+}
+
+// HistoryGoTo restores the drawing to the state just after the action at
+// given index in the undo records (-1 for the state before the first one),
+// undoing or redoing all of the actions in between, and returns the last
+// action undone or redone
+func (sv *SVGView) HistoryGoTo(idx int) string {
+	es := sv.EditState()
+	um := &es.UndoMgr
+	if idx == um.Idx || idx < -1 || idx >= len(um.Recs) {
+		return ""
+	}
+	es.ResetSelected()
+	var act string
+	var state []string
+	if idx < um.Idx {
+		sv.UndoSaveStart()
+		for um.Idx > idx {
+			act, _, state = um.Undo()
+		}
+	} else {
+		for um.Idx < idx {
+			a, _, st := um.Redo()
+			if st == nil {
+				break
+			}
+			act, state = a, st
+		}
+	}
+	sv.LoadUndoState(state)
+	return act
+}
+
+// HistoryGoTo restores the drawing to the state just after the action at
+// given index in the History (0 = the state before any of the actions)
+func (gv *GridView) HistoryGoTo(hidx int) {
+	es := &gv.EditState
+	um := &es.UndoMgr
+	idx := -1
+	for i, rec := range um.Recs { // skip over any UndoSave records
+		if hidx == 0 {
+			break
+		}
+		if !rec.UndoSave {
+			hidx--
+			idx = i
+		}
+	}
+	if idx == um.Idx {
+		return
+	}
+	undo := idx < um.Idx
+	act := gv.SVG().HistoryGoTo(idx)
+	if undo {
+		gv.SetStatus("Undid back to: " + act)
+	} else {
+		gv.SetStatus("Redid up to: " + act)
+	}
+	gv.UpdateAll()
+	gv.UpdateHistoryView()
+}
+
+///////////////////////////////////////////////////////////////
+//  HistoryView
+
+// HistoryView shows the undo history, and allows jumping back to the
+// state after any of the actions, or forward again to redo them
+type HistoryView struct {
+	gi.Layout
+
+	// the parent gridview
+	GridView *GridView `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// the history shown
+	History []*HistoryItem `copy:"-" json:"-" xml:"-" view:"-"`
+}
+
+var KiT_HistoryView = kit.Types.AddType(&HistoryView{}, HistoryViewProps)
+
+// Config configures the view
+func (hv *HistoryView) Config(gv *GridView) {
+	if hv.HasChildren() {
+		return
+	}
+	updt := hv.UpdateStart()
+	hv.GridView = gv
+	hv.Lay = gi.LayoutVert
+	hv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+
+	tb := gi.AddNewToolbar(hv, "history-tb")
+	tb.AddAction(gi.ActOpts{Label: "Undo", Icon: "rotate-left", Tooltip: "undo the last action", UpdateFunc: gv.UndoAvailFunc},
+		hv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			hv.GridView.Undo()
+		})
+	tb.AddAction(gi.ActOpts{Label: "Redo", Icon: "rotate-right", Tooltip: "redo the last undone action", UpdateFunc: gv.RedoAvailFunc},
+		hv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			hv.GridView.Redo()
+		})
+
+	tv := giv.AddNewTableView(hv, "history")
+	tv.SetStretchMax()
+	tv.SetInactive()
+	tv.SetSlice(&hv.History)
+	tv.WidgetSig.Connect(hv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		if sig == int64(gi.WidgetSelected) {
+			hv.GridView.HistoryGoTo(data.(int))
+		}
+	})
+
+	hv.UpdateEnd(updt)
+}
+
+// TableView returns the tableview of the history
+func (hv *HistoryView) TableView() *giv.TableView {
+	return hv.ChildByName("history", 1).(*giv.TableView)
+}
+
+// UpdateHistoryView updates the undo history tab, selecting the entry
+// for the current state
+func (gv *GridView) UpdateHistoryView() {
+	hv, ok := gv.Tab("History").(*HistoryView)
+	if !ok {
+		return
+	}
+	es := &gv.EditState
+	hv.History = es.History()
+	cur := 0
+	for i, hi := range hv.History {
+		if hi.Action != HistoryStartAction && !hi.Undone {
+			cur = i
+		}
+	}
+	tv := hv.TableView()
+	tv.SetSlice(&hv.History)
+	tv.ClearSelected()
+	tv.SelectIdx(cur)
+}
+
+// ShowHistory selects the undo history tab
+func (gv *GridView) ShowHistory() {
+	gv.UpdateHistoryView()
+	gv.Tabs().SelectTabByName("History")
+}
+
+var HistoryViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_VpFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+)
+
+// IsolateDimAlpha is the opacity of the veil drawn over everything
+// outside of the isolated group, in the background color
+var IsolateDimAlpha = uint8(180)
+
+// IsolatedGroup returns the group currently entered in isolation mode,
+// and nil if none.  Groups that have since been deleted (e.g., by undo)
+// are exited automatically.
+func (es *EditState) IsolatedGroup() *svg.Group {
+	for len(es.Isolated) > 0 {
+		gp := es.Isolated[len(es.Isolated)-1]
+		if !gp.IsDeleted() && !gp.IsDestroyed() && gp.Parent() != nil {
+			return gp
+		}
+		es.Isolated = es.Isolated[:len(es.Isolated)-1]
+	}
+	return nil
+}
+
+// SelectRoot returns the node within which elements are selected:
+// the isolated group if in isolation mode, else the drawing
+func (sv *SVGView) SelectRoot() ki.Ki {
+	if gp := sv.EditState().IsolatedGroup(); gp != nil {
+		return gp.This()
+	}
+	return sv.This()
+}
+
+// EnterGroup enters the selected group in isolation mode: everything
+// outside of the group is dimmed, and only the elements within it
+// can be selected.  Escape exits one level.
+func (gv *GridView) EnterGroup() {
+	if gp, ok := gv.EditState.FirstSelectedNode().(*svg.Group); ok {
+		gv.IsolateGroup(gp)
+	}
+}
+
+// IsolateGroup enters given group in isolation mode, nested within
+// any group currently isolated
+func (gv *GridView) IsolateGroup(gp *svg.Group) {
+	if NodeIsLayer(gp) {
+		return
+	}
+	es := &gv.EditState
+	es.IsolatedGroup() // prune any deleted
+	es.Isolated = append(es.Isolated, gp)
+	gv.UpdateIsolation()
+}
+
+// ExitGroup exits the current level of group isolation
+func (gv *GridView) ExitGroup() {
+	es := &gv.EditState
+	if es.IsolatedGroup() == nil {
+		return
+	}
+	gv.ExitGroupTo(len(es.Isolated) - 1)
+}
+
+// ExitGroupTo exits group isolation to given nesting level,
+// where 0 exits isolation mode entirely.  The group that was
+// exited at that level is selected.
+func (gv *GridView) ExitGroupTo(level int) {
+	es := &gv.EditState
+	es.IsolatedGroup()
+	if level < 0 || level >= len(es.Isolated) {
+		return
+	}
+	exgp := es.Isolated[level]
+	es.Isolated = es.Isolated[:level]
+	es.ResetSelected()
+	es.Select(exgp)
+	gv.UpdateIsolation()
+}
+
+// UpdateIsolation updates the view and the breadcrumb of nested
+// groups in the status bar after a change in group isolation
+func (gv *GridView) UpdateIsolation() {
+	es := &gv.EditState
+	sv := gv.SVG()
+	if gp := es.IsolatedGroup(); gp != nil {
+		for itm := range es.Selected {
+			if itm.ParentLevel(gp.This()) < 1 {
+				es.Unselect(itm)
+			}
+		}
+		gv.SetStatus("Editing group: " + gp.Name() + " -- Escape to exit")
+	} else {
+		gv.SetStatus("")
+	}
+	gv.ConfigBreadcrumb()
+	sv.UpdateSelect()
+	sv.UpdateView(true)
+}
+
+// Breadcrumb returns the breadcrumb of nested isolated groups in the status bar
+func (gv *GridView) Breadcrumb() *gi.Toolbar {
+	return gv.StatusBar().ChildByName("crumbs", 1).(*gi.Toolbar)
+}
+
+// ConfigBreadcrumb configures the breadcrumb of nested isolated groups,
+// with a button for each level -- clicking exits isolation to that level
+func (gv *GridView) ConfigBreadcrumb() {
+	es := &gv.EditState
+	tb := gv.Breadcrumb()
+	updt := tb.UpdateStart()
+	tb.DeleteChildren(ki.DestroyKids)
+	if es.IsolatedGroup() != nil {
+		tb.AddAction(gi.ActOpts{Label: "Drawing", Tooltip: "exit group isolation"},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				grr.ExitGroupTo(0)
+			})
+		for i, gp := range es.Isolated {
+			lev := i + 1
+			tb.AddSeparator("")
+			tb.AddAction(gi.ActOpts{Label: gp.Name(), Tooltip: "exit group isolation to this group"},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					grr.ExitGroupTo(lev)
+				})
+		}
+	}
+	tb.UpdateEnd(updt)
+}
+
+// RenderIsolated dims everything rendered so far with a veil in the
+// background color, and renders the isolated group again on top
+func (sv *SVGView) RenderIsolated(gp *svg.Group) {
+	bg := sv.Colors().Background
+	veil := color.NRGBA{bg.R, bg.G, bg.B, IsolateDimAlpha}
+	draw.Draw(sv.Pixels, sv.Pixels.Bounds(), &image.Uniform{veil}, image.ZP, draw.Over)
+	var pars []svg.NodeSVG
+	for p := gp.Parent(); p != nil && p != sv.This(); p = p.Parent() {
+		if psi, ok := p.(svg.NodeSVG); ok {
+			pars = append(pars, psi)
+		}
+	}
+	rs := &sv.Render
+	for i := len(pars) - 1; i >= 0; i-- {
+		rs.PushTransform(pars[i].AsSVGNode().Pnt.Transform)
+	}
+	gp.Render2D()
+	for range pars {
+		rs.PopTransform()
+	}
+}
@@ -49,22 +49,30 @@ type Layers []*Layer
 
 func (ly *Layers) SyncLayers(sv *SVGView) {
 	*ly = make(Layers, 0)
-	for _, kc := range sv.Kids {
-		if NodeIsLayer(kc) {
-			l := &Layer{Name: kc.Name()}
-			l.FromNode(kc)
-			*ly = append(*ly, l)
-		}
+	for _, kc := range sv.LayerGroups() {
+		l := &Layer{Name: kc.Name()}
+		l.FromNode(kc)
+		*ly = append(*ly, l)
 	}
 }
 
-func (ly *Layers) LayersUpdated(svg *SVGView) {
-	si := 1 // starting index -- assuming namedview
+// LayersUpdated updates the layer groups from the layers, which are in
+// the same order -- a layer that was renamed renames its group
+func (ly *Layers) LayersUpdated(sv *SVGView) {
+	es := sv.EditState()
+	lgs := sv.LayerGroups()
 	for i, l := range *ly {
-		kc := svg.ChildByName(l.Name, si+i)
-		if kc != nil {
-			l.ToNode(kc)
+		if i >= len(lgs) {
+			break
 		}
+		kc := lgs[i]
+		if l.Name != "" && l.Name != kc.Name() {
+			if es.CurLayer == kc.Name() {
+				es.CurLayer = l.Name
+			}
+			kc.SetName(l.Name)
+		}
+		l.ToNode(kc)
 	}
 }
 
@@ -95,34 +103,39 @@ func (gv *GridView) LayerViewSigs(lyv *giv.TableView) {
 	es := &gv.EditState
 	sv := gv.SVG()
 	lyv.ViewSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
-		// fmt.Printf("tv viewsig: %v  data: %v  send: %v\n", sig, data, send.Path())
+		sv.UndoSave("Layers", "")
 		updt := sv.UpdateStart()
 		es.Layers.LayersUpdated(sv)
 		sv.UpdateEnd(updt)
 		gv.UpdateLayerView()
+		gv.UpdateTreeView()
+		gv.ChangeMade()
 	})
 
 	lyv.SliceViewSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 		svs := giv.SliceViewSignals(sig)
 		idx := data.(int)
-		fmt.Printf("tv sliceviewsig: %v  data: %v\n", svs.String(), idx)
 		switch svs {
 		case giv.SliceViewInserted:
+			sv.UndoSave("AddLayer", "")
 			si := gv.FirstLayerIndex()
 			li := si + idx
 			l := es.Layers[idx]
-			l.Name = fmt.Sprintf("Layer%d", li)
+			l.Name = gv.NewLayerName()
 			l.Vis = true
 			sl := sv.InsertNewChild(svg.KiT_Group, li, l.Name)
 			sl.SetProp("groupmode", "layer")
 			// todo: move selected into this new group
+			gv.SetCurLayer(l.Name)
 			gv.UpdateLayerView()
+			gv.UpdateTreeView()
+			gv.ChangeMade()
 		case giv.SliceViewDeleted:
+			gv.DeleteLayer(idx)
 		}
 	})
 
 	lyv.WidgetSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
-		fmt.Printf("tv widgetsig: %v  data: %v\n", gi.WidgetSignals(sig).String(), data)
 		if sig == int64(gi.WidgetSelected) {
 			idx := data.(int)
 			ly := es.Layers[idx]
@@ -157,37 +170,88 @@ func (gv *GridView) UpdateLayerView() {
 
 func (gv *GridView) AddLayer() {
 	sv := gv.SVG()
+	sv.UndoSave("AddLayer", "")
 	updt := sv.UpdateStart()
 	defer sv.UpdateEnd(updt)
 
 	lys := &gv.EditState.Layers
 	lys.SyncLayers(sv)
 	nl := len(*lys)
-	si := 1 // starting index -- assuming namedview
 	if nl == 0 {
+		si := StackBottom(sv) // starting index -- after namedview
 		bg := sv.InsertNewChild(svg.KiT_Group, si, "LayerBG")
 		bg.SetProp("groupmode", "layer")
 		l1 := sv.InsertNewChild(svg.KiT_Group, si+1, "Layer1")
 		l1.SetProp("groupmode", "layer")
 		sv.SetChildAdded()
 		nk := len(sv.Kids)
-		for i := nk - 1; i >= 3; i-- {
+		for i := nk - 1; i >= si+2; i-- {
 			kc := sv.Child(i)
 			ki.MoveToParent(kc, l1)
 		}
 		gv.SetCurLayer(l1.Name())
 	} else {
-		l1 := sv.InsertNewChild(svg.KiT_Group, si+nl, fmt.Sprintf("Layer%d", nl))
+		lgs := sv.LayerGroups()
+		li, _ := lgs[nl-1].IndexInParent()
+		l1 := sv.InsertNewChild(svg.KiT_Group, li+1, gv.NewLayerName())
 		sv.SetChildAdded()
 		l1.SetProp("groupmode", "layer")
 		gv.SetCurLayer(l1.Name())
 	}
 	gv.UpdateLayerView()
+	gv.UpdateTreeView()
+	gv.ChangeMade()
+}
+
+// NewLayerName returns a name for a new layer, Layer<n>, that is not
+// already the name of another layer
+func (gv *GridView) NewLayerName() string {
+	sv := gv.SVG()
+	for n := len(sv.LayerGroups()); ; n++ {
+		nm := fmt.Sprintf("Layer%d", n)
+		if sv.ChildByName(nm, 0) == nil {
+			return nm
+		}
+	}
+}
+
+// DeleteLayer deletes the layer at given index in the list of layers,
+// including everything in it
+func (gv *GridView) DeleteLayer(idx int) {
+	sv := gv.SVG()
+	lgs := sv.LayerGroups()
+	if idx < 0 || idx >= len(lgs) {
+		return
+	}
+	ly := lgs[idx]
+	sv.UndoSave("DeleteLayer", ly.Name())
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	gv.EditState.ResetSelected()
+	gv.ClearCurLayer(ly.Name())
+	ly.Delete(ki.DestroyKids)
+	sv.UpdateEnd(updt)
+	gv.UpdateLayerView()
+	gv.UpdateTreeView()
+	sv.UpdateSelect()
+	gv.ChangeMade()
 }
 
 /////////////////////////////////////////////////////////////////
 //  Node
 
+// LayerGroups returns the layer groups of the drawing, from the
+// bottom up
+func (sv *SVGView) LayerGroups() []ki.Ki {
+	var lgs []ki.Ki
+	for _, kc := range sv.Kids {
+		if NodeIsLayer(kc) {
+			lgs = append(lgs, kc)
+		}
+	}
+	return lgs
+}
+
 // NodeIsLayer returns true if given node is a layer
 func NodeIsLayer(kn ki.Ki) bool {
 	gm := kit.ToString(kn.Prop("groupmode"))
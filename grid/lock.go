@@ -0,0 +1,127 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"github.com/goki/gi/gi"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// NodeIsLocked returns true if given element is locked, so that it
+// cannot be selected in the drawing, or moved, and is skipped by the
+// operations on the selection that modify items (see HasSelectedUnlocked):
+// the same insensitive property as for locked layers (see LayerIsLocked)
+func NodeIsLocked(kn ki.Ki) bool {
+	return LayerIsLocked(kn)
+}
+
+// SetNodeLocked sets whether given element is locked
+func SetNodeLocked(kn ki.Ki, lock bool) {
+	if lock {
+		kn.SetProp("insensitive", "true")
+	} else {
+		kn.DeleteProp("insensitive")
+	}
+}
+
+// SelectedLocked returns true if any of the selected items is locked
+// -- they can still be selected from the tree
+func (es *EditState) SelectedLocked() bool {
+	for sn := range es.Selected {
+		if NodeIsLocked(sn) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSelectedUnlocked returns true if any of the selected items is not
+// locked -- operations that modify the selected items, such as rotate,
+// flip, align and group, skip the locked ones
+func (es *EditState) HasSelectedUnlocked() bool {
+	for sn := range es.Selected {
+		if !NodeIsLocked(sn) {
+			return true
+		}
+	}
+	return false
+}
+
+// LockSelected locks the selected items, so they cannot be selected
+// in the drawing, or moved, until unlocked
+func (gv *GridView) LockSelected() {
+	gv.SetSelectedLocked(true)
+}
+
+// UnlockSelected unlocks the selected items -- locked items can be
+// selected in the tree, or use UnlockAll
+func (gv *GridView) UnlockSelected() {
+	gv.SetSelectedLocked(false)
+}
+
+// SetSelectedLocked sets whether the selected items are locked
+func (gv *GridView) SetSelectedLocked(lock bool) {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		return
+	}
+	sv := gv.SVG()
+	act := "Unlock"
+	if lock {
+		act = "Lock"
+	}
+	sv.UndoSave(act, es.SelectedNamesString())
+	for sn := range es.Selected {
+		SetNodeLocked(sn, lock)
+	}
+	sv.UpdateSelect()
+	gv.ChangeMade()
+}
+
+// UnlockAll unlocks all the locked elements in the drawing (but not
+// locked layers)
+func (gv *GridView) UnlockAll() {
+	sv := gv.SVG()
+	var ls []ki.Ki
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if k == sv.Defs.This() || NodeIsMetaData(k) {
+			return ki.Break
+		}
+		if k != sv.This() && !NodeIsLayer(k) && NodeIsLocked(k) {
+			ls = append(ls, k)
+		}
+		return ki.Continue
+	})
+	if len(ls) == 0 {
+		gv.SetStatus("Unlock All: no locked items")
+		return
+	}
+	sv.UndoSave("UnlockAll", "")
+	for _, k := range ls {
+		SetNodeLocked(k, false)
+	}
+	sv.UpdateSelect()
+	gv.ChangeMade()
+}
+
+// SetLockedSpritePos marks each of the selected items with the locked
+// bbox sprites, in place of the selection sprites
+func (sv *SVGView) SetLockedSpritePos() {
+	es := sv.EditState()
+	sl := es.SelectedList(false)
+	for si, sii := range sl {
+		bb := mat32.Box2{}
+		bb.SetFromRect(sii.AsSVGNode().WinBBox)
+		sv.SetBBoxSpritePos(SpLockedBBox, si, bb)
+	}
+}
+
+// LockedEnableFunc is an ActionUpdateFunc that inactivates action if
+// no selected items are locked
+func (gv *GridView) LockedEnableFunc(act *gi.Button) {
+	es := &gv.EditState
+	act.SetInactiveState(!es.SelectedLocked())
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"testing"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/mat32"
+)
+
+const lockTestSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<rect id="locked" x="10.3" y="10" width="40" height="20"/>
+<rect id="rect1" x="100" y="50" width="40" height="20"/>
+<rect id="rect2" x="200" y="100" width="20" height="40"/>
+<rect id="rect3" x="300" y="150" width="30" height="30"/>
+</svg>
+`
+
+// TestLockedSelectionOps tests that the operations on the selection
+// that modify items skip the locked items
+func TestLockedSelectionOps(t *testing.T) {
+	tests := []struct {
+		name    string
+		ids     []string // selected items, all if nil
+		op      func(gv *GridView)
+		changed func(d *Doc) bool // whether unlocked items changed, rect2 moved if nil
+	}{
+		{"rotate", nil, func(gv *GridView) { gv.SelRotate(90) }, nil},
+		{"scale", nil, func(gv *GridView) { gv.SelScale(2, 2) }, nil},
+		{"flip", nil, func(gv *GridView) { gv.SelFlipHoriz() }, nil},
+		{"transform", nil, func(gv *GridView) { gv.SelTransform(&TransformParams{Scale: mat32.V2(1, 1), Rotate: 45}) }, nil},
+		{"align left", nil, func(gv *GridView) { gv.AlignLeft(AlignSelectBox) }, nil},
+		{"align center", nil, func(gv *GridView) { gv.AlignCenterVert(AlignLast) }, nil},
+		{"distribute", nil, func(gv *GridView) { gv.Distribute(mat32.X, false, "Distribute") }, nil},
+		{"group", nil, func(gv *GridView) { gv.SelGroup() }, func(d *Doc) bool {
+			_, ok := d.NodeById("rect1").Parent().(*svg.Group)
+			return ok
+		}},
+		{"raise top", []string{"locked", "rect1"}, func(gv *GridView) { gv.RaiseToTop() }, func(d *Doc) bool {
+			idx, _ := d.NodeById("rect1").IndexInParent()
+			return idx == 3
+		}},
+		{"snap to pixels", nil, func(gv *GridView) { gv.SelSnapToPixels(true) }, func(d *Doc) bool { return true }},
+		{"delete", nil, func(gv *GridView) {
+			gv.UpdateTreeView()
+			gv.DeleteSelected()
+		}, func(d *Doc) bool { return d.NodeById("rect2") == nil }},
+		{"union", nil, func(gv *GridView) { gv.PathUnion() }, func(d *Doc) bool { return d.NodeById("rect2") == nil }},
+		{"round corners", nil, func(gv *GridView) { gv.SelRoundCorners(5) }, func(d *Doc) bool {
+			return d.NodeById("rect2").(*svg.Rect).Radius.X == 5
+		}},
+	}
+	for _, tt := range tests {
+		gv := openTestView(t, lockTestSVG)
+		sv := gv.SVG()
+		es := &gv.EditState
+		d := &Doc{View: gv}
+		lk := testNode(t, d, "locked")
+		SetNodeLocked(lk, true)
+		ids := tt.ids
+		if ids == nil {
+			ids = []string{"locked", "rect1", "rect2", "rect3"}
+		}
+		for _, id := range ids {
+			es.Select(testNode(t, d, id))
+		}
+		es.UpdateSelBBox()
+		lbb := lk.AsSVGNode().WinBBox
+		var lgeom []float32
+		lk.WriteGeom(&lgeom)
+		lidx, _ := lk.IndexInParent()
+		r2bb := testNode(t, d, "rect2").AsSVGNode().WinBBox
+		tt.op(gv)
+		sv.HeadlessRender()
+		if lk.Prop("transform") != nil {
+			t.Errorf("%s: locked item transformed: %v", tt.name, lk.Prop("transform"))
+		}
+		if bb := lk.AsSVGNode().WinBBox; bb != lbb {
+			t.Errorf("%s: locked item moved from %v to %v", tt.name, lbb, bb)
+		}
+		if lk.Parent() != sv.This() {
+			t.Errorf("%s: locked item moved into or deleted from %v", tt.name, lk.Parent())
+			continue
+		}
+		var geom []float32
+		lk.WriteGeom(&geom)
+		for i := range geom {
+			if geom[i] != lgeom[i] {
+				t.Errorf("%s: locked item geometry changed from %v to %v", tt.name, lgeom, geom)
+				break
+			}
+		}
+		if idx, _ := lk.IndexInParent(); idx != lidx {
+			t.Errorf("%s: locked item restacked from %d to %d", tt.name, lidx, idx)
+		}
+		if r := lk.(*svg.Rect).Radius; r != (mat32.Vec2{}) {
+			t.Errorf("%s: locked item corners rounded: %v", tt.name, r)
+		}
+		if tt.changed != nil {
+			if !tt.changed(d) {
+				t.Errorf("%s: unlocked items not changed", tt.name)
+			}
+			continue
+		}
+		if bb := testNode(t, d, "rect2").AsSVGNode().WinBBox; bb == r2bb {
+			t.Errorf("%s: unlocked item not changed", tt.name)
+		}
+	}
+
+	// all locked: nothing to do, and no undo record
+	gv := openTestView(t, lockTestSVG)
+	d := &Doc{View: gv}
+	lk := testNode(t, d, "locked")
+	SetNodeLocked(lk, true)
+	gv.EditState.Select(lk)
+	gv.SelRotate(90)
+	gv.SelGroup()
+	gv.AlignLeft(AlignDrawing)
+	gv.RaiseToTop()
+	gv.SelSnapToPixels(true)
+	gv.SelRoundCorners(5)
+	if n := len(gv.EditState.UndoMgr.Recs); n != 0 {
+		t.Errorf("all locked: got %d undo records, want 0", n)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// ExportItem is one output to generate from an export manifest
+type ExportItem struct {
+
+	// id of the element (e.g., group) to export -- empty for the full page
+	Id string
+
+	// output file name -- relative paths are relative to the manifest file
+	File string
+
+	// output format: png or pdf -- if empty, determined from File extension
+	Format string
+
+	// width of output image in pixels (png only) -- 0 for physical size or to preserve aspect ratio given Height
+	Width float32
+
+	// height of output image in pixels (png only) -- 0 for physical size or to preserve aspect ratio given Width
+	Height float32
+
+	// resolution in dots per inch -- 0 for default
+	DPI float32
+}
+
+// ExportManifest is a list of outputs to generate from a drawing,
+// so they can be regenerated after edits.  It is saved as a JSON file,
+// with a list of Items, e.g.:
+//
+//	{"Items": [{"Id": "logo", "File": "logo.png", "Width": 256}]}
+type ExportManifest struct {
+
+	// outputs to generate
+	Items []*ExportItem
+}
+
+// OpenJSON opens the manifest from given JSON file
+func (em *ExportManifest) OpenJSON(fname gi.FileName) error {
+	b, err := ioutil.ReadFile(string(fname))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, em)
+}
+
+// SaveJSON saves the manifest to given JSON file
+func (em *ExportManifest) SaveJSON(fname gi.FileName) error {
+	b, err := json.MarshalIndent(em, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(string(fname), b, 0644)
+}
+
+// OutFile returns the output file of this item, with relative
+// paths relative to given directory
+func (ei *ExportItem) OutFile(dir string) string {
+	if filepath.IsAbs(ei.File) {
+		return ei.File
+	}
+	return filepath.Join(dir, ei.File)
+}
+
+// ExportFunc returns the function that exports this item: the registered
+// exporter for its format, or for png with a Width, Height or DPI, the
+// native png export at that size
+func (ei *ExportItem) ExportFunc() (ExportFunc, error) {
+	ext := ei.Format
+	if ext == "" {
+		ext = filepath.Ext(ei.File)
+	}
+	ext = ExportExt(ext)
+	ex := ExporterForExt(ext)
+	if ex == nil {
+		return nil, fmt.Errorf("no exporter registered for format: %q", ext)
+	}
+	if ext != ".png" || (ei.Width <= 0 && ei.Height <= 0 && ei.DPI <= 0) {
+		return ex.Func, nil
+	}
+	return func(sv *SVGView, sz *PhysSize, w io.Writer) error {
+		wd, ht := int(mat32.Round(ei.Width)), int(mat32.Round(ei.Height))
+		if wd <= 0 && ht <= 0 {
+			wd = int(mat32.Round(sz.SizePx().X * ei.DPI / units.PxPerInch))
+		}
+		return PNGExportFunc(wd, ht)(sv, sz, w)
+	}, nil
+}
+
+// ExportItem exports given manifest item from the current drawing, with
+// output relative to given directory.  An item with an Id exports just
+// that element, over its bounding box.
+func (gv *GridView) ExportItem(ei *ExportItem, dir string) error {
+	fn, err := ei.ExportFunc()
+	if err != nil {
+		return err
+	}
+	sv := gv.SVG()
+	onm := gi.FileName(ei.OutFile(dir))
+	if ei.Id == "" {
+		return sv.ExportToFile(onm, fn)
+	}
+	var el svg.NodeSVG
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if el != nil || k == sv.Defs.This() || NodeIsMetaData(k) {
+			return ki.Break
+		}
+		if sii, ok := k.(svg.NodeSVG); ok && k != sv.This() && k.Name() == ei.Id {
+			el = sii
+			return ki.Break
+		}
+		return ki.Continue
+	})
+	if el == nil {
+		return fmt.Errorf("element not found: %s", ei.Id)
+	}
+	restore := sv.SetExportExtent(sv.DocBBox(el), false)
+	defer restore()
+	es := sv.EditState()
+	sel := es.Selected
+	es.Selected = map[svg.NodeSVG]*SelState{el: {}}
+	sv.exportSel = true
+	defer func() {
+		es.Selected = sel
+		sv.exportSel = false
+	}()
+	return sv.ExportToFile(onm, fn)
+}
+
+// ExportManifest generates all the outputs listed in the given export
+// manifest JSON file from the current drawing, with the registered
+// exporters.  Returns the first error encountered, after attempting
+// all items.
+func (gv *GridView) ExportManifest(fname gi.FileName) error {
+	em := &ExportManifest{}
+	err := em.OpenJSON(fname)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	mdir, _ := filepath.Split(string(fname))
+	var ferr error
+	nok := 0
+	for _, ei := range em.Items {
+		if err := gv.ExportItem(ei, mdir); err != nil {
+			if ferr == nil {
+				ferr = fmt.Errorf("ExportManifest: %s: %w", ei.File, err)
+			}
+			continue
+		}
+		nok++
+	}
+	gv.SetStatus(fmt.Sprintf("Export Manifest: exported %d of %d items", nok, len(em.Items)))
+	return ferr
+}
@@ -9,6 +9,7 @@ import (
 	"image"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/oswin/key"
@@ -31,23 +32,39 @@ func (sv *SVGView) ManipStart(act, data string) {
 // ManipDone happens when a manipulation has finished: resets action, does render
 func (sv *SVGView) ManipDone() {
 	win := sv.GridView.ParentWindow()
-	InactivateSprites(win, SpAlignMatch)
+	sv.InactivateAlignSprites(win)
 	es := sv.EditState()
 	switch {
 	case es.Action == "BoxSelect":
 		bbox := image.Rectangle{Min: es.DragStartPos, Max: es.DragCurPos}
 		bbox = bbox.Canon()
 		InactivateSprites(win, SpRubberBand)
+		InactivateSprites(win, SpSelPreview)
 		win.UpdateSig()
 		sel := sv.SelectWithinBBox(bbox, false)
-		if len(sel) > 0 {
-			es.ResetSelected() // todo: extend select -- need mouse mod
+		switch es.BoxSelMode {
+		case mouse.ExtendOne:
 			for _, se := range sel {
 				es.Select(se)
 			}
+		case mouse.Unselect:
+			for _, se := range sel {
+				es.Unselect(se)
+			}
+		default:
+			if len(sel) > 0 {
+				es.ResetSelected()
+				for _, se := range sel {
+					es.Select(se)
+				}
+			}
 		}
+		es.BoxSelMode = mouse.SelectOne
 	default:
 	}
+	if win != nil {
+		InactivateSprites(win, SpDragReadout)
+	}
 	es.DragReset()
 	es.ActDone()
 	sv.UpdateView(true)
@@ -74,8 +91,7 @@ func (sv *SVGView) GridDots() (float32, mat32.Vec2) {
 	}
 	incr := grid * sv.Scale // our zoom factor
 
-	org := mat32.Vec2{}
-	org = sv.Pnt.Transform.MulVec2AsPt(org)
+	org := sv.Pnt.Transform.MulVec2AsPt(sv.GridOffset)
 
 	// fmt.Printf("org: %v\n", org)
 
@@ -90,28 +106,208 @@ func (sv *SVGView) GridDots() (float32, mat32.Vec2) {
 	return incr, org
 }
 
+// NudgeDelta returns the actual amount to move given position along one
+// dimension, for a nudge of given raw amount, with grid snapping given
+// the grid offset and increment: the position is first snapped to the
+// grid, and then moved by a whole number of grid increments (at least
+// one, in the direction of the nudge), so that nudged items end up on
+// the grid even if they started off it.  All values are in window
+// coordinates.  Returns the raw amount if incr <= 0.
+func NudgeDelta(pos, del, off, incr float32) float32 {
+	if del == 0 || incr <= 0 {
+		return del
+	}
+	snp := mat32.Round((pos-off)/incr)*incr + off
+	steps := mat32.Round(del / incr)
+	if steps == 0 {
+		steps = 1
+		if del < 0 {
+			steps = -1
+		}
+	}
+	return snp + steps*incr - pos
+}
+
+// NudgeBigSteps is the number of grid units that the selection is moved
+// by Shift+arrow keys, instead of one for the arrow keys alone
+var NudgeBigSteps = float32(10)
+
+// NudgeUndoTime is the time since the last nudge within which another
+// nudge of the same items is part of the same undo step, so a burst of
+// nudges is undone all at once
+var NudgeUndoTime = time.Second
+
+// NudgeUndoSave saves the state for undo before a nudge, unless the
+// last nudge was of the same items, within NudgeUndoTime, and nothing
+// else has been saved for undo since
+func (gv *GridView) NudgeUndoSave() {
+	es := &gv.EditState
+	sv := gv.SVG()
+	nms := es.SelectedNamesString()
+	um := &es.UndoMgr
+	last := time.Since(es.LastNudge) < NudgeUndoTime
+	es.LastNudge = time.Now()
+	if last && um.Idx >= 0 && um.Idx == len(um.Recs)-1 {
+		if rec := um.Recs[um.Idx]; rec.Action == "Nudge" && rec.Data == nms {
+			es.Changed = true
+			return
+		}
+	}
+	sv.UndoSave("Nudge", nms)
+}
+
+// NudgeSelection moves the selected items by given amounts in drawing
+// units.  If Prefs.SnapGrid is on, the selection is moved onto the grid
+// in each dimension that it moves in, in whole grid increments
+// (see NudgeDelta).  A burst of nudges is one undo step (see NudgeUndoSave).
+func (gv *GridView) NudgeSelection(dx, dy float32) {
+	es := &gv.EditState
+	if !es.HasSelected() || es.SelectedLocked() {
+		return
+	}
+	sv := gv.SVG()
+	es.UpdateSelBBox()
+	pos := es.SelBBox.Min
+	del := mat32.V2(dx, dy).MulScalar(sv.Scale)
+	if Prefs.SnapGrid {
+		grinc, groff := sv.GridDots()
+		del.X = NudgeDelta(pos.X, del.X, groff.X, grinc)
+		del.Y = NudgeDelta(pos.Y, del.Y, groff.Y, grinc)
+	}
+	if del.X == 0 && del.Y == 0 {
+		return
+	}
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	gv.NudgeUndoSave()
+	sc := mat32.V2(1, 1)
+	for sn := range es.Selected {
+		bb := mat32.Box2{}
+		bb.SetFromRect(sn.AsSVGNode().WinBBox)
+		sn.ApplyDeltaTransform(del, sc, 0, bb.Min.Sub(svoff))
+	}
+	sv.UpdateView(true)
+	gv.ChangeMade()
+}
+
+// SnapTolPx returns the snap tolerance in window pixels: Prefs.SnapTol
+// logical pixels, scaled by gi.Prefs.LogicalDPIScale as the handle sprites
+// are (see HandleSpriteSize), so snapping feels the same on all displays
+func SnapTolPx() float32 {
+	dsc := gi.Prefs.LogicalDPIScale
+	if dsc <= 0 {
+		dsc = 1
+	}
+	return float32(Prefs.SnapTol) * dsc
+}
+
 // SnapToPt snaps value to given potential snap point, in screen pixel units.
-// Tolerance is determined by preferences.  Returns true if snapped.
+// Tolerance is SnapTolPx screen pixels, independent of the zoom level,
+// so both values must be in window coordinates.  Returns true if snapped.
 func SnapToPt(val, snap float32) (float32, bool) {
 	d := mat32.Abs(val - snap)
-	if d <= float32(Prefs.SnapTol) {
+	if d <= SnapTolPx() {
 		return snap, true
 	}
 	return val, false
 }
 
 // SnapToIncr snaps value to given increment, first subtracting given offset.
-// Tolerance is determined by preferences, which is in screen pixels.
-// Returns true if snapped.
+// Tolerance is SnapTolPx screen pixels, independent of the zoom level,
+// so all values must be in window coordinates.  Returns true if snapped.
 func SnapToIncr(val, off, incr float32) (float32, bool) {
 	nint := mat32.Round((val-off)/incr)*incr + off
 	dint := mat32.Abs(val - nint)
-	if dint <= float32(Prefs.SnapTol) {
+	if dint <= SnapTolPx() {
 		return nint, true
 	}
 	return val, false
 }
 
+// SnapAngleToIncr snaps given angle in degrees to given increment in
+// degrees, for a point being rotated at given radius in window pixels
+// from the center of rotation.  The tolerance is SnapTolPx screen pixels
+// along the arc at that radius, so it is the same on screen regardless of
+// the zoom level and the size of the selection.  Returns true if snapped.
+func SnapAngleToIncr(ang, incr, rad float32) (float32, bool) {
+	nint := mat32.Round(ang/incr) * incr
+	if rad <= 0 {
+		return nint, true
+	}
+	if mat32.Abs(ang-nint) <= mat32.RadToDeg(SnapTolPx()/rad) {
+		return nint, true
+	}
+	return ang, false
+}
+
+// SnapTolDoc returns the snap tolerance (SnapTolPx, in screen pixels)
+// in drawing coordinates at the current zoom level, for comparing
+// distances in drawing coordinates
+func (sv *SVGView) SnapTolDoc() float32 {
+	if sv.Scale <= 0 {
+		return SnapTolPx()
+	}
+	return SnapTolPx() / sv.Scale
+}
+
+// SnapFeedbackFunc is called when a snap engages during dragging, if
+// Prefs.SnapSound is on.  The default plays a click with the system
+// sound player (see PlaySnapClick) -- apps built on grid can replace it,
+// e.g., to trigger haptic feedback.
+var SnapFeedbackFunc = PlaySnapClick
+
+// SnapFeedback provides non-visual feedback via SnapFeedbackFunc when
+// a snap to a guide first engages (not repeatedly while it stays snapped)
+func (sv *SVGView) SnapFeedback(snapped bool) {
+	es := sv.EditState()
+	if snapped && !es.Snapped && Prefs.SnapSound && SnapFeedbackFunc != nil {
+		go SnapFeedbackFunc()
+	}
+	es.Snapped = snapped
+}
+
+// InactivateAlignSprites inactivates the align match and snap zone
+// sprites, at the start of processing each drag event
+func (sv *SVGView) InactivateAlignSprites(win *gi.Window) {
+	if win == nil {
+		return
+	}
+	es := sv.EditState()
+	InactivateSprites(win, SpAlignMatch)
+	InactivateSprites(win, SpSnapZone)
+	InactivateSprites(win, SpNodeSnap)
+	es.NSnapZones = 0
+}
+
+// ShowSnapZone shows the snap tolerance zone around given align
+// coordinate value along given dimension, in window coordinates,
+// if Prefs.ShowSnapZones is on.  Values within the zone snap to it.
+func (sv *SVGView) ShowSnapZone(dim mat32.Dims, val float32) {
+	if !Prefs.ShowSnapZones {
+		return
+	}
+	es := sv.EditState()
+	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
+	tol := int(mat32.Round(SnapTolPx()))
+	wb := sv.WinBBox
+	var pos, sz image.Point
+	if dim == mat32.X {
+		pos = image.Point{int(val) - tol, wb.Min.Y}
+		sz = image.Point{2*tol + 1, wb.Dy()}
+	} else {
+		pos = image.Point{wb.Min.X, int(val) - tol}
+		sz = image.Point{wb.Dx(), 2*tol + 1}
+	}
+	sp := Sprite(win, SpSnapZone, SpUnk, es.NSnapZones, sz)
+	SetSpritePos(sp, pos)
+	es.NSnapZones++
+}
+
+// SnapPointToGrid snaps given point in window coordinates to the grid,
+// if Prefs.SnapGrid is on.  Each dimension snaps independently, only if
+// within the snap tolerance of a grid line.  Returns the snapped point.
 func (sv *SVGView) SnapPointToGrid(rawpt mat32.Vec2) mat32.Vec2 {
 	if !Prefs.SnapGrid {
 		return rawpt
@@ -125,6 +321,16 @@ func (sv *SVGView) SnapPointToGrid(rawpt mat32.Vec2) mat32.Vec2 {
 
 // SnapPoint does snapping on one raw point, given that point,
 // in window coordinates. returns the snapped point.
+// Snapping is applied in order, with later steps taking precedence:
+//   - grid (SnapPointToGrid), if Prefs.SnapGrid
+//   - align points of other elements and the page, if Prefs.SnapGuide:
+//     separately in X and Y, to the closest align point in each
+//     dimension, if within the snap tolerance.  The align points must have
+//     been gathered with GatherAlignPoints at the start of the manipulation.
+//   - angled construction guides and perspective rays (SnapToGuides),
+//     if Prefs.SnapGuide
+//
+// Also shows the align match and snap zone sprites, and gives snap feedback.
 func (sv *SVGView) SnapPoint(rawpt mat32.Vec2) mat32.Vec2 {
 	es := sv.EditState()
 	snpt := sv.SnapPointToGrid(rawpt)
@@ -158,6 +364,7 @@ func (sv *SVGView) SnapPoint(rawpt mat32.Vec2) mat32.Vec2 {
 			continue
 		}
 		bv := rawpt.Dim(dim)
+		sv.ShowSnapZone(dim, clVals[dim][0].Dim(dim))
 		sval, snap := SnapToPt(bv, clVals[dim][0].Dim(dim))
 		if snap {
 			snpt.SetDim(dim, sval)
@@ -177,12 +384,20 @@ func (sv *SVGView) SnapPoint(rawpt mat32.Vec2) mat32.Vec2 {
 			}
 		}
 	}
+	gpt, gsnap := sv.SnapToGuides(rawpt) // angled guides take precedence
+	if gsnap {
+		snpt = gpt
+	}
+	sv.SnapFeedback(len(alpts) > 0 || gsnap)
 	sv.ShowAlignMatches(alpts, altyps)
 	return snpt
 }
 
 // SnapBBox does snapping on given raw bbox according to preferences,
-// aligning movement of bbox edges / centers relative to other bboxes..
+// aligning movement of bbox edges / centers relative to other bboxes --
+// edges snap to the same edges, and centers to the centers and the
+// edge midpoints of others (see SnapTargets) --
+// and of the baseline of selected text relative to other text baselines.
 // returns snapped bbox.
 func (sv *SVGView) SnapBBox(rawbb mat32.Box2) mat32.Box2 {
 	if !Prefs.SnapGuide {
@@ -196,20 +411,36 @@ func (sv *SVGView) SnapBBox(rawbb mat32.Box2) mat32.Box2 {
 	var bbval [2]mat32.Vec2
 	for ap := BBLeft; ap < BBoxPointsN; ap++ {
 		bbp := ap.PointBox(rawbb)
-		pts := es.AlignPts[ap]
 		dim := ap.Dim()
-		for _, pt := range pts {
-			pv := pt.Dim(dim)
-			bv := bbp.Dim(dim)
-			dst := mat32.Abs(pv - bv)
-			if dst < clDst[dim] {
-				clDst[dim] = dst
-				clPts[dim] = []BBoxPoints{ap}
-				clVals[dim] = []mat32.Vec2{pt}
-				bbval[dim] = bbp
-			} else if mat32.Abs(dst-clDst[dim]) < 1.0e-4 {
-				clPts[dim] = append(clPts[dim], ap)
-				clVals[dim] = append(clVals[dim], pt)
+		for _, tp := range ap.SnapTargets() {
+			for _, pt := range es.AlignPts[tp] {
+				pv := pt.Dim(dim)
+				bv := bbp.Dim(dim)
+				dst := mat32.Abs(pv - bv)
+				if dst < clDst[dim] {
+					clDst[dim] = dst
+					clPts[dim] = []BBoxPoints{ap}
+					clVals[dim] = []mat32.Vec2{pt}
+					bbval[dim] = bbp
+				} else if mat32.Abs(dst-clDst[dim]) < 1.0e-4 {
+					clPts[dim] = append(clPts[dim], ap)
+					clVals[dim] = append(clVals[dim], pt)
+				}
+			}
+		}
+	}
+	if es.SelBaseline >= 0 { // text baselines, in Y only
+		bbp := mat32.V2(rawbb.Min.X, rawbb.Min.Y+es.SelBaseline)
+		for _, pt := range es.BaselinePts {
+			dst := mat32.Abs(pt.Y - bbp.Y)
+			if dst < clDst[mat32.Y] {
+				clDst[mat32.Y] = dst
+				clPts[mat32.Y] = []BBoxPoints{BBBottom}
+				clVals[mat32.Y] = []mat32.Vec2{pt}
+				bbval[mat32.Y] = bbp
+			} else if mat32.Abs(dst-clDst[mat32.Y]) < 1.0e-4 {
+				clPts[mat32.Y] = append(clPts[mat32.Y], BBBottom)
+				clVals[mat32.Y] = append(clVals[mat32.Y], pt)
 			}
 		}
 	}
@@ -220,6 +451,7 @@ func (sv *SVGView) SnapBBox(rawbb mat32.Box2) mat32.Box2 {
 			continue
 		}
 		bv := bbval[dim].Dim(dim)
+		sv.ShowSnapZone(dim, clVals[dim][0].Dim(dim))
 		sval, snap := SnapToPt(bv, clVals[dim][0].Dim(dim))
 		if snap {
 			clPts[dim][0].MoveDelta(&snapbb, sval-bv)
@@ -239,21 +471,21 @@ func (sv *SVGView) SnapBBox(rawbb mat32.Box2) mat32.Box2 {
 			}
 		}
 	}
+	gbb, gsnap := sv.SnapBBoxToGuides(rawbb) // angled guides take precedence
+	if gsnap {
+		snapbb = gbb
+	}
+	sv.SnapFeedback(len(alpts) > 0 || gsnap)
 	sv.ShowAlignMatches(alpts, altyps)
 	return snapbb
 }
 
-// ConstrainPoint constrains movement of point relative to starting point
-// to either X, Y or diagonal.  returns constrained point, and whether the
-// constraint is along the diagonal, which can then trigger reshaping the
-// object to be along the diagonal as well.
-// also adds constraint to AlignMatches.
-func (sv *SVGView) ConstrainPoint(st, rawpt mat32.Vec2) (mat32.Vec2, bool) {
-	del := rawpt.Sub(st)
-
-	var alpts []image.Rectangle
-	var altyps []BBoxPoints
-
+// ConstrainDelta constrains given movement delta to the closest of
+// horizontal, vertical, or one of the two 45 degree diagonals.
+// Returns the constrained delta, which keeps one of the dimensions of
+// del and is the closest to del of the four candidates, and whether
+// the constraint is diagonal.
+func ConstrainDelta(del mat32.Vec2) (mat32.Vec2, bool) {
 	var cpts [4]mat32.Vec2
 
 	cpts[0] = del
@@ -284,8 +516,20 @@ func (sv *SVGView) ConstrainPoint(st, rawpt mat32.Vec2) (mat32.Vec2, bool) {
 			mind = d
 		}
 	}
+	return cpts[mini], mini >= 2
+}
+
+// ConstrainPoint constrains movement of point relative to starting point
+// to either X, Y or diagonal (see ConstrainDelta).  returns constrained
+// point, and whether the constraint is along the diagonal, which can then
+// trigger reshaping the object to be along the diagonal as well.
+// also adds constraint to AlignMatches.
+func (sv *SVGView) ConstrainPoint(st, rawpt mat32.Vec2) (mat32.Vec2, bool) {
+	var alpts []image.Rectangle
+	var altyps []BBoxPoints
 
-	cp := cpts[mini].Add(st)
+	cdel, diag := ConstrainDelta(rawpt.Sub(st))
+	cp := cdel.Add(st)
 
 	rpt := image.Rectangle{}
 	rpt.Min = st.ToPoint()
@@ -294,7 +538,6 @@ func (sv *SVGView) ConstrainPoint(st, rawpt mat32.Vec2) (mat32.Vec2, bool) {
 	alpts = append(alpts, rpt)
 	altyps = append(altyps, BBRight)
 
-	diag := mini >= 2
 	if diag {
 		rpt.Max.X++ // make it horizontal
 		alpts = append(alpts, rpt)
@@ -308,8 +551,11 @@ func (sv *SVGView) ConstrainPoint(st, rawpt mat32.Vec2) (mat32.Vec2, bool) {
 // DragMove is when dragging a selection for moving
 func (sv *SVGView) DragMove(win *gi.Window, me *mouse.DragEvent) {
 	es := sv.EditState()
+	if es.SelectedLocked() {
+		return
+	}
 
-	InactivateSprites(win, SpAlignMatch)
+	sv.InactivateAlignSprites(win)
 
 	if !es.InAction() {
 		sv.ManipStart("Move", es.SelectedNamesString())
@@ -342,6 +588,7 @@ func (sv *SVGView) DragMove(win *gi.Window, me *mouse.DragEvent) {
 	}
 	sv.SetBBoxSpritePos(SpReshapeBBox, 0, es.DragSelEffBBox)
 	sv.SetSelSpritePos()
+	sv.UpdateDragReadout(win)
 	go sv.ManipUpdate()
 	win.UpdateSig()
 
@@ -373,11 +620,53 @@ func ProportionalBBox(bb, orig mat32.Box2) mat32.Box2 {
 	return bb
 }
 
-// SpriteReshapeDrag processes a mouse reshape drag event on a selection sprite
+// ProportionalReshape sets the current and effective drag bboxes for a
+// reshape drag of the corner handle at given bbox points so that the
+// selection keeps its aspect ratio: it scales uniformly about the opposite
+// corner, by the scale along the axis that changed the most.  The dragged
+// corner is then snapped, keeping the aspect ratio, so that the dimension
+// that snapped lands on the snap target.
+func (sv *SVGView) ProportionalReshape(bbX, bbY BBoxPoints) {
+	es := sv.EditState()
+	st := es.DragSelStartBBox
+	cur := es.DragSelCurBBox
+	anc, crn, ncrn := st.Max, st.Min, cur.Min // anchor, start and new corner
+	if bbX == BBRight {
+		anc.X, crn.X, ncrn.X = st.Min.X, st.Max.X, cur.Max.X
+	}
+	if bbY == BBBottom {
+		anc.Y, crn.Y, ncrn.Y = st.Min.Y, st.Max.Y, cur.Max.Y
+	}
+	osz := crn.Sub(anc)
+	if osz.X == 0 || osz.Y == 0 {
+		return
+	}
+	sc := ncrn.Sub(anc).Div(osz)
+	s := sc.X
+	if mat32.Abs(sc.Y-1) > mat32.Abs(sc.X-1) {
+		s = sc.Y
+	}
+	ncrn = anc.Add(osz.MulScalar(s))
+	snp := sv.SnapPoint(ncrn)
+	switch {
+	case snp.X != ncrn.X:
+		s = (snp.X - anc.X) / osz.X
+	case snp.Y != ncrn.Y:
+		s = (snp.Y - anc.Y) / osz.Y
+	}
+	eff := anc.Add(osz.MulScalar(s))
+	es.DragSelCurBBox = mat32.Box2{Min: anc.Min(ncrn), Max: anc.Max(ncrn)}
+	es.DragSelEffBBox = mat32.Box2{Min: anc.Min(eff), Max: anc.Max(eff)}
+}
+
+// SpriteReshapeDrag processes a mouse reshape drag event on a selection sprite.
+// Holding Shift on a corner handle keeps the aspect ratio of the selection
+// (see ProportionalReshape), and Control constrains the drag to be
+// horizontal, vertical or diagonal.
 func (sv *SVGView) SpriteReshapeDrag(sp Sprites, win *gi.Window, me *mouse.DragEvent) {
 	es := sv.EditState()
 
-	InactivateSprites(win, SpAlignMatch)
+	sv.InactivateAlignSprites(win)
 
 	if !es.InAction() {
 		sv.ManipStart("Reshape", es.SelectedNamesString())
@@ -389,8 +678,10 @@ func (sv *SVGView) SpriteReshapeDrag(sp Sprites, win *gi.Window, me *mouse.DragE
 
 	spt := mat32.NewVec2FmPoint(es.DragStartPos)
 	mpt := mat32.NewVec2FmPoint(me.Where)
+	corner := bbX != BBCenter && bbY != BBMiddle
+	prop := corner && me.HasAnyModifier(key.Shift)
 	diag := false
-	if me.HasAnyModifier(key.Control) && (bbX != BBCenter && bbY != BBMiddle) {
+	if me.HasAnyModifier(key.Control) && corner && !prop {
 		mpt, diag = sv.ConstrainPoint(spt, mpt)
 	}
 	dv := mpt.Sub(spt)
@@ -426,7 +717,9 @@ func (sv *SVGView) SpriteReshapeDrag(sp Sprites, win *gi.Window, me *mouse.DragE
 		es.DragSelEffBBox.Max.X = sv.SnapPoint(es.DragSelCurBBox.Max).X
 	}
 
-	if diag {
+	if prop {
+		sv.ProportionalReshape(bbX, bbY)
+	} else if diag {
 		sq := false
 		if len(es.Selected) == 1 {
 			so := es.SelectedList(false)[0]
@@ -464,12 +757,32 @@ func (sv *SVGView) SpriteReshapeDrag(sp Sprites, win *gi.Window, me *mouse.DragE
 
 	sv.SetBBoxSpritePos(SpReshapeBBox, 0, es.DragSelEffBBox)
 	sv.SetSelSpritePos()
+	sv.UpdateDragReadout(win)
 	go sv.ManipUpdate()
 	win.UpdateSig()
 }
 
-// SpriteRotateDrag processes a mouse rotate drag event on a selection sprite
-func (sv *SVGView) SpriteRotateDrag(sp Sprites, delta image.Point, win *gi.Window) {
+// UpdateDragReadout shows the position and size of the selection while
+// moving or reshaping it, from DragSelEffBBox, in a label just below it.
+// Values are in the physical units of the drawing (e.g., mm), converted
+// from the viewbox units when the two differ.
+func (sv *SVGView) UpdateDragReadout(win *gi.Window) {
+	es := sv.EditState()
+	bb := es.DragSelEffBBox
+	upu := sv.UnitsPerDoc()
+	pos := sv.WinToDoc(bb.Min).MulScalar(upu)
+	sz := bb.Size().DivScalar(sv.Scale).MulScalar(upu)
+	un := strings.ToLower(sv.PhysWidth.Un.String())
+	lbl := fmt.Sprintf("X: %s  Y: %s  W: %s  H: %s %s", Prefs.FmtVal(pos.X), Prefs.FmtVal(pos.Y), Prefs.FmtVal(sz.X), Prefs.FmtVal(sz.Y), un)
+	sp := Sprite(win, SpDragReadout, SpUnk, 0, image.ZP)
+	DrawSpriteLabel(sp, lbl)
+	_, hsz := HandleSpriteSize(1) // below the reshape handles
+	SetSpritePos(sp, image.Point{int(bb.Min.X), int(bb.Max.Y) + hsz.Y + 2})
+}
+
+// SpriteRotateDrag processes a mouse rotate drag event on a selection sprite.
+// If snap is set, the angle snaps to Prefs.SnapRotateIncr degrees.
+func (sv *SVGView) SpriteRotateDrag(sp Sprites, delta image.Point, snap bool, win *gi.Window) {
 	es := sv.EditState()
 	if !es.InAction() {
 		sv.ManipStart("Rotate", es.SelectedNamesString())
@@ -526,8 +839,10 @@ func (sv *SVGView) SpriteRotateDrag(sp Sprites, delta image.Point, win *gi.Windo
 		pt = ctr
 	}
 	ang := mat32.Atan2(dy, dx)
-	ang, _ = SnapToIncr(mat32.RadToDeg(ang), 0, 15)
-	ang = mat32.DegToRad(ang)
+	if snap && Prefs.SnapRotateIncr > 0 {
+		ang, _ = SnapAngleToIncr(mat32.RadToDeg(ang), Prefs.SnapRotateIncr, mat32.Sqrt(dx*dx+dy*dy))
+		ang = mat32.DegToRad(ang)
+	}
 	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
 	pt = pt.Sub(svoff)
 	del := mat32.Vec2{}
@@ -0,0 +1,261 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+// setTestSnapPrefs sets the snapping preferences for a test, with a
+// snap tolerance of 3 pixels at a logical DPI scale of 1, restoring
+// the previous values when the test is done
+func setTestSnapPrefs(t *testing.T, grid, guide bool) {
+	t.Helper()
+	sgrid, sguide, stol, sdsc := Prefs.SnapGrid, Prefs.SnapGuide, Prefs.SnapTol, gi.Prefs.LogicalDPIScale
+	t.Cleanup(func() {
+		Prefs.SnapGrid, Prefs.SnapGuide, Prefs.SnapTol, gi.Prefs.LogicalDPIScale = sgrid, sguide, stol, sdsc
+	})
+	Prefs.SnapGrid, Prefs.SnapGuide, Prefs.SnapTol, gi.Prefs.LogicalDPIScale = grid, guide, 3, 1
+}
+
+func vec2Near(a, b mat32.Vec2) bool {
+	return a.DistTo(b) < 1.0e-3
+}
+
+func TestSnapToIncr(t *testing.T) {
+	setTestSnapPrefs(t, true, false)
+	tests := []struct {
+		val, off, incr float32
+		want           float32
+		snap           bool
+	}{
+		{20, 0, 10, 20, true},
+		{22, 0, 10, 20, true},
+		{17, 0, 10, 20, true},
+		{24, 0, 10, 24, false},
+		{15, 0, 10, 15, false},
+		{27, 5, 10, 25, true},
+		{-11, 0, 10, -10, true},
+	}
+	for _, tt := range tests {
+		got, snap := SnapToIncr(tt.val, tt.off, tt.incr)
+		if got != tt.want || snap != tt.snap {
+			t.Errorf("SnapToIncr(%g, %g, %g) = %g, %v, want %g, %v", tt.val, tt.off, tt.incr, got, snap, tt.want, tt.snap)
+		}
+	}
+}
+
+func TestConstrainDelta(t *testing.T) {
+	tests := []struct {
+		del  mat32.Vec2
+		want mat32.Vec2
+		diag bool
+	}{
+		{mat32.V2(10, 1), mat32.V2(10, 0), false},
+		{mat32.V2(-10, 2), mat32.V2(-10, 0), false},
+		{mat32.V2(1, 10), mat32.V2(0, 10), false},
+		{mat32.V2(-2, -10), mat32.V2(0, -10), false},
+		{mat32.V2(10, 9), mat32.V2(10, 10), true},
+		{mat32.V2(9, 10), mat32.V2(9, 9), true},
+		{mat32.V2(10, -9), mat32.V2(10, -10), true},
+		{mat32.V2(-9, 10), mat32.V2(-9, 9), true},
+		{mat32.V2(-10, -9), mat32.V2(-10, -10), true},
+		{mat32.V2(0, 0), mat32.V2(0, 0), false},
+	}
+	for _, tt := range tests {
+		got, diag := ConstrainDelta(tt.del)
+		if !vec2Near(got, tt.want) || diag != tt.diag {
+			t.Errorf("ConstrainDelta(%v) = %v, %v, want %v, %v", tt.del, got, diag, tt.want, tt.diag)
+		}
+	}
+}
+
+func TestConstrainPoint(t *testing.T) {
+	gv := newTestGridView(t)
+	sv := gv.SVG()
+	st := mat32.V2(100, 100)
+	tests := []struct {
+		raw  mat32.Vec2
+		want mat32.Vec2
+		diag bool
+	}{
+		{mat32.V2(150, 104), mat32.V2(150, 100), false},
+		{mat32.V2(97, 40), mat32.V2(100, 40), false},
+		{mat32.V2(140, 138), mat32.V2(140, 140), true},
+		{mat32.V2(60, 142), mat32.V2(60, 140), true},
+	}
+	for _, tt := range tests {
+		got, diag := sv.ConstrainPoint(st, tt.raw)
+		if !vec2Near(got, tt.want) || diag != tt.diag {
+			t.Errorf("ConstrainPoint(%v, %v) = %v, %v, want %v, %v", st, tt.raw, got, diag, tt.want, tt.diag)
+		}
+	}
+}
+
+func TestSnapPoint(t *testing.T) {
+	gv := newTestGridView(t)
+	sv := gv.SVG()
+	es := &gv.EditState
+	grinc, groff := sv.GridDots()
+	if grinc < 16 {
+		t.Fatalf("grid increment %g pixels too small to test snapping", grinc)
+	}
+	gpt := func(nx, ny float32) mat32.Vec2 { // grid point
+		return groff.Add(mat32.V2(nx, ny).MulScalar(grinc))
+	}
+	onGrid := gpt(10, 8)
+	offGrid := gpt(10.5, 8.5)
+	alpt := gpt(12.5, 6.5)                // align points, off grid
+	nrpt := gpt(4, 4).Add(mat32.V2(2, 2)) // align point near the grid
+	es.AlignPts[BBLeft] = []mat32.Vec2{mat32.V2(alpt.X, 0)}
+	es.AlignPts[BBCenter] = []mat32.Vec2{mat32.V2(nrpt.X, 0)}
+	es.AlignPts[BBMiddle] = []mat32.Vec2{mat32.V2(0, alpt.Y)}
+
+	tests := []struct {
+		name        string
+		grid, guide bool
+		raw         mat32.Vec2
+		want        mat32.Vec2
+	}{
+		{"none", false, false, onGrid.Add(mat32.V2(2, -2)), onGrid.Add(mat32.V2(2, -2))},
+		{"grid", true, false, onGrid.Add(mat32.V2(2, -2)), onGrid},
+		{"grid x only", true, false, mat32.V2(onGrid.X+2, offGrid.Y), mat32.V2(onGrid.X, offGrid.Y)},
+		{"grid out of tol", true, false, offGrid, offGrid},
+		{"align", false, true, alpt.Add(mat32.V2(-2.5, 2)), alpt},
+		{"align x only", false, true, mat32.V2(alpt.X+1, offGrid.Y), mat32.V2(alpt.X, offGrid.Y)},
+		{"grid and align", true, true, mat32.V2(alpt.X+2, onGrid.Y-1), mat32.V2(alpt.X, onGrid.Y)},
+		{"align over grid", true, true, gpt(4, 4).Add(mat32.V2(1, 1)), mat32.V2(nrpt.X, gpt(4, 4).Y)},
+	}
+	for _, tt := range tests {
+		setTestSnapPrefs(t, tt.grid, tt.guide)
+		got := sv.SnapPoint(tt.raw)
+		if !vec2Near(got, tt.want) {
+			t.Errorf("%s: SnapPoint(%v) = %v, want %v", tt.name, tt.raw, got, tt.want)
+		}
+	}
+
+	// angled guides take precedence over the grid and align points
+	gd := &Guide{Anchor: sv.WinToDoc(onGrid), Angle: 30}
+	es.Guides = Guides{gd}
+	setTestSnapPrefs(t, true, true)
+	u := gd.Dir()
+	nrm := mat32.V2(-u.Y, u.X) // unit normal, the same in window coordinates
+	tests2 := []struct {
+		along, off float32 // window pixels along and off the guide
+		snap       bool
+	}{
+		{0, 0, true},
+		{40, 2, true},
+		{-40, -2, true},
+		{40, 10, false},
+	}
+	for _, tt := range tests2 {
+		raw := onGrid.Add(u.MulScalar(tt.along)).Add(nrm.MulScalar(tt.off))
+		got := sv.SnapPoint(raw)
+		_, dst := gd.Project(sv.WinToDoc(got))
+		if snap := dst*sv.Scale < 1.0e-3; snap != tt.snap {
+			t.Errorf("guide %g, %g: SnapPoint(%v) = %v, %g pixels from guide", tt.along, tt.off, raw, got, dst*sv.Scale)
+		}
+	}
+}
+
+func TestSnapAngleToIncr(t *testing.T) {
+	setTestSnapPrefs(t, true, true)
+	tests := []struct {
+		ang, incr, rad float32
+		want           float32
+		snap           bool
+	}{
+		{44, 15, 100, 45, true},   // 1 deg at 100 px = 1.7 px
+		{42, 15, 100, 42, false},  // 3 deg at 100 px = 5.2 px
+		{42, 15, 50, 45, true},    // 3 deg at 50 px = 2.6 px
+		{44, 15, 200, 44, false},  // 1 deg at 200 px = 3.5 px
+		{-31, 15, 100, -30, true}, // negative angles
+		{7, 15, 0, 0, true},       // no radius: always snaps
+		{52, 45, 20, 45, true},    // 7 deg at 20 px = 2.4 px
+	}
+	for _, tt := range tests {
+		got, snap := SnapAngleToIncr(tt.ang, tt.incr, tt.rad)
+		if mat32.Abs(got-tt.want) > 1.0e-4 || snap != tt.snap {
+			t.Errorf("SnapAngleToIncr(%g, %g, %g) = %g, %v, want %g, %v", tt.ang, tt.incr, tt.rad, got, snap, tt.want, tt.snap)
+		}
+	}
+}
+
+// TestSnapTolDPI tests that the snap distance is the same number of
+// logical pixels at 1x and 2x display DPI scaling
+func TestSnapTolDPI(t *testing.T) {
+	setTestSnapPrefs(t, true, true)
+	gv := newTestGridView(t)
+	sv := gv.SVG()
+	gv.EditState.Guides = Guides{&Guide{Anchor: mat32.V2(0, 100), Angle: 0}}
+	gy := sv.DocToWin(mat32.V2(0, 100)).Y
+	for _, dsc := range []float32{1, 2} {
+		gi.Prefs.LogicalDPIScale = dsc
+		if tol := SnapTolPx(); tol != 3*dsc {
+			t.Errorf("dpi scale %g: SnapTolPx = %g, want %g", dsc, tol, 3*dsc)
+		}
+		tests := []struct {
+			dist float32 // distance in logical pixels
+			snap bool
+		}{
+			{0, true},
+			{2.5, true},
+			{2.9, true},
+			{3.5, false},
+			{6, false},
+		}
+		for _, tt := range tests {
+			d := tt.dist * dsc // in window pixels
+			if _, snap := SnapToPt(100+d, 100); snap != tt.snap {
+				t.Errorf("dpi scale %g: SnapToPt at %g logical px: snap = %v, want %v", dsc, tt.dist, snap, tt.snap)
+			}
+			if _, snap := SnapToIncr(200-d, 0, 50); snap != tt.snap {
+				t.Errorf("dpi scale %g: SnapToIncr at %g logical px: snap = %v, want %v", dsc, tt.dist, snap, tt.snap)
+			}
+			if _, snap := sv.SnapToGuides(mat32.V2(300, gy+d)); snap != tt.snap {
+				t.Errorf("dpi scale %g: SnapToGuides at %g logical px: snap = %v, want %v", dsc, tt.dist, snap, tt.snap)
+			}
+		}
+	}
+	gi.Prefs.LogicalDPIScale = 0
+	if tol := SnapTolPx(); tol != 3 {
+		t.Errorf("no dpi scale: SnapTolPx = %g, want 3", tol)
+	}
+}
+
+// TestSnapFeedback tests that the snap feedback is given only when the
+// SnapSound preference is on, and only when a snap first engages
+func TestSnapFeedback(t *testing.T) {
+	sfn, ssnd := SnapFeedbackFunc, Prefs.SnapSound
+	t.Cleanup(func() { SnapFeedbackFunc, Prefs.SnapSound = sfn, ssnd })
+	clicks := make(chan bool, 10)
+	SnapFeedbackFunc = func() { clicks <- true }
+	sv := openTestView(t, lockTestSVG).SVG()
+
+	Prefs.SnapSound = false
+	sv.SnapFeedback(true)
+	sv.SnapFeedback(false)
+	Prefs.SnapSound = true
+	for _, snapped := range []bool{true, true, false, true} {
+		sv.SnapFeedback(snapped)
+	}
+	n := 0
+	for timeout := time.After(time.Second); n < 2; n++ {
+		select {
+		case <-clicks:
+		case <-timeout:
+			t.Fatalf("got %d clicks, want 2", n)
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	if extra := len(clicks); extra > 0 {
+		t.Errorf("got %d clicks, want 2", n+extra)
+	}
+}
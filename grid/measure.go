@@ -0,0 +1,55 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/mat32"
+)
+
+// Measure returns the length of the line between given points in window
+// coordinates, in the physical units of the drawing (e.g., mm), and its
+// angle in degrees counter-clockwise from horizontal, as for guides
+func (sv *SVGView) Measure(from, to image.Point) (length, angle float32) {
+	d := sv.WinToDoc(mat32.NewVec2FmPoint(to)).Sub(sv.WinToDoc(mat32.NewVec2FmPoint(from)))
+	length = d.Length() * sv.UnitsPerDoc()
+	angle = mat32.RadToDeg(mat32.Atan2(-d.Y, d.X))
+	return
+}
+
+// MeasureDrag shows the line being measured with the MeasureTool, from
+// the start of the drag to the current mouse position, with its length
+// and angle in a label at the end and in the status bar.  The drawing
+// is not changed.
+func (sv *SVGView) MeasureDrag(win *gi.Window, from, to image.Point) {
+	ln, ang := sv.Measure(from, to)
+	un := strings.ToLower(sv.PhysWidth.Un.String())
+	lbl := fmt.Sprintf("%s %s  %s°", Prefs.FmtVal(ln), un, Prefs.FmtVal(ang))
+	lsp := Sprite(win, SpMeasure, SpUnk, 0, image.ZP)
+	DrawSpriteNodeCtrlLine(lsp, mat32.NewVec2FmPoint(from), mat32.NewVec2FmPoint(to))
+	tsp := Sprite(win, SpMeasure, SpUnk, 1, image.ZP)
+	DrawSpriteLabel(tsp, lbl)
+	_, hsz := HandleSpriteSize(.5) // clear of the cursor
+	SetSpritePos(tsp, to.Add(hsz))
+	sv.GridView.SetStatus(fmt.Sprintf("length: %s %s  angle: %s°", Prefs.FmtVal(ln), un, Prefs.FmtVal(ang)))
+	win.UpdateSig()
+}
+
+// MeasureDone clears the measured line at the end of a MeasureTool drag,
+// leaving the last measurement in the status bar
+func (sv *SVGView) MeasureDone() {
+	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
+	updt := win.UpdateStart()
+	InactivateSprites(win, SpMeasure)
+	win.UpdateEnd(updt)
+	win.UpdateSig()
+}
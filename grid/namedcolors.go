@@ -0,0 +1,322 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// NamedColor is a document-level named (spot) color that can be
+// referenced by the fill or stroke of any number of elements.
+// Editing the color updates all elements that reference it.
+type NamedColor struct {
+
+	// name of the color, e.g., Brand Blue -- must be unique within drawing
+	Name string `width:"20"`
+
+	// the color value
+	Color gist.Color
+
+	// name that elements use to reference this color, which is
+	// updated to Name when it is edited (see UpdateNamedColors)
+	refName string
+}
+
+// NamedColors is the list of named colors defined for a drawing.
+// They are saved in the drawing metadata.
+type NamedColors []*NamedColor
+
+// ByName returns the named color with given name, nil if not found
+func (nc *NamedColors) ByName(nm string) *NamedColor {
+	for _, c := range *nc {
+		if c.Name == nm {
+			return c
+		}
+	}
+	return nil
+}
+
+// Add adds a new named color with given name and color, or updates
+// the color if the name already exists.
+func (nc *NamedColors) Add(nm string, clr gist.Color) *NamedColor {
+	if c := nc.ByName(nm); c != nil {
+		c.Color = clr
+		return c
+	}
+	c := &NamedColor{Name: nm, Color: clr, refName: nm}
+	*nc = append(*nc, c)
+	return c
+}
+
+// namedColorEscaper escapes the separators of the named colors metadata
+// encoding in color names, and namedColorUnescaper undoes that
+var (
+	namedColorEscaper   = strings.NewReplacer("%", "%25", ";", "%3B", ":", "%3A")
+	namedColorUnescaper = strings.NewReplacer("%3B", ";", "%3A", ":", "%25", "%")
+)
+
+// String returns the metadata encoding of the named colors:
+// name:#rrggbbaa entries separated by semicolons, with any
+// ; : or % in the names escaped as %3B %3A %25
+func (nc NamedColors) String() string {
+	var sb strings.Builder
+	for i, c := range nc {
+		if i > 0 {
+			sb.WriteString(";")
+		}
+		sb.WriteString(namedColorEscaper.Replace(c.Name) + ":" + c.Color.HexString())
+	}
+	return sb.String()
+}
+
+// FromString sets the named colors from the metadata encoding
+// generated by String
+func (nc *NamedColors) FromString(s string) {
+	*nc = make(NamedColors, 0)
+	for _, ent := range strings.Split(s, ";") {
+		ci := strings.LastIndex(ent, ":")
+		if ci <= 0 {
+			continue
+		}
+		c := &NamedColor{Name: namedColorUnescaper.Replace(strings.TrimSpace(ent[:ci]))}
+		c.refName = c.Name
+		if err := c.Color.SetString(ent[ci+1:], nil); err != nil {
+			continue
+		}
+		*nc = append(*nc, c)
+	}
+}
+
+// NamedColorProp returns the property name used on svg nodes to record
+// the named color referenced by given color prop (fill or stroke)
+func NamedColorProp(prop string) string {
+	return "grid-" + prop + "-name"
+}
+
+/////////////////////////////////////////////////////////////////////////
+//  Actions
+
+// SetNamedColorNode sets given color prop (fill or stroke) of node to reference
+// the given named color, recursing into groups.
+func (gv *GridView) SetNamedColorNode(sii svg.NodeSVG, prop string, nc *NamedColor) {
+	if gp, isgp := sii.(*svg.Group); isgp {
+		for _, kid := range gp.Kids {
+			gv.SetNamedColorNode(kid.(svg.NodeSVG), prop, nc)
+		}
+		return
+	}
+	sii.SetProp(NamedColorProp(prop), nc.Name)
+	sii.AsSVGNode().SetColorProps(prop, nc.Color.HexString())
+	gv.UpdateMarkerColors(sii)
+}
+
+// SetNamedColor sets the given color prop (fill or stroke) of the selected
+// items to reference the named color of given name.
+func (gv *GridView) SetNamedColor(prop, name string) {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		return
+	}
+	nc := es.NamedColors.ByName(name)
+	if nc == nil {
+		gv.SetStatus(fmt.Sprintf("named color: %s not found", name))
+		return
+	}
+	sv := gv.SVG()
+	sv.UndoSave("SetNamedColor", prop+" "+name)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	for itm := range es.Selected {
+		gv.SetNamedColorNode(itm, prop, nc)
+	}
+	sv.UpdateEnd(updt)
+	gv.UpdateTabs()
+	gv.ChangeMade()
+}
+
+// AddNamedColorFromSel adds a new named color using the fill color of
+// the first selected item (or the default shape fill if none selected)
+func (gv *GridView) AddNamedColorFromSel(name string) {
+	es := &gv.EditState
+	if name == "" {
+		name = fmt.Sprintf("Color%d", len(es.NamedColors)+1)
+	}
+	clr := Prefs.ShapeStyle.FillStyle.Color.Color
+	if fs := es.FirstSelectedNode(); fs != nil {
+		clr = fs.AsSVGNode().Pnt.FillStyle.Color.Color
+	}
+	es.NamedColors.Add(name, clr)
+	gv.UpdateNamedColorsView()
+	es.Changed = true
+}
+
+// NamedColorsUndoSave saves the state for undo before updating the
+// named color references, unless that is what was last saved for undo,
+// so a session of edits in the named colors table, which updates the
+// references after each edit, is one undo step
+func (gv *GridView) NamedColorsUndoSave() {
+	es := &gv.EditState
+	um := &es.UndoMgr
+	if um.Idx >= 0 && um.Idx == len(um.Recs)-1 && um.Recs[um.Idx].Action == "UpdateNamedColors" {
+		es.Changed = true
+		return
+	}
+	gv.SVG().UndoSave("UpdateNamedColors", "")
+}
+
+// Renames returns a map from the names that elements use to reference
+// the named colors to their current names, for those that have been
+// renamed since the references were last updated
+func (nc NamedColors) Renames() map[string]string {
+	rnm := map[string]string{}
+	for _, c := range nc {
+		if c.refName != "" && c.refName != c.Name {
+			rnm[c.refName] = c.Name
+		}
+	}
+	return rnm
+}
+
+// UpdateNamedColors updates the colors of all elements that reference
+// a named color, to reflect the current named color definitions,
+// including the references of colors that have been renamed.
+// Returns the number of elements updated.  Edits until any other
+// action are one undo step (see NamedColorsUndoSave).
+func (gv *GridView) UpdateNamedColors() int {
+	es := &gv.EditState
+	sv := gv.SVG()
+	gv.NamedColorsUndoSave()
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	rnm := es.NamedColors.Renames()
+	for _, c := range es.NamedColors {
+		c.refName = c.Name
+	}
+	n := 0
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if k.This() == sv.This() {
+			return ki.Continue
+		}
+		if k.This() == sv.Defs.This() || NodeIsMetaData(k) {
+			return ki.Break
+		}
+		sii, issv := k.(svg.NodeSVG)
+		if !issv {
+			return ki.Break
+		}
+		for _, prop := range []string{"fill", "stroke"} {
+			nm := kit.ToString(sii.Prop(NamedColorProp(prop)))
+			if nm == "" {
+				continue
+			}
+			if nnm, ok := rnm[nm]; ok {
+				nm = nnm
+				sii.SetProp(NamedColorProp(prop), nm)
+			}
+			nc := es.NamedColors.ByName(nm)
+			if nc == nil {
+				continue
+			}
+			sii.AsSVGNode().SetColorProps(prop, nc.Color.HexString())
+			gv.UpdateMarkerColors(sii)
+			n++
+		}
+		return ki.Continue
+	})
+	sv.UpdateEnd(updt)
+	gv.ChangeMade()
+	return n
+}
+
+///////////////////////////////////////////////////////////////
+//  ColorsView
+
+// ColorsView provides management of the document-level named colors
+type ColorsView struct {
+	gi.Layout
+
+	// the parent gridview
+	GridView *GridView `copy:"-" json:"-" xml:"-" view:"-"`
+}
+
+var KiT_ColorsView = kit.Types.AddType(&ColorsView{}, ColorsViewProps)
+
+// Config configures the view
+func (cv *ColorsView) Config(gv *GridView) {
+	if cv.HasChildren() {
+		return
+	}
+	updt := cv.UpdateStart()
+	cv.GridView = gv
+	cv.Lay = gi.LayoutVert
+	cv.SetProp("spacing", gi.StdDialogVSpaceUnits)
+
+	tb := gi.AddNewToolbar(cv, "colors-tb")
+	tb.AddAction(gi.ActOpts{Label: "Add", Icon: "plus", Tooltip: "add a new named color, using the fill color of the first selected item"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			cv.GridView.AddNamedColorFromSel("")
+		})
+	tb.AddAction(gi.ActOpts{Label: "Fill", Icon: "paint", Tooltip: "set fill of selected items to reference the selected named color"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			cv.ApplySelColor("fill")
+		})
+	tb.AddAction(gi.ActOpts{Label: "Stroke", Icon: "paint", Tooltip: "set stroke of selected items to reference the selected named color"},
+		cv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			cv.ApplySelColor("stroke")
+		})
+
+	tv := giv.AddNewTableView(cv, "colors")
+	tv.SetStretchMax()
+	tv.SetSlice(&gv.EditState.NamedColors)
+	tv.ViewSig.Connect(cv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		n := cv.GridView.UpdateNamedColors()
+		cv.GridView.SetStatus(fmt.Sprintf("updated %d named color references", n))
+	})
+
+	cv.UpdateEnd(updt)
+}
+
+// TableView returns the tableview of named colors
+func (cv *ColorsView) TableView() *giv.TableView {
+	return cv.ChildByName("colors", 1).(*giv.TableView)
+}
+
+// ApplySelColor applies the currently selected named color to the given
+// prop of the selected items
+func (cv *ColorsView) ApplySelColor(prop string) {
+	es := &cv.GridView.EditState
+	tv := cv.TableView()
+	idx := tv.SelectedIdx
+	if idx < 0 || idx >= len(es.NamedColors) {
+		cv.GridView.SetStatus("select a named color first")
+		return
+	}
+	cv.GridView.SetNamedColor(prop, es.NamedColors[idx].Name)
+}
+
+// UpdateNamedColorsView updates the named colors tab
+func (gv *GridView) UpdateNamedColorsView() {
+	cv, ok := gv.Tab("Colors").(*ColorsView)
+	if !ok {
+		return
+	}
+	cv.TableView().SetSlice(&gv.EditState.NamedColors)
+}
+
+var ColorsViewProps = ki.Props{
+	"EnumType:Flag":    gi.KiT_VpFlags,
+	"background-color": &gi.Prefs.Colors.Background,
+	"color":            &gi.Prefs.Colors.Font,
+	"max-width":        -1,
+	"max-height":       -1,
+}
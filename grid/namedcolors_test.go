@@ -0,0 +1,74 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"testing"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/ki/kit"
+)
+
+func TestNamedColorsString(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  string
+	}{
+		{"Brand Blue", "Brand Blue:#0000FFFF"},
+		{"a;b", "a%3Bb:#0000FFFF"},
+		{"ratio 1:2", "ratio 1%3A2:#0000FFFF"},
+		{"100%", "100%25:#0000FFFF"},
+		{"%3B", "%253B:#0000FFFF"},
+	}
+	blue := gist.Color{B: 255, A: 255}
+	for _, tt := range tests {
+		nc := NamedColors{{Name: tt.name, Color: blue}}
+		enc := nc.String()
+		if enc != tt.enc {
+			t.Errorf("%q: encoded as %q, want %q", tt.name, enc, tt.enc)
+		}
+		var dc NamedColors
+		dc.FromString(enc)
+		if len(dc) != 1 || dc[0].Name != tt.name || dc[0].Color != blue {
+			t.Errorf("%q: round trip through %q gives %v", tt.name, enc, dc)
+		}
+	}
+}
+
+func TestNamedColorsFromString(t *testing.T) {
+	var nc NamedColors
+	nc.FromString("Red:#FF0000FF;bad;a%3Bb:#00FF00FF;Blue:notacolor")
+	if len(nc) != 2 {
+		t.Fatalf("got %d colors, want 2: %v", len(nc), nc)
+	}
+	if nc[0].Name != "Red" || nc[1].Name != "a;b" {
+		t.Errorf("got names %q, %q, want Red, a;b", nc[0].Name, nc[1].Name)
+	}
+}
+
+func TestNamedColorsRename(t *testing.T) {
+	gv := openTestView(t, headlessTestSVG)
+	sv := gv.SVG()
+	es := &gv.EditState
+	red := gist.Color{R: 255, A: 255}
+	nc := es.NamedColors.Add("Brand", red)
+	gv.SetNamedColorNode(viewNode(t, sv, "rect1"), "fill", nc)
+	gv.SetNamedColorNode(viewNode(t, sv, "path1"), "stroke", nc)
+
+	nc.Name = "Brand Red" // as edited in the colors table
+	nc.Color = gist.Color{G: 255, A: 255}
+	if n := gv.UpdateNamedColors(); n != 2 {
+		t.Errorf("updated %d references, want 2", n)
+	}
+	for _, tt := range []struct{ id, prop string }{{"rect1", "fill"}, {"path1", "stroke"}} {
+		sn := viewNode(t, sv, tt.id)
+		if nm := kit.ToString(sn.Prop(NamedColorProp(tt.prop))); nm != "Brand Red" {
+			t.Errorf("%s: %s references %q, want Brand Red", tt.id, tt.prop, nm)
+		}
+	}
+	if n := gv.UpdateNamedColors(); n != 2 {
+		t.Errorf("after rename: updated %d references, want 2", n)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/kit"
+	"github.com/goki/mat32"
+)
+
+// NodeTypes are the types of path nodes, for how the control handles
+// of the curves on either side of the node are related
+type NodeTypes int
+
+const (
+	// NodeCorner has independent handles, so the path can turn sharply
+	NodeCorner NodeTypes = iota
+
+	// NodeSmooth has collinear handles, so the path is smooth through the node
+	NodeSmooth
+
+	// NodeSymmetric has collinear handles of equal length
+	NodeSymmetric
+
+	NodeTypesN
+)
+
+//go:generate stringer -type=NodeTypes
+
+var KiT_NodeTypes = kit.Enums.AddEnum(NodeTypesN, kit.NotBitFlag, nil)
+
+func (ev NodeTypes) MarshalJSON() ([]byte, error)  { return kit.EnumMarshalJSON(ev) }
+func (ev *NodeTypes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev, b) }
+
+// PathSegIsCubic returns true if given segment is a cubic bezier (C, S)
+func PathSegIsCubic(ps *PathSeg) bool {
+	return ps.Cmd&^1 == svg.PcC || ps.Cmd&^1 == svg.PcS
+}
+
+// PathNodeSegs returns the indexes in given segments of the segments
+// coming into and going out of the node of the segment at index si,
+// -1 if none.  At the start of a closed subpath whose last segment ends
+// back at the start, that segment comes in, and the first one goes out.
+func PathNodeSegs(segs []*PathSeg, si int) (in, out int) {
+	in, out = -1, -1
+	st := si
+	for st > 0 && !segs[st].IsMove() {
+		st--
+	}
+	ed := si
+	for ed+1 < len(segs) && !segs[ed+1].IsMove() {
+		ed++
+	}
+	ps := segs[si]
+	if !ps.IsMove() {
+		in = si
+	}
+	if si < ed && !segs[si+1].IsClose() {
+		out = si + 1
+	}
+	ci := -1
+	for i := st + 1; i <= ed; i++ {
+		if segs[i].IsClose() {
+			ci = i
+			break
+		}
+	}
+	if ci < st+2 || segs[ci-1].Pt != segs[st].Pt { // no wrap around
+		return
+	}
+	switch si {
+	case st:
+		in = ci - 1
+	case ci - 1:
+		out = st + 1
+	}
+	return
+}
+
+// SetPathNodeType updates the control handles on either side of the node
+// of the segment at index si in given segments (with their start points
+// in sts, see PathSegStarts) for given node type, and returns true if
+// anything changed.  Smooth and symmetric nodes with a curve on only one
+// side align its handle with the segment on the other side.  The node
+// itself stays in place.  A symmetric node followed by a cubic curve makes
+// that an S command, which keeps the handles symmetric when one is moved,
+// and a corner node makes it a C command, which keeps them independent.
+func SetPathNodeType(segs []*PathSeg, sts []mat32.Vec2, si int, nt NodeTypes) bool {
+	in, out := PathNodeSegs(segs, si)
+	if in < 0 || out < 0 {
+		return false
+	}
+	iseg, oseg := segs[in], segs[out]
+	icub, ocub := PathSegIsCubic(iseg), PathSegIsCubic(oseg)
+	if !icub && !ocub {
+		return false
+	}
+	orel := oseg.Cmd & 1
+	if nt == NodeCorner {
+		if oseg.Cmd&^1 == svg.PcS {
+			oseg.Cmd = svg.PcC | orel
+			return true
+		}
+		return false
+	}
+	p := segs[si].Pt
+	a := sts[in].Sub(p) // along the segment, if it has no handle
+	switch {
+	case icub:
+		a = iseg.Ctrls[1].Sub(p)
+	case len(iseg.Ctrls) == 1: // Q, T
+		a = iseg.Ctrls[0].Sub(p)
+	}
+	b := oseg.Pt.Sub(p)
+	if len(oseg.Ctrls) > 0 {
+		b = oseg.Ctrls[0].Sub(p)
+	}
+	la, lb := a.Length(), b.Length()
+	var d mat32.Vec2 // outgoing direction
+	switch {
+	case !icub: // align with the incoming segment
+		if la == 0 {
+			return false
+		}
+		d = a.DivScalar(-la)
+	case !ocub:
+		if lb == 0 {
+			return false
+		}
+		d = b.DivScalar(lb)
+	case la == 0 && lb == 0:
+		return false
+	case la == 0:
+		d = b.DivScalar(lb)
+	case lb == 0:
+		d = a.DivScalar(-la)
+	default:
+		d = b.DivScalar(lb).Sub(a.DivScalar(la))
+		if d.Length() < 1.0e-6 { // handles on the same side: a cusp
+			d = b
+		}
+		d = d.Normal()
+	}
+	if nt == NodeSymmetric && icub && ocub {
+		la = 0.5 * (la + lb)
+		lb = la
+	}
+	if icub {
+		iseg.Ctrls[1] = p.Sub(d.MulScalar(la))
+	}
+	if ocub {
+		oseg.Ctrls[0] = p.Add(d.MulScalar(lb))
+		if nt == NodeSymmetric && icub && out == in+1 {
+			oseg.Cmd = svg.PcS | orel
+		}
+	}
+	return true
+}
+
+// SetNodeType sets the selected nodes of the active path to given type,
+// as one undoable action, by adjusting the control handles on either side
+func (gv *GridView) SetNodeType(nt NodeTypes) {
+	es := &gv.EditState
+	path := es.ActivePath
+	tnm := strings.ToLower(strings.TrimPrefix(nt.String(), "Node"))
+	if path == nil || len(es.PathSel) == 0 {
+		gv.SetStatus("Node Type: select the nodes to make " + tnm)
+		return
+	}
+	segs := PathSegs(path.Data)
+	sts := PathSegStarts(segs)
+	nchg := 0
+	for idx := range es.PathSel {
+		si := PathSegNodeIdx(segs, idx)
+		if si >= 0 && SetPathNodeType(segs, sts, si, nt) {
+			nchg++
+		}
+	}
+	if nchg == 0 {
+		gv.SetStatus(fmt.Sprintf("Node Type: none of the selected nodes are between curves with handles to make %s", tnm))
+		return
+	}
+	sv := gv.SVG()
+	sv.UndoSave(nt.String(), path.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	path.Data = PathSegsData(segs)
+	sv.UpdateEnd(updt)
+	sv.UpdateNodeSprites()
+	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("Node Type: made %d nodes %s", nchg, tnm))
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"image"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// UpdateOverlaySprites updates the debug overlay showing the bounding box
+// and id label of every element in the drawing, if Prefs.ShowBBoxes is on.
+// Uses the same sprites as the selection bounding boxes.
+func (sv *SVGView) UpdateOverlaySprites() {
+	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
+	InactivateSprites(win, SpOverlayBBox)
+	InactivateSprites(win, SpOverlayLabel)
+	if !Prefs.ShowBBoxes {
+		return
+	}
+	idx := 0
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if k.This() == sv.This() {
+			return ki.Continue
+		}
+		if k.This() == sv.Defs.This() || NodeIsMetaData(k) {
+			return ki.Break
+		}
+		sii, issv := k.(svg.NodeSVG)
+		if !issv {
+			return ki.Break
+		}
+		if NodeIsLayer(k) {
+			return ki.Continue
+		}
+		sn := sii.AsSVGNode()
+		if sn.WinBBox.Size() == image.ZP {
+			return ki.Continue
+		}
+		bb := mat32.Box2{}
+		bb.SetFromRect(sn.WinBBox)
+		sv.SetBBoxSpritePos(SpOverlayBBox, idx, bb)
+		lsp := Sprite(win, SpOverlayLabel, SpUnk, idx, image.ZP)
+		DrawSpriteLabel(lsp, sn.Name())
+		lsp.SetBottomPos(sn.WinBBox.Min)
+		win.ActivateSprite(lsp.Name)
+		idx++
+		return ki.Continue
+	})
+}
+
+// ToggleBBoxes toggles the debug overlay of element bounding boxes and ids
+func (gv *GridView) ToggleBBoxes() {
+	Prefs.ShowBBoxes = !Prefs.ShowBBoxes
+	sv := gv.SVG()
+	win := gv.ParentWindow()
+	updt := win.UpdateStart()
+	sv.UpdateOverlaySprites()
+	win.UpdateEnd(updt)
+	win.UpdateSig()
+	if Prefs.ShowBBoxes {
+		gv.SetStatus("Showing element bounding boxes and ids")
+	} else {
+		gv.SetStatus("")
+	}
+}
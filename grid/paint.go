@@ -767,6 +767,12 @@ func (pv *PaintView) Config(gv *GridView) {
 		pv.GridView.UpdateGradients()
 	})
 
+	gi.AddNewSeparator(pv, "presets-sep", true)
+	gi.AddNewLabel(pv, "presets-lab", "<b>Style Presets:</b>")
+	ptb := gi.AddNewToolbar(pv, "presets-tb")
+	ptb.SetStretchMaxWidth()
+	pv.ConfigStylePresets()
+
 	gi.AddNewStretch(pv, "endstr")
 
 	pv.UpdateEnd(updt)
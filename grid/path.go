@@ -7,6 +7,7 @@ package grid
 import (
 	"fmt"
 	"image"
+	"sort"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -42,19 +43,31 @@ func (gv *GridView) ConfigNodeToolbar() {
 		}
 	})
 
+	rel := gi.AddNewCheckBox(tb, "node-rel")
+	rel.SetText("Relative")
+	rel.Tooltip = "relative mode: X, Y values move the node by that amount from its current position, instead of to that absolute position"
+	rel.SetChecked(gv.EditState.NodeRel)
+	rel.ButtonSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		if sig == int64(gi.ButtonToggled) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetNodeRel(rel.IsChecked())
+		}
+	})
+
 	gi.NewSeparator(tb, "sep-snap")
 
-	// tb.AddAction(gi.ActOpts{Icon: "sel-group", Tooltip: "Ctrl+G: Group items together", UpdateFunc: gv.NodeEnableFunc},
-	// 	gv.This(), func(recv, send ki.Ki, sig int64, data interface{}) {
-	// 		grr := recv.Embed(KiT_GridView).(*GridView)
-	// 		grr.SelGroup()
-	// 	})
-	//
-	// gi.NewSeparator(tb, "sep-group")
+	tb.AddAction(gi.ActOpts{Icon: "sel-group", Tooltip: "Ctrl+G: Group items together", UpdateFunc: gv.SelectedEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SelGroup()
+		})
+
+	gi.NewSeparator(tb, "sep-group")
 
 	gi.AddNewLabel(tb, "posx-lab", "X: ").SetProp("vertical-align", gist.AlignMiddle)
 	px := gi.AddNewSpinBox(tb, "posx")
 	px.SetProp("step", 1)
+	Prefs.SetDisplayPrec(px)
 	px.SetValue(0)
 	px.Tooltip = "horizontal coordinate of node, in document units"
 	px.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
@@ -65,6 +78,7 @@ func (gv *GridView) ConfigNodeToolbar() {
 	gi.AddNewLabel(tb, "posy-lab", "Y: ").SetProp("vertical-align", gist.AlignMiddle)
 	py := gi.AddNewSpinBox(tb, "posy")
 	py.SetProp("step", 1)
+	Prefs.SetDisplayPrec(py)
 	py.SetValue(0)
 	py.Tooltip = "vertical coordinate of node, in document units"
 	py.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
@@ -72,12 +86,89 @@ func (gv *GridView) ConfigNodeToolbar() {
 		grr.NodeSetYPos(py.Value)
 	})
 
+	gi.NewSeparator(tb, "sep-round")
+
+	gi.AddNewLabel(tb, "round-lab", "Round: ").SetProp("vertical-align", gist.AlignMiddle)
+	rd := gi.AddNewSpinBox(tb, "round")
+	rd.SetProp("step", 1)
+	rd.SetMinMax(true, 0, false, 0)
+	Prefs.SetDisplayPrec(rd)
+	rd.SetValue(0)
+	rd.Tooltip = "round the corners of selected paths and rects with given radius, in document units -- adjusts live as the value changes"
+	rd.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		grr := recv.Embed(KiT_GridView).(*GridView)
+		grr.SelRoundCorners(rd.Value)
+	})
+
+	gi.AddNewLabel(tb, "chamfer-lab", "Chamfer: ").SetProp("vertical-align", gist.AlignMiddle)
+	ch := gi.AddNewSpinBox(tb, "chamfer")
+	ch.SetProp("step", 1)
+	ch.SetMinMax(true, 0, false, 0)
+	Prefs.SetDisplayPrec(ch)
+	ch.SetValue(0)
+	ch.Tooltip = "bevel the selected corner nodes (all corners if none selected) of selected paths by given size, in document units -- adjusts live as the value changes"
+	ch.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		grr := recv.Embed(KiT_GridView).(*GridView)
+		grr.SelChamferCorners(ch.Value)
+	})
+
+	gi.NewSeparator(tb, "sep-del")
+
+	tb.AddAction(gi.ActOpts{Name: "add-node", Icon: "plus", Tooltip: "add a node in the middle of the segment following each selected node of the path -- also double-click on a segment to add a node there", UpdateFunc: gv.NodeEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.AddSelNodes()
+		})
+
+	tb.AddAction(gi.ActOpts{Name: "delete-node", Icon: "minus", Tooltip: "Delete: delete the selected nodes of the path", UpdateFunc: gv.NodeEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.DeleteSelNodes()
+		})
+
+	tb.AddAction(gi.ActOpts{Name: "join-paths", Icon: "node-join", Tooltip: "join two paths into one at their end nodes: select an end node of one path, then click on the other path and select its end node -- ends at the same place are merged, otherwise joined with a line", UpdateFunc: gv.JoinEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.JoinPaths()
+		})
+
+	gi.NewSeparator(tb, "sep-node-type")
+
+	tb.AddAction(gi.ActOpts{Name: "node-corner", Icon: "node-corner", Tooltip: "make the selected nodes corners: the control handles on either side move independently", UpdateFunc: gv.NodeEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetNodeType(NodeCorner)
+		})
+
+	tb.AddAction(gi.ActOpts{Name: "node-smooth", Icon: "node-smooth", Tooltip: "make the selected nodes smooth: the control handles on either side are aligned in a straight line through the node", UpdateFunc: gv.NodeEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetNodeType(NodeSmooth)
+		})
+
+	tb.AddAction(gi.ActOpts{Name: "node-symmetric", Icon: "node-symmetric", Tooltip: "make the selected nodes symmetric: the control handles on either side are aligned, and of equal length", UpdateFunc: gv.NodeEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetNodeType(NodeSymmetric)
+		})
+
+	gi.NewSeparator(tb, "sep-closed")
+
+	cl := gi.AddNewCheckBox(tb, "path-closed")
+	cl.SetText("Closed")
+	cl.Tooltip = "whether the current subpath of the path (with the current or selected node) is closed back to its start -- toggle to close or open it"
+	cl.ButtonSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		if sig == int64(gi.ButtonToggled) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetPathClosed(cl.IsChecked())
+		}
+	})
 }
 
 // NodeEnableFunc is an ActionUpdateFunc that inactivates action if no node selected
 func (gv *GridView) NodeEnableFunc(act *gi.Button) {
-	// es := &gv.EditState
-	// act.SetInactiveState(!es.HasNodeed())
+	es := &gv.EditState
+	act.SetInactiveState(es.ActivePath == nil || len(es.PathSel) == 0)
 }
 
 // UpdateNodeToolbar updates the node toolbar based on current nodeion
@@ -88,10 +179,63 @@ func (gv *GridView) UpdateNodeToolbar() {
 	if es.Tool != NodeTool {
 		return
 	}
+	if cl, ok := tb.ChildByName("path-closed", 20).(*gi.CheckBox); ok {
+		closed, has := es.CurSubPathClosed()
+		cl.SetChecked(closed)
+		cl.SetInactiveState(!has)
+	}
+	if es.PathOp == "RoundCorners" {
+		tb.ChildByName("round", 13).(*gi.SpinBox).SetValue(es.RoundRadius)
+	}
+	pxl := tb.ChildByName("posx-lab", 7).(*gi.Label)
 	px := tb.ChildByName("posx", 8).(*gi.SpinBox)
-	px.SetValue(es.DragSelCurBBox.Min.X)
-	py := tb.ChildByName("posy", 9).(*gi.SpinBox)
-	py.SetValue(es.DragSelCurBBox.Min.Y)
+	pyl := tb.ChildByName("posy-lab", 9).(*gi.Label)
+	py := tb.ChildByName("posy", 10).(*gi.SpinBox)
+	Prefs.SetDisplayPrec(px)
+	Prefs.SetDisplayPrec(py)
+	if es.NodeRel {
+		pxl.SetText("dX: ")
+		pyl.SetText("dY: ")
+		px.SetValue(0)
+		py.SetValue(0)
+		return
+	}
+	pxl.SetText("X: ")
+	pyl.SetText("Y: ")
+	var pt mat32.Vec2
+	if pn := es.CurPathNode(); pn != nil {
+		pt = gv.SVG().WinToDoc(pn.WinPt)
+	}
+	px.SetValue(pt.X)
+	py.SetValue(pt.Y)
+}
+
+// SetNodeRel sets the relative vs. absolute coordinate mode for the
+// node toolbar X, Y fields, and reports the active mode in the status bar
+func (gv *GridView) SetNodeRel(rel bool) {
+	es := &gv.EditState
+	es.NodeRel = rel
+	gv.UpdateNodeToolbar()
+	if rel {
+		gv.SetStatus("Node X, Y: relative mode -- values move the node by that amount")
+	} else {
+		gv.SetStatus("Node X, Y: absolute mode -- values move the node to that position")
+	}
+}
+
+// NodePosDelta returns the amount to move the current node along
+// given dimension, for given node toolbar value, taking into
+// account the relative vs. absolute mode -- in document units
+func (gv *GridView) NodePosDelta(val float32, dim mat32.Dims) float32 {
+	es := &gv.EditState
+	if es.NodeRel {
+		return val
+	}
+	pn := es.CurPathNode()
+	if pn == nil {
+		return 0
+	}
+	return val - gv.SVG().WinToDoc(pn.WinPt).Dim(dim)
 }
 
 ///////////////////////////////////////////////////////////////////////
@@ -99,26 +243,387 @@ func (gv *GridView) UpdateNodeToolbar() {
 
 func (gv *GridView) NodeSetXPos(xp float32) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelected() || es.CurPathNode() == nil {
 		return
 	}
 	sv := gv.SVG()
 	sv.UndoSave("NodeToX", fmt.Sprintf("%g", xp))
-	// todo
+	dx := gv.NodePosDelta(xp, mat32.X)
+	gv.NodeMoveCur(mat32.V2(dx, 0))
 	gv.ChangeMade()
 }
 
 func (gv *GridView) NodeSetYPos(yp float32) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelected() || es.CurPathNode() == nil {
 		return
 	}
 	sv := gv.SVG()
 	sv.UndoSave("NodeToY", fmt.Sprintf("%g", yp))
-	// todo
+	dy := gv.NodePosDelta(yp, mat32.Y)
+	gv.NodeMoveCur(mat32.V2(0, dy))
 	gv.ChangeMade()
 }
 
+// NodeMoveCur moves the current node of the active path by given
+// amount in document units, leaving the other nodes in place
+func (gv *GridView) NodeMoveCur(dv mat32.Vec2) {
+	es := &gv.EditState
+	if dv == (mat32.Vec2{}) {
+		return
+	}
+	sv := gv.SVG()
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	sv.PathNodeSetOnePoint(es.ActivePath, es.PathNodes, es.CurNode, dv.MulScalar(sv.Scale), svoff)
+	sv.UpdateEnd(updt)
+	sv.UpdateNodeSprites()
+}
+
+// DeleteSelNodes deletes the selected nodes of the active path,
+// as one undoable action
+func (gv *GridView) DeleteSelNodes() {
+	es := &gv.EditState
+	if es.ActivePath == nil || len(es.PathSel) == 0 {
+		return
+	}
+	if len(es.PathSel) >= len(es.PathNodes) {
+		gv.SetStatus("Delete Node: cannot delete all the nodes of a path -- delete the path with the select tool")
+		return
+	}
+	idxs := make([]int, 0, len(es.PathSel))
+	for idx := range es.PathSel {
+		idxs = append(idxs, idx)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(idxs))) // later indexes are not affected
+	sv := gv.SVG()
+	sv.UndoSave("DeleteNode", es.ActivePath.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	ndel := 0
+	for _, idx := range idxs {
+		if sv.DeleteNode(idx) {
+			ndel++
+		}
+	}
+	sv.UpdateEnd(updt)
+	es.PathSel = nil
+	sv.UpdateNodeSprites()
+	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("Deleted %d nodes", ndel))
+}
+
+// DeleteNode deletes the node at given index of the active path,
+// splicing out its segment, and rebuilds the node sprites.  The other
+// nodes stay in place.  Deleting the moveto that starts a subpath
+// promotes the next node to the moveto, and a subpath that has no other
+// nodes is removed.  Returns false if the node was not deleted, e.g.,
+// if it is the only node of the path.
+func (sv *SVGView) DeleteNode(idx int) bool {
+	es := sv.EditState()
+	path := es.ActivePath
+	if path == nil || idx < 0 || idx >= len(es.PathNodes) || len(es.PathNodes) < 2 {
+		return false
+	}
+	segs := PathSegs(path.Data)
+	si := PathSegNodeIdx(segs, idx)
+	if si < 0 {
+		return false
+	}
+	ei := si + 1 // end of segments to remove
+	if ps := segs[si]; ps.IsMove() && ei < len(segs) {
+		switch nx := segs[ei]; {
+		case nx.IsClose():
+			ei++
+		case !nx.IsMove():
+			nx.Cmd = svg.PcM | ps.Cmd&1
+			nx.Ctrls = nil
+		}
+	}
+	segs = append(segs[:si], segs[ei:]...)
+	path.Data = PathSegsData(segs)
+	es.CurNode = -1
+	sv.UpdateNodeSprites()
+	return true
+}
+
+// AddSelNodes inserts a new node in the middle of the segment following
+// each of the selected nodes of the active path, as one undoable action
+func (gv *GridView) AddSelNodes() {
+	es := &gv.EditState
+	path := es.ActivePath
+	if path == nil || len(es.PathSel) == 0 {
+		return
+	}
+	idxs := make([]int, 0, len(es.PathSel))
+	for idx := range es.PathSel {
+		idxs = append(idxs, idx)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(idxs))) // earlier indexes are not affected
+	sv := gv.SVG()
+	sv.UndoSave("AddNode", path.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	nadd := 0
+	for _, idx := range idxs {
+		segs := PathSegs(path.Data)
+		si := PathSegNodeIdx(segs, idx) + 1
+		if si == 0 || si >= len(segs) || segs[si].IsMove() { // end of an open subpath
+			continue
+		}
+		if sv.InsertNodeAt(path, si, 0.5) {
+			nadd++
+		}
+	}
+	sv.UpdateEnd(updt)
+	es.PathSel = nil
+	sv.UpdateNodeSprites()
+	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("Added %d nodes", nadd))
+}
+
+// InsertNode inserts a new node into the active path at parameter t
+// along the segment at given index in PathSegs, as an undoable action
+func (gv *GridView) InsertNode(segIdx int, t float32) {
+	es := &gv.EditState
+	path := es.ActivePath
+	if path == nil {
+		return
+	}
+	sv := gv.SVG()
+	sv.UndoSave("AddNode", path.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	ok := sv.InsertNodeAt(path, segIdx, t)
+	sv.UpdateEnd(updt)
+	if !ok {
+		return
+	}
+	gv.ChangeMade()
+	gv.SetStatus("Added a node")
+}
+
+// InsertNodeAt inserts a new node into given path at parameter t (0..1)
+// along the segment at given index in PathSegs (the segment ending at a
+// node, or a closepath), splitting the segment so that the shape of the
+// path is unchanged, and rebuilds the node sprites if it is the active
+// path.  Returns false if there is no such segment.
+func (sv *SVGView) InsertNodeAt(path *svg.Path, segIdx int, t float32) bool {
+	segs := PathSegs(path.Data)
+	if segIdx < 0 || segIdx >= len(segs) || segs[segIdx].IsMove() {
+		return false
+	}
+	p0 := PathSegStarts(segs)[segIdx]
+	fs, ss := segs[segIdx].Split(p0, t)
+	segs = append(segs[:segIdx], append([]*PathSeg{fs, ss}, segs[segIdx+1:]...)...)
+	path.Data = PathSegsData(segs)
+	if es := sv.EditState(); path == es.ActivePath {
+		es.CurNode = -1
+		sv.UpdateNodeSprites()
+	}
+	return true
+}
+
+// JoinPaths joins two paths at an end node of each: the node selected in
+// the previously active path (see SetJoinNode), and the node selected in
+// the active path, as one undoable action.  The subpath of the second
+// path is appended to that of the first, reversing them as needed so the
+// selected nodes meet, with the second path translated into the
+// coordinates of the first, and the rest of its subpaths are added after.
+// If the two nodes are at the same place (within a pixel on screen), they
+// are merged into one, and otherwise they are joined with a straight line
+// segment.  The second path is then deleted.
+func (gv *GridView) JoinPaths() {
+	es := &gv.EditState
+	apath := es.JoinPath
+	bpath := es.ActivePath
+	if apath == nil || bpath == nil || apath == bpath || apath.Parent() == nil || len(es.PathSel) != 1 {
+		gv.SetStatus("Join Paths: select an end node of one path, and then an end node of another path")
+		return
+	}
+	bnode := 0
+	for idx := range es.PathSel {
+		bnode = idx
+	}
+	sv := gv.SVG()
+	apns, _ := sv.PathNodes(apath)
+	if es.JoinNode >= len(apns) || bnode >= len(es.PathNodes) {
+		return
+	}
+	asubs := PathSegsSubPaths(PathSegs(apath.Data))
+	bsegs := PathSegs(bpath.Data)
+	xf := bpath.ParTransform(true).Mul(apath.ParTransform(true).Inverse())
+	for _, ps := range bsegs {
+		ps.XForm(xf)
+	}
+	bsubs := PathSegsSubPaths(bsegs)
+	ai, aend, aok := PathSubPathEndNode(asubs, es.JoinNode)
+	bi, bend, bok := PathSubPathEndNode(bsubs, bnode)
+	if !aok || !bok {
+		gv.SetStatus("Join Paths: the selected nodes must both be at an end of an open path")
+		return
+	}
+	asub := asubs[ai]
+	if !aend { // join at the end
+		asub = PathSegsReverse(asub)
+	}
+	bsub := bsubs[bi]
+	if bend { // join at the start
+		bsub = PathSegsReverse(bsub)
+	}
+	merge := apns[es.JoinNode].WinPt.Sub(es.PathNodes[bnode].WinPt).Length() <= 1
+	joined := make([]*PathSeg, 0, len(asub)+len(bsub))
+	joined = append(joined, asub...)
+	if merge {
+		joined = append(joined, bsub[1:]...)
+	} else {
+		bst := *bsub[0]
+		bst.Cmd = svg.PcL | bst.Cmd&1
+		joined = append(joined, &bst)
+		joined = append(joined, bsub[1:]...)
+	}
+	var segs []*PathSeg
+	for i, sub := range asubs {
+		if i == ai {
+			segs = append(segs, joined...)
+		} else {
+			segs = append(segs, sub...)
+		}
+	}
+	for i, sub := range bsubs {
+		if i != bi {
+			segs = append(segs, sub...)
+		}
+	}
+
+	sv.UndoSave("JoinPaths", apath.Nm+" and "+bpath.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	apath.Data = PathSegsData(segs)
+	es.ResetSelected()
+	bpath.Delete(true)
+	es.Select(apath)
+	es.JoinPath = nil
+	es.PathSel = nil
+	sv.UpdateEnd(updt)
+	gv.UpdateAll()
+	sv.UpdateNodeSprites()
+	gv.ChangeMade()
+	if merge {
+		gv.SetStatus("Join Paths: joined " + bpath.Nm + " to " + apath.Nm + ", merging the end nodes")
+	} else {
+		gv.SetStatus("Join Paths: joined " + bpath.Nm + " to " + apath.Nm + " with a line segment")
+	}
+}
+
+// JoinEnableFunc is an ActionUpdateFunc that inactivates action if there
+// are not selected nodes in two paths to join (see JoinPaths)
+func (gv *GridView) JoinEnableFunc(act *gi.Button) {
+	es := &gv.EditState
+	act.SetInactiveState(es.JoinPath == nil || es.ActivePath == nil || es.JoinPath == es.ActivePath || len(es.PathSel) != 1)
+}
+
+// SetPathClosed closes or opens the current subpath of the active path
+// (see CurSubPathNode), as an undoable action: closing adds a closepath
+// after its last node, and opening removes it.  The nodes stay in place.
+func (gv *GridView) SetPathClosed(closed bool) {
+	es := &gv.EditState
+	path := es.ActivePath
+	idx := es.CurSubPathNode()
+	if path == nil || idx < 0 {
+		return
+	}
+	st, ed := PathSubPathEnds(es.PathNodes, idx)
+	segs := PathSegs(path.Data)
+	si := PathSegNodeIdx(segs, ed) + 1 // where the closepath is
+	if si == 0 {
+		return
+	}
+	has := si < len(segs) && segs[si].IsClose()
+	if has == closed {
+		return
+	}
+	if closed && st == ed {
+		gv.SetStatus("Close Path: the subpath has only one node")
+		gv.UpdateNodeToolbar()
+		return
+	}
+	act := "OpenPath"
+	if closed {
+		act = "ClosePath"
+	}
+	sv := gv.SVG()
+	sv.UndoSave(act, path.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	if closed {
+		segs = append(segs[:si], append([]*PathSeg{{Cmd: svg.PcZ}}, segs[si:]...)...)
+	} else {
+		segs = append(segs[:si], segs[si+1:]...)
+	}
+	path.Data = PathSegsData(segs) // relative commands after it stay in place
+	sv.UpdateEnd(updt)
+	sv.UpdateNodeSprites()
+	gv.ChangeMade()
+	if closed {
+		gv.SetStatus("Closed the path")
+	} else {
+		gv.SetStatus("Opened the path")
+	}
+}
+
+// TogglePathClosed closes the current subpath of the active path if it
+// is open, and opens it if it is closed (see SetPathClosed)
+func (gv *GridView) TogglePathClosed() {
+	closed, ok := gv.EditState.CurSubPathClosed()
+	if !ok {
+		gv.SetStatus("Close Path: select a path with the node tool")
+		return
+	}
+	gv.SetPathClosed(!closed)
+}
+
+// PathSegAt returns the segment of given path (index in PathSegs) that
+// passes within twice the snap tolerance of given window point, and the
+// parameter t along it of the closest point -- false if none, or if
+// the closest point is at one of the nodes
+func (sv *SVGView) PathSegAt(path *svg.Path, wpt image.Point) (int, float32, bool) {
+	const nsamp = 32
+	pxf := path.ParTransform(true) // include self
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	mpt := mat32.NewVec2FmPoint(wpt)
+	segs := PathSegs(path.Data)
+	sts := PathSegStarts(segs)
+	best := 2 * SnapTolPx()
+	bi, bt := -1, float32(0)
+	for i, ps := range segs {
+		if ps.IsMove() {
+			continue
+		}
+		a := pxf.MulVec2AsPt(sts[i]).Add(svoff)
+		for s := 1; s <= nsamp; s++ {
+			t1 := float32(s) / nsamp
+			b := pxf.MulVec2AsPt(ps.PointAt(sts[i], t1)).Add(svoff)
+			ab := b.Sub(a)
+			u := float32(0)
+			if l2 := ab.LengthSq(); l2 > 0 {
+				u = mat32.Clamp(mpt.Sub(a).Dot(ab)/l2, 0, 1)
+			}
+			if d := a.Add(ab.MulScalar(u)).DistTo(mpt); d <= best {
+				best = d
+				bi = i
+				bt = (float32(s-1) + u) / nsamp
+			}
+			a = b
+		}
+	}
+	if bi < 0 || bt <= 0.001 || bt >= 0.999 {
+		return -1, 0, false
+	}
+	return bi, bt, true
+}
+
 //////////////////////////////////////////////////////////////////////////
 //  PathNode
 
@@ -149,10 +654,40 @@ type PathNode struct {
 	// main point coords in window (dot) coords
 	WinPt mat32.Vec2
 
-	// control point coords in window (dot) coords (nil until manipulated)
+	// previous current point (start of the segment) in window (dot) coords
+	WinPCp mat32.Vec2
+
+	// control point coords in window (dot) coords: first and second for C, S,
+	// the one for Q, T -- nil for other commands
 	WinCtrls []mat32.Vec2
 }
 
+// CtrlDataIdx returns the index in the path data of the control point
+// at given index in WinCtrls, and -1 if it is implicit (reflected, for S, T)
+func (pn *PathNode) CtrlDataIdx(ci int) int {
+	switch pn.Cmd {
+	case svg.PcC, svg.Pcc:
+		return pn.Idx - 4 + 2*ci
+	case svg.PcS, svg.Pcs:
+		if ci == 1 {
+			return pn.Idx - 2
+		}
+	case svg.PcQ, svg.Pcq:
+		return pn.Idx - 2
+	}
+	return -1
+}
+
+// CtrlAnchor returns the window coords of the node that the control point
+// at given index in WinCtrls is attached to: the start of the segment for
+// the first control point of C, S, else this node
+func (pn *PathNode) CtrlAnchor(ci int) mat32.Vec2 {
+	if ci == 0 && len(pn.WinCtrls) == 2 {
+		return pn.WinPCp
+	}
+	return pn.WinPt
+}
+
 // PathNodes returns the PathNode data for given path data, and a list of indexes where commands start
 func (sv *SVGView) PathNodes(path *svg.Path) ([]*PathNode, []int) {
 	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
@@ -170,31 +705,67 @@ func (sv *SVGView) PathNodes(path *svg.Path) ([]*PathNode, []int) {
 			lstCmdIdx = idx - 1
 			cidxs = append(cidxs, lstCmdIdx)
 		}
-		pn := &PathNode{Cmd: cmd, PrevCmd: lstCmd, CmdIdx: lstCmdIdx, Idx: idx, PtIdx: ptIdx, PCp: pcp, Cp: cp, WinPt: cw, WinCtrls: ctrl}
+		pn := &PathNode{Cmd: cmd, PrevCmd: lstCmd, CmdIdx: lstCmdIdx, Idx: idx, PtIdx: ptIdx, PCp: pcp, Cp: cp, WinPt: cw}
+		switch cmd {
+		case svg.PcC, svg.Pcc, svg.PcQ, svg.Pcq, svg.PcT, svg.Pct:
+			for _, c := range ctrl {
+				pn.WinCtrls = append(pn.WinCtrls, pxf.MulVec2AsPt(c).Add(svoff))
+			}
+		case svg.PcS, svg.Pcs: // reflected first control is second
+			pn.WinCtrls = []mat32.Vec2{pxf.MulVec2AsPt(ctrl[1]).Add(svoff), pxf.MulVec2AsPt(ctrl[0]).Add(svoff)}
+		}
 		nc = append(nc, pn)
 		pcp = cp
 		lstCmd = cmd
 		return ki.Continue
 	})
+	// the current point after a closepath is the start of the subpath
+	segs := PathSegs(path.Data)
+	sts := PathSegStarts(segs)
+	ni := 0
+	for si, ps := range segs {
+		if ps.IsClose() || ni >= len(nc) {
+			continue
+		}
+		nc[ni].PCp = sts[si]
+		nc[ni].WinPCp = pxf.MulVec2AsPt(sts[si]).Add(svoff)
+		ni++
+	}
 	return nc, cidxs
 }
 
 func (sv *SVGView) UpdateNodeSprites() {
+	es := sv.EditState()
 	win := sv.GridView.ParentWindow()
+	if win == nil { // headless: just the nodes
+		path := es.FirstSelectedPath()
+		if path == nil {
+			sv.RemoveNodeSprites(win)
+			return
+		}
+		es.PathNodes, es.PathCmds = sv.PathNodes(path)
+		es.ActivePath = path
+		return
+	}
 	updt := win.UpdateStart()
 	defer win.UpdateEnd(updt)
 
-	es := sv.EditState()
 	prvn := es.NNodeSprites
 
 	path := es.FirstSelectedPath()
 
 	if path == nil {
 		sv.RemoveNodeSprites(win)
+		sv.UpdateRoundSprite(win) // rect
 		win.UpdateSig()
 		return
 	}
 
+	if path != es.ActivePath {
+		es.SetJoinNode()
+		es.PathSel = nil
+		es.CurNode = -1
+	}
 	es.PathNodes, es.PathCmds = sv.PathNodes(path)
 	es.NNodeSprites = len(es.PathNodes)
 	es.ActivePath = path
@@ -214,21 +785,55 @@ func (sv *SVGView) UpdateNodeSprites() {
 		win.InactivateSprite(spnm)
 	}
 
+	sv.UpdateNodeCtrlSprites(win)
+	sv.UpdateRoundSprite(win)
+
 	sv.GridView.UpdateNodeToolbar()
 
 	win.UpdateSig()
 }
 
+// UpdateNodeCtrlSprites updates the sprites for the control points of
+// the curve segments of the active path, and their lines to the nodes
+func (sv *SVGView) UpdateNodeCtrlSprites(win *gi.Window) {
+	es := sv.EditState()
+	InactivateSprites(win, SpNodeCtrlLine)
+	InactivateSprites(win, SpNodeCtrl)
+	for i, pn := range es.PathNodes {
+		for ci, wc := range pn.WinCtrls {
+			if pn.CtrlDataIdx(ci) < 0 {
+				continue
+			}
+			idx := i // key to get local var
+			sub := SpNodeCtrl1 + Sprites(ci)
+			lsp := Sprite(win, SpNodeCtrlLine, sub, idx, image.ZP)
+			DrawSpriteNodeCtrlLine(lsp, pn.CtrlAnchor(ci), wc)
+			sp := SpriteConnectEvent(win, SpNodeCtrl, sub, idx, image.ZP, sv.This(), func(recv, send ki.Ki, sig int64, d any) {
+				ssvg := recv.Embed(KiT_SVGView).(*SVGView)
+				ssvg.NodeCtrlSpriteEvent(idx, sub, oswin.EventType(sig), d)
+			})
+			SetSpritePos(sp, wc.ToPoint())
+		}
+	}
+}
+
 func (sv *SVGView) RemoveNodeSprites(win *gi.Window) {
 	es := sv.EditState()
-	for i := 0; i < es.NNodeSprites; i++ {
+	for i := 0; win != nil && i < es.NNodeSprites; i++ {
 		spnm := SpriteName(SpNodePoint, SpUnk, i)
 		win.InactivateSprite(spnm)
 	}
+	if win != nil {
+		InactivateSprites(win, SpNodeCtrlLine)
+		InactivateSprites(win, SpNodeCtrl)
+		InactivateSprites(win, SpRoundCorner)
+	}
+	es.SetJoinNode()
 	es.NNodeSprites = 0
 	es.PathNodes = nil
 	es.PathCmds = nil
 	es.ActivePath = nil
+	es.CurNode = -1
 }
 
 func (sv *SVGView) NodeSpriteEvent(idx int, et oswin.EventType, d any) {
@@ -241,8 +846,16 @@ func (sv *SVGView) NodeSpriteEvent(idx int, et oswin.EventType, d any) {
 		me.SetProcessed()
 		if me.Action == mouse.Press {
 			win.SpriteDragging = SpriteName(SpNodePoint, SpUnk, idx)
+			es.CurNode = idx
+			es.SelectNode(idx, me.SelectMode())
 			es.DragNodeStart(me.Where)
 		} else if me.Action == mouse.Release {
+			if sv.NodeDragCloses(idx) {
+				_, ed := PathSubPathEnds(es.PathNodes, idx)
+				sv.PathCloseSubPath(es.ActivePath, es.PathNodes[ed])
+				sv.GridView.SetStatus("<b>NodeAdj</b>: closed the path")
+			}
+			es.NodeSnapIdx = -1
 			sv.UpdateNodeSprites()
 			sv.ManipDone()
 		}
@@ -253,6 +866,45 @@ func (sv *SVGView) NodeSpriteEvent(idx int, et oswin.EventType, d any) {
 	}
 }
 
+// NodeCtrlSpriteEvent handles events on the control point sprite of given
+// subtype (SpNodeCtrl1, 2) for the node at given index
+func (sv *SVGView) NodeCtrlSpriteEvent(idx int, sub Sprites, et oswin.EventType, d any) {
+	win := sv.GridView.ParentWindow()
+	es := sv.EditState()
+	es.SelNoDrag = false
+	switch et {
+	case oswin.MouseEvent:
+		me := d.(*mouse.Event)
+		me.SetProcessed()
+		if me.Action == mouse.Press {
+			win.SpriteDragging = SpriteName(SpNodeCtrl, sub, idx)
+			es.DragNodeStart(me.Where)
+		} else if me.Action == mouse.Release {
+			sv.UpdateNodeSprites()
+			sv.ManipDone()
+		}
+	case oswin.MouseDragEvent:
+		me := d.(*mouse.DragEvent)
+		me.SetProcessed()
+		sv.SpriteCtrlDrag(idx, sub, win, me)
+	}
+}
+
+// PathNodeSetCtrl sets the control point at given index in WinCtrls of
+// given path node to given new point value, in *absolute* (but local)
+// coordinates -- translates into relative coordinates as needed.
+func (sv *SVGView) PathNodeSetCtrl(path *svg.Path, pn *PathNode, ci int, npt mat32.Vec2) {
+	di := pn.CtrlDataIdx(ci)
+	if di < 0 {
+		return
+	}
+	if svg.PathCmdIsRel(pn.Cmd) {
+		npt = npt.Sub(pn.PCp)
+	}
+	path.Data[di] = svg.PathData(npt.X)
+	path.Data[di+1] = svg.PathData(npt.Y)
+}
+
 // PathNodeMoveOnePoint moves given node index by given delta in window coords
 // and all following points up to cmd = z or m are moved in the opposite
 // direction to compensate, so only the one point is moved in effect.
@@ -302,6 +954,196 @@ func (sv *SVGView) PathNodeSetPoint(path *svg.Path, pn *PathNode, npt mat32.Vec2
 	}
 }
 
+// SnapToPathNodes snaps the node at given index of the active path,
+// being dragged from given start to given current mouse point, in window
+// coordinates, onto the closest other node of the same path, if within
+// the snap tolerance of it, e.g., to make the first and last nodes
+// coincide exactly.  This takes precedence over the other snapping done
+// by SnapPoint.  Returns the snapped mouse point, sets es.NodeSnapIdx,
+// and shows the node snap sprite on the snapped-to node.
+func (sv *SVGView) SnapToPathNodes(idx int, spt, mpt mat32.Vec2) mat32.Vec2 {
+	es := sv.EditState()
+	es.NodeSnapIdx = -1
+	pn := es.PathNodes[idx]
+	npt := pn.WinPt.Add(mpt.Sub(spt))
+	clDst := SnapTolPx()
+	for i, on := range es.PathNodes {
+		if i == idx || on.Cmd == svg.PcZ || on.Cmd == svg.Pcz {
+			continue
+		}
+		if dst := on.WinPt.Sub(npt).Length(); dst <= clDst {
+			clDst = dst
+			es.NodeSnapIdx = i
+		}
+	}
+	if es.NodeSnapIdx < 0 {
+		return mpt
+	}
+	on := es.PathNodes[es.NodeSnapIdx]
+	if win := sv.GridView.ParentWindow(); win != nil {
+		spi := 0
+		if sv.NodeDragCloses(idx) {
+			spi = 1
+			sv.GridView.SetStatus("<b>NodeAdj</b>: release to close the path")
+		}
+		sp := Sprite(win, SpNodeSnap, SpUnk, spi, image.ZP)
+		SetSpritePos(sp, on.WinPt.ToPoint())
+	}
+	return spt.Add(on.WinPt.Sub(pn.WinPt))
+}
+
+// PathCloseIdxs returns the data indexes of the Z (close path) commands
+// in given path data, which PathDataIterFunc does not report
+func PathCloseIdxs(data []svg.PathData) []int {
+	var zidx []int
+	for i := 0; i < len(data); {
+		ci := i
+		cmd, n := svg.PathDataNextCmd(data, &i)
+		if cmd == svg.PcZ || cmd == svg.Pcz {
+			zidx = append(zidx, ci)
+		}
+		i += n
+	}
+	return zidx
+}
+
+// PathArcAngles returns the x axis rotation, start angle and signed
+// sweep angle (all in radians) of an elliptical arc, given the ctrls
+// and end point from PathDataIterFunc for an A command
+// (center, start, radii, x axis rotation, flags).  The angles are
+// in the unrotated frame of the ellipse, as used by EllipsePt.
+func PathArcAngles(ctrls []mat32.Vec2, cp mat32.Vec2) (rot, a1, da float32) {
+	ctr, prv, rad := ctrls[0], ctrls[1], ctrls[2]
+	rot = mat32.DegToRad(ctrls[3].X)
+	sweep := ctrls[4].Y != 0
+	cs, sn := mat32.Cos(-rot), mat32.Sin(-rot)
+	unit := func(p mat32.Vec2) mat32.Vec2 {
+		d := p.Sub(ctr)
+		return mat32.V2((d.X*cs-d.Y*sn)/rad.X, (d.X*sn+d.Y*cs)/rad.Y)
+	}
+	u1 := unit(prv)
+	u2 := unit(cp)
+	a1 = mat32.Atan2(u1.Y, u1.X)
+	da = mat32.Atan2(u2.Y, u2.X) - a1
+	if sweep {
+		for da <= 0 {
+			da += 2 * mat32.Pi
+		}
+	} else {
+		for da >= 0 {
+			da -= 2 * mat32.Pi
+		}
+	}
+	return
+}
+
+// EllipsePt returns the point at given angle (radians) along the
+// ellipse with given center, radii and x axis rotation (radians)
+func EllipsePt(ctr, rad mat32.Vec2, rot, ang float32) mat32.Vec2 {
+	ex := rad.X * mat32.Cos(ang)
+	ey := rad.Y * mat32.Sin(ang)
+	cr, sr := mat32.Cos(rot), mat32.Sin(rot)
+	return mat32.V2(ctr.X+ex*cr-ey*sr, ctr.Y+ex*sr+ey*cr)
+}
+
+// PathSubPathEnds returns the indexes of the start and end nodes of the
+// subpath (starting with an M command) containing given node index
+func PathSubPathEnds(pts []*PathNode, idx int) (st, ed int) {
+	isStart := func(pn *PathNode) bool {
+		return (pn.Cmd == svg.PcM || pn.Cmd == svg.Pcm) && pn.PtIdx == 0
+	}
+	st = idx
+	for st > 0 && !isStart(pts[st]) {
+		st--
+	}
+	ed = idx
+	for ed+1 < len(pts) && !isStart(pts[ed+1]) {
+		ed++
+	}
+	return
+}
+
+// PathSubPathCloseIdx returns the index in the path data just after the
+// command of given end node of a subpath (see PathSubPathEnds), where a
+// Z command closing the subpath goes, and whether it is already closed
+func PathSubPathCloseIdx(path *svg.Path, ed *PathNode) (int, bool) {
+	_, n := path.Data[ed.CmdIdx].Cmd()
+	ci := ed.CmdIdx + 1 + n
+	if ci < len(path.Data) {
+		if cmd, _ := path.Data[ci].Cmd(); cmd == svg.PcZ || cmd == svg.Pcz {
+			return ci, true
+		}
+	}
+	return ci, false
+}
+
+// NodeDragCloses returns true if the node at given index of the active
+// path is an end of an open subpath that is currently snapped to its
+// other end (see SnapToPathNodes), so releasing the drag will close it
+func (sv *SVGView) NodeDragCloses(idx int) bool {
+	es := sv.EditState()
+	if es.NodeSnapIdx < 0 || es.ActivePath == nil {
+		return false
+	}
+	st, ed := PathSubPathEnds(es.PathNodes, idx)
+	if st == ed || !((idx == st && es.NodeSnapIdx == ed) || (idx == ed && es.NodeSnapIdx == st)) {
+		return false
+	}
+	_, closed := PathSubPathCloseIdx(es.ActivePath, es.PathNodes[ed])
+	return !closed
+}
+
+// PathCloseSubPath closes the open subpath ending at given end node
+// by inserting a Z command after it
+func (sv *SVGView) PathCloseSubPath(path *svg.Path, ed *PathNode) {
+	ci, closed := PathSubPathCloseIdx(path, ed)
+	if closed {
+		return
+	}
+	nd := make([]svg.PathData, 0, len(path.Data)+1)
+	nd = append(nd, path.Data[:ci]...)
+	nd = append(nd, svg.PcZ.EncCmd(0))
+	path.Data = append(nd, path.Data[ci:]...)
+}
+
+// SpriteCtrlDrag processes a mouse drag event on the control point sprite
+// of given subtype (SpNodeCtrl1, 2) for the node at given index
+func (sv *SVGView) SpriteCtrlDrag(idx int, sub Sprites, win *gi.Window, me *mouse.DragEvent) {
+	es := sv.EditState()
+	if !es.InAction() {
+		sv.ManipStart("NodeCtrl", es.ActivePath.Nm)
+		sv.GatherAlignPoints()
+	}
+	pn := es.PathNodes[idx]
+	ci := int(sub - SpNodeCtrl1)
+
+	sv.InactivateAlignSprites(win)
+
+	spt := mat32.NewVec2FmPoint(es.DragStartPos)
+	mpt := mat32.NewVec2FmPoint(me.Where)
+	if me.HasAnyModifier(key.Control) {
+		mpt, _ = sv.ConstrainPoint(spt, mpt)
+	}
+	if Prefs.SnapNodes {
+		mpt = sv.SnapPoint(mpt)
+	}
+	es.DragCurPos = mpt.ToPoint()
+
+	nwc := pn.WinCtrls[ci].Add(mpt.Sub(spt)) // new window coord
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	pxf := es.ActivePath.ParTransform(true) // include self
+	sv.PathNodeSetCtrl(es.ActivePath, pn, ci, pxf.Inverse().MulVec2AsPt(nwc.Sub(svoff)))
+
+	if sp, ok := win.SpriteByName(SpriteName(SpNodeCtrl, sub, idx)); ok {
+		SetSpritePos(sp, nwc.ToPoint())
+	}
+	if lsp, ok := win.SpriteByName(SpriteName(SpNodeCtrlLine, sub, idx)); ok {
+		DrawSpriteNodeCtrlLine(lsp, pn.CtrlAnchor(ci), nwc)
+	}
+	go sv.ManipUpdate()
+	win.UpdateSig()
+}
+
 // SpriteNodeDrag processes a mouse node drag event on a path node sprite
 func (sv *SVGView) SpriteNodeDrag(idx int, win *gi.Window, me *mouse.DragEvent) {
 	es := sv.EditState()
@@ -313,7 +1155,7 @@ func (sv *SVGView) SpriteNodeDrag(idx int, win *gi.Window, me *mouse.DragEvent)
 	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
 	pn := es.PathNodes[idx]
 
-	InactivateSprites(win, SpAlignMatch)
+	sv.InactivateAlignSprites(win)
 
 	spt := mat32.NewVec2FmPoint(es.DragStartPos)
 	mpt := mat32.NewVec2FmPoint(me.Where)
@@ -323,11 +1165,15 @@ func (sv *SVGView) SpriteNodeDrag(idx int, win *gi.Window, me *mouse.DragEvent)
 	}
 	if Prefs.SnapNodes {
 		mpt = sv.SnapPoint(mpt)
+		mpt = sv.SnapToPathNodes(idx, spt, mpt)
 	}
 
 	es.DragCurPos = mpt.ToPoint()
 	mdel := es.DragCurPos.Sub(es.DragStartPos)
 	dv := mat32.NewVec2FmPoint(mdel)
+	if Prefs.SnapNodes && es.NodeSnapIdx >= 0 { // exact, without pixel rounding
+		dv = es.PathNodes[es.NodeSnapIdx].WinPt.Sub(pn.WinPt)
+	}
 
 	nwc := pn.WinPt.Add(dv) // new window coord
 	sv.PathNodeSetOnePoint(es.ActivePath, es.PathNodes, idx, dv, svoff)
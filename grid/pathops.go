@@ -0,0 +1,718 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// PathPoly is a simple polygonal representation of one subpath of a path
+// that is made only of straight line segments, in absolute local coordinates.
+// It is used for path operations that work on corners.
+type PathPoly struct {
+
+	// points of the subpath
+	Pts []mat32.Vec2
+
+	// index of each point in the path nodes (see PathNodes), -1 if implicit
+	Nodes []int
+
+	// subpath is closed (Z command)
+	Closed bool
+}
+
+// PathPolys returns the polygonal representation of given path data,
+// for each subpath.  Returns false if the path contains anything
+// other than straight line segments (moveto, lineto, closepath).
+func PathPolys(data []svg.PathData) ([]*PathPoly, bool) {
+	var polys []*PathPoly
+	var cur *PathPoly
+	var cp, st mat32.Vec2
+	sz := len(data)
+	node := 0
+	add := func(pt mat32.Vec2) {
+		if cur == nil || cur.Closed {
+			cur = &PathPoly{Pts: []mat32.Vec2{st}, Nodes: []int{-1}}
+			polys = append(polys, cur)
+		}
+		cur.Pts = append(cur.Pts, pt)
+		cur.Nodes = append(cur.Nodes, node)
+		node++
+	}
+	for i := 0; i < sz; {
+		cmd, n := svg.PathDataNextCmd(data, &i)
+		switch cmd {
+		case svg.PcM, svg.Pcm:
+			for np := 0; np < n/2; np++ {
+				if cmd == svg.PcM {
+					cp = svg.PathDataNextVec(data, &i)
+				} else {
+					cp = svg.PathDataNextRel(data, &i, cp)
+				}
+				if np == 0 {
+					st = cp
+					cur = &PathPoly{Pts: []mat32.Vec2{cp}, Nodes: []int{node}}
+					polys = append(polys, cur)
+					node++
+				} else {
+					add(cp)
+				}
+			}
+		case svg.PcL, svg.Pcl:
+			for np := 0; np < n/2; np++ {
+				if cmd == svg.PcL {
+					cp = svg.PathDataNextVec(data, &i)
+				} else {
+					cp = svg.PathDataNextRel(data, &i, cp)
+				}
+				add(cp)
+			}
+		case svg.PcH, svg.Pch:
+			for np := 0; np < n; np++ {
+				if cmd == svg.PcH {
+					cp.X = svg.PathDataNext(data, &i)
+				} else {
+					cp.X += svg.PathDataNext(data, &i)
+				}
+				add(cp)
+			}
+		case svg.PcV, svg.Pcv:
+			for np := 0; np < n; np++ {
+				if cmd == svg.PcV {
+					cp.Y = svg.PathDataNext(data, &i)
+				} else {
+					cp.Y += svg.PathDataNext(data, &i)
+				}
+				add(cp)
+			}
+		case svg.PcZ, svg.Pcz:
+			if cur != nil {
+				cur.Closed = true
+				// final explicit point back to start is redundant with close
+				if np := len(cur.Pts); np > 1 && cur.Pts[np-1] == cur.Pts[0] {
+					cur.Pts = cur.Pts[:np-1]
+					cur.Nodes = cur.Nodes[:np-1]
+				}
+			}
+			cp = st
+		default:
+			return nil, false
+		}
+	}
+	return polys, true
+}
+
+// PathPolysString returns path data string for given polys
+func PathPolysString(polys []*PathPoly) string {
+	var sb strings.Builder
+	for _, pl := range polys {
+		for i, pt := range pl.Pts {
+			if i == 0 {
+				sb.WriteString(fmt.Sprintf("M %g,%g ", pt.X, pt.Y))
+			} else {
+				sb.WriteString(fmt.Sprintf("L %g,%g ", pt.X, pt.Y))
+			}
+		}
+		if pl.Closed {
+			sb.WriteString("Z ")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// PolyCorner is the result of processing one corner of a PathPoly:
+// the corner is replaced by a segment from In to Out, which is a
+// straight line if Curve is false, and otherwise a cubic bezier
+// with control points C1, C2.  If In == Out, the corner is unchanged.
+type PolyCorner struct {
+	In, Out mat32.Vec2
+	Curve   bool
+	C1, C2  mat32.Vec2
+}
+
+// PolyCornerFunc computes the replacement for the corner at point p,
+// with previous point a and next point b.  node is the index of the
+// corner point in the path nodes (-1 if implicit).
+type PolyCornerFunc func(node int, a, p, b mat32.Vec2) PolyCorner
+
+// SelCornerFunc returns a PolyCornerFunc that only applies given function
+// to the corners at given node indexes -- all corners if sel is empty.
+func SelCornerFunc(sel map[int]struct{}, fun PolyCornerFunc) PolyCornerFunc {
+	if len(sel) == 0 {
+		return fun
+	}
+	return func(node int, a, p, b mat32.Vec2) PolyCorner {
+		if _, has := sel[node]; !has {
+			return PolyCorner{In: p, Out: p}
+		}
+		return fun(node, a, p, b)
+	}
+}
+
+// PolyCornersString returns path data string for given polys,
+// with every interior corner (all corners for closed polys)
+// replaced according to given function.
+func PolyCornersString(polys []*PathPoly, fun PolyCornerFunc) string {
+	var sb strings.Builder
+	wr := func(cmd string, pts ...mat32.Vec2) {
+		sb.WriteString(cmd)
+		for _, pt := range pts {
+			sb.WriteString(fmt.Sprintf(" %g,%g", pt.X, pt.Y))
+		}
+		sb.WriteString(" ")
+	}
+	for _, pl := range polys {
+		np := len(pl.Pts)
+		crn := make([]PolyCorner, np)
+		for i, p := range pl.Pts {
+			crn[i] = PolyCorner{In: p, Out: p}
+			if np < 3 && pl.Closed || np < 2 {
+				continue
+			}
+			if !pl.Closed && (i == 0 || i == np-1) {
+				continue
+			}
+			a := pl.Pts[(i+np-1)%np]
+			b := pl.Pts[(i+1)%np]
+			crn[i] = fun(pl.Nodes[i], a, p, b)
+		}
+		corner := func(c PolyCorner) {
+			if c.In == c.Out {
+				return
+			}
+			if c.Curve {
+				wr("C", c.C1, c.C2, c.Out)
+			} else {
+				wr("L", c.Out)
+			}
+		}
+		wr("M", crn[0].Out)
+		for i := 1; i < np; i++ {
+			wr("L", crn[i].In)
+			corner(crn[i])
+		}
+		if pl.Closed {
+			wr("L", crn[0].In)
+			corner(crn[0])
+			sb.WriteString("Z ")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// cornerGeom returns the unit vectors from corner p toward a and b, the
+// max distance along each side that a corner operation can use
+// (half the shorter side), and the cosine of the angle between the sides.
+// Returns false if the corner is degenerate (zero length or straight).
+func cornerGeom(a, p, b mat32.Vec2) (u, v mat32.Vec2, maxd, cos float32, ok bool) {
+	da := a.Sub(p)
+	db := b.Sub(p)
+	la := da.Length()
+	lb := db.Length()
+	if la == 0 || lb == 0 {
+		return
+	}
+	u = da.DivScalar(la)
+	v = db.DivScalar(lb)
+	cos = u.Dot(v)
+	if cos < -0.9999 || cos > 0.9999 {
+		return
+	}
+	maxd = 0.5 * mat32.Min(la, lb)
+	ok = true
+	return
+}
+
+// RoundCornerFunc returns a PolyCornerFunc that rounds corners with
+// a circular fillet of given radius, reduced as needed to fit
+// within half of the adjoining segments.
+func RoundCornerFunc(radius float32) PolyCornerFunc {
+	return func(node int, a, p, b mat32.Vec2) PolyCorner {
+		c := PolyCorner{In: p, Out: p}
+		u, v, maxd, cos, ok := cornerGeom(a, p, b)
+		if !ok || radius <= 0 {
+			return c
+		}
+		theta := math.Acos(float64(cos))          // interior angle
+		th2 := float32(math.Tan(theta / 2))       // tan of half angle
+		t := mat32.Min(radius/th2, maxd)          // distance from corner to tangent points
+		r := t * th2                              // effective radius
+		phi := math.Pi - theta                    // arc sweep
+		k := float32(4.0/3.0*math.Tan(phi/4)) * r // bezier control distance
+		c.In = p.Add(u.MulScalar(t))
+		c.Out = p.Add(v.MulScalar(t))
+		c.Curve = true
+		c.C1 = c.In.Sub(u.MulScalar(k))
+		c.C2 = c.Out.Sub(v.MulScalar(k))
+		return c
+	}
+}
+
+// ChamferCornerFunc returns a PolyCornerFunc that replaces corners with
+// a straight bevel, cutting given distance back along each adjoining
+// segment, reduced as needed to fit within half of the segments.
+func ChamferCornerFunc(size float32) PolyCornerFunc {
+	return func(node int, a, p, b mat32.Vec2) PolyCorner {
+		c := PolyCorner{In: p, Out: p}
+		u, v, maxd, _, ok := cornerGeom(a, p, b)
+		if !ok || size <= 0 {
+			return c
+		}
+		d := mat32.Min(size, maxd)
+		c.In = p.Add(u.MulScalar(d))
+		c.Out = p.Add(v.MulScalar(d))
+		return c
+	}
+}
+
+///////////////////////////////////////////////////////////////////////
+//   Actions
+
+// SelPathsFunc calls given function on all paths and rects within the
+// current selection, recursing into groups, skipping locked items
+func (gv *GridView) SelPathsFunc(fun func(sii svg.NodeSVG)) {
+	es := &gv.EditState
+	for itm := range es.Selected {
+		itm.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+			if NodeIsLocked(k) {
+				return ki.Break
+			}
+			switch sii := k.(type) {
+			case *svg.Path, *svg.Rect:
+				fun(sii.(svg.NodeSVG))
+			}
+			return ki.Continue
+		})
+	}
+}
+
+// PathOpStart starts a live path operation with given action name:
+// if the same operation was the last action on exactly the currently
+// selected paths, the original path data is restored so the operation
+// can be re-applied with new parameters, as part of the same undoable
+// action.
+// Otherwise, the undo state is saved and the original path data is recorded,
+// along with the currently selected path nodes, which restrict the operation
+// to those nodes of the active path (see PathCornersOp).
+func (gv *GridView) PathOpStart(act, data string) {
+	es := &gv.EditState
+	sv := gv.SVG()
+	if es.PathOp == act && es.PathOpOrig != nil {
+		same := true
+		sel := make(map[*svg.Path]bool)
+		gv.SelPathsFunc(func(sii svg.NodeSVG) {
+			if pt, ok := sii.(*svg.Path); ok {
+				sel[pt] = true
+				if _, has := es.PathOpOrig[pt]; !has {
+					same = false
+				}
+			}
+		})
+		if same && len(sel) == len(es.PathOpOrig) {
+			for pt, od := range es.PathOpOrig {
+				pt.Data = make([]svg.PathData, len(od))
+				copy(pt.Data, od)
+			}
+			return
+		}
+	}
+	sv.UndoSave(act, data)
+	es.PathOp = act
+	es.PathOpNodes = es.PathSel
+	es.PathSel = nil // node indexes are no longer valid after the op
+	es.CurNode = -1
+	es.PathOpOrig = make(map[*svg.Path][]svg.PathData)
+	gv.SelPathsFunc(func(sii svg.NodeSVG) {
+		if pt, ok := sii.(*svg.Path); ok {
+			od := make([]svg.PathData, len(pt.Data))
+			copy(od, pt.Data)
+			es.PathOpOrig[pt] = od
+		}
+	})
+}
+
+// PathCornersOp applies given corner function to all selected paths
+// (recursing into groups).  If path nodes were selected at the start of
+// the operation, it only applies to those nodes of the active path.
+// Paths that have curved segments are skipped.
+// Returns the number of paths updated and skipped.
+func (gv *GridView) PathCornersOp(fun PolyCornerFunc) (nupd, nskip int) {
+	es := &gv.EditState
+	sv := gv.SVG()
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	gv.SelPathsFunc(func(sii svg.NodeSVG) {
+		pt, ok := sii.(*svg.Path)
+		if !ok {
+			return
+		}
+		fn := fun
+		if len(es.PathOpNodes) > 0 {
+			if pt != es.ActivePath {
+				return
+			}
+			fn = SelCornerFunc(es.PathOpNodes, fun)
+		}
+		polys, ok := PathPolys(pt.Data)
+		if !ok {
+			nskip++
+			return
+		}
+		pt.SetData(PolyCornersString(polys, fn))
+		nupd++
+	})
+	sv.UpdateEnd(updt)
+	return
+}
+
+// SelRoundCorners rounds the corners of all selected paths and rects
+// (recursing into groups) with a circular arc of given radius, in local
+// coordinates of each element -- both Rx and Ry for rects.  The radius is
+// reduced where needed to fit the adjoining segments.  Repeated calls on
+// the same selection adjust the radius live, as one undoable action, as
+// when dragging the round corners handle (see UpdateRoundSprite).
+// Paths with curved segments are skipped.
+func (gv *GridView) SelRoundCorners(radius float32) {
+	es := &gv.EditState
+	if !es.HasSelectedUnlocked() {
+		return
+	}
+	sv := gv.SVG()
+	gv.PathOpStart("RoundCorners", es.SelectedNamesString())
+	es.RoundRadius = radius
+	nrect := 0
+	gv.SelPathsFunc(func(sii svg.NodeSVG) {
+		if rc, ok := sii.(*svg.Rect); ok {
+			r := mat32.Min(radius, 0.5*mat32.Min(rc.Size.X, rc.Size.Y))
+			rc.Radius.Set(r, r)
+			nrect++
+		}
+	})
+	nupd, nskip := gv.PathCornersOp(RoundCornerFunc(radius))
+	sv.UpdateSelect()
+	gv.ChangeMade()
+	msg := fmt.Sprintf("Round Corners: radius %s applied to %d paths, %d rects", Prefs.FmtVal(radius), nupd, nrect)
+	if nskip > 0 {
+		msg += fmt.Sprintf(" -- skipped %d paths with curves", nskip)
+	}
+	gv.SetStatus(msg)
+}
+
+// SelChamferCorners replaces the corners of all selected paths (recursing
+// into groups) with a straight bevel, cut back by given size along each
+// adjoining segment, in local coordinates of each path.  If nodes are
+// selected in the node tool, only those corners are chamfered.
+// Repeated calls on the same selection adjust the size live, as one
+// undoable action.  Paths with curved segments are skipped.
+func (gv *GridView) SelChamferCorners(size float32) {
+	es := &gv.EditState
+	if !es.HasSelectedUnlocked() {
+		return
+	}
+	sv := gv.SVG()
+	gv.PathOpStart("ChamferCorners", es.SelectedNamesString())
+	nupd, nskip := gv.PathCornersOp(ChamferCornerFunc(size))
+	sv.UpdateSelect()
+	gv.ChangeMade()
+	msg := fmt.Sprintf("Chamfer Corners: size %s applied to %d paths", Prefs.FmtVal(size), nupd)
+	if nskip > 0 {
+		msg += fmt.Sprintf(" -- skipped %d paths with curves", nskip)
+	}
+	gv.SetStatus(msg)
+}
+
+// SegIntersect returns the intersection of the line through points p0, p1
+// with the line through q0, q1, as parameters t along p (0 = p0, 1 = p1)
+// and s along q (0 = q0, 1 = q1).  Returns false if the lines are parallel.
+func SegIntersect(p0, p1, q0, q1 mat32.Vec2) (t, s float32, ok bool) {
+	r := p1.Sub(p0)
+	d := q1.Sub(q0)
+	den := r.X*d.Y - r.Y*d.X
+	if mat32.Abs(den) < 1.0e-8 {
+		return
+	}
+	w := q0.Sub(p0)
+	t = (w.X*d.Y - w.Y*d.X) / den
+	s = (w.X*r.Y - w.Y*r.X) / den
+	ok = true
+	return
+}
+
+// PathPolysXForm returns the polys of given path transformed by given
+// transform, or false if the path has curved segments
+func PathPolysXForm(path *svg.Path, xf mat32.Mat2) ([]*PathPoly, bool) {
+	polys, ok := PathPolys(path.Data)
+	if !ok {
+		return nil, false
+	}
+	for _, pl := range polys {
+		for i := range pl.Pts {
+			pl.Pts[i] = xf.MulVec2AsPt(pl.Pts[i])
+		}
+	}
+	return polys, true
+}
+
+// TrimExtendPathData returns new path data for trimming or extending one
+// end of the first open subpath of given path to the nearest intersection
+// with the segments of the boundary path.  For trim, the end segment is cut
+// back to an intersection within it, and for extend, it is lengthened to an
+// intersection beyond its end -- the end requiring the smallest change is used.
+// Returns an error if there is no such intersection.
+func TrimExtendPathData(path, bound *svg.Path, extend bool) (string, error) {
+	pxf := path.ParTransform(true)
+	polys, ok := PathPolys(path.Data)
+	if !ok {
+		return "", fmt.Errorf("path %s has curved segments", path.Nm)
+	}
+	// boundary into path coordinates: its own transform, then the inverse of the path's
+	bpolys, ok := PathPolysXForm(bound, bound.ParTransform(true).Mul(pxf.Inverse()))
+	if !ok {
+		return "", fmt.Errorf("boundary %s has curved segments", bound.Nm)
+	}
+	var pl *PathPoly
+	for _, p := range polys {
+		if !p.Closed && len(p.Pts) >= 2 {
+			pl = p
+			break
+		}
+	}
+	if pl == nil {
+		return "", fmt.Errorf("path %s has no open segments", path.Nm)
+	}
+	np := len(pl.Pts)
+	best := float32(-1)
+	bidx := 0
+	var bpt mat32.Vec2
+	ends := [2][2]int{{1, 0}, {np - 2, np - 1}} // inner, end point indexes
+	for _, ed := range ends {
+		p0 := pl.Pts[ed[0]]
+		p1 := pl.Pts[ed[1]]
+		ln := p1.Sub(p0).Length()
+		for _, bp := range bpolys {
+			nb := len(bp.Pts)
+			nseg := nb - 1
+			if bp.Closed {
+				nseg = nb
+			}
+			for si := 0; si < nseg; si++ {
+				t, s, ok := SegIntersect(p0, p1, bp.Pts[si], bp.Pts[(si+1)%nb])
+				if !ok || s < 0 || s > 1 {
+					continue
+				}
+				if extend && t <= 1 || !extend && (t <= 0 || t >= 1) {
+					continue
+				}
+				dst := mat32.Abs(1-t) * ln
+				if best < 0 || dst < best {
+					best = dst
+					bidx = ed[1]
+					bpt = p0.Add(p1.Sub(p0).MulScalar(t))
+				}
+			}
+		}
+	}
+	if best < 0 {
+		if extend {
+			return "", fmt.Errorf("no intersection with boundary beyond the ends of %s", path.Nm)
+		}
+		return "", fmt.Errorf("no intersection with boundary within the end segments of %s", path.Nm)
+	}
+	pl.Pts[bidx] = bpt
+	return PathPolysString(polys), nil
+}
+
+// SelTrimExtend trims or extends the first selected path to the second
+// selected path, which is the boundary (see TrimExtendPathData).
+// It is a single undoable action.
+func (gv *GridView) SelTrimExtend(extend bool) {
+	es := &gv.EditState
+	act := "Trim"
+	if extend {
+		act = "Extend"
+	}
+	sls := es.SelectedList(false)
+	if len(sls) != 2 {
+		gv.SetStatus(act + ": select the path to " + strings.ToLower(act) + " and then the boundary path")
+		return
+	}
+	path, pok := sls[0].(*svg.Path)
+	bound, bok := sls[1].(*svg.Path)
+	if !pok || !bok {
+		gv.SetStatus(act + ": both selected items must be paths")
+		return
+	}
+	nd, err := TrimExtendPathData(path, bound, extend)
+	if err != nil {
+		gv.SetStatus(act + ": " + err.Error())
+		return
+	}
+	sv := gv.SVG()
+	sv.UndoSave(act, path.Nm+" to "+bound.Nm)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	path.SetData(nd)
+	sv.UpdateEnd(updt)
+	sv.UpdateSelect()
+	gv.ChangeMade()
+	gv.SetStatus(act + ": " + path.Nm + " to " + bound.Nm)
+}
+
+// SelTrim trims the end of the first selected path back to where it
+// meets the second selected path
+func (gv *GridView) SelTrim() {
+	gv.SelTrimExtend(false)
+}
+
+// SelExtend extends the end of the first selected path to where it
+// meets the second selected path
+func (gv *GridView) SelExtend() {
+	gv.SelTrimExtend(true)
+}
+
+// PathPolysCleanup removes duplicate consecutive points (zero-length
+// segments), within given tolerance, from given polys, and drops subpaths
+// that have no remaining segments.  Returns the cleaned polys and the
+// number of points removed.
+func PathPolysCleanup(polys []*PathPoly, tol float32) ([]*PathPoly, int) {
+	nrm := 0
+	cps := make([]*PathPoly, 0, len(polys))
+	for _, pl := range polys {
+		cp := &PathPoly{Closed: pl.Closed}
+		for i, pt := range pl.Pts {
+			if n := len(cp.Pts); n > 0 && cp.Pts[n-1].Sub(pt).Length() <= tol {
+				nrm++
+				continue
+			}
+			cp.Pts = append(cp.Pts, pt)
+			cp.Nodes = append(cp.Nodes, pl.Nodes[i])
+		}
+		if n := len(cp.Pts); pl.Closed && n > 1 && cp.Pts[n-1].Sub(cp.Pts[0]).Length() <= tol {
+			cp.Pts = cp.Pts[:n-1]
+			cp.Nodes = cp.Nodes[:n-1]
+			nrm++
+		}
+		if len(cp.Pts) < 2 { // no segments left
+			nrm += len(cp.Pts)
+			continue
+		}
+		cps = append(cps, cp)
+	}
+	return cps, nrm
+}
+
+// PathPolysSelfIntersections returns the number of points where segments
+// of given polys cross each other (not counting adjacent segments
+// meeting at their shared point).
+func PathPolysSelfIntersections(polys []*PathPoly) int {
+	type seg struct{ a, b mat32.Vec2 }
+	var segs []seg
+	for _, pl := range polys {
+		np := len(pl.Pts)
+		for i := 0; i < np-1; i++ {
+			segs = append(segs, seg{pl.Pts[i], pl.Pts[i+1]})
+		}
+		if pl.Closed && np > 2 {
+			segs = append(segs, seg{pl.Pts[np-1], pl.Pts[0]})
+		}
+	}
+	const eps = 1.0e-5
+	nx := 0
+	for i, si := range segs {
+		for _, sj := range segs[i+1:] {
+			t, s, ok := SegIntersect(si.a, si.b, sj.a, sj.b)
+			if ok && t > eps && t < 1-eps && s > eps && s < 1-eps {
+				nx++
+			}
+		}
+	}
+	return nx
+}
+
+// SelCleanupPaths cleans up all selected paths (recursing into groups):
+// duplicate consecutive points and zero-length segments are removed, as a
+// single undoable action.  Self-intersections are also detected, which
+// render differently depending on the fill rule: if any are found in
+// paths using the default nonzero fill rule, it offers to switch those
+// to the evenodd fill rule.  Paths with curved segments are skipped.
+// Reports what was changed in the status bar.
+func (gv *GridView) SelCleanupPaths() {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		return
+	}
+	sv := gv.SVG()
+	var paths []*svg.Path
+	gv.SelPathsFunc(func(sii svg.NodeSVG) {
+		if pt, ok := sii.(*svg.Path); ok {
+			paths = append(paths, pt)
+		}
+	})
+	ncurve, npts, nchg, nx := 0, 0, 0, 0
+	var xpaths []*svg.Path
+	saved := false
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	for _, pt := range paths {
+		polys, ok := PathPolys(pt.Data)
+		if !ok {
+			ncurve++
+			continue
+		}
+		cps, nrm := PathPolysCleanup(polys, 1.0e-4)
+		if nrm > 0 {
+			if !saved {
+				sv.UndoSave("CleanupPaths", es.SelectedNamesString())
+				saved = true
+			}
+			pt.SetData(PathPolysString(cps))
+			npts += nrm
+			nchg++
+		}
+		if n := PathPolysSelfIntersections(cps); n > 0 {
+			nx += n
+			if pt.Pnt.FillStyle.Rule == gist.FillRuleNonZero {
+				xpaths = append(xpaths, pt)
+			}
+		}
+	}
+	sv.UpdateEnd(updt)
+	if saved {
+		sv.UpdateSelect()
+		gv.ChangeMade()
+	}
+	msg := fmt.Sprintf("Clean Up Paths: removed %d duplicate points in %d of %d paths, found %d self-intersections", npts, nchg, len(paths), nx)
+	if ncurve > 0 {
+		msg += fmt.Sprintf(" -- skipped %d paths with curves", ncurve)
+	}
+	gv.SetStatus(msg)
+	if len(xpaths) == 0 {
+		return
+	}
+	gi.PromptDialog(gv.Viewport, gi.DlgOpts{Title: "Self-Intersecting Paths",
+		Prompt: fmt.Sprintf("Found %d self-intersections in %d paths using the nonzero fill rule, which may fill overlapping regions unexpectedly.  Switch these paths to the evenodd fill rule?", nx, len(xpaths))},
+		gi.AddOk, gi.AddCancel, gv.This(), func(recv, send ki.Ki, sig int64, d any) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			sv.UndoSave("FillRuleEvenOdd", es.SelectedNamesString())
+			updt := sv.UpdateStart()
+			sv.SetFullReRender()
+			for _, pt := range xpaths {
+				pt.SetProp("fill-rule", "evenodd")
+			}
+			sv.UpdateEnd(updt)
+			gv.ChangeMade()
+			gv.SetStatus(fmt.Sprintf("Clean Up Paths: set evenodd fill rule on %d paths", len(xpaths)))
+		})
+}
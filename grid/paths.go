@@ -0,0 +1,129 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/goki/gi/gi"
+)
+
+// HomeDir returns the user's home directory, from the GoGi preferences,
+// or the system if not set there
+func HomeDir() string {
+	if hd := gi.Prefs.User.HomeDir; hd != "" {
+		return hd
+	}
+	hd, _ := os.UserHomeDir()
+	return hd
+}
+
+// ExpandHome replaces a leading ~ in given path with the user's home
+// directory, as a shell does -- only for ~ alone or followed by a path
+// separator, not ~user
+func ExpandHome(pth string) string {
+	if pth == "~" {
+		return HomeDir()
+	}
+	if strings.HasPrefix(pth, "~/") || strings.HasPrefix(pth, `~\`) {
+		return filepath.Join(HomeDir(), pth[2:])
+	}
+	return pth
+}
+
+// envRefRe matches $VAR and ${VAR} environment variable references
+var envRefRe = regexp.MustCompile(`\$\{\w+\}|\$\w+`)
+
+// ExpandPath returns given file path with $VAR and ${VAR} references to
+// environment variables that are set expanded, and a leading ~ replaced
+// with the user's home directory (see ExpandHome).  References to unset
+// variables are left as they are, so a literal $ in a file name is kept.
+// This is for the recent file and batch export paths: the paths
+// chosen in the file dialogs are used as they are.
+func ExpandPath(pth string) string {
+	if pth == "" {
+		return pth
+	}
+	return ExpandHome(envRefRe.ReplaceAllStringFunc(pth, func(ref string) string {
+		if val, ok := os.LookupEnv(strings.Trim(ref, "${}")); ok {
+			return val
+		}
+		return ref
+	}))
+}
+
+// ExpandEnvValue returns given environment variable value with $VAR and
+// ${VAR} references expanded, and a leading ~ in each of its path list
+// elements (e.g., for PATH) replaced with the user's home directory
+func ExpandEnvValue(val string) string {
+	els := filepath.SplitList(os.ExpandEnv(val))
+	for i, el := range els {
+		els[i] = ExpandHome(el)
+	}
+	return strings.Join(els, string(os.PathListSeparator))
+}
+
+// CleanPath returns given file path cleaned with filepath.Clean, which
+// on Windows also converts forward slashes to backslashes, so that paths
+// with mixed separators name the same file.  It is not expanded (see
+// ExpandPath).
+func CleanPath(pth string) string {
+	if pth == "" {
+		return pth
+	}
+	return filepath.Clean(pth)
+}
+
+// AbsPath returns the absolute form of given file path, cleaned (see
+// CleanPath) -- if it cannot be made absolute, it is returned just cleaned
+func AbsPath(pth string) string {
+	pth = CleanPath(pth)
+	if pth == "" {
+		return pth
+	}
+	if apth, err := filepath.Abs(pth); err == nil {
+		return apth
+	}
+	return pth
+}
+
+// WriteFileAtomic writes given data to given file by writing it to a
+// uniquely named temporary file in the same directory and renaming that
+// over the file, so a failed or concurrent write never leaves the file
+// partly written.  An existing file keeps its permissions, otherwise
+// the file gets given permissions.  If the file is a symbolic link, the
+// file that it links to is written, and the link is kept.
+func WriteFileAtomic(fname string, b []byte, perm os.FileMode) error {
+	fname = CleanPath(fname)
+	if rfn, err := filepath.EvalSymlinks(fname); err == nil {
+		fname = rfn
+	}
+	dir, fn := filepath.Split(fname)
+	if st, err := os.Stat(fname); err == nil {
+		perm = st.Mode().Perm()
+	}
+	tf, err := os.CreateTemp(dir, "."+fn+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tnm := tf.Name()
+	_, err = tf.Write(b)
+	if cerr := tf.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tnm, perm)
+	}
+	if err == nil {
+		err = os.Rename(tnm, fname)
+	}
+	if err != nil {
+		os.Remove(tnm)
+	}
+	return err
+}
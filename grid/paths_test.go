@@ -0,0 +1,184 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/gi"
+)
+
+// setTestHomeDir sets the home directory in the GoGi preferences to
+// given directory for the rest of the test
+func setTestHomeDir(t *testing.T, dir string) {
+	shd := gi.Prefs.User.HomeDir
+	t.Cleanup(func() { gi.Prefs.User.HomeDir = shd })
+	gi.Prefs.User.HomeDir = dir
+}
+
+func TestExpandPath(t *testing.T) {
+	home := filepath.Join("home", "user")
+	setTestHomeDir(t, home)
+	t.Setenv("GRID_TEST_DIR", "/data/drawings")
+	t.Setenv("GRID_TEST_EMPTY", "")
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"$GRID_TEST_DIR/a.svg", "/data/drawings/a.svg"},
+		{"${GRID_TEST_DIR}/a.svg", "/data/drawings/a.svg"},
+		{"x${GRID_TEST_EMPTY}y.svg", "xy.svg"},
+		{"price$list.svg", "price$list.svg"},
+		{"a${GRID_TEST_UNSET}b.svg", "a${GRID_TEST_UNSET}b.svg"},
+		{"cost$.svg", "cost$.svg"},
+		{"~", home},
+		{"~/a.svg", filepath.Join(home, "a.svg")},
+		{"~user/a.svg", "~user/a.svg"},
+		{"a/~/b.svg", "a/~/b.svg"},
+	}
+	for _, tt := range tests {
+		if got := ExpandPath(tt.in); got != tt.want {
+			t.Errorf("ExpandPath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	win := runtime.GOOS == "windows"
+	tests := []struct {
+		in, want, winWant string
+	}{
+		{"", "", ""},
+		{"a/b/../c.svg", "a/c.svg", `a\c.svg`},
+		{`a\b/c.svg`, `a\b/c.svg`, `a\b\c.svg`},
+		{`C:\drawings/sub\..\a.svg`, `C:\drawings/sub\..\a.svg`, `C:\drawings\a.svg`},
+		{`C:/drawings//a.svg`, `C:/drawings/a.svg`, `C:\drawings\a.svg`},
+		{"$HOME/~/a.svg", "$HOME/~/a.svg", `$HOME\~\a.svg`}, // not expanded
+		{"#a.svg#", "#a.svg#", "#a.svg#"},
+	}
+	for _, tt := range tests {
+		want := tt.want
+		if win {
+			want = tt.winWant
+		}
+		if got := CleanPath(tt.in); got != want {
+			t.Errorf("CleanPath(%q) = %q, want %q", tt.in, got, want)
+		}
+	}
+	if got := AbsPath("a.svg"); !filepath.IsAbs(got) || filepath.Base(got) != "a.svg" {
+		t.Errorf("AbsPath(%q) = %q", "a.svg", got)
+	}
+}
+
+func TestAutoSaveFilename(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		fname, want string
+	}{
+		{filepath.Join(dir, "a.svg"), filepath.Join(dir, "#a.svg#")},
+		{dir + "/sub/../b.svg", filepath.Join(dir, "#b.svg#")},
+		{filepath.ToSlash(filepath.Join(dir, "c d.svg")), filepath.Join(dir, "#c d.svg#")},
+	}
+	gv := newTestGridView(t)
+	for _, tt := range tests {
+		gv.Filename = gi.FileName(tt.fname)
+		if got := gv.AutoSaveFilename(); got != tt.want {
+			t.Errorf("%q: AutoSaveFilename = %q, want %q", tt.fname, got, tt.want)
+		}
+	}
+
+	// unsaved drawings each get their own autosave in the temp dir
+	gv.Filename = ""
+	gv2 := newTestGridView(t)
+	as1, as2 := gv.AutoSaveFilename(), gv2.AutoSaveFilename()
+	for _, as := range []string{as1, as2} {
+		fn := filepath.Base(as)
+		if filepath.Dir(as) != filepath.Clean(os.TempDir()) || !strings.HasPrefix(fn, "#") || !strings.HasSuffix(fn, "#") {
+			t.Errorf("unsaved autosave filename = %q", as)
+		}
+	}
+	if as1 == as2 {
+		t.Errorf("unsaved drawings share the autosave filename %q", as1)
+	}
+	if as := gv.AutoSaveFilename(); as != as1 {
+		t.Errorf("autosave filename changed from %q to %q", as1, as)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "a.svg")
+	for i, b := range [][]byte{[]byte("<svg>one</svg>"), []byte("<svg>two</svg>")} {
+		// write with forward slashes, as given on Windows too
+		if err := WriteFileAtomic(filepath.ToSlash(fname), b, 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, b) {
+			t.Errorf("write %d: read back %q, want %q", i, got, b)
+		}
+	}
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ents) != 1 {
+		t.Errorf("got %d files, want 1 (no temporary files left)", len(ents))
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(fname, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFileAtomic(fname, []byte("<svg/>"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		st, err := os.Stat(fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := st.Mode().Perm(); perm != 0600 {
+			t.Errorf("permissions = %v, want %v", perm, os.FileMode(0600))
+		}
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "a.svg"), []byte("<svg/>"), 0644); err == nil {
+		t.Error("no error writing into a missing directory")
+	}
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.svg")
+	link := filepath.Join(dir, "link.svg")
+	if err := os.WriteFile(target, []byte("<svg>old</svg>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symbolic links not available:", err)
+	}
+	b := []byte("<svg>new</svg>")
+	if err := WriteFileAtomic(link, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Mode()&os.ModeSymlink == 0 {
+		t.Error("symbolic link replaced by a regular file")
+	}
+	if got, _ := os.ReadFile(target); !bytes.Equal(got, b) {
+		t.Errorf("link target = %q, want %q", got, b)
+	}
+}
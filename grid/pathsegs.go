@@ -0,0 +1,391 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"sort"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// PathSeg is one segment of a path, ending in one path node (or a
+// closepath, which has no node), with all points in absolute local
+// coordinates, so that segments can be removed or inserted without
+// affecting the position of the others.  Relative commands are
+// written back as relative, from the new previous point.
+type PathSeg struct {
+
+	// path command, as in the original data -- the absolute and relative
+	// versions are equivalent here
+	Cmd svg.PathCmds
+
+	// end point of segment (the node), absolute local coords -- for a
+	// closepath, the start of the subpath that it returns to
+	Pt mat32.Vec2
+
+	// control points: first and second for C, S, the one for Q, T
+	Ctrls []mat32.Vec2
+
+	// arc center, radius, rotation (degrees) and large-arc, sweep flags
+	Ctr   mat32.Vec2
+	Rad   mat32.Vec2
+	Ang   float32
+	Flags mat32.Vec2
+}
+
+// IsMove returns true if segment is a moveto
+func (ps *PathSeg) IsMove() bool {
+	return ps.Cmd == svg.PcM || ps.Cmd == svg.Pcm
+}
+
+// IsClose returns true if segment is a closepath, which has no node
+func (ps *PathSeg) IsClose() bool {
+	return ps.Cmd == svg.PcZ || ps.Cmd == svg.Pcz
+}
+
+// PathSegs returns the segments of given path data -- one for each
+// node, as returned by PathNodes, plus one for each closepath.
+// Implicit lineto points following a moveto are returned as lineto.
+func PathSegs(data []svg.PathData) []*PathSeg {
+	type iseg struct {
+		idx int
+		seg *PathSeg
+	}
+	var isegs []iseg
+	for i := 0; i < len(data); {
+		cmd, n := svg.PathDataNextCmd(data, &i)
+		if cmd == svg.PcZ || cmd == svg.Pcz {
+			isegs = append(isegs, iseg{i - 1, &PathSeg{Cmd: cmd}})
+		}
+		i += n
+	}
+	svg.PathDataIterFunc(data, func(idx int, cmd svg.PathCmds, ptIdx int, cp mat32.Vec2, ctrls []mat32.Vec2) bool {
+		ps := &PathSeg{Cmd: cmd, Pt: cp}
+		switch cmd {
+		case svg.PcM:
+			if ptIdx > 0 {
+				ps.Cmd = svg.PcL
+			}
+		case svg.Pcm:
+			if ptIdx > 0 {
+				ps.Cmd = svg.Pcl
+			}
+		case svg.PcC, svg.Pcc, svg.PcQ, svg.Pcq, svg.PcT, svg.Pct:
+			ps.Ctrls = append(ps.Ctrls, ctrls...)
+		case svg.PcS, svg.Pcs: // reflected first control is second
+			ps.Ctrls = []mat32.Vec2{ctrls[1], ctrls[0]}
+		case svg.PcA, svg.Pca:
+			ps.Ctr = ctrls[0]
+			ps.Rad = ctrls[2]
+			ps.Ang = ctrls[3].X
+			ps.Flags = ctrls[4]
+		}
+		isegs = append(isegs, iseg{idx, ps})
+		return ki.Continue
+	})
+	sort.SliceStable(isegs, func(i, j int) bool {
+		return isegs[i].idx < isegs[j].idx
+	})
+	segs := make([]*PathSeg, len(isegs))
+	var st mat32.Vec2
+	for i, is := range isegs {
+		ps := is.seg
+		switch {
+		case ps.IsMove():
+			st = ps.Pt
+		case ps.IsClose():
+			ps.Pt = st
+		}
+		segs[i] = ps
+	}
+	return segs
+}
+
+// PathSegStarts returns the start point of each of given segments:
+// the end point of the previous one
+func PathSegStarts(segs []*PathSeg) []mat32.Vec2 {
+	sts := make([]mat32.Vec2, len(segs))
+	var cp mat32.Vec2
+	for i, ps := range segs {
+		sts[i] = cp
+		cp = ps.Pt
+	}
+	return sts
+}
+
+// arcAngles returns the x axis rotation, start angle and sweep angle
+// of an arc segment starting at p0, as in PathArcAngles
+func (ps *PathSeg) arcAngles(p0 mat32.Vec2) (rot, a1, da float32) {
+	return PathArcAngles([]mat32.Vec2{ps.Ctr, p0, ps.Rad, {X: ps.Ang}, ps.Flags}, ps.Pt)
+}
+
+// PointAt returns the point at parameter t (0..1) along the segment,
+// which starts at p0 -- a moveto has no extent and returns its point
+func (ps *PathSeg) PointAt(p0 mat32.Vec2, t float32) mat32.Vec2 {
+	switch ps.Cmd &^ 1 {
+	case svg.PcM:
+		return ps.Pt
+	case svg.PcC, svg.PcS:
+		a := p0.Lerp(ps.Ctrls[0], t)
+		b := ps.Ctrls[0].Lerp(ps.Ctrls[1], t)
+		c := ps.Ctrls[1].Lerp(ps.Pt, t)
+		return a.Lerp(b, t).Lerp(b.Lerp(c, t), t)
+	case svg.PcQ, svg.PcT:
+		return p0.Lerp(ps.Ctrls[0], t).Lerp(ps.Ctrls[0].Lerp(ps.Pt, t), t)
+	case svg.PcA:
+		rot, a1, da := ps.arcAngles(p0)
+		return EllipsePt(ps.Ctr, ps.Rad, rot, a1+t*da)
+	}
+	return p0.Lerp(ps.Pt, t)
+}
+
+// Split splits the segment, which starts at p0, at parameter t (0..1),
+// returning the new first segment, ending at the point at t, and the
+// second segment (this one, updated) -- curves are split with
+// De Casteljau's algorithm, and arcs at the corresponding angle, so
+// the shape is unchanged.  A closepath gets a lineto before it.
+func (ps *PathSeg) Split(p0 mat32.Vec2, t float32) (*PathSeg, *PathSeg) {
+	rel := ps.Cmd & 1
+	m := ps.PointAt(p0, t)
+	switch ps.Cmd &^ 1 {
+	case svg.PcC, svg.PcS:
+		a := p0.Lerp(ps.Ctrls[0], t)
+		b := ps.Ctrls[0].Lerp(ps.Ctrls[1], t)
+		c := ps.Ctrls[1].Lerp(ps.Pt, t)
+		d := a.Lerp(b, t)
+		e := b.Lerp(c, t)
+		ps.Ctrls = []mat32.Vec2{e, c}
+		return &PathSeg{Cmd: svg.PcC | rel, Pt: m, Ctrls: []mat32.Vec2{a, d}}, ps
+	case svg.PcQ, svg.PcT:
+		a := p0.Lerp(ps.Ctrls[0], t)
+		b := ps.Ctrls[0].Lerp(ps.Pt, t)
+		ps.Ctrls = []mat32.Vec2{b}
+		return &PathSeg{Cmd: svg.PcQ | rel, Pt: m, Ctrls: []mat32.Vec2{a}}, ps
+	case svg.PcA:
+		_, _, da := ps.arcAngles(p0)
+		large := func(a float32) float32 {
+			if mat32.Abs(a) > mat32.Pi {
+				return 1
+			}
+			return 0
+		}
+		fs := &PathSeg{Cmd: ps.Cmd, Pt: m, Ctr: ps.Ctr, Rad: ps.Rad, Ang: ps.Ang, Flags: mat32.V2(large(t*da), ps.Flags.Y)}
+		ps.Flags.X = large((1 - t) * da)
+		return fs, ps
+	case svg.PcZ:
+		return &PathSeg{Cmd: svg.PcL | rel, Pt: m}, ps
+	}
+	return &PathSeg{Cmd: ps.Cmd, Pt: m}, ps // L, H, V
+}
+
+// PathSegNodeIdx returns the index in given segments of the segment
+// for given node index (as in PathNodes), and -1 if not found
+func PathSegNodeIdx(segs []*PathSeg, node int) int {
+	ni := 0
+	for i, ps := range segs {
+		if ps.IsClose() {
+			continue
+		}
+		if ni == node {
+			return i
+		}
+		ni++
+	}
+	return -1
+}
+
+// PathSegsData returns path data for given segments.  Consecutive
+// segments with the same command are combined into one command, and
+// S, T segments that no longer follow a segment that they implicitly
+// reflect, e.g., after a node was deleted, are written as the
+// equivalent C, Q.  H, V that are no longer horizontal or vertical
+// are written as L.
+func PathSegsData(segs []*PathSeg) []svg.PathData {
+	var data []svg.PathData
+	var cp, st mat32.Vec2
+	lstCmd := svg.PcErr
+	cmdIdx := -1
+	for i, ps := range segs {
+		cmd := ps.Cmd
+		rel := cmd & 1
+		switch cmd &^ 1 {
+		case svg.PcS, svg.PcT:
+			if i == 0 || !PathSegReflects(segs[i-1], ps, cp) {
+				cmd = (cmd&^1 - 2) | rel // C, Q
+			}
+		case svg.PcH:
+			if ps.Pt.Y != cp.Y {
+				cmd = svg.PcL | rel
+			}
+		case svg.PcV:
+			if ps.Pt.X != cp.X {
+				cmd = svg.PcL | rel
+			}
+		}
+		if cmd != lstCmd || ps.IsMove() || ps.IsClose() {
+			data = append(data, cmd.EncCmd(0))
+			cmdIdx = len(data) - 1
+		}
+		off := mat32.Vec2{}
+		if rel == 1 {
+			off = cp
+		}
+		pt := ps.Pt.Sub(off)
+		switch cmd &^ 1 {
+		case svg.PcM, svg.PcL, svg.PcT:
+			data = append(data, svg.PathData(pt.X), svg.PathData(pt.Y))
+		case svg.PcH:
+			data = append(data, svg.PathData(pt.X))
+		case svg.PcV:
+			data = append(data, svg.PathData(pt.Y))
+		case svg.PcC:
+			c1 := ps.Ctrls[0].Sub(off)
+			c2 := ps.Ctrls[1].Sub(off)
+			data = append(data, svg.PathData(c1.X), svg.PathData(c1.Y), svg.PathData(c2.X), svg.PathData(c2.Y), svg.PathData(pt.X), svg.PathData(pt.Y))
+		case svg.PcS:
+			c2 := ps.Ctrls[1].Sub(off)
+			data = append(data, svg.PathData(c2.X), svg.PathData(c2.Y), svg.PathData(pt.X), svg.PathData(pt.Y))
+		case svg.PcQ:
+			c := ps.Ctrls[0].Sub(off)
+			data = append(data, svg.PathData(c.X), svg.PathData(c.Y), svg.PathData(pt.X), svg.PathData(pt.Y))
+		case svg.PcA:
+			data = append(data, svg.PathData(ps.Rad.X), svg.PathData(ps.Rad.Y), svg.PathData(ps.Ang), svg.PathData(ps.Flags.X), svg.PathData(ps.Flags.Y), svg.PathData(pt.X), svg.PathData(pt.Y))
+		}
+		data[cmdIdx] = cmd.EncCmd(len(data) - cmdIdx - 1)
+		switch {
+		case ps.IsClose():
+			cp = st
+		case ps.IsMove():
+			cp = ps.Pt
+			st = cp
+		default:
+			cp = ps.Pt
+		}
+		lstCmd = cmd
+	}
+	return data
+}
+
+// PathSegReflects returns true if the implicit first control point of
+// given S or T segment, following given previous segment ending at
+// current point cp, is the one it has -- i.e., it can remain an S or T
+func PathSegReflects(prv, ps *PathSeg, cp mat32.Vec2) bool {
+	const tol = 1.0e-4
+	ctrl := cp // not following the same kind of curve: no reflection
+	switch {
+	case ps.Cmd&^1 == svg.PcS && (prv.Cmd&^1 == svg.PcC || prv.Cmd&^1 == svg.PcS):
+		ctrl = cp.MulScalar(2).Sub(prv.Ctrls[1])
+	case ps.Cmd&^1 == svg.PcT && (prv.Cmd&^1 == svg.PcQ || prv.Cmd&^1 == svg.PcT):
+		ctrl = cp.MulScalar(2).Sub(prv.Ctrls[0])
+	}
+	return ctrl.Sub(ps.Ctrls[0]).Length() <= tol
+}
+
+// XForm transforms all the points of the segment by given transform.
+// The radii and rotation of arcs are scaled and rotated along with it,
+// which is exact for uniform scaling, and the sweep is reversed for
+// a mirroring transform.
+func (ps *PathSeg) XForm(xf mat32.Mat2) {
+	ps.Pt = xf.MulVec2AsPt(ps.Pt)
+	for i := range ps.Ctrls {
+		ps.Ctrls[i] = xf.MulVec2AsPt(ps.Ctrls[i])
+	}
+	if ps.Cmd&^1 != svg.PcA {
+		return
+	}
+	ps.Ctr = xf.MulVec2AsPt(ps.Ctr)
+	sx, sy := xf.ExtractScale()
+	ps.Rad = mat32.V2(ps.Rad.X*mat32.Abs(sx), ps.Rad.Y*mat32.Abs(sy))
+	ps.Ang += mat32.RadToDeg(xf.ExtractRot())
+	if xf.XX*xf.YY-xf.XY*xf.YX < 0 {
+		ps.Flags.Y = 1 - ps.Flags.Y
+	}
+}
+
+// PathSegsSubPaths splits given segments into subpaths, each starting
+// with a moveto (sharing the segments)
+func PathSegsSubPaths(segs []*PathSeg) [][]*PathSeg {
+	var subs [][]*PathSeg
+	st := 0
+	for i, ps := range segs {
+		if i > st && ps.IsMove() {
+			subs = append(subs, segs[st:i])
+			st = i
+		}
+	}
+	if st < len(segs) {
+		subs = append(subs, segs[st:])
+	}
+	return subs
+}
+
+// PathSubPathIsClosed returns true if given subpath has a closepath
+func PathSubPathIsClosed(sub []*PathSeg) bool {
+	for _, ps := range sub {
+		if ps.IsClose() {
+			return true
+		}
+	}
+	return false
+}
+
+// PathSubPathEndNode returns the index in given subpaths (see
+// PathSegsSubPaths) of the one with the node at given index (as in
+// PathNodes), and whether the node is its last node rather than its
+// first.  Returns false if the node is not an end of an open subpath.
+func PathSubPathEndNode(subs [][]*PathSeg, node int) (si int, end bool, ok bool) {
+	ni := 0
+	for i, sub := range subs {
+		nn := 0
+		for _, ps := range sub {
+			if !ps.IsClose() {
+				nn++
+			}
+		}
+		if ni+nn <= node {
+			ni += nn
+			continue
+		}
+		if PathSubPathIsClosed(sub) {
+			return i, false, false
+		}
+		switch node - ni {
+		case 0:
+			return i, false, true
+		case nn - 1:
+			return i, true, true
+		}
+		return i, false, false
+	}
+	return -1, false, false
+}
+
+// PathSegsReverse returns new segments for given open subpath, starting
+// with a moveto to its last node, and going back through the nodes to
+// its first, with the same shape
+func PathSegsReverse(segs []*PathSeg) []*PathSeg {
+	n := len(segs)
+	rs := make([]*PathSeg, 0, n)
+	rs = append(rs, &PathSeg{Cmd: segs[0].Cmd, Pt: segs[n-1].Pt})
+	for i := n - 1; i > 0; i-- {
+		ps := segs[i]
+		rel := ps.Cmd & 1
+		rp := &PathSeg{Cmd: ps.Cmd, Pt: segs[i-1].Pt, Ctr: ps.Ctr, Rad: ps.Rad, Ang: ps.Ang, Flags: ps.Flags}
+		switch ps.Cmd &^ 1 {
+		case svg.PcC, svg.PcS:
+			rp.Cmd = svg.PcC | rel
+			rp.Ctrls = []mat32.Vec2{ps.Ctrls[1], ps.Ctrls[0]}
+		case svg.PcQ, svg.PcT:
+			rp.Cmd = svg.PcQ | rel
+			rp.Ctrls = []mat32.Vec2{ps.Ctrls[0]}
+		case svg.PcA:
+			rp.Flags.Y = 1 - ps.Flags.Y
+		}
+		rs = append(rs, rp)
+	}
+	return rs
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/mat32"
+)
+
+// pdfExport holds the state for exporting one drawing to PDF, using the
+// EPS exporter to make the paths, as the path operators are the same
+type pdfExport struct {
+	epsExport
+
+	// the content stream
+	content bytes.Buffer
+
+	// fill, stroke opacities of the graphics states used, named GS1, GS2..
+	gstates [][2]float32
+
+	// base names of the standard fonts used, named F1, F2..
+	fonts []string
+}
+
+// gstate returns the name of the graphics state resource
+// for given fill and stroke opacity
+func (pe *pdfExport) gstate(fop, sop float32) string {
+	k := [2]float32{fop, sop}
+	for i, gs := range pe.gstates {
+		if gs == k {
+			return fmt.Sprintf("GS%d", i+1)
+		}
+	}
+	pe.gstates = append(pe.gstates, k)
+	return fmt.Sprintf("GS%d", len(pe.gstates))
+}
+
+// font returns the name of the font resource for given base font
+func (pe *pdfExport) font(base string) string {
+	for i, f := range pe.fonts {
+		if f == base {
+			return fmt.Sprintf("F%d", i+1)
+		}
+	}
+	pe.fonts = append(pe.fonts, base)
+	return fmt.Sprintf("F%d", len(pe.fonts))
+}
+
+// PDFBaseFont returns the standard PDF font that best matches given
+// font style: Helvetica, Times or Courier, in bold and italic variants
+func PDFBaseFont(fs *gist.Font) string {
+	fam := strings.ToLower(fs.Family)
+	bold := fs.Weight >= gist.WeightSemiBold && fs.Weight <= gist.WeightBolder
+	ital := fs.Style != gist.FontNormal
+	var base, bsfx, isfx string
+	switch {
+	case strings.Contains(fam, "mono") || strings.Contains(fam, "courier"):
+		base, bsfx, isfx = "Courier", "Bold", "Oblique"
+	case strings.Contains(fam, "times") || (strings.Contains(fam, "serif") && !strings.Contains(fam, "sans")):
+		base, bsfx, isfx = "Times", "Bold", "Italic"
+		if !bold && !ital {
+			return "Times-Roman"
+		}
+	default:
+		base, bsfx, isfx = "Helvetica", "Bold", "Oblique"
+	}
+	switch {
+	case bold && ital:
+		return base + "-" + bsfx + isfx
+	case bold:
+		return base + "-" + bsfx
+	case ital:
+		return base + "-" + isfx
+	}
+	return base
+}
+
+// PDFString returns given text as a PDF literal string, in the
+// WinAnsiEncoding of the standard fonts -- characters outside of
+// Latin-1 are replaced with ?
+func PDFString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 32:
+			sb.WriteByte(' ')
+		case r < 128:
+			sb.WriteRune(r)
+		case r >= 160 && r < 256:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
+
+// setColor writes the fill (rg) or stroke (RG) color operator for given
+// color spec, returning false if it is fully transparent
+func (pe *pdfExport) setColor(cs *gist.ColorSpec, opacity float32, op string) bool {
+	rgb, ok := EPSColor(cs)
+	if !ok || opacity <= 0 {
+		return false
+	}
+	fmt.Fprintf(pe.w, "%s %s %s %s\n", pe.num(rgb[0]), pe.num(rgb[1]), pe.num(rgb[2]), op)
+	return true
+}
+
+// text exports a text element, with given transform, in the standard
+// font matching its font style
+func (pe *pdfExport) text(nd *svg.Text, xf mat32.Mat2) {
+	pc := &nd.Pnt
+	if nd.Text == "" || !pc.FillStyle.On {
+		return
+	}
+	sz := pc.FontStyle.Size.Dots
+	if sz == 0 {
+		sz = pc.FontStyle.Size.Val
+	}
+	pos := nd.Pos
+	switch pc.TextStyle.Anchor {
+	case gist.AnchorMiddle:
+		pos.X -= nd.TextRender.Size.X / 2
+	case gist.AnchorEnd:
+		pos.X -= nd.TextRender.Size.X
+	}
+	// text space: x along the baseline and y up, in local units
+	o := pe.Pt(xf, pos)
+	ex := pe.Pt(xf, pos.Add(mat32.V2(1, 0))).Sub(o)
+	ey := pe.Pt(xf, pos.Add(mat32.V2(0, -1))).Sub(o)
+	pe.w.WriteString("q\n")
+	if op := pc.FillStyle.Opacity * pc.FontStyle.Opacity; op < 1 {
+		fmt.Fprintf(pe.w, "/%s gs\n", pe.gstate(op, 1))
+	}
+	if !pe.setColor(&pc.FillStyle.Color, pc.FillStyle.Opacity, "rg") {
+		pe.w.WriteString("Q\n")
+		return
+	}
+	fmt.Fprintf(pe.w, "BT\n/%s %s Tf\n%s %s %s %s %s %s Tm\n%s Tj\nET\nQ\n", pe.font(PDFBaseFont(&pc.FontStyle)), pe.num(sz), pe.num(ex.X), pe.num(ex.Y), pe.num(ey.X), pe.num(ey.Y), pe.num(o.X), pe.num(o.Y), PDFString(nd.Text))
+}
+
+// Node exports one element
+func (pe *pdfExport) Node(sii svg.NodeSVG) {
+	g := sii.AsSVGNode()
+	xf := g.ParTransform(true)
+	if nd, ok := sii.(*svg.Text); ok {
+		pe.text(nd, xf)
+		return
+	}
+	pe.path.Reset()
+	if !pe.shape(sii, xf) || pe.path.Len() == 0 {
+		return
+	}
+	pc := &g.Pnt
+	var paint []string
+	pe.w.WriteString("q\n")
+	fop, sop := float32(1), float32(1)
+	if pc.FillStyle.On && pe.setColor(&pc.FillStyle.Color, pc.FillStyle.Opacity, "rg") {
+		fop = pc.FillStyle.Opacity * pc.FontStyle.Opacity
+		if pc.FillStyle.Rule == gist.FillRuleEvenOdd {
+			paint = append(paint, "f*")
+		} else {
+			paint = append(paint, "f")
+		}
+	}
+	if pc.StrokeStyle.On && pe.setColor(&pc.StrokeStyle.Color, pc.StrokeStyle.Opacity, "RG") {
+		sop = pc.StrokeStyle.Opacity * pc.FontStyle.Opacity
+		ps := &pc.StrokeStyle
+		wd, lcap, join, dsh := pe.strokeParams(xf, ps)
+		fmt.Fprintf(pe.w, "%s w %d J %d j %s M [%s] 0 d\n", pe.num(wd), lcap, join, pe.num(mat32.Max(ps.MiterLimit, 1)), strings.Join(dsh, " "))
+		paint = append(paint, "S")
+	}
+	if len(paint) == 0 {
+		pe.w.WriteString("Q\n")
+		return
+	}
+	if fop < 1 || sop < 1 {
+		fmt.Fprintf(pe.w, "/%s gs\n", pe.gstate(fop, sop))
+	}
+	op := paint[0]
+	if len(paint) == 2 { // fill and stroke: B, B*
+		op = strings.Replace(strings.ToUpper(paint[0]), "F", "B", 1)
+	}
+	pe.w.WriteString(pe.path.String() + op + "\nQ\n")
+}
+
+// ExportPDFFunc is the ExportFunc for the .pdf format, which writes
+// a single page vector PDF file the size of the PhysSize, in points,
+// with lines, polylines, polygons, rects, circles, ellipses and paths
+// as PDF paths with their fill and stroke colors, opacities, widths,
+// caps, joins and dashes, and text in the standard PDF font that best
+// matches its font.  All curves are exact, as beziers.  Gradients are
+// approximated by the average of their stop colors.  Images and markers
+// are not exported.
+func ExportPDFFunc(sv *SVGView, sz *PhysSize, w io.Writer) error {
+	pe := &pdfExport{}
+	pe.ExportXForm = NewExportXForm(sv, sz, units.PtPerInch)
+	pe.epsExport.w = bufio.NewWriter(&pe.content)
+	pw := sz.Size.X * units.PtPerInch / UnitsPerInch(sz.Units)
+	ph := sz.Size.Y * units.PtPerInch / UnitsPerInch(sz.Units)
+	if sv.exportClip != nil {
+		fmt.Fprintf(pe.w, "0 0 %s %s re W n\n", pe.num(pw), pe.num(ph))
+	}
+	sv.ExportNodes(pe.Node)
+	if err := pe.w.Flush(); err != nil {
+		return err
+	}
+
+	// objects: 1 catalog, 2 pages, 3 page, 4 content, then fonts and graphics states
+	var objs []string
+	var fres, gres []string
+	for i, base := range pe.fonts {
+		fres = append(fres, fmt.Sprintf("/F%d %d 0 R", i+1, 5+len(objs)))
+		objs = append(objs, fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", base))
+	}
+	for i, k := range pe.gstates {
+		gres = append(gres, fmt.Sprintf("/GS%d %d 0 R", i+1, 5+len(objs)))
+		objs = append(objs, fmt.Sprintf("<< /Type /ExtGState /ca %s /CA %s >>", pe.num(k[0]), pe.num(k[1])))
+	}
+	res := "<< /Font << " + strings.Join(fres, " ") + " >> /ExtGState << " + strings.Join(gres, " ") + " >> >>"
+	objs = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents 4 0 R >>", pe.num(pw), pe.num(ph), res),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", pe.content.Len(), pe.content.String()),
+	}, objs...)
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offs := make([]int, len(objs))
+	for i, ob := range objs {
+		offs[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, ob)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offs {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Producer (Grid) >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// ExportPDF exports the drawing to given vector PDF file, with a page
+// the physical size of the drawing (see ExportPDFFunc).  Renders full
+// current page, or the export extent in Prefs.Export.  The output does
+// not depend on the current zoom level of the view.
+func (gv *GridView) ExportPDF(filename gi.FileName) error {
+	return gv.ExportWith(filename, ExportPDFFunc)
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/svg"
+	"github.com/goki/mat32"
+)
+
+// PencilFitIters is the max number of Newton-Raphson reparameterizations
+// tried by FitCurve before splitting the points, when a curve is close to fitting
+var PencilFitIters = 4
+
+// FitCurve fits a sequence of cubic bezier curves through given points,
+// so that no point is more than tol away from the curves (Schneider,
+// "An Algorithm for Automatically Fitting Digitized Curves", Graphics
+// Gems, 1990).  Each curve is returned as its start point, two control
+// points and end point, with the end of each the start of the next, and
+// the curves are smooth where they join.  Returns nil if there are fewer
+// than 2 distinct points.
+func FitCurve(pts []mat32.Vec2, tol float32) [][4]mat32.Vec2 {
+	d := make([]mat32.Vec2, 0, len(pts))
+	for _, p := range pts {
+		if len(d) > 0 && p == d[len(d)-1] {
+			continue
+		}
+		d = append(d, p)
+	}
+	n := len(d)
+	if n < 2 {
+		return nil
+	}
+	t1 := d[1].Sub(d[0]).Normal()
+	t2 := d[n-2].Sub(d[n-1]).Normal()
+	var bzs [][4]mat32.Vec2
+	fitCubic(d, 0, n-1, t1, t2, tol*tol, &bzs)
+	return bzs
+}
+
+// fitCubic fits a cubic bezier to points first..last of d, with given
+// unit tangents at the ends (t2 pointing back along the curve), splitting
+// at the point of max error and recursing if it is more than errSq
+func fitCubic(d []mat32.Vec2, first, last int, t1, t2 mat32.Vec2, errSq float32, bzs *[][4]mat32.Vec2) {
+	p0, p3 := d[first], d[last]
+	if last-first == 1 {
+		dist := p0.DistTo(p3) / 3
+		*bzs = append(*bzs, [4]mat32.Vec2{p0, p0.Add(t1.MulScalar(dist)), p3.Add(t2.MulScalar(dist)), p3})
+		return
+	}
+	u := chordLengthParams(d, first, last)
+	bz := generateBezier(d, first, last, u, t1, t2)
+	maxErr, split := bezierMaxError(d, first, last, bz, u)
+	if maxErr < errSq {
+		*bzs = append(*bzs, bz)
+		return
+	}
+	if maxErr < 4*errSq { // close: try improving the parameters first
+		for i := 0; i < PencilFitIters; i++ {
+			u = reparameterize(d, first, last, u, bz)
+			bz = generateBezier(d, first, last, u, t1, t2)
+			maxErr, split = bezierMaxError(d, first, last, bz, u)
+			if maxErr < errSq {
+				*bzs = append(*bzs, bz)
+				return
+			}
+		}
+	}
+	tc := d[split-1].Sub(d[split+1])
+	if tc.Length() == 0 {
+		tc = d[split-1].Sub(d[split])
+	}
+	tc = tc.Normal()
+	fitCubic(d, first, split, t1, tc, errSq, bzs)
+	fitCubic(d, split, last, tc.Negate(), t2, errSq, bzs)
+}
+
+// chordLengthParams returns the parameter of each of points first..last
+// of d along a curve through them, by the relative distance along the
+// polyline, from 0 to 1
+func chordLengthParams(d []mat32.Vec2, first, last int) []float32 {
+	u := make([]float32, last-first+1)
+	for i := first + 1; i <= last; i++ {
+		u[i-first] = u[i-first-1] + d[i].DistTo(d[i-1])
+	}
+	tot := u[len(u)-1]
+	for i := range u {
+		u[i] /= tot
+	}
+	return u
+}
+
+// generateBezier returns the bezier with the least squares error to
+// points first..last of d at parameters u, with control points along
+// given unit tangents from the ends
+func generateBezier(d []mat32.Vec2, first, last int, u []float32, t1, t2 mat32.Vec2) [4]mat32.Vec2 {
+	p0, p3 := d[first], d[last]
+	var c [2][2]float32
+	var x [2]float32
+	for i, t := range u {
+		b0, b1, b2, b3 := bernstein(t)
+		a1, a2 := t1.MulScalar(b1), t2.MulScalar(b2)
+		c[0][0] += a1.Dot(a1)
+		c[0][1] += a1.Dot(a2)
+		c[1][1] += a2.Dot(a2)
+		tmp := d[first+i].Sub(p0.MulScalar(b0 + b1).Add(p3.MulScalar(b2 + b3)))
+		x[0] += a1.Dot(tmp)
+		x[1] += a2.Dot(tmp)
+	}
+	c[1][0] = c[0][1]
+	det := c[0][0]*c[1][1] - c[1][0]*c[0][1]
+	var al, ar float32
+	if det != 0 {
+		al = (x[0]*c[1][1] - x[1]*c[0][1]) / det
+		ar = (c[0][0]*x[1] - c[1][0]*x[0]) / det
+	}
+	seg := p0.DistTo(p3)
+	if eps := 1.0e-6 * seg; al < eps || ar < eps { // fall back on a third of the distance
+		al = seg / 3
+		ar = al
+	}
+	return [4]mat32.Vec2{p0, p0.Add(t1.MulScalar(al)), p3.Add(t2.MulScalar(ar)), p3}
+}
+
+// reparameterize improves the parameters u of points first..last of d
+// on given bezier by a Newton-Raphson step toward the nearest point
+func reparameterize(d []mat32.Vec2, first, last int, u []float32, bz [4]mat32.Vec2) []float32 {
+	nu := make([]float32, len(u))
+	for i, t := range u {
+		p := d[first+i]
+		q := bezierPt(bz, t).Sub(p)
+		q1 := bezierDeriv(bz, t)
+		q2 := bezierDeriv2(bz, t)
+		den := q1.Dot(q1) + q.Dot(q2)
+		if den == 0 {
+			nu[i] = t
+			continue
+		}
+		nu[i] = t - q.Dot(q1)/den
+	}
+	return nu
+}
+
+// bezierMaxError returns the max squared distance of points first..last
+// of d from their parameters u on given bezier, and the index of the
+// point where it is
+func bezierMaxError(d []mat32.Vec2, first, last int, bz [4]mat32.Vec2, u []float32) (float32, int) {
+	split := (last + first + 1) / 2
+	var mx float32
+	for i := first + 1; i < last; i++ {
+		v := bezierPt(bz, u[i-first]).Sub(d[i])
+		dsq := v.Dot(v)
+		if dsq >= mx {
+			mx = dsq
+			split = i
+		}
+	}
+	return mx, split
+}
+
+// bernstein returns the cubic bernstein polynomials at t
+func bernstein(t float32) (b0, b1, b2, b3 float32) {
+	mt := 1 - t
+	return mt * mt * mt, 3 * t * mt * mt, 3 * t * t * mt, t * t * t
+}
+
+// bezierPt returns the point at t on given cubic bezier
+func bezierPt(bz [4]mat32.Vec2, t float32) mat32.Vec2 {
+	b0, b1, b2, b3 := bernstein(t)
+	return bz[0].MulScalar(b0).Add(bz[1].MulScalar(b1)).Add(bz[2].MulScalar(b2)).Add(bz[3].MulScalar(b3))
+}
+
+// bezierDeriv returns the first derivative at t of given cubic bezier
+func bezierDeriv(bz [4]mat32.Vec2, t float32) mat32.Vec2 {
+	mt := 1 - t
+	d0 := bz[1].Sub(bz[0]).MulScalar(3 * mt * mt)
+	d1 := bz[2].Sub(bz[1]).MulScalar(6 * mt * t)
+	d2 := bz[3].Sub(bz[2]).MulScalar(3 * t * t)
+	return d0.Add(d1).Add(d2)
+}
+
+// bezierDeriv2 returns the second derivative at t of given cubic bezier
+func bezierDeriv2(bz [4]mat32.Vec2, t float32) mat32.Vec2 {
+	a := bz[2].Sub(bz[1].MulScalar(2)).Add(bz[0])
+	b := bz[3].Sub(bz[2].MulScalar(2)).Add(bz[1])
+	return a.MulScalar(6 * (1 - t)).Add(b.MulScalar(6 * t))
+}
+
+// PencilDrag adds the mouse position to the points being drawn with the
+// PencilTool, starting from the start of the drag, and shows them as a line
+func (sv *SVGView) PencilDrag(win *gi.Window, start, pt image.Point) {
+	es := sv.EditState()
+	if len(es.PencilPts) == 0 {
+		es.PencilPts = append(es.PencilPts, mat32.NewVec2FmPoint(start))
+	}
+	p := mat32.NewVec2FmPoint(pt)
+	if p == es.PencilPts[len(es.PencilPts)-1] {
+		return
+	}
+	es.PencilPts = append(es.PencilPts, p)
+	sp := Sprite(win, SpPencil, SpUnk, 0, image.ZP)
+	DrawSpritePolyline(sp, es.PencilPts)
+	win.UpdateSig()
+}
+
+// PencilDone makes a new path from the points drawn with the PencilTool,
+// fit with smooth curves to within the PencilTol preference, at the end
+// of the drag
+func (sv *SVGView) PencilDone() {
+	win := sv.GridView.ParentWindow()
+	es := sv.EditState()
+	pts := es.PencilPts
+	es.PencilPts = nil
+	if win != nil {
+		updt := win.UpdateStart()
+		InactivateSprites(win, SpPencil)
+		win.UpdateEnd(updt)
+		win.UpdateSig()
+	}
+	dsc := gi.Prefs.LogicalDPIScale
+	if dsc <= 0 {
+		dsc = 1
+	}
+	bzs := FitCurve(pts, Prefs.PencilTol*dsc)
+	if len(bzs) == 0 {
+		return
+	}
+	xfi := sv.Pnt.Transform.Inverse()
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	loc := func(p mat32.Vec2) mat32.Vec2 {
+		return xfi.MulVec2AsPt(p.Sub(svoff))
+	}
+	var sb strings.Builder
+	st := loc(bzs[0][0])
+	fmt.Fprintf(&sb, "M %g,%g", st.X, st.Y)
+	for _, bz := range bzs {
+		c1, c2, ed := loc(bz[1]), loc(bz[2]), loc(bz[3])
+		fmt.Fprintf(&sb, " C %g,%g %g,%g %g,%g", c1.X, c1.Y, c2.X, c2.Y, ed.X, ed.Y)
+	}
+	sv.ManipStart("NewPencil", "")
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	nr := sv.NewEl(svg.KiT_Path).(*svg.Path)
+	nr.SetData(sb.String())
+	es.SelectAction(nr, mouse.SelectOne, pts[len(pts)-1].ToPoint())
+	sv.UpdateEnd(updt)
+	sv.ManipDone()
+	sv.GridView.SetStatus(fmt.Sprintf("Pencil: made a path with %d curves from %d points", len(bzs), len(pts)))
+}
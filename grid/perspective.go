@@ -0,0 +1,186 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// Perspective is a 1, 2 or 3 point perspective guide system: rays are
+// projected from each vanishing point at regular angles, and drawn in the
+// view as construction lines that points can snap to.  The vanishing
+// points can be moved by dragging.  These are view aids only, and are
+// never exported.
+type Perspective struct {
+
+	// vanishing points, in drawing coordinates -- none if perspective guides are off
+	VanishPts []mat32.Vec2
+
+	// number of rays projected from each vanishing point, evenly spaced around the full circle
+	Rays int `min:"4"`
+}
+
+// On returns true if the perspective guides are in use
+func (ps *Perspective) On() bool {
+	return len(ps.VanishPts) > 0
+}
+
+// RayAngle returns the angle in degrees between successive rays
+func (ps *Perspective) RayAngle() float32 {
+	if ps.Rays < 4 {
+		ps.Rays = 36
+	}
+	return 360 / float32(ps.Rays)
+}
+
+// NearestRay returns the ray from given vanishing point that is
+// closest to given point, in drawing coordinates, as a guide
+func (ps *Perspective) NearestRay(vp, pt mat32.Vec2) Guide {
+	inc := ps.RayAngle()
+	d := pt.Sub(vp)
+	ang := mat32.RadToDeg(mat32.Atan2(-d.Y, d.X))
+	ang = mat32.Round(ang/inc) * inc
+	return Guide{Anchor: vp, Angle: ang}
+}
+
+// String returns the metadata encoding of the perspective:
+// number of rays followed by x,y vanishing points, separated by semicolons
+func (ps *Perspective) String() string {
+	if !ps.On() {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d", ps.Rays))
+	for _, vp := range ps.VanishPts {
+		sb.WriteString(fmt.Sprintf(";%g,%g", vp.X, vp.Y))
+	}
+	return sb.String()
+}
+
+// FromString sets the perspective from the metadata encoding generated by String
+func (ps *Perspective) FromString(s string) {
+	ps.VanishPts = nil
+	ents := strings.Split(s, ";")
+	fmt.Sscanf(ents[0], "%d", &ps.Rays)
+	for _, ent := range ents[1:] {
+		var vp mat32.Vec2
+		if n, _ := fmt.Sscanf(ent, "%g,%g", &vp.X, &vp.Y); n != 2 {
+			continue
+		}
+		ps.VanishPts = append(ps.VanishPts, vp)
+	}
+}
+
+// PerspectiveColor is the color used for drawing perspective rays
+var PerspectiveColor = gist.Color{R: 255, G: 128, B: 0, A: 96}
+
+// RenderPerspective renders the perspective rays into the background
+func (sv *SVGView) RenderPerspective() {
+	es := sv.EditState()
+	ps := &es.Perspective
+	if !ps.On() {
+		return
+	}
+	rs := &sv.BgRender
+	pc := &rs.Paint
+	pc.StrokeStyle.SetColor(&PerspectiveColor)
+	pc.StrokeStyle.Width.Dots = 1 / sv.Scale
+	vb := sv.ViewBBox()
+	inc := ps.RayAngle()
+	for _, vp := range ps.VanishPts {
+		ext := vb.Size().Length() + vp.Sub(vb.Min.Add(vb.Max).MulScalar(.5)).Length()
+		for i := 0; i < ps.Rays; i++ {
+			gd := Guide{Anchor: vp, Angle: float32(i) * inc}
+			ed := vp.Add(gd.Dir().MulScalar(ext))
+			pc.DrawLine(rs, vp.X, vp.Y, ed.X, ed.Y)
+		}
+	}
+	pc.FillStrokeClear(rs)
+}
+
+// UpdatePerspectiveSprites updates the draggable sprites for the
+// perspective vanishing points
+func (sv *SVGView) UpdatePerspectiveSprites() {
+	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
+	InactivateSprites(win, SpVanishPt)
+	es := sv.EditState()
+	for i, vp := range es.Perspective.VanishPts {
+		idx := i
+		sp := SpriteConnectEvent(win, SpVanishPt, SpUnk, i, image.ZP, sv.This(), func(recv, send ki.Ki, sig int64, d any) {
+			ssvg := recv.Embed(KiT_SVGView).(*SVGView)
+			ssvg.VanishPtSpriteEvent(idx, oswin.EventType(sig), d)
+		})
+		SetSpritePos(sp, sv.DocToWin(vp).ToPoint())
+	}
+}
+
+// VanishPtSpriteEvent processes a mouse event on a vanishing point sprite,
+// moving the vanishing point while dragging
+func (sv *SVGView) VanishPtSpriteEvent(idx int, et oswin.EventType, d any) {
+	win := sv.GridView.ParentWindow()
+	es := sv.EditState()
+	switch et {
+	case oswin.MouseEvent:
+		me := d.(*mouse.Event)
+		me.SetProcessed()
+		if me.Action == mouse.Press {
+			win.SpriteDragging = SpriteName(SpVanishPt, SpUnk, idx)
+		} else if me.Action == mouse.Release {
+			es.Changed = true
+			sv.GridView.ChangeMade()
+		}
+	case oswin.MouseDragEvent:
+		me := d.(*mouse.DragEvent)
+		me.SetProcessed()
+		if idx >= len(es.Perspective.VanishPts) {
+			return
+		}
+		es.Perspective.VanishPts[idx] = sv.WinToDoc(mat32.NewVec2FmPoint(me.Where))
+		sp, _ := win.SpriteByName(SpriteName(SpVanishPt, SpUnk, idx))
+		SetSpritePos(sp, me.Where)
+		sv.RenderBg()
+		go sv.ManipUpdate()
+		win.UpdateSig()
+	}
+}
+
+///////////////////////////////////////////////////////////////////////
+//   Actions
+
+// SetPerspective sets up perspective guides with given number of
+// vanishing points (1, 2 or 3, or 0 to turn off), projecting given number
+// of rays from each.  Vanishing points are initially placed on a horizon
+// through the middle of the page (1 point: center, 2 point: left and right
+// edges), with the 3rd point below the page, and can be moved by dragging.
+func (gv *GridView) SetPerspective(points, rays int) {
+	es := &gv.EditState
+	sv := gv.SVG()
+	ps := &es.Perspective
+	ps.Rays = rays
+	ps.VanishPts = nil
+	vb := sv.ViewBox
+	ctr := vb.Min.Add(vb.Size.MulScalar(.5))
+	switch {
+	case points == 1:
+		ps.VanishPts = []mat32.Vec2{ctr}
+	case points >= 2:
+		ps.VanishPts = []mat32.Vec2{mat32.V2(vb.Min.X, ctr.Y), mat32.V2(vb.Min.X+vb.Size.X, ctr.Y)}
+		if points >= 3 {
+			ps.VanishPts = append(ps.VanishPts, mat32.V2(ctr.X, vb.Min.Y+1.5*vb.Size.Y))
+		}
+	}
+	gv.GuidesChanged()
+}
@@ -5,7 +5,9 @@
 package grid
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/kit"
@@ -18,7 +20,10 @@ type PhysSize struct {
 	// select a standard size -- this will set units and size
 	StdSize StdSizes
 
-	// for standard size, use first number as width, second as height
+	// select a user-defined standard size, from UserSizes in the preferences -- this overrides StdSize, and will set units and size
+	UserSize UserSizeName
+
+	// page is in portrait orientation (taller than wide) -- standard sizes are oriented accordingly
 	Portrait bool
 
 	// default units to use, e.g., in line widths etc
@@ -29,38 +34,118 @@ type PhysSize struct {
 
 	// grid spacing, in units of ViewBox size
 	Grid float32
+
+	// origin of the grid, in units of ViewBox size -- grid lines and grid snapping are aligned to this point
+	GridOffset mat32.Vec2
+
+	// keep the aspect ratio of the drawing constant when changing the width or height -- the other dimension is adjusted automatically.  Selecting a standard size clears the lock.
+	LockAspect bool
+
+	// size as of the last update, for LockAspect
+	prvSize mat32.Vec2
+
+	// standard size as of the last update, to detect selection of a new standard size
+	prvStd StdSizes
+
+	// user standard size as of the last update, to detect selection of a new user size
+	prvUser UserSizeName
 }
 
 var KiT_PhysSize = kit.Types.AddType(&PhysSize{}, nil)
 
 func (ps *PhysSize) Defaults() {
 	ps.StdSize = Img1280x720
+	ps.UserSize = ""
 	ps.Units = units.Px
 	ps.Size.Set(1280, 720)
 	ps.Grid = 12
+	ps.SetPrev()
 }
 
 func (ps *PhysSize) Update() {
-	if ps.StdSize != CustomSize {
+	switch {
+	case ps.UserSize != ps.prvUser && ps.UserSize != "":
+		ps.StdSize = CustomSize
+		ps.SetToStdSize()
+	case ps.StdSize != ps.prvStd && ps.StdSize != CustomSize:
+		ps.UserSize = ""
+		ps.SetToStdSize()
+	case ps.LockAspect:
+		ps.KeepAspect()
+		ps.StdSize, ps.UserSize = MatchStdSize(ps.Size.X, ps.Size.Y, ps.Units)
+	case ps.StdSize != CustomSize || ps.UserSize != "":
 		ps.SetToStdSize()
 	}
+	ps.SetPrev()
+}
+
+// SetPrev records the current size as the previous size, for LockAspect
+func (ps *PhysSize) SetPrev() {
+	ps.prvSize = ps.Size
+	ps.prvStd = ps.StdSize
+	ps.prvUser = ps.UserSize
+}
+
+// KeepAspect adjusts the height if the width has been changed, or the
+// width if the height has been changed, to keep the aspect ratio of the
+// previous size
+func (ps *PhysSize) KeepAspect() {
+	if ps.prvSize.X <= 0 || ps.prvSize.Y <= 0 {
+		return
+	}
+	switch {
+	case ps.Size.X != ps.prvSize.X:
+		ps.Size.Y = ps.Size.X * ps.prvSize.Y / ps.prvSize.X
+	case ps.Size.Y != ps.prvSize.Y:
+		ps.Size.X = ps.Size.Y * ps.prvSize.X / ps.prvSize.Y
+	}
 }
 
 // SetStdSize sets drawing to a standard size
 func (ps *PhysSize) SetStdSize(std StdSizes) error {
 	ps.StdSize = std
+	ps.UserSize = ""
+	return ps.SetToStdSize()
+}
+
+// SetUserSize sets drawing to a user-defined standard size,
+// from UserSizes in the preferences
+func (ps *PhysSize) SetUserSize(name UserSizeName) error {
+	ps.StdSize = CustomSize
+	ps.UserSize = name
 	return ps.SetToStdSize()
 }
 
-// SetToStdSize sets drawing to the current standard size value
+// ErrStdSizeNotFound is returned (wrapped) by SetToStdSize when the
+// standard size is not in StdSizesMap, e.g., CustomSize, or the user
+// size is not in the UserSizes preferences
+var ErrStdSizeNotFound = errors.New("not found in StdSizesMap")
+
+// SetToStdSize sets drawing to the current standard size value: the
+// UserSize if set, and otherwise the StdSize.
+// Clears LockAspect, as the standard size defines both dimensions.
 func (ps *PhysSize) SetToStdSize() error {
-	ssv, has := StdSizesMap[ps.StdSize]
-	if !has {
-		return fmt.Errorf("StdSize: %v not found in StdSizesMap")
+	var ssv *StdSizeVals
+	var has bool
+	if ps.UserSize != "" {
+		ssv, has = Prefs.UserSizes[string(ps.UserSize)]
+		if !has || ssv == nil {
+			return fmt.Errorf("UserSize: %v: %w", ps.UserSize, ErrStdSizeNotFound)
+		}
+	} else {
+		ssv, has = StdSizesMap[ps.StdSize]
+		if !has {
+			return fmt.Errorf("StdSize: %v: %w", ps.StdSize, ErrStdSizeNotFound)
+		}
 	}
 	ps.Units = ssv.Units
 	ps.Size.X = ssv.X
 	ps.Size.Y = ssv.Y
+	if ps.Portrait != (ps.Size.Y > ps.Size.X) {
+		ps.Size.X, ps.Size.Y = ps.Size.Y, ps.Size.X
+	}
+	ps.LockAspect = false
+	ps.SetPrev()
 	return nil
 }
 
@@ -70,7 +155,18 @@ func (ps *PhysSize) SetFromSVG(sv *SVGView) {
 	ps.Units = sv.PhysWidth.Un
 	ps.Size.Y = sv.PhysHeight.Val
 	ps.Grid = sv.Grid
-	ps.StdSize = MatchStdSize(ps.Size.X, ps.Size.Y, ps.Units)
+	ps.GridOffset = sv.GridOffset
+	ps.Portrait = ps.Size.Y > ps.Size.X
+	ps.StdSize, ps.UserSize = MatchStdSize(ps.Size.X, ps.Size.Y, ps.Units)
+	ps.SetPrev()
+}
+
+// Rotate swaps the width and height, switching between portrait
+// and landscape orientation
+func (ps *PhysSize) Rotate() {
+	ps.Size.X, ps.Size.Y = ps.Size.Y, ps.Size.X
+	ps.Portrait = !ps.Portrait
+	ps.SetPrev()
 }
 
 // SetToSVG sets svg from us
@@ -79,20 +175,31 @@ func (ps *PhysSize) SetToSVG(sv *SVGView) {
 	sv.PhysHeight.Set(ps.Size.Y, ps.Units)
 	sv.ViewBox.Size = ps.Size
 	sv.Grid = ps.Grid
+	sv.GridOffset = ps.GridOffset
 }
 
 // StdSizes are standard physical drawing sizes
 type StdSizes int
 
-func MatchStdSize(wd, ht float32, un units.Units) StdSizes {
+// MatchStdSize returns the standard size matching given size, in either
+// orientation.  If none of the StdSizesMap sizes match, it returns
+// CustomSize and the name of the matching user size from UserSizes in
+// the preferences, or "" if none matches either.
+func MatchStdSize(wd, ht float32, un units.Units) (StdSizes, UserSizeName) {
 	trgl := StdSizeVals{Units: un, X: wd, Y: ht}
 	trgp := StdSizeVals{Units: un, X: ht, Y: wd}
 	for k, v := range StdSizesMap {
 		if *v == trgl || *v == trgp {
-			return k
+			return k, ""
+		}
+	}
+	for _, nm := range Prefs.UserSizeNames() {
+		v := Prefs.UserSizes[nm]
+		if *v == trgl || *v == trgp {
+			return CustomSize, UserSizeName(nm)
 		}
 	}
-	return CustomSize
+	return CustomSize, ""
 }
 
 const (
@@ -174,9 +281,33 @@ func (ev *StdSizes) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON
 
 // StdSizeVals are values for standard sizes
 type StdSizeVals struct {
+
+	// units of the size
 	Units units.Units
-	X     float32
-	Y     float32
+
+	// width, in Units
+	X float32
+
+	// height, in Units
+	Y float32
+}
+
+// UserSizeName is the name of a user-defined standard size, in UserSizes
+// in the preferences -- it has an associated ValueView for selecting from
+// the available names
+type UserSizeName string
+
+// UserSizeNames returns the names of the UserSizes, in sorted order,
+// skipping any without values
+func (pf *Preferences) UserSizeNames() []string {
+	nms := make([]string, 0, len(pf.UserSizes))
+	for nm, v := range pf.UserSizes {
+		if v != nil {
+			nms = append(nms, nm)
+		}
+	}
+	sort.Strings(nms)
+	return nms
 }
 
 // StdSizesMap is the map of size values for each standard size
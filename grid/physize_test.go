@@ -0,0 +1,66 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goki/gi/units"
+)
+
+func TestSetToStdSize(t *testing.T) {
+	Prefs.UserSizes = map[string]*StdSizeVals{
+		"Card": &StdSizeVals{units.Mm, 85, 55},
+		"Nil":  nil,
+	}
+	defer func() { Prefs.UserSizes = make(map[string]*StdSizeVals) }()
+
+	tests := []struct {
+		name     string
+		std      StdSizes
+		user     UserSizeName
+		portrait bool
+		err      string // substring of the error, "" if none
+		want     StdSizeVals
+	}{
+		{"std", Img1280x720, "", false, "", StdSizeVals{units.Px, 1280, 720}},
+		{"std portrait", Img1280x720, "", true, "", StdSizeVals{units.Px, 720, 1280}},
+		{"user", CustomSize, "Card", false, "", StdSizeVals{units.Mm, 85, 55}},
+		{"custom", CustomSize, "", false, "StdSize: CustomSize", StdSizeVals{}},
+		{"bad std", StdSizes(-1), "", false, "StdSize: ", StdSizeVals{}},
+		{"missing user", CustomSize, "Poster", false, "UserSize: Poster", StdSizeVals{}},
+		{"nil user", CustomSize, "Nil", false, "UserSize: Nil", StdSizeVals{}},
+	}
+	for _, tt := range tests {
+		ps := &PhysSize{StdSize: tt.std, UserSize: tt.user, Portrait: tt.portrait, LockAspect: true}
+		err := ps.SetToStdSize()
+		if tt.err != "" {
+			if !errors.Is(err, ErrStdSizeNotFound) {
+				t.Errorf("%s: error %v is not ErrStdSizeNotFound", tt.name, err)
+				continue
+			}
+			if !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: error %q does not contain %q", tt.name, err, tt.err)
+			}
+			if !ps.LockAspect {
+				t.Errorf("%s: LockAspect cleared on error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		got := StdSizeVals{ps.Units, ps.Size.X, ps.Size.Y}
+		if got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if ps.LockAspect {
+			t.Errorf("%s: LockAspect not cleared", tt.name)
+		}
+	}
+}
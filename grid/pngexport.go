@@ -0,0 +1,160 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// SizePx returns the size in px (at 96 per inch)
+func (ps *PhysSize) SizePx() mat32.Vec2 {
+	return ps.Size.MulScalar(units.PxPerInch / UnitsPerInch(ps.Units))
+}
+
+// PNGExportFunc returns the ExportFunc for the .png format, with given
+// width or height of the image in pixels, or both 0 for the physical size
+// in px.  The drawing is rendered natively, scaled uniformly to fit the
+// image and centered in it, over the background color from the color
+// preferences, which is transparent if its alpha is 0.  The grid, guides
+// and page border are drawn into the image according to Prefs.Export.
+func PNGExportFunc(width, height int) ExportFunc {
+	return func(sv *SVGView, sz *PhysSize, w io.Writer) error {
+		img, err := sv.RenderImage(sz, width, height)
+		if err != nil {
+			return err
+		}
+		return png.Encode(w, img)
+	}
+}
+
+// PNGImageSize returns the size of the image in pixels for given view,
+// of given physical size, for given width and height (see PNGExportFunc)
+func (sv *SVGView) PNGImageSize(sz *PhysSize, width, height int) image.Point {
+	vb := sv.ViewBox.Size
+	switch {
+	case width > 0 && height > 0:
+		return image.Point{width, height}
+	case width > 0:
+		return image.Point{width, int(mat32.Round(float32(width) * vb.Y / vb.X))}
+	case height > 0:
+		return image.Point{int(mat32.Round(float32(height) * vb.X / vb.Y)), height}
+	}
+	px := sz.SizePx()
+	return image.Point{int(mat32.Round(px.X)), int(mat32.Round(px.Y))}
+}
+
+// RenderImage renders the drawing in given view, of given physical size,
+// to a new image with given width and height in pixels (see PNGExportFunc)
+func (sv *SVGView) RenderImage(sz *PhysSize, width, height int) (*image.RGBA, error) {
+	return sv.RenderImageOverlay(sz, width, height, &Prefs.Export, sv.Grid)
+}
+
+// RenderImageOverlay renders the drawing as RenderImage, with the grid,
+// guides and page border drawn according to given export prefs, with
+// given grid spacing
+func (sv *SVGView) RenderImageOverlay(sz *PhysSize, width, height int, ep *ExportPrefs, grid float32) (*image.RGBA, error) {
+	vb := sv.ViewBox.Size
+	if vb.X <= 0 || vb.Y <= 0 {
+		return nil, errors.New("export: the drawing has an empty ViewBox")
+	}
+	isz := sv.PNGImageSize(sz, width, height)
+	if isz.X <= 0 || isz.Y <= 0 {
+		return nil, errors.New("export: the image size is empty")
+	}
+	isf := mat32.NewVec2FmPoint(isz)
+	sc := mat32.Min(isf.X/vb.X, isf.Y/vb.Y)
+	b, err := sv.ExportSVGBytes()
+	if err != nil {
+		return nil, err
+	}
+	vbb := mat32.Box2{Min: sv.ViewBox.Min, Max: sv.ViewBox.Min.Add(vb)}
+	b = InsertSVGBehind(b, sv.ExportOverlaySVG(ep, vbb, grid, 1/sc)) // lines one pixel wide
+
+	tmp := &svg.SVG{}
+	tmp.InitName(tmp, "export-png")
+	err = tmp.ReadXML(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	tmp.FullRender2DTree() // init, style and layout, at the ViewBox size
+
+	fsz := isf.DivScalar(sc) // ViewBox fitting the image, centered
+	tmp.ViewBox.Min = sv.ViewBox.Min.Sub(fsz.Sub(vb).MulScalar(.5))
+	tmp.ViewBox.Size = fsz
+	tmp.Norm = true
+	tmp.Fill = false
+	tmp.Resize(isz)
+	tmp.VpBBox = tmp.Pixels.Bounds()
+	tmp.WinBBox = tmp.VpBBox
+	draw.Draw(tmp.Pixels, tmp.VpBBox, &image.Uniform{sv.Colors().Background}, image.ZP, draw.Src)
+	tmp.Render2D()
+	img := tmp.Pixels
+	tmp.Destroy()
+	return img, nil
+}
+
+// ExportPNG exports the drawing to given PNG image file, rendering it
+// natively at given width and height in pixels (see PNGExportFunc).
+// Renders full current page, or the export extent in Prefs.Export.
+// The output does not depend on the current zoom level of the view.
+func (gv *GridView) ExportPNG(filename gi.FileName, width, height int) error {
+	return gv.ExportWith(filename, PNGExportFunc(width, height))
+}
+
+// PNGExportParams are the parameters for exporting the drawing
+// as a PNG image
+type PNGExportParams struct {
+
+	// image file to export to
+	Filename gi.FileName `ext:".png"`
+
+	// width of the image in pixels -- 0 to preserve the aspect ratio given the Height
+	Width int
+
+	// height of the image in pixels -- 0 to preserve the aspect ratio given the Width
+	Height int
+}
+
+// Defaults sets the file name to that of the drawing with a .png
+// suffix, and the size to the physical size of the drawing in px
+func (pp *PNGExportParams) Defaults(gv *GridView) {
+	fext := filepath.Ext(string(gv.Filename))
+	pp.Filename = gi.FileName(strings.TrimSuffix(string(gv.Filename), fext) + ".png")
+	sz := &PhysSize{}
+	sz.SetFromSVG(gv.SVG())
+	isz := gv.SVG().PNGImageSize(sz, 0, 0)
+	pp.Width, pp.Height = isz.X, isz.Y
+}
+
+// PromptExportPNG prompts for the file and size in pixels of a PNG
+// image to export the drawing to, defaulting to the physical size in px
+func (gv *GridView) PromptExportPNG() {
+	pp := &PNGExportParams{}
+	pp.Defaults(gv)
+	giv.StructViewDialog(gv.Viewport, pp, giv.DlgOpts{Title: "Export PNG", Prompt: "Export the drawing as a PNG image of given size in pixels -- the drawing is scaled to fit", Ok: true, Cancel: true}, gv.This(),
+		func(recv, send ki.Ki, sig int64, d any) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			err := gv.ExportPNG(pp.Filename, pp.Width, pp.Height)
+			if err != nil {
+				gi.PromptDialog(gv.Viewport, gi.DlgOpts{Title: "Export PNG", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
+			}
+		})
+}
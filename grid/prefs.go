@@ -6,10 +6,13 @@ package grid
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/girl"
@@ -29,6 +32,9 @@ type Preferences struct {
 	// default physical size, when app is started without opening a file
 	Size PhysSize
 
+	// user-defined standard drawing sizes, by name, in addition to the built-in StdSizes -- selected with UserSize in the drawing size
+	UserSizes map[string]*StdSizeVals
+
 	// active color preferences
 	Colors ColorPrefs
 
@@ -47,9 +53,27 @@ type Preferences struct {
 	// default line styles
 	LineStyle girl.Paint
 
+	// how far in logical screen pixels, scaled by the display DPI, the smooth curve made by the pencil tool may be from the points drawn -- larger values simplify the path to fewer nodes
+	PencilTol float32 `min:"0.5" step:"0.5"`
+
+	// how far in logical screen pixels, scaled by the display DPI, Simplify Path may move a path from where it was, at the current zoom -- larger values remove more nodes
+	SimplifyTol float32 `min:"0.1" step:"0.5"`
+
 	// turns on the grid display
 	GridDisp bool
 
+	// grid spacing presets, in drawing units, for quickly switching the grid spacing from the Grid menu or with the [ and ] keys -- defaults are derived from the Size Grid spacing
+	GridPresets []float32
+
+	// debug overlay: show the bounding box and id label of every element in the drawing
+	ShowBBoxes bool
+
+	// render a checkerboard pattern behind the background color, to show where it is transparent -- only in the view, not in exports
+	Checkerboard bool
+
+	// show rulers along the top and left edges of the drawing view, in the units of the drawing, with a marker following the mouse
+	Rulers bool
+
 	// snap positions and sizes to underlying grid
 	SnapGrid bool
 
@@ -59,9 +83,60 @@ type Preferences struct {
 	// snap node movements to align with guides
 	SnapNodes bool
 
-	// number of screen pixels around target point (in either direction) to snap
+	// snap positions and sizes to line up with the edges and center of the page, when aligning with other elements
+	SnapPage bool
+
+	// number of logical screen pixels around target point (in either direction) to snap, scaled by the display DPI as the selection handles are -- this is the same on screen regardless of the zoom level, so comparisons in drawing coordinates must divide it by the view scale (see SnapTolPx, SVGView.SnapTolDoc)
 	SnapTol int `min:"1"`
 
+	// increment in degrees that rotation snaps to when rotating the selection by dragging with Alt -- also hold Shift to rotate freely -- 0 = no snapping
+	SnapRotateIncr float32 `min:"0" max:"180" step:"2.5"`
+
+	// while dragging, highlight the snap tolerance zone around the closest candidate align points, to show why a snap did or did not happen
+	ShowSnapZones bool
+
+	// play a subtle click when a snap engages while dragging, where the platform has a way to play one (see SnapFeedbackFunc)
+	SnapSound bool
+
+	// named combinations of the snap settings above, for quickly switching between them with the Snap chooser in the select toolbar
+	SnapProfiles []*SnapProfile
+
+	// name of the active snap profile -- cleared when an individual snap setting is changed in the toolbar
+	SnapProfile string
+
+	// named object styles (fill, stroke, width, dash, markers and effects) for applying to the selection with one click from the Style Presets bar in the Paint tab
+	StylePresets []*StylePreset
+
+	// the region covered by exports, and what is drawn into raster exports in addition to the drawing
+	Export ExportPrefs `view:"inline"`
+
+	// DXF export and import settings
+	DXF DXFPrefs `view:"inline"`
+
+	// padding in screen pixels left around the page when zooming to fit the page or contents, so selection and node handles at the page edges remain reachable instead of being clipped at the edge of the view
+	CanvasPad int `min:"0"`
+
+	// padding around the page when zooming to fit, as a fraction of the view size -- the larger of this and CanvasPad is used
+	CanvasPadFrac float32 `min:"0" max:"0.25" step:"0.01"`
+
+	// trackpad mode: scrolling pans the view, and Ctrl+scroll zooms -- otherwise scrolling zooms, as with a mouse wheel
+	ScrollPan bool
+
+	// invert the direction of scrolling for panning and zooming
+	InvertScroll bool
+
+	// number of decimal places shown for coordinates and sizes in toolbar fields and status messages -- display only: values are stored at full precision
+	DisplayPrec int `min:"0" max:"6"`
+
+	// when opening a drawing, apply the transforms of its elements and groups to the element geometry and remove them, so positions, sizes and snapping match what is shown -- for files from other tools that nest many transforms.  Off by default, so files are saved as they were opened
+	FlattenOnOpen bool
+
+	// save files with element attributes and style properties in sorted order, so re-saving an unchanged drawing gives identical output -- minimizes diffs under version control.  The whole drawing is still written anew: the original formatting of unchanged elements is not preserved.  Off by default, as the first save reorders the attributes of existing files
+	StableSave bool
+
+	// with StableSave, round the decimal numbers in the geometry and numeric style properties of the saved file to this many decimal places, so float rounding noise from edits does not show up in diffs -- -1 to save numbers as is
+	StablePrec int `min:"-1"`
+
 	// named-split config in use for configuring the splitters
 	SplitName SplitName
 
@@ -78,6 +153,7 @@ func (pf *Preferences) Defaults() {
 	pf.Size.Defaults()
 	pf.Colors.Defaults()
 	pf.ColorSchemes = DefaultColorSchemes()
+	pf.UserSizes = make(map[string]*StdSizeVals)
 	pf.ShapeStyle.Defaults()
 	pf.ShapeStyle.FontStyle.Family = "Arial"
 	pf.ShapeStyle.FontStyle.Size.Set(12, units.Px)
@@ -100,16 +176,128 @@ func (pf *Preferences) Defaults() {
 	pf.LineStyle.StrokeStyle.On = true
 	pf.LineStyle.FillStyle.On = false
 	pf.GridDisp = true
+	pf.Rulers = true
+	g := pf.Size.Grid
+	pf.GridPresets = []float32{g / 4, g / 2, g, 2 * g, 4 * g}
+	pf.PencilTol = 4
+	pf.SimplifyTol = 1
 	pf.SnapTol = 3
+	pf.SnapRotateIncr = 15
 	pf.SnapGrid = true
 	pf.SnapGuide = true
 	pf.SnapNodes = true
+	pf.SnapPage = true
+	pf.SnapProfiles = DefaultSnapProfiles()
+	pf.SnapProfile = "Precise"
+	pf.StablePrec = -1
+	pf.DisplayPrec = 2
+	pf.Export.Clip = true
+	pf.DXF.Defaults()
+	pf.CanvasPad = 16
 	home := gi.Prefs.User.HomeDir
 	pf.EnvVars = map[string]string{
 		"PATH": home + "/bin:" + home + "/go/bin:/usr/local/bin:/opt/homebrew/bin:/opt/homebrew/shbin:/Library/TeX/texbin:/usr/bin:/bin:/usr/sbin:/sbin",
 	}
 }
 
+// Validate clamps values to their valid ranges and fills in missing
+// values from defaults, so that a hand-edited or corrupted prefs file
+// cannot put the app into a bad state
+func (pf *Preferences) Validate() {
+	if pf.Size.Size.X <= 0 || pf.Size.Size.Y <= 0 {
+		grid := pf.Size.Grid
+		pf.Size.Defaults()
+		pf.Size.Grid = grid
+	}
+	if pf.Size.Grid <= 0 {
+		pf.Size.Grid = 12
+	}
+	if pf.UserSizes == nil {
+		pf.UserSizes = make(map[string]*StdSizeVals)
+	}
+	for nm, v := range pf.UserSizes {
+		if nm == "" || v == nil || v.X <= 0 || v.Y <= 0 {
+			delete(pf.UserSizes, nm)
+		}
+	}
+	if _, has := pf.UserSizes[string(pf.Size.UserSize)]; !has {
+		pf.Size.UserSize = ""
+	}
+	if pf.PencilTol < 0.5 {
+		pf.PencilTol = 0.5
+	}
+	if pf.SimplifyTol < 0.1 {
+		pf.SimplifyTol = 0.1
+	}
+	if pf.SnapTol < 1 {
+		pf.SnapTol = 1
+	}
+	if pf.SnapRotateIncr < 0 {
+		pf.SnapRotateIncr = 0
+	}
+	if pf.SnapRotateIncr > 180 {
+		pf.SnapRotateIncr = 180
+	}
+	if pf.DisplayPrec < 0 {
+		pf.DisplayPrec = 0
+	}
+	if pf.DisplayPrec > 6 {
+		pf.DisplayPrec = 6
+	}
+	if pf.Export.Margin < 0 {
+		pf.Export.Margin = 0
+	}
+	if pf.Export.Extent < 0 || pf.Export.Extent >= ExportExtentsN {
+		pf.Export.Extent = ExportPage
+	}
+	if pf.SnapProfiles == nil {
+		pf.SnapProfiles = DefaultSnapProfiles()
+	}
+	if pf.SnapProfile != "" && pf.SnapProfileByName(pf.SnapProfile) == nil {
+		pf.SnapProfile = ""
+	}
+	if pf.DXF.FlattenTol <= 0 {
+		pf.DXF.Defaults()
+	}
+	if pf.DXF.LineWidth <= 0 {
+		pf.DXF.LineWidth = 0.25
+	}
+	if pf.CanvasPad < 0 {
+		pf.CanvasPad = 0
+	}
+	if pf.CanvasPadFrac < 0 {
+		pf.CanvasPadFrac = 0
+	}
+	if pf.CanvasPadFrac > 0.25 {
+		pf.CanvasPadFrac = 0.25
+	}
+	if pf.StablePrec < -1 {
+		pf.StablePrec = -1
+	}
+	gp := pf.GridPresets[:0]
+	for _, g := range pf.GridPresets {
+		if g > 0 {
+			gp = append(gp, g)
+		}
+	}
+	pf.GridPresets = gp
+	if len(pf.GridPresets) == 0 {
+		g := pf.Size.Grid
+		pf.GridPresets = []float32{g / 4, g / 2, g, 2 * g, 4 * g}
+	}
+	if pf.ColorSchemes == nil {
+		pf.ColorSchemes = DefaultColorSchemes()
+	}
+	for nm, cs := range DefaultColorSchemes() {
+		if pf.ColorSchemes[nm] == nil {
+			pf.ColorSchemes[nm] = cs
+		}
+	}
+	if pf.EnvVars == nil {
+		pf.EnvVars = make(map[string]string)
+	}
+}
+
 func (pf *Preferences) Update() {
 	pf.Size.Update()
 }
@@ -134,15 +322,26 @@ func InitPrefs() {
 // PrefsFileName is the name of the preferences file in GoGi prefs directory
 var PrefsFileName = "grid_prefs.json"
 
-// Open preferences from GoGi standard prefs directory, and applies them
+// Open preferences from GoGi standard prefs directory, and applies them.
+// If there is no prefs file yet (first run), the current (default) values
+// are applied and saved to create it, and no error is returned.
 func (pf *Preferences) Open() error {
 	pdir := oswin.TheApp.AppDataDir()
 	pnm := filepath.Join(pdir, PrefsFileName)
 	b, err := ioutil.ReadFile(pnm)
 	if err != nil {
+		if os.IsNotExist(err) {
+			pf.ApplyEnvVars()
+			return pf.Save()
+		}
+		log.Println(err)
 		return err
 	}
 	err = json.Unmarshal(b, pf)
+	if err != nil {
+		log.Printf("Grid Preferences: error reading %s: %v\n", pnm, err)
+	}
+	pf.Validate()
 	AvailSplits.OpenPrefs()
 	pf.ApplyEnvVars()
 	pf.Changed = false
@@ -158,7 +357,7 @@ func (pf *Preferences) Save() error {
 		log.Println(err)
 		return err
 	}
-	err = ioutil.WriteFile(pnm, b, 0644)
+	err = WriteFileAtomic(pnm, b, 0644)
 	if err != nil {
 		log.Println(err)
 	}
@@ -167,10 +366,35 @@ func (pf *Preferences) Save() error {
 	return err
 }
 
-// ApplyEnvVars applies environment variables set in EnvVars
+// DisplayFormat returns the format string for displaying coordinate and
+// size values, according to DisplayPrec
+func (pf *Preferences) DisplayFormat() string {
+	return fmt.Sprintf("%%.%df", pf.DisplayPrec)
+}
+
+// FmtVal returns given coordinate or size value formatted for display,
+// according to DisplayPrec
+func (pf *Preferences) FmtVal(val float32) string {
+	return fmt.Sprintf(pf.DisplayFormat(), val)
+}
+
+// SetDisplayPrec sets the display format of given spinbox, which shows
+// a coordinate or size value, according to DisplayPrec
+func (pf *Preferences) SetDisplayPrec(sb *gi.SpinBox) {
+	sb.Format = pf.DisplayFormat()
+}
+
+// ApplyEnvVars applies environment variables set in EnvVars, with
+// references to other variables and a leading ~ in their values expanded
+// (see ExpandEnvValue), in order of their names
 func (pf *Preferences) ApplyEnvVars() {
-	for k, v := range pf.EnvVars {
-		os.Setenv(k, v)
+	keys := make([]string, 0, len(pf.EnvVars))
+	for k := range pf.EnvVars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		os.Setenv(k, ExpandEnvValue(pf.EnvVars[k]))
 	}
 }
 
@@ -227,6 +451,26 @@ func (pf *Preferences) UpdateAll() {
 	for _, w := range gi.AllWindows {
 		w.FullReRender()
 	}
+	pf.ApplyAllColors()
+}
+
+// ApplyColors pushes the current background, border and grid colors
+// into the rendering of given view, re-rendering the cached background
+// and then the whole view, so that color changes show immediately
+func (pf *Preferences) ApplyColors(sv *SVGView) {
+	if sv == nil || sv.Pixels == nil {
+		return
+	}
+	sv.RenderBg()
+	sv.SetFullReRender()
+	sv.UpdateView(true)
+}
+
+// ApplyAllColors applies the colors to the views of all open drawings
+func (pf *Preferences) ApplyAllColors() {
+	for _, gv := range OpenGridViews() {
+		pf.ApplyColors(gv.SVG())
+	}
 }
 
 // PreferencesProps define the Toolbar and MenuBar for StructView, e.g., giv.PrefsView
@@ -300,8 +544,11 @@ var GridViewResetRecents = "<i>Reset Recents</i>"
 // GridViewEditRecents defines a string that is added as an item to the recents menu
 var GridViewEditRecents = "<i>Edit Recents...</i>"
 
-// SavedPathsExtras are the reset and edit items we add to the recents menu
-var SavedPathsExtras = []string{gi.MenuTextSeparator, GridViewResetRecents, GridViewEditRecents}
+// GridViewClearMissingRecents defines a string that is added as an item to the recents menu
+var GridViewClearMissingRecents = "<i>Clear Missing</i>"
+
+// SavedPathsExtras are the reset, clear and edit items we add to the recents menu
+var SavedPathsExtras = []string{gi.MenuTextSeparator, GridViewResetRecents, GridViewClearMissingRecents, GridViewEditRecents}
 
 // SavePaths saves the active SavedPaths to prefs dir
 func SavePaths() {
@@ -313,16 +560,39 @@ func SavePaths() {
 	gi.StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
 }
 
-// OpenPaths loads the active SavedPaths from prefs dir
+// OpenPaths loads the active SavedPaths from prefs dir.  A missing file
+// (first run) is not an error.  Files that no longer exist are kept, as
+// they may be on a drive that is not mounted (see SavedPathsRemoveMissing).
 func OpenPaths() {
 	// remove to be sure we don't have duplicate extras
 	gi.StringsRemoveExtras((*[]string)(&SavedPaths), SavedPathsExtras)
 	pdir := oswin.TheApp.AppDataDir()
 	pnm := filepath.Join(pdir, SavedPathsFileName)
-	SavedPaths.OpenJSON(pnm)
+	if err := SavedPaths.OpenJSON(pnm); err != nil && !os.IsNotExist(err) {
+		log.Println(err)
+	}
 	gi.StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
 }
 
+// SavedPathsRemoveMissing removes the files that no longer exist from
+// SavedPaths, returning the number removed.  Files on a drive or network
+// share that is not mounted look the same as missing ones, so this is
+// only done when the user asks for it.
+func SavedPathsRemoveMissing() int {
+	gi.StringsRemoveExtras((*[]string)(&SavedPaths), SavedPathsExtras)
+	var keep gi.FilePaths
+	for _, pth := range SavedPaths {
+		if _, err := os.Stat(ExpandPath(pth)); os.IsNotExist(err) {
+			continue
+		}
+		keep = append(keep, pth)
+	}
+	nrm := len(SavedPaths) - len(keep)
+	SavedPaths = keep
+	gi.StringsAddExtras((*[]string)(&SavedPaths), SavedPathsExtras)
+	return nrm
+}
+
 /////////////////////////////////////////////////////////////////////////////////
 //   ColorPrefs
 
@@ -364,6 +634,23 @@ func DefaultColorSchemes() map[string]*ColorPrefs {
 	return cs
 }
 
+// String returns the metadata encoding of the colors:
+// background, border and grid hex colors separated by semicolons
+func (pf *ColorPrefs) String() string {
+	return pf.Background.HexString() + ";" + pf.Border.HexString() + ";" + pf.Grid.HexString()
+}
+
+// FromString sets the colors from the metadata encoding generated by String
+func (pf *ColorPrefs) FromString(s string) {
+	cs := strings.Split(s, ";")
+	if len(cs) != 3 {
+		return
+	}
+	pf.Background.SetString(cs[0], nil)
+	pf.Border.SetString(cs[1], nil)
+	pf.Grid.SetString(cs[2], nil)
+}
+
 // OpenJSON opens colors from a JSON-formatted file.
 func (pf *ColorPrefs) OpenJSON(filename gi.FileName) error {
 	b, err := ioutil.ReadFile(string(filename))
@@ -382,7 +669,7 @@ func (pf *ColorPrefs) SaveJSON(filename gi.FileName) error {
 		log.Println(err) // unlikely
 		return err
 	}
-	err = ioutil.WriteFile(string(filename), b, 0644)
+	err = WriteFileAtomic(string(filename), b, 0644)
 	if err != nil {
 		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
 		log.Println(err)
@@ -0,0 +1,226 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/ki/kit"
+)
+
+// StylePresetProps are the style properties captured by a StylePreset
+var StylePresetProps = []string{"fill", "fill-opacity", "fill-rule", "stroke", "stroke-opacity", "stroke-width", "stroke-dasharray", "stroke-linecap", "stroke-linejoin", "stroke-miterlimit", "opacity", "marker-start", "marker-mid", "marker-end", "filter"}
+
+// StylePreset is a named object style: the fill, stroke, width, dash,
+// marker and effect (filter) properties of an element, for applying
+// the same full style to other elements with one click
+type StylePreset struct {
+
+	// name of the preset
+	Name string
+
+	// style property values, by property name -- properties not set here are removed when applying the preset
+	Props map[string]string
+}
+
+// Label satisfies the Labeler interface
+func (sp StylePreset) Label() string {
+	return sp.Name
+}
+
+// FromNode sets the preset style from given element -- for a group,
+// the first element within it
+func (sp *StylePreset) FromNode(sii svg.NodeSVG) {
+	if gp, isgp := sii.(*svg.Group); isgp {
+		for _, kid := range gp.Kids {
+			if ksi, ok := kid.(svg.NodeSVG); ok {
+				sp.FromNode(ksi)
+				return
+			}
+		}
+	}
+	sp.Props = make(map[string]string)
+	for _, p := range StylePresetProps {
+		if v := kit.ToString(sii.Prop(p)); v != "" {
+			sp.Props[p] = v
+		}
+	}
+}
+
+// ToNode applies the preset style to given element -- for a group, to
+// all the elements within it.  References to gradients, markers and
+// filters (url(#id)) that are not defined in given drawing, e.g., for
+// presets saved from another drawing, are skipped.
+func (sp *StylePreset) ToNode(sv *SVGView, sii svg.NodeSVG) {
+	if gp, isgp := sii.(*svg.Group); isgp {
+		for _, kid := range gp.Kids {
+			if ksi, ok := kid.(svg.NodeSVG); ok {
+				sp.ToNode(sv, ksi)
+			}
+		}
+		return
+	}
+	for _, p := range StylePresetProps {
+		v, has := sp.Props[p]
+		if !has {
+			sii.DeleteProp(p)
+			continue
+		}
+		if strings.HasPrefix(v, "url(") && sv.FindDefByName(svg.NameFromURL(v)) == nil {
+			continue
+		}
+		sii.SetProp(p, v)
+	}
+}
+
+// StylePresetByName returns the style preset with given name, and nil if none
+func (pf *Preferences) StylePresetByName(name string) *StylePreset {
+	for _, sp := range pf.StylePresets {
+		if sp.Name == name {
+			return sp
+		}
+	}
+	return nil
+}
+
+// ApplyStylePreset applies the style preset of given name to the
+// selected elements
+func (gv *GridView) ApplyStylePreset(name string) error {
+	sp := Prefs.StylePresetByName(name)
+	if sp == nil {
+		return fmt.Errorf("grid: style preset named: %s not found", name)
+	}
+	es := &gv.EditState
+	if !es.HasSelected() {
+		return nil
+	}
+	sv := gv.SVG()
+	sv.UndoSave("ApplyStylePreset", name)
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	for itm := range es.Selected {
+		sp.ToNode(sv, itm)
+	}
+	sv.UpdateEnd(updt)
+	gv.UpdateTabs()
+	gv.ChangeMade()
+	gv.SetStatus("Applied style preset: " + name)
+	return nil
+}
+
+// SaveStylePreset saves the style of the first selected element as the
+// style preset of given name, replacing any existing preset of that
+// name, and saves the preferences
+func (gv *GridView) SaveStylePreset(name string) error {
+	if name == "" {
+		return nil
+	}
+	fsel := gv.EditState.FirstSelectedNode()
+	if fsel == nil {
+		return fmt.Errorf("grid: select an element to save its style as a preset")
+	}
+	sp := Prefs.StylePresetByName(name)
+	if sp == nil {
+		sp = &StylePreset{Name: name}
+		Prefs.StylePresets = append(Prefs.StylePresets, sp)
+	}
+	sp.FromNode(fsel)
+	Prefs.Save()
+	gv.UpdateStylePresets()
+	gv.SetStatus("Saved style preset: " + name)
+	return nil
+}
+
+// UpdateStylePreset updates the style preset of given name from the
+// style of the first selected element, and saves the preferences
+func (gv *GridView) UpdateStylePreset(name string) error {
+	if Prefs.StylePresetByName(name) == nil {
+		return fmt.Errorf("grid: style preset named: %s not found", name)
+	}
+	return gv.SaveStylePreset(name)
+}
+
+// DeleteStylePreset deletes the style preset of given name,
+// and saves the preferences
+func (gv *GridView) DeleteStylePreset(name string) {
+	for i, sp := range Prefs.StylePresets {
+		if sp.Name == name {
+			Prefs.StylePresets = append(Prefs.StylePresets[:i], Prefs.StylePresets[i+1:]...)
+			Prefs.Save()
+			gv.UpdateStylePresets()
+			gv.SetStatus("Deleted style preset: " + name)
+			return
+		}
+	}
+}
+
+// StylePresetsToolbar returns the style presets bar in the Paint tab
+func (pv *PaintView) StylePresetsToolbar() *gi.Toolbar {
+	return pv.ChildByName("presets-tb", 9).(*gi.Toolbar)
+}
+
+// UpdateStylePresets updates the style presets bar in the Paint tab
+// from the presets in preferences
+func (gv *GridView) UpdateStylePresets() {
+	pv, ok := gv.Tab("Paint").(*PaintView)
+	if !ok {
+		return
+	}
+	pv.ConfigStylePresets()
+}
+
+// ConfigStylePresets configures the style presets bar, with a button
+// for applying each preset to the selection, and a menu for saving,
+// updating and deleting presets
+func (pv *PaintView) ConfigStylePresets() {
+	gv := pv.GridView
+	tb := pv.StylePresetsToolbar()
+	updt := tb.UpdateStart()
+	tb.DeleteChildren(ki.DestroyKids)
+	for _, sp := range Prefs.StylePresets {
+		nm := sp.Name
+		tb.AddAction(gi.ActOpts{Label: nm, Tooltip: "apply style preset " + nm + " to the selected elements", UpdateFunc: gv.SelectedEnableFunc},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				grr.ApplyStylePreset(nm)
+			})
+	}
+	pmen := tb.AddAction(gi.ActOpts{Label: "Presets", Icon: "gear", Tooltip: "save the style of the selected element as a preset, or update or delete presets"}, nil, nil)
+	pmen.MakeMenuFunc = func(obj ki.Ki, m *gi.Menu) {
+		*m = gi.Menu{}
+		m.AddAction(gi.ActOpts{Label: "Save Selection Style As...", UpdateFunc: gv.SelectedEnableFunc},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				giv.CallMethod(grr, "SaveStylePreset", grr.ViewportSafe())
+			})
+		if len(Prefs.StylePresets) == 0 {
+			return
+		}
+		m.AddSeparator("sep-update")
+		for _, sp := range Prefs.StylePresets {
+			nm := sp.Name
+			m.AddAction(gi.ActOpts{Label: "Update " + nm + " From Selection", UpdateFunc: gv.SelectedEnableFunc},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					grr.UpdateStylePreset(nm)
+				})
+		}
+		m.AddSeparator("sep-delete")
+		for _, sp := range Prefs.StylePresets {
+			nm := sp.Name
+			m.AddAction(gi.ActOpts{Label: "Delete " + nm},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					grr.DeleteStylePreset(nm)
+				})
+		}
+	}
+	tb.UpdateEnd(updt)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+)
+
+// RenameParams are the parameters for renaming the ids of the
+// selected items using a pattern
+type RenameParams struct {
+
+	// name pattern: {n} is replaced with an auto-incrementing number, e.g., btn-{n} -- if no {n} is present, the number is appended
+	Pattern string `width:"30"`
+
+	// starting number
+	Start int
+}
+
+// Defaults sets default parameter values
+func (rp *RenameParams) Defaults() {
+	rp.Pattern = "item-{n}"
+	rp.Start = 1
+}
+
+// Name returns the name for given number according to the pattern
+func (rp *RenameParams) Name(n int) string {
+	ns := fmt.Sprintf("%d", n)
+	if !strings.Contains(rp.Pattern, "{n}") {
+		return rp.Pattern + ns
+	}
+	return strings.ReplaceAll(rp.Pattern, "{n}", ns)
+}
+
+// SelRenameNames returns the current and new names for the selected
+// items, in order of selection, according to given params.  Numbers
+// that would give a name already used elsewhere in the drawing are
+// skipped, so all names remain unique.
+func (gv *GridView) SelRenameNames(rp *RenameParams) (sel []svg.NodeSVG, names []string) {
+	es := &gv.EditState
+	sv := gv.SVG()
+	sel = es.SelectedList(false)
+	used := make(map[string]bool)
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if sii, ok := k.(svg.NodeSVG); ok && es.IsSelected(sii) {
+			return ki.Continue
+		}
+		used[k.Name()] = true
+		return ki.Continue
+	})
+	n := rp.Start
+	for range sel {
+		nm := rp.Name(n)
+		for used[nm] {
+			n++
+			nm = rp.Name(n)
+		}
+		used[nm] = true
+		names = append(names, nm)
+		n++
+	}
+	return
+}
+
+// SelRename renames the ids of the selected items according to given
+// params (see SelRenameNames), as a single undoable action
+func (gv *GridView) SelRename(rp *RenameParams) {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		return
+	}
+	sv := gv.SVG()
+	sel, names := gv.SelRenameNames(rp)
+	sv.UndoSave("Rename", rp.Pattern)
+	for i, sn := range sel {
+		sn.SetName(names[i])
+	}
+	gv.UpdateAll()
+	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("Rename: renamed %d items", len(sel)))
+}
+
+// PromptSelRename prompts for a name pattern for renaming the ids of the
+// selected items, and shows a preview of the resulting names before renaming
+func (gv *GridView) PromptSelRename() {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		gv.SetStatus("Rename: nothing selected")
+		return
+	}
+	rp := &RenameParams{}
+	rp.Defaults()
+	giv.StructViewDialog(gv.Viewport, rp, giv.DlgOpts{Title: "Rename Selected", Prompt: "Rename the ids of the selected items using a pattern, with {n} replaced by an auto-incrementing number", Ok: true, Cancel: true}, gv.This(),
+		func(recv, send ki.Ki, sig int64, d any) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			sel, names := gv.SelRenameNames(rp)
+			var sb strings.Builder
+			mx := len(sel)
+			if mx > 20 {
+				mx = 20
+			}
+			for i := 0; i < mx; i++ {
+				sb.WriteString(fmt.Sprintf("%s &rarr; %s<br>\n", sel[i].Name(), names[i]))
+			}
+			if len(sel) > mx {
+				sb.WriteString(fmt.Sprintf("... and %d more<br>\n", len(sel)-mx))
+			}
+			gi.PromptDialog(gv.Viewport, gi.DlgOpts{Title: "Rename Selected",
+				Prompt: fmt.Sprintf("Rename %d items:<br>\n%s", len(sel), sb.String())},
+				gi.AddOk, gi.AddCancel, gv.This(), func(recv, send ki.Ki, sig int64, d any) {
+					if sig != int64(gi.DialogAccepted) {
+						return
+					}
+					gv.SelRename(rp)
+				})
+		})
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+)
+
+// ReplaceColorParams are the parameters for replacing one color with
+// another across the entire drawing
+type ReplaceColorParams struct {
+
+	// source color to find
+	From gist.Color
+
+	// target color to replace it with
+	To gist.Color
+
+	// tolerance for matching colors: max difference per R,G,B,A component (0 = exact match)
+	Tol int `min:"0" max:"255"`
+
+	// replace fill colors
+	Fill bool
+
+	// replace stroke colors
+	Stroke bool
+
+	// also replace matching colors in gradient stops
+	Gradients bool
+}
+
+// Defaults sets default parameter values
+func (rp *ReplaceColorParams) Defaults() {
+	rp.Fill = true
+	rp.Stroke = true
+}
+
+// ColorsMatch returns true if the two colors are within given
+// tolerance of each other on each of the R,G,B,A components
+func ColorsMatch(a, b gist.Color, tol int) bool {
+	df := func(x, y uint8) int {
+		d := int(x) - int(y)
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+	return df(a.R, b.R) <= tol && df(a.G, b.G) <= tol && df(a.B, b.B) <= tol && df(a.A, b.A) <= tol
+}
+
+// matchProps returns the color props (fill, stroke) of given node
+// that match the replace params
+func (rp *ReplaceColorParams) matchProps(sii svg.NodeSVG) []string {
+	var props []string
+	pc := &sii.AsSVGNode().Pnt
+	if rp.Fill && pc.FillStyle.On && pc.FillStyle.Color.Source == gist.SolidColor {
+		if ColorsMatch(pc.FillStyle.Color.Color, rp.From, rp.Tol) {
+			props = append(props, "fill")
+		}
+	}
+	if rp.Stroke && pc.StrokeStyle.On && pc.StrokeStyle.Color.Source == gist.SolidColor {
+		if ColorsMatch(pc.StrokeStyle.Color.Color, rp.From, rp.Tol) {
+			props = append(props, "stroke")
+		}
+	}
+	return props
+}
+
+// matchStop returns true if the gradient stop matches -- stop alpha is
+// ignored, as opacity is set separately
+func (rp *ReplaceColorParams) matchStop(gs *GradStop) bool {
+	sc := gs.Color
+	sc.A = rp.From.A
+	return ColorsMatch(sc, rp.From, rp.Tol)
+}
+
+// ReplaceColorFunc calls given function on each leaf element in the
+// drawing that has fill and / or stroke color matching the params,
+// with the list of matching props
+func (gv *GridView) ReplaceColorFunc(rp *ReplaceColorParams, fun func(sii svg.NodeSVG, props []string)) {
+	sv := gv.SVG()
+	sv.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if k.This() == sv.This() {
+			return ki.Continue
+		}
+		if k.This() == sv.Defs.This() || NodeIsMetaData(k) {
+			return ki.Break
+		}
+		sii, issv := k.(svg.NodeSVG)
+		if !issv {
+			return ki.Break
+		}
+		if _, isgp := sii.(*svg.Group); isgp {
+			return ki.Continue
+		}
+		props := rp.matchProps(sii)
+		if len(props) > 0 {
+			fun(sii, props)
+		}
+		return ki.Continue
+	})
+}
+
+// ReplaceColorCount returns the number of elements, and gradient stops
+// (if included) that would be affected by ReplaceColor with given params
+func (gv *GridView) ReplaceColorCount(rp *ReplaceColorParams) (nels, nstops int) {
+	gv.ReplaceColorFunc(rp, func(sii svg.NodeSVG, props []string) {
+		nels++
+	})
+	if !rp.Gradients {
+		return
+	}
+	for _, gr := range gv.EditState.Gradients {
+		for _, gs := range gr.Stops {
+			if rp.matchStop(gs) {
+				nstops++
+			}
+		}
+	}
+	return
+}
+
+// ReplaceColor replaces all fill and / or stroke colors matching the From
+// color (within tolerance) with the To color, across the entire drawing,
+// optionally including gradient stops.  It is a single undoable action.
+// Returns the number of elements and gradient stops updated.
+func (gv *GridView) ReplaceColor(rp *ReplaceColorParams) (nels, nstops int) {
+	es := &gv.EditState
+	sv := gv.SVG()
+	sv.UndoSave("ReplaceColor", rp.From.HexString()+" -> "+rp.To.HexString())
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	tc := rp.To.HexString()
+	gv.ReplaceColorFunc(rp, func(sii svg.NodeSVG, props []string) {
+		for _, prop := range props {
+			sii.DeleteProp(NamedColorProp(prop)) // no longer references named color
+			sii.AsSVGNode().SetColorProps(prop, tc)
+		}
+		gv.UpdateMarkerColors(sii)
+		nels++
+	})
+	if rp.Gradients {
+		for _, gr := range es.Gradients {
+			for _, gs := range gr.Stops {
+				if rp.matchStop(gs) {
+					gs.Color = rp.To
+					gs.Color.A = 255
+					nstops++
+				}
+			}
+		}
+		if nstops > 0 {
+			sv.UpdateGradients(es.Gradients)
+		}
+	}
+	sv.UpdateEnd(updt)
+	gv.UpdateTabs()
+	gv.ChangeMade()
+	return
+}
+
+// PromptReplaceColor prompts for the colors to find and replace across the
+// entire drawing, showing the number of affected items before replacing
+func (gv *GridView) PromptReplaceColor() {
+	rp := &ReplaceColorParams{}
+	rp.Defaults()
+	if fs := gv.EditState.FirstSelectedNode(); fs != nil {
+		rp.From = fs.AsSVGNode().Pnt.FillStyle.Color.Color
+	}
+	giv.StructViewDialog(gv.Viewport, rp, giv.DlgOpts{Title: "Replace Color", Prompt: "Replace all matching fill / stroke colors in the drawing", Ok: true, Cancel: true}, gv.This(),
+		func(recv, send ki.Ki, sig int64, d any) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			nels, nstops := gv.ReplaceColorCount(rp)
+			if nels == 0 && nstops == 0 {
+				gv.SetStatus("Replace Color: no matching colors found")
+				return
+			}
+			gi.PromptDialog(gv.Viewport, gi.DlgOpts{Title: "Replace Color",
+				Prompt: fmt.Sprintf("Replace %s with %s in %d elements and %d gradient stops?", rp.From.HexString(), rp.To.HexString(), nels, nstops)},
+				gi.AddOk, gi.AddCancel, gv.This(), func(recv, send ki.Ki, sig int64, d any) {
+					if sig != int64(gi.DialogAccepted) {
+						return
+					}
+					nels, nstops := gv.ReplaceColor(rp)
+					gv.SetStatus(fmt.Sprintf("Replace Color: updated %d elements and %d gradient stops", nels, nstops))
+				})
+		})
+}
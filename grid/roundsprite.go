@@ -0,0 +1,187 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"image"
+	"math"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/oswin/mouse"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// RoundCornerNode returns the element whose corners are rounded with
+// the round corners handle: the first selected path or rect, with the
+// node tool, unless it is locked
+func (sv *SVGView) RoundCornerNode() svg.NodeSVG {
+	es := sv.EditState()
+	if es.Tool != NodeTool {
+		return nil
+	}
+	switch sii := es.FirstSelectedNode().(type) {
+	case *svg.Path, *svg.Rect:
+		if NodeIsLocked(sii) {
+			return nil
+		}
+		return sii
+	}
+	return nil
+}
+
+// RoundCorner returns the corner of given path or rect that the round
+// corners handle is on, in its local coordinates: the corner point, the
+// unit vector along the side that the handle moves along, the tangent of
+// half the interior angle, which converts the distance of the handle from
+// the corner into the radius, and the max distance of the handle.
+// For a rect this is its top-left corner, and for a path its first corner
+// (of the selected nodes if any), in the original path data during live
+// rounding (see PathOpStart).  Returns false if it has no such corner.
+func (sv *SVGView) RoundCorner(sii svg.NodeSVG) (p, v mat32.Vec2, th2, maxd float32, ok bool) {
+	es := sv.EditState()
+	switch nd := sii.(type) {
+	case *svg.Rect:
+		if nd.Size.X <= 0 || nd.Size.Y <= 0 {
+			return
+		}
+		return nd.Pos, mat32.V2(1, 0), 1, 0.5 * mat32.Min(nd.Size.X, nd.Size.Y), true
+	case *svg.Path:
+		data := nd.Data
+		sel := es.PathSel
+		if es.PathOp == "RoundCorners" {
+			if od, has := es.PathOpOrig[nd]; has {
+				data = od
+			}
+			sel = es.PathOpNodes
+		}
+		if nd != es.ActivePath {
+			sel = nil
+		}
+		polys, pok := PathPolys(data)
+		if !pok {
+			return
+		}
+		for _, pl := range polys {
+			np := len(pl.Pts)
+			if np < 3 && pl.Closed || np < 2 {
+				continue
+			}
+			for i, pt := range pl.Pts {
+				if !pl.Closed && (i == 0 || i == np-1) {
+					continue
+				}
+				if _, has := sel[pl.Nodes[i]]; len(sel) > 0 && !has {
+					continue
+				}
+				_, cv, cmaxd, cos, cok := cornerGeom(pl.Pts[(i+np-1)%np], pt, pl.Pts[(i+1)%np])
+				if !cok {
+					continue
+				}
+				th2 = float32(math.Tan(math.Acos(float64(cos)) / 2))
+				return pt, cv, th2, cmaxd, true
+			}
+		}
+	}
+	return
+}
+
+// RoundRadius returns the current corner radius of given path or rect:
+// that of the rect, or that of the live rounding of the path
+func (sv *SVGView) RoundRadius(sii svg.NodeSVG) float32 {
+	if rc, ok := sii.(*svg.Rect); ok {
+		return rc.Radius.X
+	}
+	es := sv.EditState()
+	if es.PathOp == "RoundCorners" {
+		return es.RoundRadius
+	}
+	return 0
+}
+
+// RoundHandleOff returns the offset of the round corners handle of given
+// element along the side v of its corner, in local coordinates, so that
+// it is one handle size beyond the point where the rounding arc starts,
+// and does not cover the node at the corner when there is no rounding
+func (sv *SVGView) RoundHandleOff(sii svg.NodeSVG, v mat32.Vec2) float32 {
+	_, sz := HandleSpriteSize(1)
+	wl := sii.AsSVGNode().ParTransform(true).MulVec2AsVec(v).Length()
+	if wl == 0 {
+		return 0
+	}
+	return float32(sz.X) / wl
+}
+
+// RoundHandleWinPt returns the window coordinates of the round corners
+// handle of given path or rect: on the side of its corner (see
+// RoundCorner), just beyond the point where the rounding arc starts
+func (sv *SVGView) RoundHandleWinPt(sii svg.NodeSVG) (mat32.Vec2, bool) {
+	p, v, th2, maxd, ok := sv.RoundCorner(sii)
+	if !ok {
+		return mat32.Vec2{}, false
+	}
+	t := mat32.Min(sv.RoundRadius(sii)/th2, maxd) + sv.RoundHandleOff(sii, v)
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	return sii.AsSVGNode().ParTransform(true).MulVec2AsPt(p.Add(v.MulScalar(t))).Add(svoff), true
+}
+
+// UpdateRoundSprite updates the draggable sprite for rounding the
+// corners of the selected path or rect with the node tool
+func (sv *SVGView) UpdateRoundSprite(win *gi.Window) {
+	InactivateSprites(win, SpRoundCorner)
+	sii := sv.RoundCornerNode()
+	if sii == nil {
+		return
+	}
+	wp, ok := sv.RoundHandleWinPt(sii)
+	if !ok {
+		return
+	}
+	sp := SpriteConnectEvent(win, SpRoundCorner, SpUnk, 0, image.ZP, sv.This(), func(recv, send ki.Ki, sig int64, d any) {
+		ssvg := recv.Embed(KiT_SVGView).(*SVGView)
+		ssvg.RoundSpriteEvent(oswin.EventType(sig), d)
+	})
+	SetSpritePos(sp, wp.ToPoint())
+}
+
+// RoundSpriteEvent processes a mouse event on the round corners sprite,
+// rounding the corners of the selected paths and rects live while dragging
+func (sv *SVGView) RoundSpriteEvent(et oswin.EventType, d any) {
+	win := sv.GridView.ParentWindow()
+	es := sv.EditState()
+	es.SelNoDrag = false
+	switch et {
+	case oswin.MouseEvent:
+		me := d.(*mouse.Event)
+		me.SetProcessed()
+		if me.Action == mouse.Press {
+			win.SpriteDragging = SpriteName(SpRoundCorner, SpUnk, 0)
+		}
+	case oswin.MouseDragEvent:
+		me := d.(*mouse.DragEvent)
+		me.SetProcessed()
+		sv.RoundDrag(mat32.NewVec2FmPoint(me.Where))
+	}
+}
+
+// RoundDrag rounds the corners of the selected paths and rects with the
+// radius given by dragging the round corners handle to given window
+// position, projected onto the side of the corner that it moves along
+func (sv *SVGView) RoundDrag(wpt mat32.Vec2) {
+	sii := sv.RoundCornerNode()
+	if sii == nil {
+		return
+	}
+	p, v, th2, maxd, ok := sv.RoundCorner(sii)
+	if !ok {
+		return
+	}
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	lpt := sii.AsSVGNode().ParTransform(true).Inverse().MulVec2AsPt(wpt.Sub(svoff))
+	t := mat32.Min(mat32.Max(lpt.Sub(p).Dot(v)-sv.RoundHandleOff(sii, v), 0), maxd)
+	sv.GridView.SelRoundCorners(t * th2)
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/units"
+	"github.com/goki/ki/ints"
+	"github.com/goki/mat32"
+)
+
+var (
+	// RulerSize is the thickness of the rulers, in logical screen pixels
+	RulerSize = float32(16)
+
+	// RulerLabelMin is the minimum spacing of the labeled ticks on the
+	// rulers, in logical screen pixels
+	RulerLabelMin = float32(60)
+
+	// RulerTickMin is the minimum spacing of the smallest ticks on the
+	// rulers, in logical screen pixels
+	RulerTickMin = float32(5)
+
+	RulerBgColor   = gist.Color{240, 240, 240, 255}
+	RulerTickColor = gist.Color{80, 80, 80, 255}
+	RulerMarkColor = gist.Color{220, 0, 0, 255}
+)
+
+// RulerSpriteSize returns the thickness of the rulers in screen pixels
+func RulerSpriteSize() int {
+	return ints.MaxInt(int(mat32.Ceil(gi.Prefs.LogicalDPIScale*RulerSize)), 10)
+}
+
+// RulerSteps returns the spacing of the labeled major ticks and of the
+// minor ticks between them, in the units of the ruler, for given number
+// of screen pixels per unit.  Major ticks are at 1, 2 or 5 times a power
+// of 10, so the labels are round numbers at any zoom level.
+func RulerSteps(ppu float32) (major, minor float32) {
+	lmin := RulerLabelMin * gi.Prefs.LogicalDPIScale
+	tmin := RulerTickMin * gi.Prefs.LogicalDPIScale
+	major = mat32.Pow(10, mat32.Floor(mat32.Log10(lmin/ppu)))
+	for _, m := range []float32{1, 2, 5, 10} {
+		if major*m*ppu >= lmin {
+			major *= m
+			break
+		}
+	}
+	minor = major
+	for _, d := range []float32{10, 5, 2} {
+		if major/d*ppu >= tmin {
+			minor = major / d
+			break
+		}
+	}
+	return
+}
+
+// UpdateRulerSprites updates the rulers along the top and left edges of
+// the view, if Prefs.Rulers is on.  The rulers are only re-rendered when
+// the view size, zoom, pan or units have changed.
+func (sv *SVGView) UpdateRulerSprites() {
+	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
+	bb := sv.WinBBox
+	rsz := RulerSpriteSize()
+	if !Prefs.Rulers || bb.Dx() <= rsz || bb.Dy() <= rsz {
+		InactivateSprites(win, SpRuler)
+		InactivateSprites(win, SpRulerMark)
+		return
+	}
+	hsp := Sprite(win, SpRuler, SpBBoxUpC, 0, image.ZP)
+	sv.DrawRuler(hsp, true, bb.Min, image.Point{bb.Dx(), rsz})
+	vsp := Sprite(win, SpRuler, SpBBoxLfM, 0, image.ZP)
+	sv.DrawRuler(vsp, false, image.Point{bb.Min.X, bb.Min.Y + rsz}, image.Point{rsz, bb.Dy() - rsz})
+	sv.UpdateRulerMarks(win, sv.rulerPos)
+}
+
+// DrawRuler renders a horizontal or vertical ruler of given size into the
+// sprite, and places it at given window position.  Ticks and labels are in
+// the physical units of the drawing (e.g., mm), at the current zoom and pan.
+func (sv *SVGView) DrawRuler(sp *gi.Sprite, horiz bool, pos, sz image.Point) {
+	sp.Geom.Pos = pos
+	upu := sv.UnitsPerDoc()
+	key := fmt.Sprintf("%v %v %v %v %v", pos, sz, sv.Scale, sv.Trans, upu)
+	if ck, has := sp.Props["grid-ruler"]; has && ck.(string) == key && sp.Pixels != nil {
+		return
+	}
+	sp.Props.Set("grid-ruler", key)
+	sp.SetSize(sz)
+	ibd := sp.Pixels.Bounds()
+	draw.Draw(sp.Pixels, ibd, &image.Uniform{RulerBgColor}, image.ZP, draw.Src)
+
+	rs := &girl.State{}
+	rs.Init(sz.X, sz.Y, sp.Pixels)
+	rs.PushBounds(ibd)
+	pc := &rs.Paint
+	pc.Defaults()
+	pc.FontStyle.Size.Set(9, units.Px)
+	pc.FontStyle.Color = RulerTickColor
+	pc.UnContext.Defaults()
+	pc.ToDots()
+	girl.OpenFont(&pc.FontStyle, &pc.UnContext)
+	pc.StrokeStyle.SetColor(&RulerTickColor)
+	pc.StrokeStyle.Width.Dots = 1
+	pc.FillStyle.SetColor(nil)
+
+	ppu := sv.Scale / upu // screen pixels per unit
+	major, minor := RulerSteps(ppu)
+	nper := int(mat32.Round(major / minor))
+	st := sv.WinToDoc(mat32.NewVec2FmPoint(pos)).MulScalar(upu)
+	start, ln, thk := st.X, float32(sz.X), float32(sz.Y)
+	if !horiz {
+		start, ln, thk = st.Y, float32(sz.Y), float32(sz.X)
+	}
+	type label struct {
+		px  float32
+		val float32
+	}
+	var lbls []label
+	for i := int(mat32.Floor(start / minor)); ; i++ {
+		px := mat32.Floor((float32(i)*minor-start)*ppu) + .5
+		if px > ln {
+			break
+		}
+		if px < 0 {
+			continue
+		}
+		tl := thk / 4
+		switch {
+		case i%nper == 0:
+			tl = thk
+			lbls = append(lbls, label{px, float32(i/nper) * major})
+		case nper%2 == 0 && i%(nper/2) == 0:
+			tl = thk / 2
+		}
+		if horiz {
+			pc.DrawLine(rs, px, thk, px, thk-tl)
+		} else {
+			pc.DrawLine(rs, thk, px, thk-tl, px)
+		}
+	}
+	if horiz {
+		pc.DrawLine(rs, 0, thk-.5, ln, thk-.5)
+	} else {
+		pc.DrawLine(rs, thk-.5, 0, thk-.5, ln)
+	}
+	pc.Stroke(rs)
+
+	prec := ints.MaxInt(int(-mat32.Floor(mat32.Log10(major))), 0)
+	for _, lb := range lbls {
+		tr := &girl.Text{}
+		lbl := fmt.Sprintf("%.*f", prec, lb.val)
+		if horiz {
+			tr.SetString(lbl, &pc.FontStyle, &pc.UnContext, &pc.TextStyle, true, 0, 1)
+			tr.Render(rs, mat32.Vec2{lb.px + 2, 0})
+		} else {
+			tr.SetStringRot90(lbl, &pc.FontStyle, &pc.UnContext, &pc.TextStyle, true, 1)
+			tr.Render(rs, mat32.Vec2{1, lb.px + 2})
+		}
+	}
+	rs.PopBounds()
+}
+
+// UpdateRulerMarks moves the marks on the rulers to given mouse position
+// in window coordinates, hiding them when it is outside of the view
+func (sv *SVGView) UpdateRulerMarks(win *gi.Window, pos image.Point) {
+	if win == nil {
+		return
+	}
+	sv.rulerPos = pos
+	rsz := RulerSpriteSize()
+	bb := sv.WinBBox
+	if !Prefs.Rulers || !pos.In(bb) {
+		InactivateSprites(win, SpRulerMark)
+		return
+	}
+	hsp := Sprite(win, SpRulerMark, SpBBoxUpC, 0, image.ZP)
+	hsp.Geom.Pos = image.Point{pos.X - hsp.Geom.Size.X/2, bb.Min.Y}
+	vsp := Sprite(win, SpRulerMark, SpBBoxLfM, 0, image.ZP)
+	vsp.Geom.Pos = image.Point{bb.Min.X, pos.Y - vsp.Geom.Size.Y/2}
+	if pos.X < bb.Min.X+rsz {
+		win.InactivateSprite(hsp.Name) // under the vertical ruler
+	}
+	if pos.Y < bb.Min.Y+rsz {
+		win.InactivateSprite(vsp.Name)
+	}
+}
+
+// DrawSpriteRulerMark renders the mouse position mark on a ruler: a line
+// across the horizontal ruler (SpBBoxUpC) or the vertical one (SpBBoxLfM)
+func DrawSpriteRulerMark(sp *gi.Sprite, subtyp Sprites) {
+	rsz := RulerSpriteSize()
+	wd := ints.MaxInt(int(mat32.Round(gi.Prefs.LogicalDPIScale)), 1)
+	ssz := image.Point{wd, rsz}
+	if subtyp == SpBBoxLfM {
+		ssz = image.Point{rsz, wd}
+	}
+	if !sp.SetSize(ssz) { // already set
+		return
+	}
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{RulerMarkColor}, image.ZP, draw.Src)
+}
+
+// ToggleRulers toggles the rulers along the top and left edges of the view
+func (gv *GridView) ToggleRulers() {
+	Prefs.Rulers = !Prefs.Rulers
+	sv := gv.SVG()
+	win := gv.ParentWindow()
+	updt := win.UpdateStart()
+	sv.UpdateRulerSprites()
+	win.UpdateEnd(updt)
+	win.UpdateSig()
+}
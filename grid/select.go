@@ -7,9 +7,11 @@ package grid
 import (
 	"fmt"
 	"image"
+	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
+	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/key"
 	"github.com/goki/gi/oswin/mouse"
@@ -39,6 +41,7 @@ func (gv *GridView) ConfigSelectToolbar() {
 	grs.ButtonSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 		if sig == int64(gi.ButtonToggled) {
 			Prefs.SnapGrid = grs.IsChecked()
+			gv.SnapChanged()
 		}
 	})
 
@@ -49,8 +52,28 @@ func (gv *GridView) ConfigSelectToolbar() {
 	gis.ButtonSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 		if sig == int64(gi.ButtonToggled) {
 			Prefs.SnapGuide = gis.IsChecked()
+			gv.SnapChanged()
 		}
 	})
+
+	spa := tb.AddAction(gi.ActOpts{Name: "snap-profile", Label: Prefs.SnapProfileLabel(), Icon: "gear", Tooltip: "switch among the named snap setting profiles in preferences, or save the current snap settings as a profile"}, nil, nil)
+	spa.MakeMenuFunc = func(obj ki.Ki, m *gi.Menu) {
+		*m = gi.Menu{}
+		for _, sp := range Prefs.SnapProfiles {
+			nm := sp.Name
+			m.AddAction(gi.ActOpts{Label: nm},
+				gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+					grr := recv.Embed(KiT_GridView).(*GridView)
+					grr.SetSnapProfile(nm)
+				}).SetSelectedState(nm == Prefs.SnapProfile)
+		}
+		m.AddSeparator("sep-save")
+		m.AddAction(gi.ActOpts{Label: "Save Current As..."},
+			gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+				grr := recv.Embed(KiT_GridView).(*GridView)
+				giv.CallMethod(grr, "SaveSnapProfile", grr.ViewportSafe())
+			})
+	}
 	gi.NewSeparator(tb, "sep-snap")
 
 	tb.AddAction(gi.ActOpts{Icon: "sel-group", Tooltip: "Ctrl+G: Group items together", UpdateFunc: gv.SelectedEnableFunc},
@@ -65,6 +88,18 @@ func (gv *GridView) ConfigSelectToolbar() {
 			grr.SelUnGroup()
 		})
 
+	tb.AddAction(gi.ActOpts{Icon: "sel-lock", Tooltip: "Lock selection: locked items cannot be selected in the drawing or moved", UpdateFunc: gv.SelectedEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.LockSelected()
+		})
+
+	tb.AddAction(gi.ActOpts{Icon: "sel-unlock", Tooltip: "Unlock selection -- select locked items in the tree", UpdateFunc: gv.LockedEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.UnlockSelected()
+		})
+
 	gi.NewSeparator(tb, "sep-group")
 
 	tb.AddAction(gi.ActOpts{Icon: "sel-rotate-left", Tooltip: "Ctrl-[: rotate selection 90deg counter-clockwise", UpdateFunc: gv.SelectedEnableFunc},
@@ -87,33 +122,39 @@ func (gv *GridView) ConfigSelectToolbar() {
 			grr := recv.Embed(KiT_GridView).(*GridView)
 			grr.SelFlipVert()
 		})
+	tb.AddAction(gi.ActOpts{Label: "Transform", Icon: "gear", Tooltip: "scale, rotate and move the selection by exact amounts, about its center", UpdateFunc: gv.SelectedEnableFunc},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.PromptTransform()
+		})
 
 	gi.NewSeparator(tb, "sep-rot")
-	tb.AddAction(gi.ActOpts{Icon: "sel-raise-top", Tooltip: "Raise selection to top (within layer)", UpdateFunc: gv.SelectedEnableFunc},
+	tb.AddAction(gi.ActOpts{Icon: "sel-raise-top", Tooltip: "Raise selection to top (Home)", UpdateFunc: gv.SelectedEnableFunc},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
-			grr.SelRaiseTop()
+			grr.RaiseToTop()
 		})
-	tb.AddAction(gi.ActOpts{Icon: "sel-raise", Tooltip: "Raise selection one level (within layer)", UpdateFunc: gv.SelectedEnableFunc},
+	tb.AddAction(gi.ActOpts{Icon: "sel-raise", Tooltip: "Raise selection one level (PageUp)", UpdateFunc: gv.SelectedEnableFunc},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
-			grr.SelRaise()
+			grr.RaiseSelection()
 		})
-	tb.AddAction(gi.ActOpts{Icon: "sel-lower-bottom", Tooltip: "Lower selection to bottom (within layer)", UpdateFunc: gv.SelectedEnableFunc},
+	tb.AddAction(gi.ActOpts{Icon: "sel-lower-bottom", Tooltip: "Lower selection to bottom (End)", UpdateFunc: gv.SelectedEnableFunc},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
-			grr.SelLowerBot()
+			grr.LowerToBottom()
 		})
-	tb.AddAction(gi.ActOpts{Icon: "sel-lower", Tooltip: "Lower selection one level (within layer)", UpdateFunc: gv.SelectedEnableFunc},
+	tb.AddAction(gi.ActOpts{Icon: "sel-lower", Tooltip: "Lower selection one level (PageDown)", UpdateFunc: gv.SelectedEnableFunc},
 		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 			grr := recv.Embed(KiT_GridView).(*GridView)
-			grr.SelLower()
+			grr.LowerSelection()
 		})
 	gi.NewSeparator(tb, "sep-size")
 
 	gi.AddNewLabel(tb, "posx-lab", "X: ").SetProp("vertical-align", gist.AlignMiddle)
 	px := gi.AddNewSpinBox(tb, "posx")
 	px.SetProp("step", 1)
+	Prefs.SetDisplayPrec(px)
 	px.SetValue(0)
 	px.Tooltip = "horizontal coordinate of selection, in document units"
 	px.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
@@ -124,6 +165,7 @@ func (gv *GridView) ConfigSelectToolbar() {
 	gi.AddNewLabel(tb, "posy-lab", "Y: ").SetProp("vertical-align", gist.AlignMiddle)
 	py := gi.AddNewSpinBox(tb, "posy")
 	py.SetProp("step", 1)
+	Prefs.SetDisplayPrec(py)
 	py.SetValue(0)
 	py.Tooltip = "vertical coordinate of selection, in document units"
 	py.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
@@ -134,6 +176,7 @@ func (gv *GridView) ConfigSelectToolbar() {
 	gi.AddNewLabel(tb, "width-lab", "W: ").SetProp("vertical-align", gist.AlignMiddle)
 	wd := gi.AddNewSpinBox(tb, "width")
 	wd.SetProp("step", 1)
+	Prefs.SetDisplayPrec(wd)
 	wd.SetValue(0)
 	wd.Tooltip = "width of selection, in document units"
 	wd.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
@@ -144,12 +187,24 @@ func (gv *GridView) ConfigSelectToolbar() {
 	gi.AddNewLabel(tb, "height-lab", "H: ").SetProp("vertical-align", gist.AlignMiddle)
 	ht := gi.AddNewSpinBox(tb, "height")
 	ht.SetProp("step", 1)
+	Prefs.SetDisplayPrec(ht)
 	ht.SetValue(0)
 	ht.Tooltip = "height of selection, in document units"
 	ht.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
 		grr := recv.Embed(KiT_GridView).(*GridView)
 		grr.SelSetHeight(ht.Value)
 	})
+
+	gi.AddNewLabel(tb, "rot-lab", "R: ").SetProp("vertical-align", gist.AlignMiddle)
+	rt := gi.AddNewSpinBox(tb, "rot")
+	rt.SetProp("step", 15)
+	rt.SetMinMax(true, -360, true, 360)
+	rt.SetValue(0)
+	rt.Tooltip = "rotation of selection in degrees, about the center of the selection -- shows current rotation of first selected item, or if it is a path or line, whose rotation is not known, rotates by the amount entered"
+	rt.SpinBoxSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		grr := recv.Embed(KiT_GridView).(*GridView)
+		grr.SelSetRotation(rt.Value)
+	})
 }
 
 // SelectedEnableFunc is an ActionUpdateFunc that inactivates action if no selected items
@@ -168,13 +223,26 @@ func (gv *GridView) UpdateSelectToolbar() {
 	}
 	sz := es.DragSelEffBBox.Size()
 	px := tb.ChildByName("posx", 8).(*gi.SpinBox)
+	Prefs.SetDisplayPrec(px)
 	px.SetValue(es.DragSelEffBBox.Min.X)
 	py := tb.ChildByName("posy", 9).(*gi.SpinBox)
+	Prefs.SetDisplayPrec(py)
 	py.SetValue(es.DragSelEffBBox.Min.Y)
 	wd := tb.ChildByName("width", 10).(*gi.SpinBox)
+	Prefs.SetDisplayPrec(wd)
 	wd.SetValue(sz.X)
 	ht := tb.ChildByName("height", 11).(*gi.SpinBox)
+	Prefs.SetDisplayPrec(ht)
 	ht.SetValue(sz.Y)
+	rl := tb.ChildByName("rot-lab", 12).(*gi.Label)
+	rt := tb.ChildByName("rot", 13).(*gi.SpinBox)
+	rot, known := gv.SelRotation()
+	if known {
+		rl.SetText("R: ")
+	} else {
+		rl.SetText("R+: ")
+	}
+	rt.SetValue(rot)
 }
 
 // UpdateSelect should be called whenever selection changes
@@ -194,25 +262,190 @@ func (sv *SVGView) UpdateSelect() {
 	}
 }
 
+// SelectableItems returns the elements that SelectAll selects: the
+// children of the isolated group if any, else of the current layer, else
+// the top-level elements of the drawing, including those in visible,
+// unlocked layers.  Hidden and locked elements are skipped.
+func (sv *SVGView) SelectableItems() []svg.NodeSVG {
+	es := sv.EditState()
+	root := sv.SelectRoot()
+	if root == sv.This() && es.CurLayer != "" {
+		if ly := sv.ChildByName(es.CurLayer, 1); ly != nil && LayerIsVisible(ly) && !LayerIsLocked(ly) {
+			root = ly
+		}
+	}
+	var sl []svg.NodeSVG
+	var add func(par ki.Ki)
+	add = func(par ki.Ki) {
+		for _, k := range *par.Children() {
+			if k == sv.Defs.This() || NodeIsMetaData(k) {
+				continue
+			}
+			if NodeIsLayer(k) {
+				if par == sv.This() && LayerIsVisible(k) && !LayerIsLocked(k) {
+					add(k)
+				}
+				continue
+			}
+			sii, issvg := k.(svg.NodeSVG)
+			if !issvg || sii.AsSVGNode().Pnt.Off || NodeIsLocked(k) {
+				continue
+			}
+			sl = append(sl, sii)
+		}
+	}
+	add(root)
+	return sl
+}
+
+// SelectAll selects all of the SelectableItems -- only changes the
+// selection, so there is nothing to undo
+func (gv *GridView) SelectAll() {
+	es := &gv.EditState
+	sv := gv.SVG()
+	sl := sv.SelectableItems()
+	es.ResetSelected()
+	for _, sn := range sl {
+		es.Select(sn)
+	}
+	sv.UpdateSelect()
+	gv.SetStatus(fmt.Sprintf("Selected %d items", len(es.Selected)))
+}
+
+// SelectNone clears the selection
+func (gv *GridView) SelectNone() {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		return
+	}
+	es.ResetSelected()
+	gv.SVG().UpdateSelect()
+	gv.SetStatus("")
+}
+
+// InvertSelection selects the SelectableItems that are not selected,
+// and unselects the rest
+func (gv *GridView) InvertSelection() {
+	es := &gv.EditState
+	sv := gv.SVG()
+	sl := sv.SelectableItems()
+	prv := es.Selected
+	es.ResetSelected()
+	for _, sn := range sl {
+		if _, has := prv[sn]; !has {
+			es.Select(sn)
+		}
+	}
+	sv.UpdateSelect()
+	gv.SetStatus(fmt.Sprintf("Selected %d items", len(es.Selected)))
+}
+
+// FillGradientName returns the name of the gradient that the fill of
+// given element gets its stops from, or "" if it is not a gradient
+func FillGradientName(sn svg.NodeSVG) string {
+	fs, ok := sn.Prop("fill").(string)
+	if !ok || !strings.HasPrefix(fs, "url(") {
+		return ""
+	}
+	gr, ok := svg.NodeFindURL(sn, fs).(*gi.Gradient)
+	if !ok || gr == nil {
+		return ""
+	}
+	if gr.StopsName != "" { // elements each have their own gradient, sharing stops
+		return gr.StopsName
+	}
+	return gr.Name()
+}
+
+// SameFill returns true if given elements have the same fill: both none,
+// the same solid color, or gradients with the same stops
+func SameFill(a, b svg.NodeSVG) bool {
+	fa, fb := &a.AsSVGNode().Pnt.FillStyle, &b.AsSVGNode().Pnt.FillStyle
+	if !fa.On || !fb.On {
+		return fa.On == fb.On
+	}
+	if fa.Color.Source != fb.Color.Source {
+		return false
+	}
+	if fa.Color.Source == gist.SolidColor {
+		return fa.Color.Color == fb.Color.Color
+	}
+	return FillGradientName(a) == FillGradientName(b)
+}
+
+// SelectSame adds the SelectableItems for which given function returns
+// true, compared with the first selected item, to the selection
+func (gv *GridView) SelectSame(act string, same func(ref, sn svg.NodeSVG) bool) {
+	es := &gv.EditState
+	ref := es.FirstSelectedNode()
+	if ref == nil {
+		gv.SetStatus(act + ": select an item to match first")
+		return
+	}
+	sv := gv.SVG()
+	n := 0
+	for _, sn := range sv.SelectableItems() {
+		if es.IsSelected(sn) || !same(ref, sn) {
+			continue
+		}
+		es.Select(sn)
+		n++
+	}
+	sv.UpdateSelect()
+	gv.SetStatus(fmt.Sprintf("%s: added %d items to the selection", act, n))
+}
+
+// SelectSameType adds the items of the same svg element type (rect,
+// path, text, etc) as the first selected item to the selection, from
+// among the SelectableItems (see SelectAll)
+func (gv *GridView) SelectSameType() {
+	gv.SelectSame("Select Same Type", func(ref, sn svg.NodeSVG) bool {
+		return sn.Type() == ref.Type()
+	})
+}
+
+// SelectSameFill adds the items with the same fill as the first selected
+// item to the selection, from among the SelectableItems (see SelectAll)
+func (gv *GridView) SelectSameFill() {
+	gv.SelectSame("Select Same Fill", SameFill)
+}
+
 func (sv *SVGView) RemoveSelSprites(win *gi.Window) {
+	if win == nil {
+		return
+	}
 	InactivateSprites(win, SpReshapeBBox)
 	InactivateSprites(win, SpSelBBox)
+	InactivateSprites(win, SpLockedBBox)
+	InactivateSprites(win, SpGradientPt)
+	InactivateSprites(win, SpGradientLine)
 	es := sv.EditState()
 	es.NSelSprites = 0
 	win.UpdateSig()
 }
 
 func (sv *SVGView) UpdateSelSprites() {
+	es := sv.EditState()
 	win := sv.GridView.ParentWindow()
+	if win == nil { // headless
+		es.UpdateSelBBox()
+		return
+	}
 	updt := win.UpdateStart()
 	defer win.UpdateEnd(updt)
 
-	es := sv.EditState()
 	es.UpdateSelBBox()
 	if !es.HasSelected() {
 		sv.RemoveSelSprites(win)
 		return
 	}
+	if es.SelectedLocked() { // no reshaping
+		sv.RemoveSelSprites(win)
+		sv.SetLockedSpritePos()
+		win.UpdateSig()
+		return
+	}
+	InactivateSprites(win, SpLockedBBox)
 
 	for i := SpBBoxUpL; i <= SpBBoxRtM; i++ {
 		spi := i // key to get a unique local var
@@ -229,6 +462,9 @@ func (sv *SVGView) UpdateSelSprites() {
 
 func (sv *SVGView) SetSelSpritePos() {
 	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
 	es := sv.EditState()
 	nsel := es.NSelSprites
 
@@ -255,14 +491,18 @@ func (sv *SVGView) SetSelSpritePos() {
 			win.InactivateSprite(spnm)
 		}
 	}
+	sv.UpdateGradientSprites(win)
 }
 
 // SetBBoxSpritePos sets positions of given type of sprites
 func (sv *SVGView) SetBBoxSpritePos(typ Sprites, idx int, bbox mat32.Box2) {
 	win := sv.GridView.ParentWindow()
-	_, spsz := HandleSpriteSize(1)
-	midX := int(0.5 * (bbox.Min.X + bbox.Max.X - float32(spsz.X)))
-	midY := int(0.5 * (bbox.Min.Y + bbox.Max.Y - float32(spsz.Y)))
+	if win == nil {
+		return
+	}
+	_, spsz := HandleSpriteSize(BBoxHandleScale(typ))
+	midX := int(mat32.Round(0.5 * (bbox.Min.X + bbox.Max.X - float32(spsz.X))))
+	midY := int(mat32.Round(0.5 * (bbox.Min.Y + bbox.Max.Y - float32(spsz.Y))))
 	for i := SpBBoxUpL; i <= SpBBoxRtM; i++ {
 		spi := i // key to get a unique local var
 		sp := Sprite(win, typ, spi, idx, image.ZP)
@@ -308,13 +548,43 @@ func (sv *SVGView) SelSpriteEvent(sp Sprites, et oswin.EventType, d any) {
 		me.SetProcessed()
 		// fmt.Printf("drag %v delta: %v\n", sp, me.Delta())
 		if me.HasAnyModifier(key.Alt) {
-			sv.SpriteRotateDrag(sp, me.Delta(), win)
+			sv.SpriteRotateDrag(sp, me.Delta(), !me.HasAnyModifier(key.Shift), win) // Shift = free rotation
 		} else {
 			sv.SpriteReshapeDrag(sp, win, me)
 		}
 	}
 }
 
+// BoxSelectMode returns how a box select drag combines the elements
+// within the box with the existing selection, from the modifier keys of
+// given drag event: Ctrl or Cmd adds them (ExtendOne, as with clicking),
+// Alt removes them (Unselect), otherwise they replace it (SelectOne).
+// Shift is not used, as Shift+drag pans the view.
+func BoxSelectMode(me *mouse.DragEvent) mouse.SelectModes {
+	switch {
+	case me.HasAnyModifier(key.Control, key.Meta):
+		return mouse.ExtendOne
+	case me.HasAnyModifier(key.Alt):
+		return mouse.Unselect
+	}
+	return mouse.SelectOne
+}
+
+// BoxSelectStarts returns true if a drag starting at given point, with
+// modifier keys that add to or remove from the selection, starts a box
+// select instead of moving the selection: there must be a selection, and
+// the point must be outside of it and of any other element
+func (sv *SVGView) BoxSelectStarts(me *mouse.DragEvent) bool {
+	es := sv.EditState()
+	if es.Tool != SelectTool || !es.HasSelected() || es.InAction() || BoxSelectMode(me) == mouse.SelectOne {
+		return false
+	}
+	if es.SelBBox.ContainsPoint(mat32.NewVec2FmPoint(me.Start)) {
+		return false
+	}
+	return sv.SelectContainsPoint(me.Start, false, false) == nil
+}
+
 // SetRubberBand updates the rubber band postion
 func (sv *SVGView) SetRubberBand(cur image.Point) {
 	win := sv.GridView.ParentWindow()
@@ -345,31 +615,76 @@ func (sv *SVGView) SetRubberBand(cur image.Point) {
 	SetSpritePos(rr, image.Point{bbox.Max.X, bbox.Min.Y})
 	SetSpritePos(rl, bbox.Min)
 
+	sv.UpdateSelPreviewSprites(bbox)
+
 	win.UpdateSig()
 }
 
+// UpdateSelPreviewSprites outlines the elements that would be selected
+// on releasing a box select drag over given bbox
+func (sv *SVGView) UpdateSelPreviewSprites(bbox image.Rectangle) {
+	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
+	InactivateSprites(win, SpSelPreview)
+	sel := sv.SelectWithinBBox(bbox, false)
+	for i, se := range sel {
+		bb := mat32.Box2{}
+		bb.SetFromRect(se.AsSVGNode().WinBBox)
+		sv.SetBBoxSpritePos(SpSelPreview, i, bb)
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////
 //   Actions
 
+// SetNodeTransform sets the transform of given element to given
+// transform, removing the transform property if it is the identity
+func SetNodeTransform(sn svg.NodeSVG, xf mat32.Mat2) {
+	nb := sn.AsSVGNode()
+	nb.Pnt.Transform = xf
+	if xf.IsIdentity() {
+		nb.DeleteProp("transform")
+	} else {
+		nb.SetProp("transform", xf.String())
+	}
+}
+
+// SelGroup wraps the selected items in a new group, in the layer or
+// group of the topmost selected item, at its place in the stacking
+// order.  The items keep their stacking order, and items moved from
+// other layers or groups keep their place in the drawing.  The new
+// group is selected.  Locked items are left out of the group.
 func (gv *GridView) SelGroup() {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
 	sv.UndoSave("Group", es.SelectedNamesString())
 
 	updt := sv.UpdateStart()
-	sl := es.SelectedListDepth(sv, false) // ascending depth order
-
-	fsel := sl[len(sl)-1] // first selected -- use parent of this for new group
+	var sl []svg.NodeSVG // ascending depth order, without locked items
+	for _, se := range es.SelectedListDepth(sv, false) {
+		if !NodeIsLocked(se) {
+			sl = append(sl, se)
+		}
+	}
 
+	fsel := sl[len(sl)-1] // topmost selected -- use parent of this for new group
+	par := fsel.Parent()
 	fidx, _ := fsel.IndexInParent()
 
-	ng := fsel.Parent().InsertNewChild(svg.KiT_Group, fidx, "newgp").(svg.NodeSVG)
+	ng := par.InsertNewChild(svg.KiT_Group, fidx, "newgp").(svg.NodeSVG)
 	sv.SetSVGName(ng)
+	gxf := ng.AsSVGNode().ParTransform(false) // group itself has none
 
 	for _, se := range sl {
+		if se.Parent() != par {
+			xf := se.AsSVGNode().ParTransform(true) // include self
+			SetNodeTransform(se, xf.Mul(gxf.Inverse()))
+		}
 		ki.MoveToParent(se, ng)
 	}
 
@@ -378,9 +693,14 @@ func (gv *GridView) SelGroup() {
 
 	sv.UpdateEnd(updt)
 	gv.UpdateAll()
+	sv.UpdateSelect()
 	gv.ChangeMade()
 }
 
+// SelUnGroup dissolves the selected groups, moving their children into
+// the parent of each group, at its place in the stacking order, with
+// the group transform composed into the transform of each child.  The
+// children are selected in place of their group.
 func (gv *GridView) SelUnGroup() {
 	es := &gv.EditState
 	if !es.HasSelected() {
@@ -392,13 +712,16 @@ func (gv *GridView) SelUnGroup() {
 	updt := sv.UpdateStart()
 
 	sl := es.SelectedList(true) // true = descending = reverse order
+	es.ResetSelected()
 	for _, se := range sl {
 		gp, isgp := se.(*svg.Group)
 		if !isgp {
+			es.Select(se)
 			continue
 		}
 		np := gp.Par
 		gidx, _ := gp.IndexInParent()
+		gxf := gp.Pnt.Transform
 		klist := make(ki.Slice, len(gp.Kids)) // make a temp copy of list of kids
 		for i, k := range gp.Kids {
 			klist[i] = k
@@ -407,44 +730,86 @@ func (gv *GridView) SelUnGroup() {
 			ki.SetParent(k, nil)
 			gp.DeleteChild(k, false) // no destroy
 			np.InsertChild(k, gidx+i)
-			se := k.(svg.NodeSVG)
-			if !gp.Pnt.Transform.IsIdentity() {
-				se.ApplyTransform(gp.Pnt.Transform) // group no longer there!
+			kn, issv := k.(svg.NodeSVG)
+			if !issv {
+				continue
+			}
+			if !gxf.IsIdentity() {
+				// child transform first, then that of the group -- group no longer there!
+				SetNodeTransform(kn, kn.AsSVGNode().Pnt.Transform.Mul(gxf))
 			}
+			es.Select(kn)
 		}
 		gp.Delete(ki.DestroyKids)
 	}
 	sv.UpdateEnd(updt)
 	gv.UpdateAll()
+	sv.UpdateSelect()
 	gv.ChangeMade()
 }
 
+// SelRotate rotates the selection by given number of degrees about
+// the center of the overall selection bounding box, using the same
+// transform path as rotation dragging
 func (gv *GridView) SelRotate(deg float32) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() || deg == 0 {
 		return
 	}
 	sv := gv.SVG()
 	sv.UndoSave("Rotate", fmt.Sprintf("%g", deg))
 
-	svoff := sv.WinBBox.Min
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	ctr := es.SelBBox.Min.Add(es.SelBBox.Max).MulScalar(.5).Sub(svoff)
 	del := mat32.Vec2{}
 	sc := mat32.V2(1, 1)
 	rot := mat32.DegToRad(deg)
 	for sn := range es.Selected {
-		sng := sn.AsSVGNode()
-		sz := mat32.NewVec2FmPoint(sng.WinBBox.Size())
-		mn := mat32.NewVec2FmPoint(sng.WinBBox.Min.Sub(svoff))
-		ctr := mn.Add(sz.MulScalar(.5))
+		if NodeIsLocked(sn) {
+			continue
+		}
 		sn.ApplyDeltaTransform(del, sc, rot, ctr)
 	}
 	sv.UpdateView(true)
 	gv.ChangeMade()
 }
 
-func (gv *GridView) SelScale(scx, scy float32) {
+// SelRotation returns the current rotation in degrees of the first
+// selected item, derived from its transform, and whether that is its
+// actual rotation.  Paths, polylines and lines can have rotation baked
+// into their points, so their rotation is not known, and 0 is returned.
+func (gv *GridView) SelRotation() (float32, bool) {
 	es := &gv.EditState
 	if !es.HasSelected() {
+		return 0, true
+	}
+	sn := es.SelectedList(false)[0]
+	switch sn.(type) {
+	case *svg.Path, *svg.Polyline, *svg.Polygon, *svg.Line:
+		return 0, false
+	}
+	xf := sn.AsSVGNode().Pnt.Transform
+	return mat32.RadToDeg(mat32.Atan2(xf.YX, xf.XX)), true
+}
+
+// SelSetRotation rotates the selection about its center so that the
+// first selected item has given absolute rotation, in degrees
+// (see SelRotation).  If its rotation is not known, the selection is
+// rotated by given number of degrees instead, and the rotation field
+// is reset to 0 for the next relative rotation.
+func (gv *GridView) SelSetRotation(deg float32) {
+	rot, known := gv.SelRotation()
+	if known {
+		gv.SelRotate(deg - rot)
+		return
+	}
+	gv.SelRotate(deg)
+	gv.SelectToolbar().ChildByName("rot", 13).(*gi.SpinBox).SetValue(0)
+}
+
+func (gv *GridView) SelScale(scx, scy float32) {
+	es := &gv.EditState
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
@@ -454,6 +819,9 @@ func (gv *GridView) SelScale(scx, scy float32) {
 	del := mat32.Vec2{}
 	sc := mat32.V2(scx, scy)
 	for sn := range es.Selected {
+		if NodeIsLocked(sn) {
+			continue
+		}
 		sng := sn.AsSVGNode()
 		sz := mat32.NewVec2FmPoint(sng.WinBBox.Size())
 		mn := mat32.NewVec2FmPoint(sng.WinBBox.Min.Sub(svoff))
@@ -472,100 +840,189 @@ func (gv *GridView) SelRotateRight() {
 	gv.SelRotate(90)
 }
 
+// SelFlipHoriz mirrors the selection horizontally, in place
 func (gv *GridView) SelFlipHoriz() {
-	gv.SelScale(-1, 1)
+	gv.SVG().FlipSelectionHoriz()
 }
 
+// SelFlipVert mirrors the selection vertically, in place
 func (gv *GridView) SelFlipVert() {
-	gv.SelScale(1, -1)
+	gv.SVG().FlipSelectionVert()
 }
 
-func (gv *GridView) SelRaiseTop() {
-	es := &gv.EditState
-	if !es.HasSelected() {
+// FlipSelectionHoriz mirrors the selected items left to right about
+// the center of the overall selection bounding box
+func (sv *SVGView) FlipSelectionHoriz() {
+	sv.FlipSelection("FlipHoriz", mat32.V2(-1, 1))
+}
+
+// FlipSelectionVert mirrors the selected items top to bottom about
+// the center of the overall selection bounding box
+func (sv *SVGView) FlipSelectionVert() {
+	sv.FlipSelection("FlipVert", mat32.V2(1, -1))
+}
+
+// FlipSelection applies given scale, with -1 on the axis to flip, to
+// the selected items about the center of the overall selection bounding
+// box, so the bbox stays in place, as an undoable action of given name.
+// Gradient points are updated to follow the items.
+func (sv *SVGView) FlipSelection(act string, sc mat32.Vec2) {
+	es := sv.EditState()
+	if !es.HasSelectedUnlocked() {
 		return
 	}
-	sv := gv.SVG()
-	sv.UndoSave("RaiseTop", es.SelectedNamesString())
-
-	sl := es.SelectedList(true) // true = descending = reverse order
-	for _, se := range sl {
-		par := se.Parent()
-		if !(NodeIsLayer(par) || par == sv.This()) {
+	sv.UndoSave(act, es.SelectedNamesString())
+	es.UpdateSelBBox()
+	es.DragSelCurBBox = es.SelBBox
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	ctr := es.DragSelCurBBox.Min.Add(es.DragSelCurBBox.Max).MulScalar(.5).Sub(svoff)
+	del := mat32.Vec2{}
+	for itm := range es.Selected {
+		if NodeIsLocked(itm) {
 			continue
 		}
-		ci, _ := se.IndexInParent()
-		par.Children().Move(ci, par.NumChildren()-1)
+		itm.ApplyDeltaTransform(del, sc, 0, ctr)
+		svg.UpdateNodeGradientPoints(itm, "fill")
+		svg.UpdateNodeGradientPoints(itm, "stroke")
 	}
-	gv.UpdateDisp()
-	gv.ChangeMade()
+	sv.UpdateView(true)
+	sv.UpdateSelect()
+	sv.GridView.ChangeMade()
 }
 
-func (gv *GridView) SelRaise() {
+// StackBottom returns the lowest index among the children of given
+// parent that an element can be lowered to: above any metadata and
+// other non-svg children at the start
+func StackBottom(par ki.Ki) int {
+	for i, k := range *par.Children() {
+		if _, issv := k.(svg.NodeSVG); issv && !NodeIsMetaData(k) {
+			return i
+		}
+	}
+	return par.NumChildren()
+}
+
+// SelStackMove moves each selected item among its siblings, calling
+// given function with the parent, the current index of the item, and
+// whether the sibling it would swap with (in the direction of the move)
+// is selected, which returns the new index.  Items are processed from
+// the top down if top is true, and bottom up otherwise, so that their
+// relative order is preserved.  Locked items are not moved.
+func (gv *GridView) SelStackMove(act string, top bool, fun func(par ki.Ki, ci int, nbrSel bool) int) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
-	sv.UndoSave("Raise", es.SelectedNamesString())
-
-	sl := es.SelectedList(true) // true = descending = reverse order
+	sv.UndoSave(act, es.SelectedNamesString())
+	sl := es.SelectedListDepth(sv, top) // descending = top first
 	for _, se := range sl {
 		par := se.Parent()
-		if !(NodeIsLayer(par) || par == sv.This()) {
+		if par == nil || NodeIsLocked(se) {
+			continue
+		}
+		ci, ok := se.IndexInParent()
+		if !ok {
 			continue
 		}
-		ci, _ := se.IndexInParent()
-		if ci < par.NumChildren()-1 {
-			par.Children().Move(ci, ci+1)
+		ni := ci + 1
+		if !top {
+			ni = ci - 1
+		}
+		nbrSel := false
+		if ni >= 0 && ni < par.NumChildren() {
+			if nb, issv := par.Child(ni).(svg.NodeSVG); issv {
+				nbrSel = es.IsSelected(nb) && !NodeIsLocked(nb)
+			}
+		}
+		if nci := fun(par, ci, nbrSel); nci != ci {
+			par.Children().Move(ci, nci)
 		}
 	}
+	sv.SetFullReRender()
+	gv.UpdateTreeView()
 	gv.UpdateDisp()
 	gv.ChangeMade()
 }
 
-func (gv *GridView) SelLowerBot() {
-	es := &gv.EditState
-	if !es.HasSelected() {
-		return
-	}
-	sv := gv.SVG()
-	sv.UndoSave("LowerBottom", es.SelectedNamesString())
+// RaiseToTop moves the selected items to the top of the stacking order
+// among their siblings, keeping their relative order
+func (gv *GridView) RaiseToTop() {
+	gv.SelStackMove("RaiseTop", false, func(par ki.Ki, ci int, nbrSel bool) int {
+		return par.NumChildren() - 1
+	})
+}
 
-	sl := es.SelectedList(true) // true = descending = reverse order
-	for _, se := range sl {
-		par := se.Parent()
-		if !(NodeIsLayer(par) || par == sv.This()) {
-			continue
+// RaiseSelection moves each of the selected items one step up in the
+// stacking order among its siblings, keeping their relative order
+func (gv *GridView) RaiseSelection() {
+	gv.SelStackMove("Raise", true, func(par ki.Ki, ci int, nbrSel bool) int {
+		if nbrSel || ci >= par.NumChildren()-1 {
+			return ci
 		}
-		ci, _ := se.IndexInParent()
-		par.Children().Move(ci, 0)
-	}
-	gv.UpdateDisp()
-	gv.ChangeMade()
+		return ci + 1
+	})
+}
+
+// LowerToBottom moves the selected items to the bottom of the stacking
+// order among their siblings, keeping their relative order
+func (gv *GridView) LowerToBottom() {
+	gv.SelStackMove("LowerBottom", true, func(par ki.Ki, ci int, nbrSel bool) int {
+		return StackBottom(par)
+	})
+}
+
+// LowerSelection moves each of the selected items one step down in the
+// stacking order among its siblings, keeping their relative order
+func (gv *GridView) LowerSelection() {
+	gv.SelStackMove("Lower", false, func(par ki.Ki, ci int, nbrSel bool) int {
+		if nbrSel || ci <= StackBottom(par) {
+			return ci
+		}
+		return ci - 1
+	})
 }
 
-func (gv *GridView) SelLower() {
+// SelSnapToPixels moves each selected item so its position is at
+// integer document pixel (user unit) coordinates, and optionally also
+// resizes it to integer width and height, so it renders crisply at 1:1
+// scale.  This is independent of the grid snap.  It is a single undoable
+// action, and reports how many items were changed.  Locked items are skipped.
+func (gv *GridView) SelSnapToPixels(sizes bool) {
 	es := &gv.EditState
-	if !es.HasSelected() {
+	if !es.HasSelectedUnlocked() {
 		return
 	}
 	sv := gv.SVG()
-	sv.UndoSave("Lower", es.SelectedNamesString())
-
-	sl := es.SelectedList(true) // true = descending = reverse order
-	for _, se := range sl {
-		par := se.Parent()
-		if !(NodeIsLayer(par) || par == sv.This()) {
+	sv.UndoSave("SnapToPixels", es.SelectedNamesString())
+	const tol = 1.0e-3
+	nmv := 0
+	for sn := range es.Selected {
+		if NodeIsLocked(sn) {
 			continue
 		}
-		ci, _ := se.IndexInParent()
-		if ci > 0 {
-			par.Children().Move(ci, ci-1)
+		bb := sv.DocBBox(sn)
+		sz := bb.Size()
+		rmn := mat32.V2(mat32.Round(bb.Min.X), mat32.Round(bb.Min.Y))
+		sc := mat32.V2(1, 1)
+		if sizes {
+			for d := mat32.X; d <= mat32.Y; d++ {
+				if dsz := sz.Dim(d); dsz > 0 {
+					sc.SetDim(d, mat32.Max(mat32.Round(dsz), 1)/dsz)
+				}
+			}
+		}
+		del := rmn.Sub(bb.Min)
+		if mat32.Abs(del.X) < tol && mat32.Abs(del.Y) < tol && mat32.Abs(sc.X-1) < tol && mat32.Abs(sc.Y-1) < tol {
+			continue
 		}
+		pt := bb.Min.Add(sv.Trans).MulScalar(sv.Scale) // window coords relative to sv
+		sn.ApplyDeltaTransform(del.MulScalar(sv.Scale), sc, 0, pt)
+		nmv++
 	}
-	gv.UpdateDisp()
+	sv.UpdateView(true)
 	gv.ChangeMade()
+	gv.SetStatus(fmt.Sprintf("Snap To Pixels: moved %d of %d items", nmv, len(es.Selected)))
 }
 
 func (gv *GridView) SelSetXPos(xp float32) {
@@ -620,8 +1077,9 @@ func (gv *GridView) SelSetHeight(ht float32) {
 func (sv *SVGView) SelectWithinBBox(bbox image.Rectangle, leavesOnly bool) []svg.NodeSVG {
 	var rval []svg.NodeSVG
 	var curlay ki.Ki
-	sv.FuncDownMeFirst(0, sv.This(), func(k ki.Ki, level int, d any) bool {
-		if k == sv.This() {
+	root := sv.SelectRoot()
+	root.FuncDownMeFirst(0, root, func(k ki.Ki, level int, d any) bool {
+		if k == root {
 			return ki.Continue
 		}
 		if k.IsDeleted() || k.IsDestroyed() {
@@ -648,7 +1106,7 @@ func (sv *SVGView) SelectWithinBBox(bbox image.Rectangle, leavesOnly bool) []svg
 			}
 		}
 		sg := sii.AsSVGNode()
-		if sg.Pnt.Off {
+		if sg.Pnt.Off || NodeIsLocked(k) {
 			return ki.Break
 		}
 		nl := NodeParentLayer(k)
@@ -684,8 +1142,9 @@ func (sv *SVGView) SelectContainsPoint(pt image.Point, leavesOnly, excludeSel bo
 		curlay = NodeParentLayer(fn)
 	}
 	var rval svg.NodeSVG
-	sv.FuncDownMeFirst(0, sv.This(), func(k ki.Ki, level int, d any) bool {
-		if k == sv.This() {
+	root := sv.SelectRoot()
+	root.FuncDownMeFirst(0, root, func(k ki.Ki, level int, d any) bool {
+		if k == root {
 			return ki.Continue
 		}
 		if k.IsDeleted() || k.IsDestroyed() {
@@ -720,7 +1179,7 @@ func (sv *SVGView) SelectContainsPoint(pt image.Point, leavesOnly, excludeSel bo
 			}
 		}
 		sg := sii.AsSVGNode()
-		if sg.Pnt.Off {
+		if sg.Pnt.Off || NodeIsLocked(k) {
 			return ki.Break
 		}
 		nl := NodeParentLayer(k)
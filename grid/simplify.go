@@ -0,0 +1,235 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+	"github.com/goki/mat32"
+)
+
+// SimplifyCornerAngle is the min angle in degrees between the curves on
+// either side of a node for SimplifyPathSegs to keep it as a corner,
+// instead of fitting new curves through it
+var SimplifyCornerAngle = float32(10)
+
+// PtSegDist returns the distance from point p to the line segment from a to b
+func PtSegDist(p, a, b mat32.Vec2) float32 {
+	ab := b.Sub(a)
+	lsq := ab.Dot(ab)
+	if lsq == 0 {
+		return p.DistTo(a)
+	}
+	t := mat32.Clamp(p.Sub(a).Dot(ab)/lsq, 0, 1)
+	return p.DistTo(a.Add(ab.MulScalar(t)))
+}
+
+// SimplifyPolyline returns the indexes of the points of given polyline
+// to keep so that none of the others is more than tol from it, using the
+// Ramer-Douglas-Peucker algorithm -- the first and last are always kept
+func SimplifyPolyline(pts []mat32.Vec2, tol float32) []int {
+	n := len(pts)
+	if n < 3 {
+		keep := make([]int, n)
+		for i := range keep {
+			keep[i] = i
+		}
+		return keep
+	}
+	keep := []int{0}
+	var rdp func(first, last int)
+	rdp = func(first, last int) {
+		mx, mi := float32(0), -1
+		for i := first + 1; i < last; i++ {
+			if d := PtSegDist(pts[i], pts[first], pts[last]); d > mx {
+				mx, mi = d, i
+			}
+		}
+		if mi < 0 || mx <= tol {
+			keep = append(keep, last)
+			return
+		}
+		rdp(first, mi)
+		rdp(mi, last)
+	}
+	rdp(0, n-1)
+	return keep
+}
+
+// pathSegIsLine returns true if given segment is a straight line (L, H, V)
+func pathSegIsLine(ps *PathSeg) bool {
+	switch ps.Cmd &^ 1 {
+	case svg.PcL, svg.PcH, svg.PcV:
+		return true
+	}
+	return false
+}
+
+// pathSegIsCurve returns true if given segment is a bezier curve (C, S, Q, T)
+func pathSegIsCurve(ps *PathSeg) bool {
+	switch ps.Cmd &^ 1 {
+	case svg.PcC, svg.PcS, svg.PcQ, svg.PcT:
+		return true
+	}
+	return false
+}
+
+// pathSegEndDirs returns the unit directions of given curve segment,
+// starting at p0, at its start and end, from its control points, or
+// the chord where they coincide with the ends
+func pathSegEndDirs(ps *PathSeg, p0 mat32.Vec2) (st, ed mat32.Vec2) {
+	st = ps.Ctrls[0].Sub(p0)
+	if st.Length() == 0 {
+		st = ps.Pt.Sub(p0)
+	}
+	ed = ps.Pt.Sub(ps.Ctrls[len(ps.Ctrls)-1])
+	if ed.Length() == 0 {
+		ed = ps.Pt.Sub(p0)
+	}
+	if st.Length() > 0 {
+		st = st.Normal()
+	}
+	if ed.Length() > 0 {
+		ed = ed.Normal()
+	}
+	return
+}
+
+// SimplifyPathSegs returns given segments with fewer nodes where possible,
+// staying within tol of the original path: runs of straight lines are
+// simplified with SimplifyPolyline, and runs of curves that join smoothly
+// (within SimplifyCornerAngle) are re-fit with FitCurve, when that takes
+// fewer curves.  Movetos, closepaths and arcs are kept as they are.
+func SimplifyPathSegs(segs []*PathSeg, tol float32) []*PathSeg {
+	sts := PathSegStarts(segs)
+	cosCorner := mat32.Cos(mat32.DegToRad(SimplifyCornerAngle))
+	var out []*PathSeg
+	for i := 0; i < len(segs); {
+		ps := segs[i]
+		switch {
+		case pathSegIsLine(ps):
+			ed := i + 1
+			for ed < len(segs) && pathSegIsLine(segs[ed]) {
+				ed++
+			}
+			pts := []mat32.Vec2{sts[i]}
+			for _, ls := range segs[i:ed] {
+				pts = append(pts, ls.Pt)
+			}
+			for _, k := range SimplifyPolyline(pts, tol)[1:] {
+				out = append(out, &PathSeg{Cmd: svg.PcL | ps.Cmd&1, Pt: pts[k]})
+			}
+			i = ed
+		case pathSegIsCurve(ps):
+			ed := i + 1
+			for ed < len(segs) && pathSegIsCurve(segs[ed]) {
+				_, pd := pathSegEndDirs(segs[ed-1], sts[ed-1])
+				nd, _ := pathSegEndDirs(segs[ed], sts[ed])
+				if pd.Dot(nd) < cosCorner {
+					break
+				}
+				ed++
+			}
+			var bzs [][4]mat32.Vec2
+			if ed-i > 1 {
+				pts := []mat32.Vec2{sts[i]}
+				for k := i; k < ed; k++ {
+					for j := 1; j <= PathFlattenSegs; j++ {
+						pts = append(pts, segs[k].PointAt(sts[k], float32(j)/float32(PathFlattenSegs)))
+					}
+				}
+				bzs = FitCurve(pts, tol)
+			}
+			if len(bzs) == 0 || len(bzs) >= ed-i {
+				out = append(out, segs[i:ed]...)
+			} else {
+				for _, bz := range bzs {
+					out = append(out, &PathSeg{Cmd: svg.PcC | ps.Cmd&1, Pt: bz[3], Ctrls: []mat32.Vec2{bz[1], bz[2]}})
+				}
+			}
+			i = ed
+		default:
+			out = append(out, ps)
+			i++
+		}
+	}
+	return out
+}
+
+// PathSegsNNodes returns the number of nodes in given segments (all
+// but the closepaths)
+func PathSegsNNodes(segs []*PathSeg) int {
+	n := 0
+	for _, ps := range segs {
+		if !ps.IsClose() {
+			n++
+		}
+	}
+	return n
+}
+
+// SimplifyPath reduces the number of nodes in all selected paths
+// (recursing into groups), staying within the SimplifyTol preference of
+// the original paths on screen (see SimplifyPathSegs), as a single
+// undoable action.  Reports the node counts before and after in the
+// status bar.
+func (gv *GridView) SimplifyPath() {
+	es := &gv.EditState
+	if !es.HasSelectedUnlocked() {
+		gv.SetStatus("Simplify Path: select the paths to simplify")
+		return
+	}
+	sv := gv.SVG()
+	var paths []*svg.Path
+	gv.SelPathsFunc(func(sii svg.NodeSVG) {
+		if pt, ok := sii.(*svg.Path); ok {
+			paths = append(paths, pt)
+		}
+	})
+	if len(paths) == 0 {
+		gv.SetStatus("Simplify Path: there are no paths in the selection")
+		return
+	}
+	dsc := gi.Prefs.LogicalDPIScale
+	if dsc <= 0 {
+		dsc = 1
+	}
+	nbef, naft, nchg := 0, 0, 0
+	saved := false
+	updt := sv.UpdateStart()
+	sv.SetFullReRender()
+	for _, pt := range paths {
+		xf := pt.ParTransform(true) // includes the zoom
+		xsc := mat32.Sqrt(mat32.Abs(xf.XX*xf.YY - xf.XY*xf.YX))
+		if xsc == 0 {
+			xsc = 1
+		}
+		tol := Prefs.SimplifyTol * dsc / xsc // screen pixels to local coords
+		segs := PathSegs(pt.Data)
+		nb := PathSegsNNodes(segs)
+		nbef += nb
+		ssegs := SimplifyPathSegs(segs, tol)
+		na := PathSegsNNodes(ssegs)
+		if na >= nb {
+			naft += nb
+			continue
+		}
+		if !saved {
+			sv.UndoSave("SimplifyPath", es.SelectedNamesString())
+			saved = true
+		}
+		pt.Data = PathSegsData(ssegs)
+		naft += na
+		nchg++
+	}
+	sv.UpdateEnd(updt)
+	if saved {
+		sv.UpdateSelect()
+		gv.ChangeMade()
+	}
+	gv.SetStatus(fmt.Sprintf("Simplify Path: reduced %d nodes to %d, in %d of %d paths", nbef, naft, nchg, len(paths)))
+}
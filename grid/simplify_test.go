@@ -0,0 +1,53 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/svg"
+)
+
+// TestSimplifyPathZoom tests that the simplify tolerance is in screen
+// pixels at any zoom: the middle node is dropped only when it is within
+// SimplifyTol pixels of the line through the others on screen
+func TestSimplifyPathZoom(t *testing.T) {
+	stol, sdsc := Prefs.SimplifyTol, gi.Prefs.LogicalDPIScale
+	defer func() { Prefs.SimplifyTol, gi.Prefs.LogicalDPIScale = stol, sdsc }()
+	Prefs.SimplifyTol, gi.Prefs.LogicalDPIScale = 1, 1
+
+	tests := []struct {
+		zoom float32
+		dev  float32 // distance of the middle node from the line, in the drawing
+		keep bool
+	}{
+		{0.5, 3, true},    // 1.5 px
+		{0.5, 1.5, false}, // 0.75 px
+		{2, 0.7, true},    // 1.4 px
+		{2, 0.4, false},   // 0.8 px
+	}
+	for _, tt := range tests {
+		src := fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="640px" height="360px" viewBox="0 0 640 360">
+<path id="path1" d="M 100 100 L 200 %g L 300 100" style="fill:none;stroke:#000000"/>
+</svg>
+`, 100+tt.dev)
+		gv := openTestView(t, src)
+		sv := gv.SVG()
+		setTestZoom(sv, tt.zoom, 0, 0)
+		d := &Doc{View: gv}
+		path := testNode(t, d, "path1").(*svg.Path)
+		gv.EditState.Select(path)
+		gv.SimplifyPath()
+		want := 2
+		if tt.keep {
+			want = 3
+		}
+		if n := PathSegsNNodes(PathSegs(path.Data)); n != want {
+			t.Errorf("zoom %g, node %g off the line: got %d nodes, want %d", tt.zoom, tt.dev, n, want)
+		}
+	}
+}
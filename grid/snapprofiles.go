@@ -0,0 +1,154 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+)
+
+// SnapProfile is a named combination of the snap settings in
+// Preferences, for quickly switching between ways of working,
+// e.g., precise work with all snapping on vs. loose sketching
+type SnapProfile struct {
+
+	// name of the profile
+	Name string
+
+	// snap positions and sizes to underlying grid
+	SnapGrid bool
+
+	// snap positions and sizes to line up with other elements
+	SnapGuide bool
+
+	// snap node movements to align with guides
+	SnapNodes bool
+
+	// snap positions and sizes to line up with the edges and center of the page
+	SnapPage bool
+
+	// number of logical screen pixels around target point (in either direction) to snap (see Preferences.SnapTol)
+	SnapTol int `min:"1"`
+}
+
+// Label satisfies the Labeler interface
+func (sp SnapProfile) Label() string {
+	return sp.Name
+}
+
+// FromPrefs sets the profile settings from the current settings in given prefs
+func (sp *SnapProfile) FromPrefs(pf *Preferences) {
+	sp.SnapGrid = pf.SnapGrid
+	sp.SnapGuide = pf.SnapGuide
+	sp.SnapNodes = pf.SnapNodes
+	sp.SnapPage = pf.SnapPage
+	sp.SnapTol = pf.SnapTol
+}
+
+// ToPrefs sets the current settings in given prefs from the profile
+func (sp *SnapProfile) ToPrefs(pf *Preferences) {
+	pf.SnapGrid = sp.SnapGrid
+	pf.SnapGuide = sp.SnapGuide
+	pf.SnapNodes = sp.SnapNodes
+	pf.SnapPage = sp.SnapPage
+	pf.SnapTol = sp.SnapTol
+	if pf.SnapTol < 1 {
+		pf.SnapTol = 1
+	}
+}
+
+// DefaultSnapProfiles returns the standard snap profiles
+func DefaultSnapProfiles() []*SnapProfile {
+	return []*SnapProfile{
+		{Name: "Precise", SnapGrid: true, SnapGuide: true, SnapNodes: true, SnapPage: true, SnapTol: 3},
+		{Name: "Loose", SnapGrid: false, SnapGuide: true, SnapNodes: false, SnapPage: true, SnapTol: 8},
+		{Name: "No Snap", SnapTol: 3},
+	}
+}
+
+// SnapProfileByName returns the snap profile with given name, and nil if none
+func (pf *Preferences) SnapProfileByName(name string) *SnapProfile {
+	for _, sp := range pf.SnapProfiles {
+		if sp.Name == name {
+			return sp
+		}
+	}
+	return nil
+}
+
+// SnapProfileNames returns the names of the snap profiles
+func (pf *Preferences) SnapProfileNames() []string {
+	nms := make([]string, len(pf.SnapProfiles))
+	for i, sp := range pf.SnapProfiles {
+		nms[i] = sp.Name
+	}
+	return nms
+}
+
+// SnapProfileLabel returns the label for the snap profile chooser:
+// the name of the active profile, or Snap if none is active
+func (pf *Preferences) SnapProfileLabel() string {
+	if pf.SnapProfile == "" {
+		return "Snap"
+	}
+	return pf.SnapProfile
+}
+
+// SetSnapProfile sets the snap settings from the snap profile of given
+// name, making it the active profile, and saves the preferences
+func (gv *GridView) SetSnapProfile(name string) error {
+	sp := Prefs.SnapProfileByName(name)
+	if sp == nil {
+		return fmt.Errorf("grid: snap profile named: %s not found", name)
+	}
+	sp.ToPrefs(&Prefs)
+	Prefs.SnapProfile = sp.Name
+	Prefs.Save()
+	gv.UpdateSnapToolbar()
+	gv.SetStatus("Snap profile: " + sp.Name)
+	return nil
+}
+
+// SaveSnapProfile saves the current snap settings as the snap profile of
+// given name, replacing any existing profile of that name, making it the
+// active profile, and saves the preferences
+func (gv *GridView) SaveSnapProfile(name string) {
+	if name == "" {
+		return
+	}
+	sp := Prefs.SnapProfileByName(name)
+	if sp == nil {
+		sp = &SnapProfile{Name: name}
+		Prefs.SnapProfiles = append(Prefs.SnapProfiles, sp)
+	}
+	sp.FromPrefs(&Prefs)
+	Prefs.SnapProfile = sp.Name
+	Prefs.Save()
+	gv.UpdateSnapToolbar()
+	gv.SetStatus("Saved snap profile: " + sp.Name)
+}
+
+// SnapChanged is called when an individual snap setting is changed in the
+// toolbar, which no longer matches the active snap profile
+func (gv *GridView) SnapChanged() {
+	Prefs.SnapProfile = ""
+	gv.UpdateSnapToolbar()
+}
+
+// UpdateSnapToolbar updates the snap settings and snap profile chooser
+// in the select toolbar from the current preferences
+func (gv *GridView) UpdateSnapToolbar() {
+	tb := gv.SelectToolbar()
+	if grs, ok := tb.ChildByName("snap-grid", 0).(*gi.CheckBox); ok {
+		grs.SetChecked(Prefs.SnapGrid)
+	}
+	if gis, ok := tb.ChildByName("snap-guide", 1).(*gi.CheckBox); ok {
+		gis.SetChecked(Prefs.SnapGuide)
+	}
+	if spa, ok := tb.ChildByName("snap-profile", 2).(*gi.Action); ok {
+		spa.SetText(Prefs.SnapProfileLabel())
+	}
+}
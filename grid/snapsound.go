@@ -0,0 +1,61 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+)
+
+// SnapClickCmds are the commands, for each platform, that play the short,
+// quiet click of PlaySnapClick -- the first one whose program and sound
+// file are installed is used
+var SnapClickCmds = map[string][][]string{
+	"darwin": {
+		{"afplay", "-v", "0.3", "/System/Library/Sounds/Tink.aiff"},
+	},
+	"linux": {
+		{"paplay", "--volume=20000", "/usr/share/sounds/freedesktop/stereo/audio-volume-change.oga"},
+		{"canberra-gtk-play", "-i", "audio-volume-change"},
+	},
+}
+
+var (
+	snapClickOnce sync.Once
+	snapClickCmd  []string
+)
+
+// SnapClickCmd returns the command from SnapClickCmds that plays a click
+// on this platform, or nil if there is none
+func SnapClickCmd() []string {
+	snapClickOnce.Do(func() {
+		for _, cmd := range SnapClickCmds[runtime.GOOS] {
+			if _, err := exec.LookPath(cmd[0]); err != nil {
+				continue
+			}
+			if fn := cmd[len(cmd)-1]; fn[0] == '/' {
+				if _, err := os.Stat(fn); err != nil {
+					continue
+				}
+			}
+			snapClickCmd = cmd
+			return
+		}
+	})
+	return snapClickCmd
+}
+
+// PlaySnapClick plays a subtle click with the system sound player, if
+// there is one on this platform (see SnapClickCmds), waiting until it
+// is done
+func PlaySnapClick() {
+	cmd := SnapClickCmd()
+	if cmd == nil {
+		return
+	}
+	exec.Command(cmd[0], cmd[1:]...).Run()
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 
 	"github.com/goki/gi/gi"
@@ -134,7 +135,7 @@ func (lt *Splits) SaveJSON(filename gi.FileName) error {
 		log.Println(err) // unlikely
 		return err
 	}
-	err = ioutil.WriteFile(string(filename), b, 0644)
+	err = WriteFileAtomic(string(filename), b, 0644)
 	if err != nil {
 		gi.PromptDialog(nil, gi.DlgOpts{Title: "Could not Save to File", Prompt: err.Error()}, gi.AddOk, gi.NoCancel, nil, nil)
 		log.Println(err)
@@ -150,6 +151,8 @@ func (lt *Splits) OpenPrefs() error {
 	err := lt.OpenJSON(gi.FileName(pnm))
 	if err == nil {
 		AvailSplitNames = lt.Names()
+	} else if os.IsNotExist(err) { // first run: keep the standard splits
+		return nil
 	}
 	return err
 }
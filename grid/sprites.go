@@ -11,8 +11,10 @@ import (
 	"image/draw"
 
 	"github.com/goki/gi/gi"
+	"github.com/goki/gi/girl"
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/oswin"
+	"github.com/goki/gi/units"
 	"github.com/goki/ki/ints"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
@@ -36,7 +38,9 @@ const (
 	// SpNodePoint is a main coordinate point for path node
 	SpNodePoint
 
-	// SpNodeCtrl is a control coordinate point for path node
+	// SpNodeCtrl is a control coordinate point for path node,
+	// subtyp = SpNodeCtrl1 or SpNodeCtrl2 for the first or second control point
+	// of the curve segment ending at node idx
 	SpNodeCtrl
 
 	// SpRubberBand is the draggable sel box
@@ -47,6 +51,65 @@ const (
 	// subtyp is actually BBoxPoints so we just hack cast that
 	SpAlignMatch
 
+	// SpOverlayBBox is a debug overlay bounding box of an element (n of these),
+	// subtyp = bbox corners as for SpSelBBox
+	SpOverlayBBox
+
+	// SpOverlayLabel is a debug overlay id label of an element (n of these)
+	SpOverlayLabel
+
+	// SpSnapZone is the snap tolerance zone around a candidate align point (n of these)
+	SpSnapZone
+
+	// SpVanishPt is a draggable perspective vanishing point (n of these)
+	SpVanishPt
+
+	// SpNodeSnap highlights the node of the same path that a dragged node is snapped to,
+	// idx = 1 if releasing the drag will close the path, else 0
+	SpNodeSnap
+
+	// SpSelPreview outlines an element that would be selected on releasing
+	// a box select drag (n of these), subtyp = bbox corners as for SpSelBBox
+	SpSelPreview
+
+	// SpNodeCtrlLine is the line from a path node to its control point,
+	// subtyp and idx as for SpNodeCtrl
+	SpNodeCtrlLine
+
+	// SpGradientPt is a draggable point of the gradient of the selected element,
+	// idx = 0 for the fill, 1 for the stroke gradient, subtyp = SpGradientStart,
+	// SpGradientEnd for linear or SpGradientCenter, SpGradientRadius for radial
+	SpGradientPt
+
+	// SpGradientLine is the line between the points of a gradient, idx as for SpGradientPt
+	SpGradientLine
+
+	// SpLockedBBox marks a selected element that is locked, which cannot be
+	// moved or reshaped (n of these), subtyp = bbox corners as for SpSelBBox
+	SpLockedBBox
+
+	// SpDragReadout is a label showing the position and size of the selection
+	// in drawing units while moving or reshaping it
+	SpDragReadout
+
+	// SpRuler is a ruler along the edge of the view, subtyp = SpBBoxUpC for
+	// the horizontal ruler along the top, SpBBoxLfM for the vertical one
+	SpRuler
+
+	// SpRulerMark marks the mouse position on a ruler, subtyp as for SpRuler
+	SpRulerMark
+
+	// SpMeasure is the line being measured with the MeasureTool (idx = 0)
+	// and the label showing its length and angle (idx = 1)
+	SpMeasure
+
+	// SpPencil is the line being drawn with the PencilTool
+	SpPencil
+
+	// SpRoundCorner is the draggable handle for rounding the corners of
+	// the selected path or rect with the NodeTool
+	SpRoundCorner
+
 	// below are subtypes:
 
 	// Sprite bounding boxes are set as a "bbox" property on sprites
@@ -59,7 +122,16 @@ const (
 	SpBBoxLfM
 	SpBBoxRtM
 
-	// todo: add nodectrl subtypes
+	// Node control points: first and second control point of a curve segment
+	SpNodeCtrl1
+	SpNodeCtrl2
+
+	// Gradient points: start and end of a linear gradient, center and
+	// radius of a radial gradient
+	SpGradientStart
+	SpGradientEnd
+	SpGradientCenter
+	SpGradientRadius
 
 	SpritesN
 )
@@ -86,12 +158,46 @@ var SpriteNames = map[Sprites]string{
 
 	SpSelBBox: "sel-bbox",
 
-	SpNodePoint: "node-point",
-	SpNodeCtrl:  "node-ctrl",
+	SpNodePoint:    "node-point",
+	SpNodeCtrl:     "node-ctrl",
+	SpNodeCtrlLine: "node-ctrl-line",
+	SpNodeCtrl1:    "ctrl-1",
+	SpNodeCtrl2:    "ctrl-2",
 
 	SpRubberBand: "rubber-band",
 
 	SpAlignMatch: "align-match",
+
+	SpOverlayBBox:  "overlay-bbox",
+	SpOverlayLabel: "overlay-label",
+
+	SpSnapZone: "snap-zone",
+
+	SpVanishPt: "vanish-pt",
+
+	SpNodeSnap: "node-snap",
+
+	SpSelPreview: "sel-preview",
+
+	SpGradientPt:     "gradient-pt",
+	SpGradientLine:   "gradient-line",
+	SpGradientStart:  "start",
+	SpGradientEnd:    "end",
+	SpGradientCenter: "center",
+	SpGradientRadius: "radius",
+
+	SpLockedBBox: "locked-bbox",
+
+	SpDragReadout: "drag-readout",
+
+	SpRuler:     "ruler",
+	SpRulerMark: "ruler-mark",
+
+	SpMeasure: "measure",
+
+	SpPencil: "pencil",
+
+	SpRoundCorner: "round-corner",
 }
 
 // SpriteName returns the unique name of the sprite based
@@ -105,12 +211,34 @@ func SpriteName(typ, subtyp Sprites, idx int) string {
 		nm += fmt.Sprintf("-%d-%s", idx, SpriteNames[subtyp])
 	case SpNodePoint:
 		nm += fmt.Sprintf("-%d", idx)
-	case SpNodeCtrl: // todo: subtype
-		nm += fmt.Sprintf("-%d", idx)
+	case SpNodeCtrl, SpNodeCtrlLine:
+		nm += fmt.Sprintf("-%d-%s", idx, SpriteNames[subtyp])
 	case SpRubberBand:
 		nm += "-" + SpriteNames[subtyp]
 	case SpAlignMatch:
 		nm += fmt.Sprintf("-%d", idx)
+	case SpOverlayBBox:
+		nm += fmt.Sprintf("-%d-%s", idx, SpriteNames[subtyp])
+	case SpOverlayLabel:
+		nm += fmt.Sprintf("-%d", idx)
+	case SpSnapZone:
+		nm += fmt.Sprintf("-%d", idx)
+	case SpVanishPt:
+		nm += fmt.Sprintf("-%d", idx)
+	case SpNodeSnap:
+		nm += fmt.Sprintf("-%d", idx)
+	case SpSelPreview:
+		nm += fmt.Sprintf("-%d-%s", idx, SpriteNames[subtyp])
+	case SpGradientPt:
+		nm += fmt.Sprintf("-%d-%s", idx, SpriteNames[subtyp])
+	case SpGradientLine:
+		nm += fmt.Sprintf("-%d", idx)
+	case SpLockedBBox:
+		nm += fmt.Sprintf("-%d-%s", idx, SpriteNames[subtyp])
+	case SpRuler, SpRulerMark:
+		nm += "-" + SpriteNames[subtyp]
+	case SpMeasure:
+		nm += fmt.Sprintf("-%d", idx)
 	}
 	return nm
 }
@@ -150,7 +278,7 @@ func Sprite(win *gi.Window, typ, subtyp Sprites, idx int, trgsz image.Point) *gi
 	switch typ {
 	case SpReshapeBBox:
 		DrawSpriteReshape(sp, subtyp)
-	case SpSelBBox:
+	case SpSelBBox, SpOverlayBBox:
 		DrawSpriteSel(sp, subtyp)
 	case SpNodePoint:
 		DrawSpriteNodePoint(sp, subtyp)
@@ -170,6 +298,22 @@ func Sprite(win *gi.Window, typ, subtyp Sprites, idx int, trgsz image.Point) *gi
 		default:
 			DrawAlignMatchVert(sp, trgsz)
 		}
+	case SpSnapZone:
+		DrawSnapZone(sp, trgsz)
+	case SpVanishPt:
+		DrawSpriteVanishPt(sp)
+	case SpNodeSnap:
+		DrawSpriteNodeSnap(sp, idx == 1)
+	case SpSelPreview:
+		DrawSpriteSelPreview(sp, subtyp)
+	case SpGradientPt:
+		DrawSpriteGradientPt(sp, subtyp, idx)
+	case SpLockedBBox:
+		DrawSpriteLocked(sp, subtyp)
+	case SpRulerMark:
+		DrawSpriteRulerMark(sp, subtyp)
+	case SpRoundCorner:
+		DrawSpriteRoundCorner(sp)
 	}
 	win.ActivateSprite(sp.Name)
 	return sp
@@ -210,16 +354,24 @@ func SetSpritePos(sp *gi.Sprite, pos image.Point) {
 		case BBMiddle:
 			pos.Y -= sz / 2
 		}
-	case typ == SpNodePoint || typ == SpNodeCtrl:
+	case typ == SpNodePoint || typ == SpVanishPt || typ == SpRoundCorner:
 		_, sz := HandleSpriteSize(1)
 		pos.X -= sz.X / 2
 		pos.Y -= sz.Y / 2
+	case typ == SpNodeCtrl:
+		_, sz := HandleSpriteSize(.8)
+		pos.X -= sz.X / 2
+		pos.Y -= sz.Y / 2
+	case typ == SpGradientPt:
+		_, sz := HandleSpriteSize(GradientHandleScale(subtyp))
+		pos.X -= sz.X / 2
+		pos.Y -= sz.Y / 2
+	case typ == SpNodeSnap:
+		_, sz := HandleSpriteSize(1.6)
+		pos.X -= sz.X / 2
+		pos.Y -= sz.Y / 2
 	case subtyp >= SpBBoxUpL && subtyp <= SpBBoxRtM: // Reshape, Sel BBox
-		sc := float32(1)
-		if typ == SpSelBBox {
-			sc = .8
-		}
-		_, sz := HandleSpriteSize(sc)
+		_, sz := HandleSpriteSize(BBoxHandleScale(typ))
 		if subtyp == SpBBoxDnL || subtyp == SpBBoxUpL || subtyp == SpBBoxLfM {
 			pos.X -= sz.X
 		}
@@ -251,7 +403,9 @@ var (
 )
 
 // HandleSpriteSize returns the border size and overall size
-// of handle-type sprites, with given scaling factor
+// of handle-type sprites, with given scaling factor.
+// The size is in screen pixels, independent of the view zoom,
+// so handles are always the same size on screen.
 func HandleSpriteSize(scale float32) (int, image.Point) {
 	sz := int(mat32.Ceil(scale * gi.Prefs.LogicalDPIScale * HandleSpriteScale))
 	sz = ints.MaxInt(sz, HandleSizeMin)
@@ -260,6 +414,15 @@ func HandleSpriteSize(scale float32) (int, image.Point) {
 	return bsz, bbsz
 }
 
+// BBoxHandleScale returns the scaling factor for the size of the
+// bbox handle sprites of given type
+func BBoxHandleScale(typ Sprites) float32 {
+	if typ == SpSelBBox || typ == SpOverlayBBox || typ == SpSelPreview || typ == SpLockedBBox {
+		return .8
+	}
+	return 1
+}
+
 // DrawSpriteReshape renders a Reshape sprite handle
 func DrawSpriteReshape(sp *gi.Sprite, bbtyp Sprites) {
 	bsz, bbsz := HandleSpriteSize(1)
@@ -292,6 +455,44 @@ func DrawSpriteSel(sp *gi.Sprite, bbtyp Sprites) {
 	draw.Draw(sp.Pixels, bbd, &image.Uniform{color.Black}, image.ZP, draw.Src)
 }
 
+// DrawSpriteSelPreview renders a box select preview sprite handle --
+// same as the Select handle but in a highlight color
+func DrawSpriteSelPreview(sp *gi.Sprite, bbtyp Sprites) {
+	bsz, bbsz := HandleSpriteSize(.8)
+	if !sp.SetSize(bbsz) { // already set
+		return
+	}
+	ibd := sp.Pixels.Bounds()
+	bbd := ibd
+	bbd.Min.X += bsz
+	bbd.Min.Y += bsz
+	bbd.Max.X -= bsz
+	bbd.Max.Y -= bsz
+	draw.Draw(sp.Pixels, ibd, &image.Uniform{color.White}, image.ZP, draw.Src)
+	draw.Draw(sp.Pixels, bbd, &image.Uniform{color.RGBA{0, 120, 215, 255}}, image.ZP, draw.Src)
+}
+
+// LockedColor is the color of the handles marking selected elements
+// that are locked
+var LockedColor = color.RGBA{200, 40, 40, 255}
+
+// DrawSpriteLocked renders a locked element sprite handle -- same as
+// the Select handle but in LockedColor
+func DrawSpriteLocked(sp *gi.Sprite, bbtyp Sprites) {
+	bsz, bbsz := HandleSpriteSize(.8)
+	if !sp.SetSize(bbsz) { // already set
+		return
+	}
+	ibd := sp.Pixels.Bounds()
+	bbd := ibd
+	bbd.Min.X += bsz
+	bbd.Min.Y += bsz
+	bbd.Max.X -= bsz
+	bbd.Max.Y -= bsz
+	draw.Draw(sp.Pixels, ibd, &image.Uniform{color.White}, image.ZP, draw.Src)
+	draw.Draw(sp.Pixels, bbd, &image.Uniform{LockedColor}, image.ZP, draw.Src)
+}
+
 // DrawSpriteNodePoint renders a NodePoint sprite handle
 func DrawSpriteNodePoint(sp *gi.Sprite, bbtyp Sprites) {
 	bsz, bbsz := HandleSpriteSize(1)
@@ -308,8 +509,43 @@ func DrawSpriteNodePoint(sp *gi.Sprite, bbtyp Sprites) {
 	draw.Draw(sp.Pixels, bbd, &image.Uniform{color.Black}, image.ZP, draw.Src)
 }
 
-// DrawSpriteNodeCtrl renders a NodePoint sprite handle
-func DrawSpriteNodeCtrl(sp *gi.Sprite, subtyp Sprites) {
+// GradientStrokeColor is the color of the stroke gradient handles --
+// the fill gradient handles use the selection preview color
+var GradientStrokeColor = color.RGBA{255, 128, 0, 255}
+
+// GradientHandleScale returns the scaling factor for the size of the
+// gradient handle sprites of given subtype: the start and center
+// points are larger than the end and radius points
+func GradientHandleScale(subtyp Sprites) float32 {
+	if subtyp == SpGradientStart || subtyp == SpGradientCenter {
+		return 1
+	}
+	return .8
+}
+
+// DrawSpriteGradientPt renders a gradient point sprite handle,
+// for the fill (idx = 0) or stroke (idx = 1) gradient
+func DrawSpriteGradientPt(sp *gi.Sprite, subtyp Sprites, idx int) {
+	bsz, bbsz := HandleSpriteSize(GradientHandleScale(subtyp))
+	if !sp.SetSize(bbsz) { // already set
+		return
+	}
+	ibd := sp.Pixels.Bounds()
+	bbd := ibd
+	bbd.Min.X += bsz
+	bbd.Min.Y += bsz
+	bbd.Max.X -= bsz
+	bbd.Max.Y -= bsz
+	var clr color.Color = color.RGBA{0, 120, 215, 255}
+	if idx == 1 {
+		clr = GradientStrokeColor
+	}
+	draw.Draw(sp.Pixels, ibd, &image.Uniform{color.White}, image.ZP, draw.Src)
+	draw.Draw(sp.Pixels, bbd, &image.Uniform{clr}, image.ZP, draw.Src)
+}
+
+// DrawSpriteVanishPt renders a perspective vanishing point sprite handle
+func DrawSpriteVanishPt(sp *gi.Sprite) {
 	bsz, bbsz := HandleSpriteSize(1)
 	if !sp.SetSize(bbsz) { // already set
 		return
@@ -321,7 +557,166 @@ func DrawSpriteNodeCtrl(sp *gi.Sprite, subtyp Sprites) {
 	bbd.Max.X -= bsz
 	bbd.Max.Y -= bsz
 	draw.Draw(sp.Pixels, ibd, &image.Uniform{color.White}, image.ZP, draw.Src)
-	draw.Draw(sp.Pixels, bbd, &image.Uniform{color.Black}, image.ZP, draw.Src)
+	clr := PerspectiveColor
+	clr.A = 255
+	draw.Draw(sp.Pixels, bbd, &image.Uniform{clr}, image.ZP, draw.Src)
+}
+
+// NodeCloseColor is the color of the node snap sprite when releasing
+// the drag will close the path
+var NodeCloseColor = gist.Color{R: 0, G: 200, B: 64, A: 255}
+
+// DrawSpriteNodeSnap renders a NodeSnap sprite: a hollow square
+// around the node that a dragged node snaps to, in NodeCloseColor
+// if releasing the drag will close the path
+func DrawSpriteNodeSnap(sp *gi.Sprite, closes bool) {
+	bsz, bbsz := HandleSpriteSize(1.6)
+	if !sp.SetSize(bbsz) { // already set
+		return
+	}
+	ibd := sp.Pixels.Bounds()
+	bbd := ibd
+	bbd.Min.X += bsz
+	bbd.Min.Y += bsz
+	bbd.Max.X -= bsz
+	bbd.Max.Y -= bsz
+	clr := GuideColor
+	clr.A = 255
+	if closes {
+		clr = NodeCloseColor
+	}
+	draw.Draw(sp.Pixels, ibd, &image.Uniform{clr}, image.ZP, draw.Src)
+	draw.Draw(sp.Pixels, bbd, &image.Uniform{color.Transparent}, image.ZP, draw.Src)
+}
+
+// RoundCornerColor is the color of the round corners handle
+var RoundCornerColor = gist.Color{R: 255, G: 140, B: 0, A: 255}
+
+// DrawSpriteRoundCorner renders the round corners sprite handle: a round
+// dot in RoundCornerColor with a white border, to set it apart from the
+// square node handles
+func DrawSpriteRoundCorner(sp *gi.Sprite) {
+	bsz, bbsz := HandleSpriteSize(1)
+	if !sp.SetSize(bbsz) { // already set
+		return
+	}
+	c := .5 * float32(bbsz.X)
+	for y := 0; y < bbsz.Y; y++ {
+		for x := 0; x < bbsz.X; x++ {
+			d := mat32.V2(float32(x)+.5-c, float32(y)+.5-c).Length()
+			var clr color.Color = color.Transparent
+			switch {
+			case d <= c-float32(bsz):
+				clr = RoundCornerColor
+			case d <= c:
+				clr = color.White
+			}
+			sp.Pixels.Set(x, y, clr)
+		}
+	}
+}
+
+// DrawSpriteNodeCtrl renders a NodeCtrl sprite handle -- smaller than
+// a node point, in the guide color
+func DrawSpriteNodeCtrl(sp *gi.Sprite, subtyp Sprites) {
+	bsz, bbsz := HandleSpriteSize(.8)
+	if !sp.SetSize(bbsz) { // already set
+		return
+	}
+	ibd := sp.Pixels.Bounds()
+	bbd := ibd
+	bbd.Min.X += bsz
+	bbd.Min.Y += bsz
+	bbd.Max.X -= bsz
+	bbd.Max.Y -= bsz
+	clr := GuideColor
+	clr.A = 255
+	draw.Draw(sp.Pixels, ibd, &image.Uniform{color.White}, image.ZP, draw.Src)
+	draw.Draw(sp.Pixels, bbd, &image.Uniform{clr}, image.ZP, draw.Src)
+}
+
+// DrawSpriteNodeCtrlLine renders the line from a node to its control
+// point, given in window coords, and positions the sprite to cover it
+func DrawSpriteNodeCtrlLine(sp *gi.Sprite, from, to mat32.Vec2) {
+	bb := mat32.Box2{}
+	bb.SetEmpty()
+	bb.ExpandByPoint(from)
+	bb.ExpandByPoint(to)
+	bb.ExpandByScalar(1)
+	r := bb.ToRect()
+	pos, sz := r.Min, r.Size()
+	sp.SetSize(sz)
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{color.Transparent}, image.ZP, draw.Src)
+	off := mat32.NewVec2FmPoint(pos)
+	pc := &girl.Paint{}
+	pc.Defaults()
+	rs := &girl.State{}
+	rs.Init(sz.X, sz.Y, sp.Pixels)
+	rs.PushBounds(sp.Pixels.Bounds())
+	clr := GuideColor
+	pc.StrokeStyle.SetColor(&clr)
+	pc.StrokeStyle.Width.Dots = 1
+	pc.DrawLine(rs, from.X-off.X, from.Y-off.Y, to.X-off.X, to.Y-off.Y)
+	pc.Stroke(rs)
+	rs.PopBounds()
+	sp.Geom.Pos = pos
+}
+
+// DrawSpritePolyline renders a line through given points in window
+// coordinates, in the guide color, sizing and positioning the sprite to fit
+func DrawSpritePolyline(sp *gi.Sprite, pts []mat32.Vec2) {
+	bb := mat32.Box2{}
+	bb.SetEmpty()
+	for _, p := range pts {
+		bb.ExpandByPoint(p)
+	}
+	bb.ExpandByScalar(1)
+	r := bb.ToRect()
+	pos, sz := r.Min, r.Size()
+	sp.SetSize(sz)
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{color.Transparent}, image.ZP, draw.Src)
+	off := mat32.NewVec2FmPoint(pos)
+	lpts := make([]mat32.Vec2, len(pts))
+	for i, p := range pts {
+		lpts[i] = p.Sub(off)
+	}
+	pc := &girl.Paint{}
+	pc.Defaults()
+	rs := &girl.State{}
+	rs.Init(sz.X, sz.Y, sp.Pixels)
+	rs.PushBounds(sp.Pixels.Bounds())
+	clr := GuideColor
+	pc.StrokeStyle.SetColor(&clr)
+	pc.StrokeStyle.Width.Dots = 1
+	pc.DrawPolyline(rs, lpts)
+	pc.Stroke(rs)
+	rs.PopBounds()
+	sp.Geom.Pos = pos
+}
+
+// DrawSpriteLabel renders given text label into the sprite, which is
+// sized to fit -- only re-renders if the label has changed
+func DrawSpriteLabel(sp *gi.Sprite, label string) {
+	if cl, has := sp.Props["grid-label"]; has && cl.(string) == label && sp.Pixels != nil {
+		return
+	}
+	sp.Props.Set("grid-label", label)
+	pc := &girl.Paint{}
+	pc.Defaults()
+	pc.FontStyle.Size.Set(10, units.Px)
+	pc.UnContext.Defaults()
+	pc.ToDots()
+	girl.OpenFont(&pc.FontStyle, &pc.UnContext)
+	tr := &girl.Text{}
+	tr.SetString(label, &pc.FontStyle, &pc.UnContext, &pc.TextStyle, true, 0, 1)
+	sz := image.Point{int(mat32.Ceil(tr.Size.X)) + 4, int(mat32.Ceil(tr.Size.Y)) + 2}
+	sp.SetSize(sz)
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{gist.Color{255, 255, 200, 220}}, image.ZP, draw.Src)
+	rs := &girl.State{}
+	rs.Init(sz.X, sz.Y, sp.Pixels)
+	rs.PushBounds(sp.Pixels.Bounds())
+	tr.Render(rs, mat32.Vec2{2, 1})
+	rs.PopBounds()
 }
 
 var (
@@ -407,3 +802,12 @@ func DrawAlignMatchVert(sp *gi.Sprite, trgsz image.Point) {
 	draw.Draw(sp.Pixels, ibd, &image.Uniform{color.White}, image.ZP, draw.Src)
 	draw.Draw(sp.Pixels, bbd, &image.Uniform{clr}, image.ZP, draw.Src)
 }
+
+// DrawSnapZone renders a translucent snap tolerance zone
+func DrawSnapZone(sp *gi.Sprite, trgsz image.Point) {
+	if !sp.SetSize(trgsz) { // already set
+		return
+	}
+	clr := gist.Color{0, 200, 200, 60}
+	draw.Draw(sp.Pixels, sp.Pixels.Bounds(), &image.Uniform{clr}, image.ZP, draw.Src)
+}
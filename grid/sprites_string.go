@@ -20,20 +20,42 @@ func _() {
 	_ = x[SpNodeCtrl-4]
 	_ = x[SpRubberBand-5]
 	_ = x[SpAlignMatch-6]
-	_ = x[SpBBoxUpL-7]
-	_ = x[SpBBoxUpC-8]
-	_ = x[SpBBoxUpR-9]
-	_ = x[SpBBoxDnL-10]
-	_ = x[SpBBoxDnC-11]
-	_ = x[SpBBoxDnR-12]
-	_ = x[SpBBoxLfM-13]
-	_ = x[SpBBoxRtM-14]
-	_ = x[SpritesN-15]
+	_ = x[SpOverlayBBox-7]
+	_ = x[SpOverlayLabel-8]
+	_ = x[SpSnapZone-9]
+	_ = x[SpVanishPt-10]
+	_ = x[SpNodeSnap-11]
+	_ = x[SpSelPreview-12]
+	_ = x[SpNodeCtrlLine-13]
+	_ = x[SpGradientPt-14]
+	_ = x[SpGradientLine-15]
+	_ = x[SpLockedBBox-16]
+	_ = x[SpDragReadout-17]
+	_ = x[SpRuler-18]
+	_ = x[SpRulerMark-19]
+	_ = x[SpMeasure-20]
+	_ = x[SpPencil-21]
+	_ = x[SpRoundCorner-22]
+	_ = x[SpBBoxUpL-23]
+	_ = x[SpBBoxUpC-24]
+	_ = x[SpBBoxUpR-25]
+	_ = x[SpBBoxDnL-26]
+	_ = x[SpBBoxDnC-27]
+	_ = x[SpBBoxDnR-28]
+	_ = x[SpBBoxLfM-29]
+	_ = x[SpBBoxRtM-30]
+	_ = x[SpNodeCtrl1-31]
+	_ = x[SpNodeCtrl2-32]
+	_ = x[SpGradientStart-33]
+	_ = x[SpGradientEnd-34]
+	_ = x[SpGradientCenter-35]
+	_ = x[SpGradientRadius-36]
+	_ = x[SpritesN-37]
 }
 
-const _Sprites_name = "SpUnkSpReshapeBBoxSpSelBBoxSpNodePointSpNodeCtrlSpRubberBandSpAlignMatchSpBBoxUpLSpBBoxUpCSpBBoxUpRSpBBoxDnLSpBBoxDnCSpBBoxDnRSpBBoxLfMSpBBoxRtMSpritesN"
+const _Sprites_name = "SpUnkSpReshapeBBoxSpSelBBoxSpNodePointSpNodeCtrlSpRubberBandSpAlignMatchSpOverlayBBoxSpOverlayLabelSpSnapZoneSpVanishPtSpNodeSnapSpSelPreviewSpNodeCtrlLineSpGradientPtSpGradientLineSpLockedBBoxSpDragReadoutSpRulerSpRulerMarkSpMeasureSpPencilSpRoundCornerSpBBoxUpLSpBBoxUpCSpBBoxUpRSpBBoxDnLSpBBoxDnCSpBBoxDnRSpBBoxLfMSpBBoxRtMSpNodeCtrl1SpNodeCtrl2SpGradientStartSpGradientEndSpGradientCenterSpGradientRadiusSpritesN"
 
-var _Sprites_index = [...]uint8{0, 5, 18, 27, 38, 48, 60, 72, 81, 90, 99, 108, 117, 126, 135, 144, 152}
+var _Sprites_index = [...]uint16{0, 5, 18, 27, 38, 48, 60, 72, 85, 99, 109, 119, 129, 141, 155, 167, 181, 193, 206, 213, 224, 233, 241, 254, 263, 272, 281, 290, 299, 308, 317, 326, 337, 348, 363, 376, 392, 408, 416}
 
 func (i Sprites) String() string {
 	if i < 0 || i >= Sprites(len(_Sprites_index)-1) {
@@ -0,0 +1,287 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xmlAttr is a raw attribute name and (escaped) value within a start tag
+type xmlAttr struct {
+	name string
+	val  string
+}
+
+// StableXML returns given svg output with the attributes of every element
+// in a deterministic order (id first, then sorted by name), and the
+// properties within style attributes sorted by name.  The svg writer
+// otherwise emits these in random map order, so re-saving an unchanged
+// drawing produces a large diff under version control.
+// Anything that cannot be parsed as a simple start tag is copied verbatim.
+func StableXML(b []byte) []byte {
+	return StableXMLPrec(b, -1)
+}
+
+// StableXMLPrec is StableXML with the decimal numbers in the geometry
+// attributes (see StableNumAttrs) and numeric style properties also
+// rounded to given number of decimal places and formatted in a fixed way
+// (no exponent, no trailing zeros), so that float rounding noise from
+// manipulations does not affect the output.  Integers, hex colors and
+// other attributes, such as labels, are not changed.  prec < 0 leaves
+// numbers as is.
+// This is suitable for comparing output against golden files in tests.
+func StableXMLPrec(b []byte, prec int) []byte {
+	var out bytes.Buffer
+	out.Grow(len(b))
+	n := len(b)
+	i := 0
+	for i < n {
+		lt := bytes.IndexByte(b[i:], '<')
+		if lt < 0 {
+			out.Write(b[i:])
+			break
+		}
+		out.Write(b[i : i+lt])
+		i += lt
+		tag, nm, ok := stableStartTag(b[i:], prec)
+		if !ok {
+			out.WriteByte('<')
+			i++
+			continue
+		}
+		out.WriteString(tag)
+		i += stableTagLen(b[i:])
+		if nm == "style" { // css content is copied verbatim
+			if ei := bytes.Index(b[i:], []byte("</style>")); ei >= 0 {
+				out.Write(b[i : i+ei])
+				i += ei
+			}
+		}
+	}
+	return out.Bytes()
+}
+
+// StableNumAttrs are the attributes whose decimal numbers StableXMLPrec
+// rounds: the numeric geometry -- numbers in other attributes can be
+// part of text, such as a layer label or file name
+var StableNumAttrs = map[string]bool{
+	"x": true, "y": true, "width": true, "height": true,
+	"cx": true, "cy": true, "r": true, "rx": true, "ry": true,
+	"x1": true, "y1": true, "x2": true, "y2": true,
+	"d": true, "points": true, "transform": true, "viewBox": true,
+}
+
+// stableTagLen returns the length of the start tag at start of b,
+// through the closing '>'
+func stableTagLen(b []byte) int {
+	inq := false
+	for i, c := range b {
+		switch {
+		case c == '"':
+			inq = !inq
+		case c == '>' && !inq:
+			return i + 1
+		}
+	}
+	return len(b)
+}
+
+// stableStartTag parses the start tag at the start of b, returning the
+// start tag with its attributes sorted, the element name, and false
+// if b does not start with a simple start tag.  Numbers are rounded to
+// prec decimal places if prec >= 0.
+func stableStartTag(b []byte, prec int) (string, string, bool) {
+	if len(b) < 2 || b[0] != '<' || b[1] == '/' || b[1] == '?' || b[1] == '!' {
+		return "", "", false
+	}
+	tl := stableTagLen(b)
+	if b[tl-1] != '>' {
+		return "", "", false
+	}
+	s := string(b[1 : tl-1])
+	end := ">"
+	if strings.HasSuffix(s, "/") {
+		end = "/>"
+		s = s[:len(s)-1]
+	}
+	ni := strings.IndexAny(s, " \t\r\n")
+	if ni < 0 {
+		return string(b[:tl]), s, true
+	}
+	nm := s[:ni]
+	s = s[ni:]
+	var attrs []xmlAttr
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			break
+		}
+		ei := strings.Index(s, `="`)
+		if ei <= 0 {
+			return "", "", false
+		}
+		an := s[:ei]
+		s = s[ei+2:]
+		qi := strings.IndexByte(s, '"')
+		if qi < 0 {
+			return "", "", false
+		}
+		av := s[:qi]
+		s = s[qi+1:]
+		switch {
+		case an == "style":
+			av = StableStyle(av)
+			if prec >= 0 {
+				av = stableStyleNumbers(av, prec)
+			}
+		case prec >= 0 && StableNumAttrs[an]:
+			av = stableNumbers(av, prec, an == "d" || an == "points")
+		}
+		attrs = append(attrs, xmlAttr{an, av})
+	}
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if attrs[i].name == "id" {
+			return attrs[j].name != "id"
+		}
+		if attrs[j].name == "id" {
+			return false
+		}
+		return attrs[i].name < attrs[j].name
+	})
+	var sb strings.Builder
+	sb.WriteString("<" + nm)
+	for _, at := range attrs {
+		sb.WriteString(" " + at.name + `="` + at.val + `"`)
+	}
+	sb.WriteString(end)
+	return sb.String(), nm, true
+}
+
+// StableStyle returns the given style attribute value with its
+// properties sorted by name
+func StableStyle(st string) string {
+	ps := strings.Split(st, ";")
+	sps := make([]string, 0, len(ps))
+	for _, p := range ps {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			sps = append(sps, p)
+		}
+	}
+	sort.Strings(sps)
+	return strings.Join(sps, ";")
+}
+
+// stableStyleNumbers returns given style attribute value with the
+// decimal numbers in the values of its numeric properties (those that
+// start with a number, e.g., 12.5px) rounded to given number of decimal
+// places (see StableNumbers) -- font names and the like are not changed
+func stableStyleNumbers(st string, prec int) string {
+	ps := strings.Split(st, ";")
+	for i, p := range ps {
+		ci := strings.IndexByte(p, ':')
+		if ci < 0 {
+			continue
+		}
+		v := strings.TrimSpace(p[ci+1:])
+		if v == "" || !strings.ContainsRune("0123456789.-+", rune(v[0])) {
+			continue
+		}
+		ps[i] = p[:ci+1] + StableNumbers(v, prec)
+	}
+	return strings.Join(ps, ";")
+}
+
+// StableNumbers returns given string with all the decimal numbers in it
+// (numbers with a decimal point or exponent) rounded to given number of
+// decimal places, and formatted without exponent or trailing zeros.
+// Numbers that are part of a name or hex value (preceded by a letter,
+// digit, '#' or '_', e.g., rect2.5, or by a '-' or '.' within a name,
+// e.g., sodipodi-0.dtd) are not changed.
+func StableNumbers(s string, prec int) string {
+	return stableNumbers(s, prec, false)
+}
+
+// stableNumbers is StableNumbers, with letters treated as separators
+// if pathData is set, as in path d data (e.g., M10.5,2).
+func stableNumbers(s string, prec int, pathData bool) string {
+	var sb strings.Builder
+	n := len(s)
+	i := 0
+	for i < n {
+		st := i
+		if s[i] == '-' || s[i] == '+' {
+			i++
+		}
+		if i >= n || !(isDigit(s[i]) || (s[i] == '.' && i+1 < n && isDigit(s[i+1]))) || (st > 0 && isNameChar(s[st-1], pathData)) {
+			sb.WriteByte(s[st])
+			i = st + 1
+			if st > 0 && !isDigit(s[st-1]) && isNameChar(s[st-1], pathData) && isNamePart(s[st], pathData) { // rest of name, e.g., sodipodi-0.dtd
+				for i < n && isNamePart(s[i], pathData) {
+					sb.WriteByte(s[i])
+					i++
+				}
+			}
+			continue
+		}
+		dec := false
+		for i < n && isDigit(s[i]) {
+			i++
+		}
+		if i < n && s[i] == '.' {
+			dec = true
+			i++
+			for i < n && isDigit(s[i]) {
+				i++
+			}
+		}
+		if i+1 < n && (s[i] == 'e' || s[i] == 'E') && (isDigit(s[i+1]) || ((s[i+1] == '-' || s[i+1] == '+') && i+2 < n && isDigit(s[i+2]))) {
+			dec = true
+			i += 2
+			for i < n && isDigit(s[i]) {
+				i++
+			}
+		}
+		num := s[st:i]
+		if !dec {
+			sb.WriteString(num)
+			continue
+		}
+		v, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			sb.WriteString(num)
+			continue
+		}
+		ns := strconv.FormatFloat(v, 'f', prec, 64)
+		if strings.Contains(ns, ".") {
+			ns = strings.TrimRight(strings.TrimRight(ns, "0"), ".")
+		}
+		if ns == "-0" {
+			ns = "0"
+		}
+		sb.WriteString(ns)
+	}
+	return sb.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameChar(c byte, pathData bool) bool {
+	if isDigit(c) || c == '#' || c == '_' {
+		return true
+	}
+	return !pathData && ((c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'))
+}
+
+// isNamePart returns true if given char can continue a name that
+// started with a name char, including the '-' and '.' separators
+func isNamePart(c byte, pathData bool) bool {
+	return c == '-' || c == '.' || isNameChar(c, pathData)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden instead of comparing against them")
+
+// compareGolden compares given svg output against the contents of given
+// golden file, reporting the first differing line as a test error.
+// If the -update flag is set, the golden file is (re)written with the
+// output instead.  Outputs should be generated with StableXMLPrec so
+// they are deterministic.
+func compareGolden(t *testing.T, got []byte, golden string) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, want) {
+		return
+	}
+	gl := strings.Split(string(got), "\n")
+	wl := strings.Split(string(want), "\n")
+	for i := 0; i < len(gl) || i < len(wl); i++ {
+		var g, w string
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if g != w {
+			t.Errorf("%s: line %d differs:\n  got:  %s\n  want: %s", golden, i+1, g, w)
+			return
+		}
+	}
+	t.Errorf("%s: output differs", golden)
+}
+
+func TestStableXMLGolden(t *testing.T) {
+	tests := []struct {
+		in     string
+		prec   int
+		golden string
+	}{
+		{"testdata/stablexml.svg", 3, "testdata/golden/stablexml.svg"},
+		{"testdata/stablexml.svg", -1, "testdata/golden/stablexml-noprec.svg"},
+		{"../testdata/shapes.svg", 3, "testdata/golden/shapes.svg"},
+	}
+	for _, tt := range tests {
+		b, err := os.ReadFile(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := StableXMLPrec(b, tt.prec)
+		compareGolden(t, got, tt.golden)
+		if again := StableXMLPrec(got, tt.prec); !bytes.Equal(again, got) {
+			t.Errorf("%s: StableXMLPrec(%d) is not idempotent", tt.in, tt.prec)
+		}
+	}
+}
+
+func TestStableNumbers(t *testing.T) {
+	tests := []struct {
+		in   string
+		prec int
+		want string
+	}{
+		{"10.000000001", 3, "10"},
+		{"-0.30000000000000004", 3, "-0.3"},
+		{"-0.0001", 3, "0"},
+		{"1e-07", 3, "0"},
+		{"2.5E2", 3, "250"},
+		{"12", 3, "12"},
+		{"translate(1.23456,-7.5)", 2, "translate(1.23,-7.5)"},
+		{"font-size:12.0000001px", 3, "font-size:12px"},
+		{"#1a2.5", 3, "#1a2.5"},
+		{"rect2.25", 1, "rect2.25"},
+		{"http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd", 3, "http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd"},
+	}
+	for _, tt := range tests {
+		if got := StableNumbers(tt.in, tt.prec); got != tt.want {
+			t.Errorf("StableNumbers(%q, %d) = %q, want %q", tt.in, tt.prec, got, tt.want)
+		}
+	}
+	if got, want := stableNumbers("M10.5555,2 L3.14159-1.0001Z", 2, true), "M10.56,2 L3.14-1Z"; got != want {
+		t.Errorf("path data: got %q, want %q", got, want)
+	}
+	tag, _, _ := stableStartTag([]byte(`<g inkscape:label="Layer 1.5555" style="font-family:Font 1.5;stroke-width:0.12345" transform="scale(1.5555)">`), 2)
+	if want := `<g inkscape:label="Layer 1.5555" style="font-family:Font 1.5;stroke-width:0.12" transform="scale(1.56)">`; tag != want {
+		t.Errorf("start tag: got %s, want %s", tag, want)
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/girl"
+	"github.com/goki/gi/gist"
 	"github.com/goki/gi/giv"
 	"github.com/goki/gi/oswin"
 	"github.com/goki/gi/oswin/cursor"
@@ -45,6 +46,9 @@ type SVGView struct {
 	// grid spacing, in native ViewBox units
 	Grid float32
 
+	// origin of the grid, in native ViewBox units -- grid lines pass through this point
+	GridOffset mat32.Vec2
+
 	// effective grid spacing given Scale level
 	GridEff float32 `view:"inactive"`
 
@@ -65,6 +69,21 @@ type SVGView struct {
 
 	// bg rendered grid
 	bgGridEff float32 `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// bg rendered grid offset
+	bgGridOffset mat32.Vec2 `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// bg rendered colors
+	bgColors ColorPrefs `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// region to clip the contents to while exporting, in drawing coordinates, if set -- see SetExportExtent
+	exportClip *mat32.Box2 `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// export only the selected elements, while exporting -- see ExportWith
+	exportSel bool `copy:"-" json:"-" xml:"-" view:"-"`
+
+	// last mouse position in window coordinates, marked on the rulers
+	rulerPos image.Point `copy:"-" json:"-" xml:"-" view:"-"`
 }
 
 var KiT_SVGView = kit.Types.AddType(&SVGView{}, SVGViewProps)
@@ -114,7 +133,13 @@ func (sv *SVGView) UpdateView(full bool) {
 	if sv.BgNeedsUpdate() {
 		sv.RenderBg()
 	}
-	sv.UpdateSelSprites()
+	if es := sv.EditState(); es != nil && es.Tool == NodeTool {
+		sv.UpdateNodeSprites() // node positions change with zoom
+	} else {
+		sv.UpdateSelSprites()
+	}
+	sv.UpdateOverlaySprites()
+	sv.UpdatePerspectiveSprites()
 }
 
 func (sv *SVGView) SVGViewKeys(kt *key.ChordEvent) {
@@ -123,11 +148,27 @@ func (sv *SVGView) SVGViewKeys(kt *key.ChordEvent) {
 		fmt.Printf("SVGView KeyInput: %v\n", sv.Path())
 	}
 	kf := keyfun.(kc)
+	switch kc {
+	case "Control+D", "Meta+D":
+		kf = keyfun.Duplicate // whatever it is in the key map
+	case "Control+Shift+Z", "Meta+Shift+Z":
+		kf = keyfun.Redo // in addition to the key map one
+	}
 	switch kf {
 	case keyfun.Abort:
-		// todo: maybe something else
 		kt.SetProcessed()
+		if es := sv.EditState(); es.Tool == SelectTool && es.HasSelected() {
+			sv.GridView.SelectNone()
+			break
+		}
+		if es := sv.EditState(); es.Tool == SelectTool && es.IsolatedGroup() != nil {
+			sv.GridView.ExitGroup()
+			break
+		}
 		sv.GridView.SetTool(SelectTool)
+	case keyfun.SelectAll:
+		kt.SetProcessed()
+		sv.GridView.SelectAll()
 	case keyfun.Undo:
 		kt.SetProcessed()
 		sv.GridView.Undo()
@@ -145,9 +186,13 @@ func (sv *SVGView) SVGViewKeys(kt *key.ChordEvent) {
 		sv.GridView.CutSelected()
 	case keyfun.Paste:
 		kt.SetProcessed()
-		sv.GridView.PasteClip()
+		sv.GridView.Paste()
 	case keyfun.Delete, keyfun.Backspace:
 		kt.SetProcessed()
+		if es := sv.EditState(); es.Tool == NodeTool && len(es.PathSel) > 0 {
+			sv.GridView.DeleteSelNodes()
+			break
+		}
 		sv.GridView.DeleteSelected()
 	}
 	if kt.IsProcessed() {
@@ -179,6 +224,69 @@ func (sv *SVGView) SVGViewKeys(kt *key.ChordEvent) {
 	case "t", "Shift+T":
 		kt.SetProcessed()
 		sv.GridView.SetTool(TextTool)
+	case "d", "Shift+D":
+		kt.SetProcessed()
+		sv.GridView.SetTool(EyedropperTool)
+	case "m", "Shift+M":
+		kt.SetProcessed()
+		sv.GridView.SetTool(MeasureTool)
+	case "p", "Shift+P":
+		kt.SetProcessed()
+		sv.GridView.SetTool(PencilTool)
+	case "h", "Shift+H", "v", "Shift+V":
+		es := sv.EditState()
+		if !es.HasSelected() || es.Tool != SelectTool {
+			break
+		}
+		kt.SetProcessed()
+		if kc == "h" || kc == "Shift+H" {
+			sv.FlipSelectionHoriz()
+		} else {
+			sv.FlipSelectionVert()
+		}
+	case "LeftArrow", "RightArrow", "UpArrow", "DownArrow", "Shift+LeftArrow", "Shift+RightArrow", "Shift+UpArrow", "Shift+DownArrow":
+		es := sv.EditState()
+		if !es.HasSelected() || es.Tool != SelectTool {
+			break
+		}
+		kt.SetProcessed()
+		step := sv.Grid
+		if strings.HasPrefix(kc, "Shift+") {
+			step *= NudgeBigSteps
+		}
+		var dx, dy float32
+		switch strings.TrimPrefix(kc, "Shift+") {
+		case "LeftArrow":
+			dx = -step
+		case "RightArrow":
+			dx = step
+		case "UpArrow":
+			dy = -step
+		case "DownArrow":
+			dy = step
+		}
+		sv.GridView.NudgeSelection(dx, dy)
+	case "PageUp", "PageDown", "Home", "End":
+		if !sv.EditState().HasSelected() {
+			break
+		}
+		kt.SetProcessed()
+		switch kc {
+		case "PageUp":
+			sv.GridView.RaiseSelection()
+		case "PageDown":
+			sv.GridView.LowerSelection()
+		case "Home":
+			sv.GridView.RaiseToTop()
+		case "End":
+			sv.GridView.LowerToBottom()
+		}
+	case "[":
+		kt.SetProcessed()
+		sv.GridView.CycleGridPreset(false)
+	case "]":
+		kt.SetProcessed()
+		sv.GridView.CycleGridPreset(true)
 	}
 }
 
@@ -217,7 +325,18 @@ func (sv *SVGView) MouseScroll() {
 			oswin.TheApp.Cursor(ssvg.ParentWindow().OSWin).Pop()
 			ssvg.SetDragCursor = false
 		}
-		delta := float32(me.NonZeroDelta(false)) / 50
+		sign := float32(1)
+		if Prefs.InvertScroll {
+			sign = -1
+		}
+		if Prefs.ScrollPan && !me.HasAnyModifier(key.Control) {
+			dv := mat32.NewVec2FmPoint(me.Delta).MulScalar(sign / ssvg.Scale)
+			ssvg.Trans.SetSub(dv)
+			ssvg.SetTransform()
+			ssvg.UpdateView(true)
+			return
+		}
+		delta := sign * float32(me.NonZeroDelta(false)) / 50
 		sv.ZoomAt(me.Where, delta)
 		// ssvg.InitScale()
 		// ssvg.Scale +=
@@ -238,6 +357,41 @@ func (sv *SVGView) MouseEvent() {
 			oswin.TheApp.Cursor(ssvg.ParentWindow().OSWin).Pop()
 			ssvg.SetDragCursor = false
 		}
+		if me.Action == mouse.DoubleClick && me.Button == mouse.Left && es.Tool == NodeTool && es.ActivePath != nil {
+			if si, t, ok := ssvg.PathSegAt(es.ActivePath, me.Where); ok {
+				me.SetProcessed()
+				ssvg.GridView.InsertNode(si, t)
+				return
+			}
+		}
+		if me.Action == mouse.DoubleClick && me.Button == mouse.Left && es.Tool == SelectTool {
+			if gp, isgp := ssvg.SelectContainsPoint(me.Where, false, false).(*svg.Group); isgp {
+				me.SetProcessed()
+				ssvg.GridView.IsolateGroup(gp)
+				return
+			}
+		}
+		if es.Tool == EyedropperTool {
+			if me.Action == mouse.Press && me.Button == mouse.Left {
+				me.SetProcessed()
+				ssvg.GridView.PickColor(me.Where, me.HasAnyModifier(key.Shift))
+			}
+			return
+		}
+		if es.Tool == MeasureTool {
+			me.SetProcessed()
+			if me.Action == mouse.Release {
+				ssvg.MeasureDone()
+			}
+			return
+		}
+		if es.Tool == PencilTool {
+			me.SetProcessed()
+			if me.Action == mouse.Release {
+				ssvg.PencilDone()
+			}
+			return
+		}
 		sob := ssvg.SelectContainsPoint(me.Where, false, true) // not leavesonly, yes exclude existing sels
 		if me.Action == mouse.Press && me.Button == mouse.Left {
 			me.SetProcessed()
@@ -256,6 +410,8 @@ func (sv *SVGView) MouseEvent() {
 				es.SelectAction(sob, mouse.SelectOne, me.Where)
 				ssvg.EditState().DragSelStart(me.Where)
 				ssvg.UpdateNodeSprites()
+			case sob == nil && es.Tool == SelectTool && me.HasAnyModifier(key.Alt, key.Control, key.Meta):
+				// keep the selection, to add to or remove from by box select
 			case sob == nil:
 				es.ResetSelected()
 				ssvg.UpdateSelect()
@@ -315,9 +471,24 @@ func (sv *SVGView) MouseHover() {
 	})
 }
 
+// MouseMove follows the mouse position with the ruler marks
+func (sv *SVGView) MouseMove() {
+	sv.ConnectEvent(oswin.MouseMoveEvent, gi.RegPri, func(recv, send ki.Ki, sig int64, d any) {
+		me := d.(*mouse.MoveEvent)
+		ssvg := recv.Embed(KiT_SVGView).(*SVGView)
+		win := ssvg.GridView.ParentWindow()
+		if win == nil || !Prefs.Rulers {
+			return
+		}
+		ssvg.UpdateRulerMarks(win, me.Where)
+		win.UpdateSig()
+	})
+}
+
 // DragEvent processes a mouse drag event on the SVG canvas
 func (sv *SVGView) DragEvent(me *mouse.DragEvent) {
 	win := sv.GridView.ParentWindow()
+	sv.UpdateRulerMarks(win, me.Where)
 	delta := me.Where.Sub(me.From)
 	es := sv.EditState()
 	es.SelNoDrag = false
@@ -333,6 +504,19 @@ func (sv *SVGView) DragEvent(me *mouse.DragEvent) {
 		sv.UpdateView(true)
 		return
 	}
+	if es.Action == "BoxSelect" || sv.BoxSelectStarts(me) {
+		es.BoxSelMode = BoxSelectMode(me) // can change during the drag
+		sv.SetRubberBand(me.Where)
+		return
+	}
+	if es.Tool == MeasureTool {
+		sv.MeasureDrag(win, es.DragStartPos, me.Where)
+		return
+	}
+	if es.Tool == PencilTool {
+		sv.PencilDrag(win, es.DragStartPos, me.Where)
+		return
+	}
 	if es.HasSelected() {
 		if !es.NewTextMade {
 			sv.DragMove(win, me) // in manip
@@ -341,6 +525,7 @@ func (sv *SVGView) DragEvent(me *mouse.DragEvent) {
 		if !es.InAction() {
 			switch es.Tool {
 			case SelectTool:
+				es.BoxSelMode = BoxSelectMode(me)
 				sv.SetRubberBand(me.From)
 			case RectTool:
 				sv.NewElDrag(svg.KiT_Rect, es.DragStartPos, me.Where)
@@ -357,11 +542,6 @@ func (sv *SVGView) DragEvent(me *mouse.DragEvent) {
 			case BezierTool:
 				sv.NewPath(es.DragStartPos, me.Where)
 			}
-		} else {
-			switch {
-			case es.Action == "BoxSelect":
-				sv.SetRubberBand(me.Where)
-			}
 		}
 	}
 }
@@ -372,6 +552,7 @@ func (sv *SVGView) SVGViewEvents() {
 	sv.MouseScroll()
 	sv.MouseEvent()
 	sv.MouseHover()
+	sv.MouseMove()
 	sv.KeyChordEvent()
 }
 
@@ -417,6 +598,17 @@ func (sv *SVGView) ContentsBBox() mat32.Box2 {
 	return bbox
 }
 
+// DocBBox returns the bounding box of given node in document
+// (drawing) coordinates, with full floating point precision,
+// including all transforms of the node and its parents.
+func (sv *SVGView) DocBBox(sn svg.NodeSVG) mat32.Box2 {
+	xf := sn.AsSVGNode().ParTransform(true) // include self
+	bb := sn.SVGLocalBBox().MulMat2(xf)     // window coords relative to sv
+	bb.Min = bb.Min.DivScalar(sv.Scale).Sub(sv.Trans)
+	bb.Max = bb.Max.DivScalar(sv.Scale).Sub(sv.Trans)
+	return bb
+}
+
 // TransformAllLeaves transforms all the leaf items in the drawing (not groups)
 // uses ApplyDeltaTransform manipulation.
 func (sv *SVGView) TransformAllLeaves(trans mat32.Vec2, scale mat32.Vec2, rot float32, pt mat32.Vec2) {
@@ -447,7 +639,19 @@ func (sv *SVGView) TransformAllLeaves(trans mat32.Vec2, scale mat32.Vec2, rot fl
 	})
 }
 
-// ZoomToPage sets the scale to fit the current viewbox
+// CanvasPad returns the padding in screen pixels to leave around the page
+// or contents when zooming to fit, per Prefs.CanvasPad and CanvasPadFrac,
+// for a view of given size.  Returns 0 if the view is too small to pad.
+func CanvasPad(vsz mat32.Vec2) float32 {
+	pad := mat32.Max(float32(Prefs.CanvasPad), Prefs.CanvasPadFrac*mat32.Min(vsz.X, vsz.Y))
+	if vsz.X-2*pad <= 0 || vsz.Y-2*pad <= 0 {
+		return 0
+	}
+	return pad
+}
+
+// ZoomToPage sets the scale to fit the current viewbox, leaving
+// the CanvasPad padding around it
 func (sv *SVGView) ZoomToPage(width bool) {
 	vb := mat32.NewVec2FmPoint(sv.WinBBox.Size())
 	if vb.IsNil() {
@@ -457,17 +661,19 @@ func (sv *SVGView) ZoomToPage(width bool) {
 	if bsz.X <= 0 || bsz.Y <= 0 {
 		return
 	}
-	sc := vb.Div(bsz)
-	sv.Trans.Set(0, 0)
+	pad := CanvasPad(vb)
+	sc := vb.Sub(mat32.V2(2*pad, 2*pad)).Div(bsz)
 	if width {
 		sv.Scale = sc.X
 	} else {
 		sv.Scale = mat32.Min(sc.X, sc.Y)
 	}
+	sv.Trans.Set(pad/sv.Scale, pad/sv.Scale)
 	sv.SetTransform()
 }
 
-// ZoomToContents sets the scale to fit the current contents into view
+// ZoomToContents sets the scale to fit the current contents into view,
+// leaving the CanvasPad padding around them
 func (sv *SVGView) ZoomToContents(width bool) {
 	vb := mat32.NewVec2FmPoint(sv.WinBBox.Size())
 	if vb.IsNil() {
@@ -480,13 +686,15 @@ func (sv *SVGView) ZoomToContents(width bool) {
 	if bsz.X <= 0 || bsz.Y <= 0 {
 		return
 	}
-	sc := vb.Div(bsz)
-	sv.Trans = bb.Min.DivScalar(sv.Scale).Negate()
+	pad := CanvasPad(vb)
+	sc := vb.Sub(mat32.V2(2*pad, 2*pad)).Div(bsz)
+	cmin := bb.Min.DivScalar(sv.Scale).Sub(sv.Trans) // contents min in drawing coords
 	if width {
 		sv.Scale *= sc.X
 	} else {
 		sv.Scale *= mat32.Min(sc.X, sc.Y)
 	}
+	sv.Trans = mat32.V2(pad, pad).DivScalar(sv.Scale).Sub(cmin)
 	sv.SetTransform()
 }
 
@@ -503,12 +711,14 @@ func (sv *SVGView) ResizeToContents(grid_off bool) {
 	if bsz.X <= 0 || bsz.Y <= 0 {
 		return
 	}
-	trans := bb.Min
+	// relative to page origin, excluding the CanvasPad offset
+	trans := bb.Min.Sub(sv.Trans.MulScalar(sv.Scale))
 	incr := sv.Grid * sv.Scale // our zoom factor
+	goff := sv.GridOffset.MulScalar(sv.Scale)
 	treff := trans
 	if grid_off {
-		treff.X = mat32.Floor(trans.X/incr) * incr
-		treff.Y = mat32.Floor(trans.Y/incr) * incr
+		treff.X = mat32.Floor((trans.X-goff.X)/incr)*incr + goff.X
+		treff.Y = mat32.Floor((trans.Y-goff.Y)/incr)*incr + goff.Y
 	}
 	bsz.SetAdd(trans.Sub(treff))
 	treff = treff.Negate()
@@ -551,6 +761,27 @@ func (sv *SVGView) SetTransform() {
 	sv.SetProp("transform", fmt.Sprintf("scale(%v,%v) translate(%v,%v)", sv.Scale, sv.Scale, sv.Trans.X, sv.Trans.Y))
 }
 
+// UnitsPerDoc returns the number of physical units of the drawing
+// (PhysWidth.Un, e.g., mm) per drawing (viewbox) unit
+func (sv *SVGView) UnitsPerDoc() float32 {
+	if sv.ViewBox.Size.X > 0 && sv.PhysWidth.Val > 0 {
+		return sv.PhysWidth.Val / sv.ViewBox.Size.X
+	}
+	return 1
+}
+
+// DocToWin returns the window coordinates of given point in drawing coordinates
+func (sv *SVGView) DocToWin(pt mat32.Vec2) mat32.Vec2 {
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	return pt.Add(sv.Trans).MulScalar(sv.Scale).Add(svoff)
+}
+
+// WinToDoc returns the drawing coordinates of given point in window coordinates
+func (sv *SVGView) WinToDoc(pt mat32.Vec2) mat32.Vec2 {
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	return pt.Sub(svoff).DivScalar(sv.Scale).Sub(sv.Trans)
+}
+
 // MetaData returns the overall metadata and grid if present.
 // if mknew is true, it will create new ones if not found.
 func (sv *SVGView) MetaData(mknew bool) (main, grid *gi.MetaData2D) {
@@ -592,6 +823,27 @@ func (sv *SVGView) SetMetaData() {
 	nv.SetProp("inkscape:cy", fmt.Sprintf("%g", sv.Trans.Y))
 	nv.SetProp("inkscape:zoom", fmt.Sprintf("%g", sv.Scale))
 	nv.SetProp("inkscape:document-units", uts)
+	nv.SetProp("grid:version", Version)
+	if len(es.NamedColors) > 0 {
+		nv.SetProp("grid:named-colors", es.NamedColors.String())
+	} else {
+		nv.DeleteProp("grid:named-colors")
+	}
+	if len(es.Guides) > 0 {
+		nv.SetProp("grid:guides", es.Guides.String())
+	} else {
+		nv.DeleteProp("grid:guides")
+	}
+	if es.Perspective.On() {
+		nv.SetProp("grid:perspective", es.Perspective.String())
+	} else {
+		nv.DeleteProp("grid:perspective")
+	}
+	if es.DocColors != nil {
+		nv.SetProp("grid:colors", es.DocColors.String())
+	} else {
+		nv.DeleteProp("grid:colors")
+	}
 
 	//	get rid of inkscape props we don't set
 	nv.DeleteProp("cx")
@@ -599,6 +851,11 @@ func (sv *SVGView) SetMetaData() {
 	nv.DeleteProp("zoom")
 	nv.DeleteProp("document-units")
 	nv.DeleteProp("current-layer")
+	nv.DeleteProp("named-colors")
+	nv.DeleteProp("guides")
+	nv.DeleteProp("perspective")
+	nv.DeleteProp("colors")
+	nv.DeleteProp("version")
 	nv.DeleteProp("objecttolerance")
 	nv.DeleteProp("guidetolerance")
 	nv.DeleteProp("gridtolerance")
@@ -613,6 +870,8 @@ func (sv *SVGView) SetMetaData() {
 	spc := fmt.Sprintf("%g", sv.Grid)
 	gr.SetProp("spacingx", spc)
 	gr.SetProp("spacingy", spc)
+	gr.SetProp("originx", fmt.Sprintf("%g", sv.GridOffset.X))
+	gr.SetProp("originy", fmt.Sprintf("%g", sv.GridOffset.Y))
 	gr.SetProp("type", "xygrid")
 	gr.SetProp("units", uts)
 }
@@ -620,6 +879,7 @@ func (sv *SVGView) SetMetaData() {
 // ReadMetaData reads meta data of drawing
 func (sv *SVGView) ReadMetaData() {
 	es := sv.EditState()
+	sv.GridOffset = mat32.Vec2{} // not that of a previous drawing
 	nv, gr := sv.MetaData(false)
 	if nv == nil {
 		return
@@ -639,6 +899,23 @@ func (sv *SVGView) ReadMetaData() {
 	if cl := nv.Prop("current-layer"); cl != nil {
 		es.CurLayer = kit.ToString(cl)
 	}
+	if nc := nv.Prop("named-colors"); nc != nil {
+		es.NamedColors.FromString(kit.ToString(nc))
+	}
+	if gd := nv.Prop("guides"); gd != nil {
+		es.Guides.FromString(kit.ToString(gd))
+	}
+	if ps := nv.Prop("perspective"); ps != nil {
+		es.Perspective.FromString(kit.ToString(ps))
+	}
+	if cs := nv.Prop("colors"); cs != nil {
+		es.DocColors = &ColorPrefs{}
+		es.DocColors.Defaults()
+		es.DocColors.FromString(kit.ToString(cs))
+	}
+	if vr := nv.Prop("version"); vr != nil {
+		es.FileVersion = kit.ToString(vr)
+	}
 
 	if gr == nil {
 		return
@@ -649,6 +926,33 @@ func (sv *SVGView) ReadMetaData() {
 			sv.Grid = gv
 		}
 	}
+	if ox := gr.Prop("originx"); ox != nil {
+		sv.GridOffset.X, _ = kit.ToFloat32(ox)
+	}
+	if oy := gr.Prop("originy"); oy != nil {
+		sv.GridOffset.Y, _ = kit.ToFloat32(oy)
+	}
+}
+
+// VersionNewer returns true if version string a (e.g., v0.5.5) is
+// newer than version string b, comparing numeric components in order.
+// Non-numeric suffixes (e.g., -dev) are ignored.
+func VersionNewer(a, b string) bool {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var va, vb int
+		if i < len(pa) {
+			fmt.Sscanf(pa[i], "%d", &va)
+		}
+		if i < len(pb) {
+			fmt.Sscanf(pb[i], "%d", &vb)
+		}
+		if va != vb {
+			return va > vb
+		}
+	}
+	return false
 }
 
 ///////////////////////////////////////////////////////////////////////////
@@ -667,6 +971,11 @@ func (sv *SVGView) MakeNodeContextMenu(m *gi.Menu, kn ki.Ki) {
 	m.AddAction(gi.ActOpts{Label: "Select in Tree"}, sv.This(), func(recv, send ki.Ki, sig int64, data any) {
 		sv.GridView.SelectNodeInTree(kn, mouse.SelectOne)
 	})
+	if gp, isgp := kn.(*svg.Group); isgp && !NodeIsLayer(kn) {
+		m.AddAction(gi.ActOpts{Label: "Enter Group"}, sv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			sv.GridView.IsolateGroup(gp)
+		})
+	}
 	m.AddSeparator("sep-clip")
 	m.AddAction(gi.ActOpts{Label: "Duplicate", ShortcutKey: keyfun.Duplicate}, sv.This(), func(recv, send ki.Ki, sig int64, data any) {
 		sv.GridView.DuplicateSelected()
@@ -678,7 +987,7 @@ func (sv *SVGView) MakeNodeContextMenu(m *gi.Menu, kn ki.Ki) {
 		sv.GridView.CutSelected()
 	})
 	m.AddAction(gi.ActOpts{Label: "Paste", ShortcutKey: keyfun.Paste}, sv.This(), func(recv, send ki.Ki, sig int64, data any) {
-		sv.GridView.PasteClip()
+		sv.GridView.Paste()
 	})
 }
 
@@ -703,13 +1012,10 @@ func (sv *SVGView) NodeContextMenu(kn ki.Ki, pos image.Point) {
 ///////////////////////////////////////////////////////////////////////////
 // Undo
 
-// UndoSave save current state for potential undo
-func (sv *SVGView) UndoSave(action, data string) {
-	es := sv.EditState()
-	if es == nil {
-		return
-	}
-	es.Changed = true
+// UndoState returns the current state of the drawing, for saving in the
+// undo records.  All of the states must be written the same way, as the
+// records are diffs of successive states.
+func (sv *SVGView) UndoState() []string {
 	b := &bytes.Buffer{}
 	// sv.WriteXML(b, false)
 	err := sv.WriteJSON(b, true) // should be false
@@ -717,54 +1023,64 @@ func (sv *SVGView) UndoSave(action, data string) {
 		fmt.Printf("SaveUndo Error: %s\n", err)
 	}
 	// fmt.Printf("%s\n", string(b.Bytes()))
-	bs := strings.Split(string(b.Bytes()), "\n")
-	es.UndoMgr.Save(action, data, bs)
+	return strings.Split(string(b.Bytes()), "\n")
+}
+
+// UndoSave save current state for potential undo
+func (sv *SVGView) UndoSave(action, data string) {
+	es := sv.EditState()
+	if es == nil {
+		return
+	}
+	es.Changed = true
+	es.PathOp = "" // any other action ends a live path operation
+	es.PathOpOrig = nil
+	es.UndoMgr.Save(action, data, sv.UndoState())
 	// fmt.Println(es.UndoMgr.MemStats(true))
 }
 
 // UndoSaveReplace save current state to replace current
 func (sv *SVGView) UndoSaveReplace(action, data string) {
 	es := sv.EditState()
-	b := &bytes.Buffer{}
-	// sv.WriteXML(b, false)
-	err := sv.WriteJSON(b, true) // should be false
-	if err != nil {
-		fmt.Printf("SaveUndo Error: %s\n", err)
-	}
-	bs := strings.Split(string(b.Bytes()), "\n")
-	es.UndoMgr.SaveReplace(action, data, bs)
+	es.UndoMgr.SaveReplace(action, data, sv.UndoState())
 	// fmt.Println(es.UndoMgr.MemStats(true))
 }
 
-// Undo undoes one step, returning the action that was undone
-func (sv *SVGView) Undo() string {
+// UndoSaveStart saves the current state before undoing from the end of
+// the undo records, so the undone actions can be redone
+func (sv *SVGView) UndoSaveStart() {
 	es := sv.EditState()
-	es.ResetSelected()
 	if es.UndoMgr.MustSaveUndoStart() { // need to save current state!
-		b := &bytes.Buffer{}
-		// sv.WriteXML(b, false)
-		err := sv.WriteJSON(b, false)
-		if err != nil {
-			fmt.Printf("SaveUndo Error: %s\n", err)
-		}
-		bs := strings.Split(string(b.Bytes()), "\n")
-		es.UndoMgr.SaveUndoStart(bs)
+		es.UndoMgr.SaveUndoStart(sv.UndoState())
 	}
-	// fmt.Printf("undo idx: %d\n", es.UndoMgr.Idx)
-	act, _, state := es.UndoMgr.Undo()
+}
+
+// LoadUndoState restores the drawing to given state from the undo records
+func (sv *SVGView) LoadUndoState(state []string) {
 	if state == nil {
-		return act
+		return
 	}
 	sb := strings.Join(state, "\n")
 	b := bytes.NewBufferString(sb)
+	// sv.ReadXML(b)
 	updt := sv.UpdateStart()
-	err := sv.ReadJSON(b)
+	err := sv.ReadJSON(b) // json preserves all objects
 	_ = err
 	// if err != nil {
 	// 	fmt.Printf("Undo load Error: %s\n", err)
 	// }
 	sv.UpdateEnd(updt)
 	sv.UpdateSelect()
+}
+
+// Undo undoes one step, returning the action that was undone
+func (sv *SVGView) Undo() string {
+	es := sv.EditState()
+	es.ResetSelected()
+	sv.UndoSaveStart()
+	// fmt.Printf("undo idx: %d\n", es.UndoMgr.Idx)
+	act, _, state := es.UndoMgr.Undo()
+	sv.LoadUndoState(state)
 	return act
 }
 
@@ -774,20 +1090,7 @@ func (sv *SVGView) Redo() string {
 	es.ResetSelected()
 	// fmt.Printf("redo idx: %d\n", es.UndoMgr.Idx)
 	act, _, state := es.UndoMgr.Redo()
-	if state == nil {
-		return act
-	}
-	sb := strings.Join(state, "\n")
-	b := bytes.NewBufferString(sb)
-	// sv.ReadXML(b)
-	updt := sv.UpdateStart()
-	err := sv.ReadJSON(b) // json preserves all objects
-	_ = err
-	// if err != nil {
-	// 	fmt.Printf("Redo load Error: %s\n", err)
-	// }
-	sv.UpdateEnd(updt)
-	sv.UpdateSelect()
+	sv.LoadUndoState(state)
 	return act
 }
 
@@ -798,6 +1101,9 @@ func (sv *SVGView) Redo() string {
 // between BBox Min - Max.  typs are corresponding bounding box sources.
 func (sv *SVGView) ShowAlignMatches(pts []image.Rectangle, typs []BBoxPoints) {
 	win := sv.GridView.ParentWindow()
+	if win == nil {
+		return
+	}
 
 	sz := ints.MinInt(len(pts), 8)
 	for i := 0; i < sz; i++ {
@@ -832,6 +1138,17 @@ func (sv *SVGView) SetSVGName(el svg.NodeSVG) {
 	el.SetName(nwnm)
 }
 
+// SetSVGNameTree sets the names of given element and all of its
+// children to new unique names, as with SetSVGName -- for copies
+func (sv *SVGView) SetSVGNameTree(el svg.NodeSVG) {
+	el.FuncDownMeFirst(0, nil, func(k ki.Ki, level int, d any) bool {
+		if sn, issv := k.(svg.NodeSVG); issv {
+			sv.SetSVGName(sn)
+		}
+		return ki.Continue
+	})
+}
+
 // NewEl makes a new SVG element, giving it a new unique name.
 // Uses currently active layer if set.
 func (sv *SVGView) NewEl(typ reflect.Type) svg.NodeSVG {
@@ -866,14 +1183,17 @@ func (sv *SVGView) NewElDrag(typ reflect.Type, start, end image.Point) svg.NodeS
 	updt := sv.UpdateStart()
 	sv.SetFullReRender()
 	nr := sv.NewEl(typ)
+	es.SelectAction(nr, mouse.SelectOne, end)
+	sv.GatherAlignPoints() // snap to other elements and the page, here and in the reshape drag
 	xfi := sv.Pnt.Transform.Inverse()
 	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
-	pos := mat32.NewVec2FmPoint(start).Sub(svoff)
+	stpt := mat32.NewVec2FmPoint(start)
+	spt := sv.SnapPoint(stpt)
+	pos := spt.Sub(svoff)
 	nr.SetPos(xfi.MulVec2AsPt(pos))
 	// sz := dv.Abs().Max(mat32.NewVec2Scalar(minsz / 2))
-	sz := dv
+	sz := dv.Add(stpt.Sub(spt))
 	nr.SetSize(xfi.MulVec2AsVec(sz))
-	es.SelectAction(nr, mouse.SelectOne, end)
 	sv.UpdateEnd(updt)
 	sv.UpdateSelSprites()
 	es.DragSelStart(start)
@@ -1010,15 +1330,21 @@ func (sv *SVGView) Render2D() {
 		rs := &sv.Render
 		rs.PushTransform(sv.Pnt.Transform)
 		sv.Render2DChildren() // we must do children first, then us!
+		if es := sv.EditState(); es != nil {
+			if gp := es.IsolatedGroup(); gp != nil {
+				sv.RenderIsolated(gp)
+			}
+		}
 		sv.PopBounds()
 		rs.PopTransform()
 		sv.RenderViewport2D() // update our parent image
 		sv.ClearFlag(int(svg.Rendering))
+		sv.UpdateRulerSprites() // view size can change on any render
 	}
 }
 
 func (sv *SVGView) BgNeedsUpdate() bool {
-	updt := sv.EnsureBgSize() || (sv.Trans != sv.bgTrans) || (sv.Scale != sv.bgScale) || (sv.GridEff != sv.bgGridEff)
+	updt := sv.EnsureBgSize() || (sv.Trans != sv.bgTrans) || (sv.Scale != sv.bgScale) || (sv.GridEff != sv.bgGridEff) || (sv.GridOffset != sv.bgGridOffset) || (*sv.Colors() != sv.bgColors)
 	// fmt.Printf("updt: %v\n", updt)
 	return updt
 }
@@ -1051,12 +1377,37 @@ func (sv *SVGView) EnsureBgSize() bool {
 func (sv *SVGView) UpdateGridEff() {
 	sv.GridEff = sv.Grid
 	sp := sv.GridEff * sv.Scale
-	for sp <= 2*(float32(Prefs.SnapTol)+1) {
+	for sp <= 2*(SnapTolPx()+1) {
 		sv.GridEff *= 2
 		sp = sv.GridEff * sv.Scale
 	}
 }
 
+// CheckerSize is the size of checkerboard squares in drawing units
+// (scaled by the zoom level, subject to a minimum screen size)
+var CheckerSize = float32(8)
+
+// RenderCheckerboard renders a checkerboard pattern into the background,
+// to show the transparency of the background color.  Only drawn in the
+// view -- not part of the drawing.
+func (sv *SVGView) RenderCheckerboard() {
+	bb := sv.BgPixels.Bounds()
+	csz := int(mat32.Max(CheckerSize*sv.Scale, 4))
+	lt := image.Uniform{gist.Color{R: 255, G: 255, B: 255, A: 255}}
+	dk := image.Uniform{gist.Color{R: 204, G: 204, B: 204, A: 255}}
+	off := image.Point{int(sv.Trans.X*sv.Scale) % (2 * csz), int(sv.Trans.Y*sv.Scale) % (2 * csz)} // Trans is in drawing units
+	for y := bb.Min.Y - 2*csz + off.Y; y < bb.Max.Y; y += csz {
+		for x := bb.Min.X - 2*csz + off.X; x < bb.Max.X; x += csz {
+			clr := &lt
+			if ((x-off.X)/csz+(y-off.Y)/csz)%2 != 0 {
+				clr = &dk
+			}
+			r := image.Rect(x, y, x+csz, y+csz).Intersect(bb)
+			draw.Draw(sv.BgPixels, r, clr, image.ZP, draw.Src)
+		}
+	}
+}
+
 // RenderBg renders our background image
 func (sv *SVGView) RenderBg() {
 	rs := &sv.BgRender
@@ -1066,13 +1417,19 @@ func (sv *SVGView) RenderBg() {
 	sv.UpdateGridEff()
 
 	bb := sv.BgPixels.Bounds()
+	clrs := sv.Colors()
 
-	draw.Draw(sv.BgPixels, bb, &image.Uniform{Prefs.Colors.Background}, image.ZP, draw.Src)
+	if Prefs.Checkerboard {
+		sv.RenderCheckerboard()
+		draw.Draw(sv.BgPixels, bb, &image.Uniform{clrs.Background}, image.ZP, draw.Over)
+	} else {
+		draw.Draw(sv.BgPixels, bb, &image.Uniform{clrs.Background}, image.ZP, draw.Src)
+	}
 
 	rs.PushBounds(bb)
 	rs.PushTransform(sv.Pnt.Transform)
 
-	pc.StrokeStyle.SetColor(&Prefs.Colors.Border)
+	pc.StrokeStyle.SetColor(&clrs.Border)
 
 	sc := sv.Scale
 
@@ -1087,19 +1444,32 @@ func (sv *SVGView) RenderBg() {
 
 	if Prefs.GridDisp {
 		gsz := float32(sv.GridEff)
-		pc.StrokeStyle.SetColor(&Prefs.Colors.Grid)
-		for x := gsz; x < sz.X; x += gsz {
+		pc.StrokeStyle.SetColor(&clrs.Grid)
+		x0 := mat32.Mod(sv.GridOffset.X, gsz)
+		if x0 <= 0 {
+			x0 += gsz
+		}
+		for x := x0; x < sz.X; x += gsz {
 			pc.DrawLine(rs, x, 0, x, sz.Y)
 		}
-		for y := gsz; y < sz.Y; y += gsz {
+		y0 := mat32.Mod(sv.GridOffset.Y, gsz)
+		if y0 <= 0 {
+			y0 += gsz
+		}
+		for y := y0; y < sz.Y; y += gsz {
 			pc.DrawLine(rs, 0, y, sz.X, y)
 		}
 		pc.FillStrokeClear(rs)
 	}
 
+	sv.RenderGuides()
+	sv.RenderPerspective()
+
 	sv.bgTrans = sv.Trans
 	sv.bgScale = sv.Scale
 	sv.bgGridEff = sv.GridEff
+	sv.bgGridOffset = sv.GridOffset
+	sv.bgColors = *clrs
 
 	rs.PopTransform()
 	rs.PopBounds()
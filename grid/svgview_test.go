@@ -0,0 +1,83 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goki/gi/svg"
+	"github.com/goki/mat32"
+)
+
+// TestManipUndoRedo tests that undo restores the drawing to its state
+// before each kind of manipulation, and redo to its state after it
+func TestManipUndoRedo(t *testing.T) {
+	tests := []struct {
+		act string
+		id  string
+		op  func(sv *SVGView, sn svg.NodeSVG)
+	}{
+		{"Move", "rect1", func(sv *SVGView, sn svg.NodeSVG) {
+			sn.ApplyDeltaTransform(mat32.V2(30, -20), mat32.V2(1, 1), 0, mat32.Vec2{})
+		}},
+		{"Reshape", "rect1", func(sv *SVGView, sn svg.NodeSVG) {
+			sn.ApplyDeltaTransform(mat32.Vec2{}, mat32.V2(1.5, 0.5), 0, mat32.V2(600, 200))
+		}},
+		{"Rotate", "path1", func(sv *SVGView, sn svg.NodeSVG) {
+			sn.ApplyDeltaTransform(mat32.Vec2{}, mat32.V2(1, 1), mat32.DegToRad(30), mat32.V2(300, 150))
+		}},
+		{"NodeAdj", "path1", func(sv *SVGView, sn svg.NodeSVG) {
+			path := sn.(*svg.Path)
+			pns, _ := sv.PathNodes(path)
+			sv.PathNodeSetOnePoint(path, pns, 2, mat32.V2(20, 10), mat32.NewVec2FmPoint(sv.WinBBox.Min))
+		}},
+	}
+	for _, tt := range tests {
+		gv := openTestView(t, headlessTestSVG)
+		sv := gv.SVG()
+		es := &gv.EditState
+		sn := viewNode(t, sv, tt.id)
+		es.Select(sn)
+		before, _ := gv.SVGBytes()
+
+		sv.ManipStart(tt.act, es.SelectedNamesString())
+		tt.op(sv, sn)
+		sv.ManipDone()
+		sv.HeadlessRender()
+		after, _ := gv.SVGBytes()
+		if bytes.Equal(after, before) {
+			t.Errorf("%s: drawing not changed", tt.act)
+			continue
+		}
+
+		if act := sv.Undo(); act != tt.act {
+			t.Errorf("%s: undid %q", tt.act, act)
+		}
+		sv.HeadlessRender()
+		if got, _ := gv.SVGBytes(); !bytes.Equal(got, before) {
+			t.Errorf("%s: undo did not restore the drawing:\n%s\nwant:\n%s", tt.act, got, before)
+		}
+		if act := sv.Redo(); act != tt.act {
+			t.Errorf("%s: redid %q", tt.act, act)
+		}
+		sv.HeadlessRender()
+		if got, _ := gv.SVGBytes(); !bytes.Equal(got, after) {
+			t.Errorf("%s: redo did not restore the drawing:\n%s\nwant:\n%s", tt.act, got, after)
+		}
+	}
+}
+
+// TestReadMetaDataGridOffset tests that the grid offset of a previous
+// drawing is not kept for a drawing without one
+func TestReadMetaDataGridOffset(t *testing.T) {
+	gv := openTestView(t, lockTestSVG)
+	sv := gv.SVG()
+	sv.GridOffset = mat32.V2(5, 7)
+	sv.ReadMetaData()
+	if sv.GridOffset != (mat32.Vec2{}) {
+		t.Errorf("got grid offset %v, want 0", sv.GridOffset)
+	}
+}
@@ -6,6 +6,7 @@ package grid
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
@@ -49,6 +50,9 @@ type TextStyle struct {
 	// prop: text-align (inherited) = how to align text, horizontally. This *only* applies to the text within its containing element, and is typically relevant only for multi-line text: for single-line text, if element does not have a specified size that is different from the text size, then this has *no effect*.
 	Align gist.Align `xml:"text-align" inherit:"true"`
 
+	// prop: text-anchor (inherited) = alignment of text relative to its position: start, middle, end
+	Anchor gist.TextAnchors `xml:"text-anchor" inherit:"true"`
+
 	// font value view for font toolbar
 	FontVal giv.FontValueView `view:"-"`
 
@@ -75,6 +79,7 @@ func (ts *TextStyle) Defaults() {
 	ts.Deco = gist.TextDecorations(0)
 	ts.Shift = gist.BaselineShifts(0)
 	ts.Align = gist.AlignLeft
+	ts.Anchor = gist.AnchorStart
 
 	ts.SetFromFontStyle(&Prefs.TextStyle.FontStyle)
 }
@@ -90,15 +95,36 @@ func (ts *TextStyle) SetFromFontStyle(fs *gist.Font) {
 	ts.Shift = fs.Shift
 }
 
-// SetFromNode sets text style info from given svg.Text node
+// SetFromNode sets text style info from given svg.Text node.
+// For multi-run text (tspan children), the text and font style
+// come from the first run that has text, which is the one edited by SetText.
 func (ts *TextStyle) SetFromNode(txt *svg.Text) {
-	ts.Defaults()                            // always start fresh
-	if txt.Text == "" && txt.HasChildren() { // todo: multi-line text..
-		tspan := txt.Kids[0].(*svg.Text)
-		ts.Text = tspan.Text
+	ts.Defaults() // always start fresh
+	run := TextRunNode(txt)
+	if run == nil {
+		run = txt
 	}
-	ts.SetFromFontStyle(&txt.Pnt.FontStyle)
+	ts.Text = run.Text
+	ts.SetFromFontStyle(&run.Pnt.FontStyle)
 	ts.Align = txt.Pnt.TextStyle.Align
+	ts.Anchor = txt.Pnt.TextStyle.Anchor
+}
+
+// TextRunNode returns the first text run within given text node that
+// has text: either the node itself or one of its nested tspan runs.
+// Returns nil if none has text.
+func TextRunNode(txt *svg.Text) *svg.Text {
+	if txt.Text != "" {
+		return txt
+	}
+	for _, kid := range txt.Kids {
+		if tspan, ok := kid.(*svg.Text); ok {
+			if run := TextRunNode(tspan); run != nil {
+				return run
+			}
+		}
+	}
+	return nil
 }
 
 // SetTextPropsNode sets the text properties of given Text node
@@ -109,7 +135,7 @@ func (gv *GridView) SetTextPropsNode(sii svg.NodeSVG, tps map[string]string) {
 		}
 		return
 	}
-	_, istxt := sii.(*svg.Text)
+	txt, istxt := sii.(*svg.Text)
 	if !istxt {
 		return
 	}
@@ -121,6 +147,21 @@ func (gv *GridView) SetTextPropsNode(sii svg.NodeSVG, tps map[string]string) {
 			g.SetProp(k, v)
 		}
 	}
+	// runs that override a prop individually get the new value too,
+	// so the edit is visible while other per-run styling (fill etc) is preserved
+	for _, kid := range txt.Kids {
+		tspan, ok := kid.(*svg.Text)
+		if !ok {
+			continue
+		}
+		tps2 := make(map[string]string)
+		for k, v := range tps {
+			if tspan.Prop(k) != nil {
+				tps2[k] = v
+			}
+		}
+		gv.SetTextPropsNode(tspan, tps2)
+	}
 }
 
 // SetTextProps sets the text properties of selected Text nodes
@@ -168,11 +209,24 @@ func (ts *TextStyle) TextProps() map[string]string {
 		tps["baseline-shift"] = ""
 	}
 	tps["text-align"] = ts.Align.String()
+	if ts.Anchor != gist.AnchorStart {
+		tps["text-anchor"] = strings.ToLower(strings.TrimPrefix(ts.Anchor.String(), "Anchor"))
+	} else {
+		tps["text-anchor"] = ""
+	}
 	return tps
 }
 
-// SetTextNode sets the text of given Text node
+// SetTextNode sets the text of given Text node -- for multi-run text,
+// only the first run with text is set (see TextRunNode), preserving the
+// other runs and their styling
 func (gv *GridView) SetTextNode(sii svg.NodeSVG, txt string) bool {
+	if tn, istxt := sii.(*svg.Text); istxt {
+		if run := TextRunNode(tn); run != nil {
+			run.Text = txt
+			return true
+		}
+	}
 	if sii.HasChildren() {
 		for _, kid := range *sii.Children() {
 			if gv.SetTextNode(kid.(svg.NodeSVG), txt) {
@@ -258,6 +312,14 @@ func (gv *GridView) ConfigTextToolbar() {
 		ts.Update()
 	})
 
+	anc := gi.AddNewComboBox(tb, "anchor")
+	anc.Tooltip = "text-anchor: alignment of text relative to its position"
+	anc.ItemsFromEnum(gist.KiT_TextAnchors, false, 0)
+	anc.SetCurIndex(int(ts.Anchor))
+	anc.ComboSig.Connect(gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		ts.Anchor = gist.TextAnchors(anc.CurIndex)
+		ts.Update()
+	})
 }
 
 // UpdateTextToolbar updates the select toolbar based on current selection
@@ -278,4 +340,7 @@ func (gv *GridView) UpdateTextToolbar() {
 
 	fzu := tb.ChildByName("size-units", 0).(*gi.ComboBox)
 	fzu.SetCurIndex(int(ts.Size.Un))
+
+	anc := tb.ChildByName("anchor", 0).(*gi.ComboBox)
+	anc.SetCurIndex(int(ts.Anchor))
 }
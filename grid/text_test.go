@@ -0,0 +1,103 @@
+// Copyright (c) 2021, The Grid Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package grid
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/goki/gi/gist"
+	"github.com/goki/gi/svg"
+)
+
+func TestTextRunNode(t *testing.T) {
+	d := readTestDoc(t, "testdata/textruns.svg")
+	tests := []struct {
+		text string
+		run  string
+	}{
+		{"title", "run1"},
+		{"label", "em"},
+		{"run2", "run2"},
+		{"line1", "em"},
+	}
+	for _, tt := range tests {
+		txt := testNode(t, d, tt.text).(*svg.Text)
+		run := TextRunNode(txt)
+		if run == nil || run.Name() != tt.run {
+			t.Errorf("TextRunNode(%s) = %v, want %s", tt.text, run, tt.run)
+		}
+	}
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	files := []string{
+		"testdata/textruns.svg",
+		"../testdata/textrect.svg",
+		"../testdata/shapes.svg",
+		"../testdata/graphtxt.svg",
+	}
+	for _, fn := range files {
+		d := readTestDoc(t, fn)
+		want := docTexts(d)
+		if len(want) == 0 {
+			t.Errorf("%s: no text elements read", fn)
+			continue
+		}
+		got := docTexts(roundTripDoc(t, d))
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: text runs changed on round trip:\ngot:  %v\nwant: %v", fn, got, want)
+		}
+	}
+}
+
+func TestTextEditRuns(t *testing.T) {
+	d := readTestDoc(t, "testdata/textruns.svg")
+	gv := d.View
+	title := testNode(t, d, "title").(*svg.Text)
+
+	ts := &gv.EditState.Text
+	ts.SetFromNode(title)
+	if ts.Text != "Hello" {
+		t.Errorf("SetFromNode: Text = %q, want Hello", ts.Text)
+	}
+	if ts.Anchor != gist.AnchorMiddle {
+		t.Errorf("SetFromNode: Anchor = %v, want AnchorMiddle", ts.Anchor)
+	}
+
+	if !gv.SetTextNode(title, "Goodbye") {
+		t.Fatal("SetTextNode returned false")
+	}
+	d.Render()
+	want := docTexts(d)
+	if r1 := want["run1"]; r1[0] != "Goodbye" || r1[1] != "#ff0000" {
+		t.Errorf("edited run1 = %v, want Goodbye in #ff0000", r1)
+	}
+	if r2 := want["run2"]; r2[0] != "World" || r2[1] != "#0000ff" {
+		t.Errorf("other run2 = %v, want World in #0000ff", r2)
+	}
+	if got := docTexts(roundTripDoc(t, d)); !reflect.DeepEqual(got, want) {
+		t.Errorf("edited text runs changed on round trip:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTextPropsAnchor(t *testing.T) {
+	tests := []struct {
+		anchor gist.TextAnchors
+		prop   string
+	}{
+		{gist.AnchorStart, ""},
+		{gist.AnchorMiddle, "middle"},
+		{gist.AnchorEnd, "end"},
+	}
+	for _, tt := range tests {
+		ts := &TextStyle{}
+		ts.Defaults()
+		ts.Anchor = tt.anchor
+		if got := ts.TextProps()["text-anchor"]; got != tt.prop {
+			t.Errorf("%v: text-anchor = %q, want %q", tt.anchor, got, tt.prop)
+		}
+	}
+}
@@ -21,6 +21,9 @@ const (
 	EllipseTool
 	BezierTool
 	TextTool
+	EyedropperTool
+	MeasureTool
+	PencilTool
 	ToolsN
 )
 
@@ -33,7 +36,13 @@ func (ev *Tools) UnmarshalJSON(b []byte) error { return kit.EnumUnmarshalJSON(ev
 
 // ToolDoesBasicSelect returns true if tool should do select for clicks
 func ToolDoesBasicSelect(tl Tools) bool {
-	return tl != NodeTool
+	return tl != NodeTool && tl != EyedropperTool && tl != MeasureTool && tl != PencilTool
+}
+
+// ToolUsesCrossCursor returns true if tool uses a crosshair cursor,
+// for pointing at exact positions in the drawing
+func ToolUsesCrossCursor(tl Tools) bool {
+	return tl == EyedropperTool || tl == MeasureTool || tl == PencilTool
 }
 
 // SetTool sets the current active tool
@@ -64,6 +73,7 @@ func (gv *GridView) SetTool(tl Tools) {
 		}
 	}
 	es.ResetSelected()
+	gv.SetToolCursor(es.Tool, tl)
 	gv.EditState.Tool = tl
 	gv.SetDefaultStyle()
 	gv.SetModalToolbar()
@@ -124,6 +134,21 @@ func (gv *GridView) ConfigTools() {
 			grr := recv.Embed(KiT_GridView).(*GridView)
 			grr.SetTool(TextTool)
 		})
+	tb.AddAction(gi.ActOpts{Label: "D", Icon: "tool-eyedropper", Tooltip: "D: pick the fill color from the drawing (Shift+click: stroke color)"},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetTool(EyedropperTool)
+		})
+	tb.AddAction(gi.ActOpts{Label: "M", Icon: "tool-measure", Tooltip: "M: measure the distance and angle between two points, by dragging from one to the other"},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetTool(MeasureTool)
+		})
+	tb.AddAction(gi.ActOpts{Label: "P", Icon: "tool-pencil", Tooltip: "P: draw freehand, making a smooth curve from the points drawn (see PencilTol in Preferences)"},
+		gv.This(), func(recv, send ki.Ki, sig int64, data any) {
+			grr := recv.Embed(KiT_GridView).(*GridView)
+			grr.SetTool(PencilTool)
+		})
 
 	gv.SetTool(SelectTool)
 }
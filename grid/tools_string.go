@@ -19,12 +19,15 @@ func _() {
 	_ = x[EllipseTool-3]
 	_ = x[BezierTool-4]
 	_ = x[TextTool-5]
-	_ = x[ToolsN-6]
+	_ = x[EyedropperTool-6]
+	_ = x[MeasureTool-7]
+	_ = x[PencilTool-8]
+	_ = x[ToolsN-9]
 }
 
-const _Tools_name = "SelectToolNodeToolRectToolEllipseToolBezierToolTextToolToolsN"
+const _Tools_name = "SelectToolNodeToolRectToolEllipseToolBezierToolTextToolEyedropperToolMeasureToolPencilToolToolsN"
 
-var _Tools_index = [...]uint8{0, 10, 18, 26, 37, 47, 55, 61}
+var _Tools_index = [...]uint8{0, 10, 18, 26, 37, 47, 55, 69, 80, 90, 96}
 
 func (i Tools) String() string {
 	if i < 0 || i >= Tools(len(_Tools_index)-1) {
@@ -10,14 +10,11 @@ import (
 	"github.com/goki/gi/gi"
 	"github.com/goki/gi/gist"
 	"github.com/goki/gi/giv"
-	"github.com/goki/gi/oswin"
-	"github.com/goki/gi/oswin/dnd"
 	"github.com/goki/gi/oswin/mouse"
 	"github.com/goki/gi/svg"
 	"github.com/goki/gi/units"
 	"github.com/goki/ki/ki"
 	"github.com/goki/ki/kit"
-	"github.com/goki/pi/filecat"
 	"goki.dev/gi/v2/keyfun"
 )
 
@@ -53,7 +50,8 @@ func (gv *GridView) SelectNodeInTree(kn ki.Ki, mode mouse.SelectModes) {
 	}
 }
 
-// SelectedAsTreeViews returns the currently-selected items from SVG as TreeView nodes
+// SelectedAsTreeViews returns the currently-selected items from SVG as
+// TreeView nodes, skipping locked items, which cannot be deleted
 func (gv *GridView) SelectedAsTreeViews() []*giv.TreeView {
 	es := &gv.EditState
 	sl := es.SelectedList(false)
@@ -63,6 +61,9 @@ func (gv *GridView) SelectedAsTreeViews() []*giv.TreeView {
 	tv := gv.TreeView()
 	var tvl []*giv.TreeView
 	for _, si := range sl {
+		if NodeIsLocked(si) {
+			continue
+		}
 		tvn := tv.FindSrcNode(si.This())
 		if tvn != nil {
 			tvl = append(tvl, tvn)
@@ -71,98 +72,44 @@ func (gv *GridView) SelectedAsTreeViews() []*giv.TreeView {
 	return tvl
 }
 
-// DuplicateSelected duplicates selected items in SVG view, using TreeView methods
+// DuplicateSelected duplicates selected items in SVG view, inserting
+// a copy of each (including all children of groups) just after it,
+// offset by PasteOffset, and selecting the copies
 func (gv *GridView) DuplicateSelected() {
-	tvl := gv.SelectedAsTreeViews()
-	if len(tvl) == 0 {
-		gv.SetStatus("Duplicate: no tree items found")
-		return
-	}
-	sv := gv.SVG()
-	sv.UndoSave("DuplicateSelected", "")
-	updt := sv.UpdateStart()
-	sv.SetFullReRender()
-	tv := gv.TreeView()
-	tvupdt := tv.UpdateStart()
-	tv.SetFullReRender()
-	for _, tvi := range tvl {
-		tvi.SrcDuplicate()
-	}
-	gv.SetStatus("Duplicated selected items")
-	tv.ReSync() // todo: should not be needed
-	tv.UpdateEnd(tvupdt)
-	sv.UpdateEnd(updt)
-	gv.ChangeMade()
-}
-
-// CopySelected copies selected items in SVG view, using TreeView methods
-func (gv *GridView) CopySelected() {
-	tvl := gv.SelectedAsTreeViews()
-	if len(tvl) == 0 {
-		gv.SetStatus("Copy: no tree items found")
-		return
-	}
-	tv := gv.TreeView()
-	tv.SetSelectedViews(tvl)
-	tvl[0].Copy(true) // operates on first element in selection
-	gv.SetStatus("Copied selected items")
-}
-
-// CutSelected cuts selected items in SVG view, using TreeView methods
-func (gv *GridView) CutSelected() {
-	tvl := gv.SelectedAsTreeViews()
-	if len(tvl) == 0 {
-		gv.SetStatus("Cut: no tree items found")
-		return
-	}
-	sv := gv.SVG()
-	sv.UndoSave("CutSelected", "")
-	updt := sv.UpdateStart()
-	sv.SetFullReRender()
-	sv.EditState().ResetSelected()
-	tv := gv.TreeView()
-	tvupdt := tv.UpdateStart()
-	tv.SetFullReRender()
-	tv.SetSelectedViews(tvl)
-	tvl[0].Cut() // operates on first element in selection
-	gv.SetStatus("Cut selected items")
-	tv.ReSync() // todo: should not be needed
-	tv.UpdateEnd(tvupdt)
-	sv.UpdateEnd(updt)
-	sv.UpdateSelSprites()
-	gv.ChangeMade()
-}
-
-// PasteClip pastes clipboard, using cur layer etc
-func (gv *GridView) PasteClip() {
-	md := oswin.TheApp.Clipboard(gv.ParentWindow().OSWin).Read([]string{filecat.DataJson})
-	if md == nil {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		gv.SetStatus("Duplicate: nothing selected")
 		return
 	}
-	es := &gv.EditState
 	sv := gv.SVG()
-	sv.UndoSave("Paste", "")
+	sl := es.SelectedListDepth(sv, false)
+	sv.UndoSave("DuplicateSelected", es.SelectedNamesString())
 	updt := sv.UpdateStart()
 	sv.SetFullReRender()
-	tv := gv.TreeView()
-	tvupdt := tv.UpdateStart()
-	tv.SetFullReRender()
-	par := tv
-	if es.CurLayer != "" {
-		ly := tv.ChildByName("tv_"+es.CurLayer, 1)
-		if ly != nil {
-			par = ly.Embed(KiT_TreeView).(*TreeView)
+	es.ResetSelected()
+	for _, sn := range sl {
+		par := sn.Parent()
+		idx, ok := sn.IndexInParent()
+		if par == nil || !ok {
+			continue
 		}
+		nw := sn.Clone().(svg.NodeSVG)
+		par.SetChildAdded()
+		par.InsertChild(nw, idx+1)
+		sv.SetSVGNameTree(nw)
+		sv.OffsetCopy(nw, PasteOffset)
+		es.Select(nw)
 	}
-	par.PasteChildren(md, dnd.DropCopy)
-	gv.SetStatus("Pasted items from clipboard")
-	tv.ReSync() // todo: should not be needed
-	tv.UpdateEnd(tvupdt)
+	gv.SetStatus("Duplicated selected items")
+	gv.UpdateTreeView()
 	sv.UpdateEnd(updt)
+	sv.UpdateView(true)
+	sv.UpdateSelect()
 	gv.ChangeMade()
 }
 
 // DeleteSelected deletes selected items in SVG view, using TreeView methods
+// -- locked items are left in place
 func (gv *GridView) DeleteSelected() {
 	tvl := gv.SelectedAsTreeViews()
 	if len(tvl) == 0 {
@@ -424,6 +371,9 @@ func (tv *TreeView) Style2D() {
 			// todo: visibility and locked flags
 		} else {
 			tv.AddClass("svgnode")
+			if NodeIsLocked(sn) {
+				tv.AddClass("locked")
+			}
 			switch sn.(type) {
 			case *svg.Circle:
 				tv.Icon = gi.IconName("circlebutton-off")
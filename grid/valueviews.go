@@ -41,6 +41,13 @@ func PrefsView(pf *Preferences) *gi.Window {
 	sv.SetStruct(pf)
 	sv.SetStretchMaxWidth()
 	sv.SetStretchMaxHeight()
+	clrs := pf.Colors
+	sv.ViewSig.Connect(mfr.This(), func(recv, send ki.Ki, sig int64, data any) {
+		if pf.Colors != clrs { // show color edits in open drawings right away
+			clrs = pf.Colors
+			pf.ApplyAllColors()
+		}
+	})
 
 	mmen := win.MainMenu
 	giv.MainMenuView(pf, win, mmen)
@@ -242,3 +249,78 @@ func (vv *SplitValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.
 		})
 
 }
+
+////////////////////////////////////////////////////////////////////////////////////////
+//  UserSizeValueView
+
+// ValueView registers UserSizeValueView as the viewer of UserSizeName
+func (kn UserSizeName) ValueView() giv.ValueView {
+	vv := &UserSizeValueView{}
+	ki.InitNode(vv)
+	return vv
+}
+
+// UserSizeValueView presents an action for displaying a UserSizeName and
+// selecting from the UserSizes in the preferences
+type UserSizeValueView struct {
+	giv.ValueViewBase
+}
+
+var KiT_UserSizeValueView = kit.Types.AddType(&UserSizeValueView{}, nil)
+
+func (vv *UserSizeValueView) WidgetType() reflect.Type {
+	vv.WidgetTyp = gi.KiT_Action
+	return vv.WidgetTyp
+}
+
+func (vv *UserSizeValueView) UpdateWidget() {
+	if vv.Widget == nil {
+		return
+	}
+	ac := vv.Widget.(*gi.Button)
+	txt := kit.ToString(vv.Value.Interface())
+	if txt == "" {
+		txt = "(none)"
+	}
+	ac.SetText(txt)
+}
+
+func (vv *UserSizeValueView) ConfigWidget(widg gi.Node2D) {
+	vv.Widget = widg
+	ac := vv.Widget.(*gi.Button)
+	ac.SetProp("border-radius", units.NewValue(4, units.Px))
+	ac.ActionSig.ConnectOnly(vv.This(), func(recv, send ki.Ki, sig int64, data any) {
+		vvv, _ := recv.Embed(KiT_UserSizeValueView).(*UserSizeValueView)
+		ac := vvv.Widget.(*gi.Button)
+		vvv.Activate(ac.Viewport, nil, nil)
+	})
+	vv.UpdateWidget()
+}
+
+func (vv *UserSizeValueView) HasAction() bool {
+	return true
+}
+
+func (vv *UserSizeValueView) Activate(vp *gi.Viewport2D, dlgRecv ki.Ki, dlgFunc ki.RecvFunc) {
+	if vv.IsInactive() {
+		return
+	}
+	cur := kit.ToString(vv.Value.Interface())
+	if cur == "" {
+		cur = "(none)"
+	}
+	nms := append([]string{"(none)"}, Prefs.UserSizeNames()...)
+	ac := vv.Widget.(*gi.Button)
+	gi.StringsChooserPopup(nms, cur, ac, func(recv, send ki.Ki, sig int64, data any) {
+		sac := send.(*gi.Action)
+		nm := sac.Text
+		if nm == "(none)" {
+			nm = ""
+		}
+		vv.SetValue(UserSizeName(nm))
+		vv.UpdateWidget()
+		if dlgRecv != nil && dlgFunc != nil {
+			dlgFunc(dlgRecv, send, int64(gi.DialogAccepted), data)
+		}
+	})
+}
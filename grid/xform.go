@@ -3,3 +3,93 @@
 // license that can be found in the LICENSE file.
 
 package grid
+
+import (
+	"fmt"
+
+	"github.com/goki/gi/gi"
+	"github.com/goki/gi/giv"
+	"github.com/goki/gi/svg"
+	"github.com/goki/ki/ki"
+	"github.com/goki/mat32"
+)
+
+// TransformParams are the parameters for transforming the selection
+// by exact amounts, about the center of the selection
+type TransformParams struct {
+
+	// scale factors, horizontally and vertically -- negative to flip
+	Scale mat32.Vec2
+
+	// rotation in degrees, clockwise
+	Rotate float32
+
+	// amount to move, horizontally and vertically, in drawing units
+	Translate mat32.Vec2
+}
+
+// Defaults sets the identity transform
+func (tp *TransformParams) Defaults() {
+	tp.Scale.Set(1, 1)
+	tp.Rotate = 0
+	tp.Translate = mat32.Vec2{}
+}
+
+// IsIdentity returns true if the params do not change anything
+func (tp *TransformParams) IsIdentity() bool {
+	return tp.Scale == mat32.V2(1, 1) && tp.Rotate == 0 && tp.Translate == mat32.Vec2{}
+}
+
+// String returns a summary of the params, for undo
+func (tp *TransformParams) String() string {
+	return fmt.Sprintf("scale: %g,%g rotate: %g translate: %g,%g", tp.Scale.X, tp.Scale.Y, tp.Rotate, tp.Translate.X, tp.Translate.Y)
+}
+
+// SelTransform scales and rotates the selected items by given params,
+// about the center of the overall selection bounding box, and then
+// moves them, as a single undoable manipulation
+func (gv *GridView) SelTransform(tp *TransformParams) {
+	es := &gv.EditState
+	if !es.HasSelectedUnlocked() || tp.IsIdentity() {
+		return
+	}
+	if tp.Scale.X == 0 || tp.Scale.Y == 0 {
+		gv.SetStatus("Transform: scale factors cannot be 0")
+		return
+	}
+	sv := gv.SVG()
+	sv.ManipStart("Transform", tp.String())
+	es.UpdateSelBBox()
+	svoff := mat32.NewVec2FmPoint(sv.WinBBox.Min)
+	ctr := es.SelBBox.Min.Add(es.SelBBox.Max).MulScalar(.5).Sub(svoff)
+	del := tp.Translate.MulScalar(sv.Scale)
+	rot := mat32.DegToRad(tp.Rotate)
+	for itm := range es.Selected {
+		if NodeIsLocked(itm) {
+			continue
+		}
+		itm.ApplyDeltaTransform(del, tp.Scale, rot, ctr)
+		svg.UpdateNodeGradientPoints(itm, "fill")
+		svg.UpdateNodeGradientPoints(itm, "stroke")
+	}
+	sv.ManipDone()
+}
+
+// PromptTransform prompts for the scale, rotation and translation to
+// apply to the selection, about its center (see SelTransform)
+func (gv *GridView) PromptTransform() {
+	es := &gv.EditState
+	if !es.HasSelected() {
+		gv.SetStatus("Transform: nothing selected")
+		return
+	}
+	tp := &TransformParams{}
+	tp.Defaults()
+	giv.StructViewDialog(gv.Viewport, tp, giv.DlgOpts{Title: "Transform Selected", Prompt: "Scale and rotate the selection by exact amounts about its center, and then move it", Ok: true, Cancel: true}, gv.This(),
+		func(recv, send ki.Ki, sig int64, d any) {
+			if sig != int64(gi.DialogAccepted) {
+				return
+			}
+			gv.SelTransform(tp)
+		})
+}